@@ -0,0 +1,86 @@
+// Command libmarky builds the converters as a C shared library, so that
+// programs in other languages can convert documents in-process:
+//
+//	go build -buildmode=c-shared -o libmarky.so ./cmd/libmarky
+//
+// The build writes libmarky.h next to the library, declaring:
+//
+//	char* marky_convert(char* path, char* options, char** err);
+//	void marky_free(void* p);
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"encoding/json"
+	"fmt"
+	"unsafe"
+
+	"github.com/flaviodelgrosso/marky"
+)
+
+// convertOptions are the conversion options passed to marky_convert as a JSON
+// object. Missing fields keep their defaults.
+type convertOptions struct {
+	HeadingOffset   int  `json:"heading_offset"`
+	MaxHeadingDepth int  `json:"max_heading_depth"`
+	PrettyTables    bool `json:"pretty_tables"`
+	HTMLTables      bool `json:"html_tables"`
+	MaxCellWidth    int  `json:"max_cell_width"`
+}
+
+// marky_convert converts the document or http(s) URL at path to markdown.
+// options is a JSON object of conversion options, or NULL. It returns the
+// markdown, or NULL and sets *err to the error message when the conversion
+// fails. Strings returned are released with marky_free.
+//
+//export marky_convert
+func marky_convert(path, options *C.char, err **C.char) *C.char {
+	markdown, e := convert(C.GoString(path), options)
+	if e != nil {
+		if err != nil {
+			*err = C.CString(e.Error())
+		}
+		return nil
+	}
+	if err != nil {
+		*err = nil
+	}
+	return C.CString(markdown)
+}
+
+// marky_free releases a string returned by marky_convert.
+//
+//export marky_free
+func marky_free(p unsafe.Pointer) {
+	C.free(p)
+}
+
+func convert(path string, options *C.char) (string, error) {
+	var o convertOptions
+	if options != nil {
+		if err := json.Unmarshal([]byte(C.GoString(options)), &o); err != nil {
+			return "", fmt.Errorf("invalid options: %w", err)
+		}
+	}
+
+	settings := []marky.Option{marky.WithMaxCellWidth(o.MaxCellWidth)}
+	if o.PrettyTables {
+		settings = append(settings, marky.WithTableStyle(marky.TablePretty))
+	}
+	if o.HTMLTables {
+		settings = append(settings, marky.WithHTMLTableFallback(120))
+	}
+
+	settings = append(settings, marky.WithHeadingLevels(o.HeadingOffset, o.MaxHeadingDepth))
+	m, err := marky.New(settings...)
+	if err != nil {
+		return "", err
+	}
+	return m.Convert(path)
+}
+
+func main() {}
@@ -0,0 +1,96 @@
+//go:build js && wasm
+
+// Command marky-wasm exposes the converters to JavaScript when built for
+// WebAssembly. It registers a global markyConvert function, wrapped by
+// marky.js, taking the bytes and name of a document and returning a promise
+// of its markdown and front matter metadata.
+package main
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall/js"
+
+	"github.com/flaviodelgrosso/marky"
+	"github.com/flaviodelgrosso/marky/internal/utils"
+)
+
+func main() {
+	js.Global().Set("markyConvert", js.FuncOf(convert))
+	select {}
+}
+
+// convert converts the document in args[0], a Uint8Array, named args[1].
+// It returns a promise, so that long conversions do not block the caller.
+func convert(_ js.Value, args []js.Value) any {
+	if len(args) != 2 || args[0].Type() != js.TypeObject || args[1].Type() != js.TypeString {
+		return reject("expected the document bytes and name")
+	}
+	data := make([]byte, args[0].Get("length").Int())
+	js.CopyBytesToGo(data, args[0])
+	name := args[1].String()
+
+	return promise(func() (any, error) {
+		result, err := convertBytes(data, name)
+		if err != nil {
+			return nil, err
+		}
+
+		fields, markdown := utils.SplitFrontMatter(result)
+		return map[string]any{"markdown": markdown, "metadata": utils.FrontMatterMap(fields)}, nil
+	})
+}
+
+// promise returns a promise settled with the result of fn, run in a new
+// goroutine.
+func promise(fn func() (any, error)) js.Value {
+	var executor js.Func
+	executor = js.FuncOf(func(_ js.Value, args []js.Value) any {
+		resolve, rejectFn := args[0], args[1]
+		go func() {
+			defer executor.Release()
+			result, err := fn()
+			if err != nil {
+				rejectFn.Invoke(js.Global().Get("Error").New(err.Error()))
+				return
+			}
+			resolve.Invoke(result)
+		}()
+		return nil
+	})
+	return js.Global().Get("Promise").New(executor)
+}
+
+// reject returns a promise rejected with an error.
+func reject(message string) js.Value {
+	return js.Global().Get("Promise").Call("reject", js.Global().Get("Error").New(message))
+}
+
+// convertBytes converts a document held in memory with the converter of the
+// extension of its name, or of the type detected from its content when no
+// converter accepts the extension. No file is read or written, as browsers
+// have no file system.
+func convertBytes(data []byte, name string) (string, error) {
+	m, err := marky.New(marky.WithInMemory())
+	if err != nil {
+		return "", err
+	}
+	return m.ConvertBytes(data, mimeTypeOf(m, name))
+}
+
+// mimeTypeOf returns the first MIME type of the converter accepting the
+// extension of name, or an empty string when there is none.
+func mimeTypeOf(m *marky.Marky, name string) string {
+	extension := strings.ToLower(filepath.Ext(name))
+	if extension == "" {
+		return ""
+	}
+	for _, r := range m.Registrations() {
+		mimeTypes := r.Converter.AcceptedMimeTypes()
+		if slices.Contains(r.Converter.AcceptedExtensions(), extension) && len(mimeTypes) > 0 {
+			return mimeTypes[0]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,33 @@
+// marky.js wraps marky.wasm, the converters built for WebAssembly, for
+// browsers and Node. Load wasm_exec.js from the Go distribution first: it
+// defines the Go class running the module. Documents are converted from
+// memory, so no file system is needed.
+
+/**
+ * Instantiates marky.wasm and returns the converter.
+ *
+ * @param {BufferSource|Response|Promise<Response>} wasm the module bytes or
+ *   the response of fetching them
+ * @returns {Promise<{convert(bytes: Uint8Array, name: string): Promise<{markdown: string, metadata: Object}>}>}
+ */
+export async function load(wasm) {
+  const go = new Go();
+  const source = await wasm;
+  const { instance } =
+    typeof Response !== "undefined" && source instanceof Response
+      ? await WebAssembly.instantiateStreaming(source, go.importObject)
+      : await WebAssembly.instantiate(source, go.importObject);
+  go.run(instance);
+
+  return {
+    /**
+     * Converts a document to markdown. The name selects the converter by its
+     * extension; the content is inspected when it has none. The metadata
+     * holds the front matter of the document, such as its title.
+     */
+    async convert(bytes, name) {
+      const result = await globalThis.markyConvert(bytes, name);
+      return { markdown: result.markdown, metadata: result.metadata };
+    },
+  };
+}
@@ -1,35 +1,166 @@
 package main
 
 import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/flaviodelgrosso/marky"
+	"github.com/flaviodelgrosso/marky/internal/converters"
+	"github.com/flaviodelgrosso/marky/internal/server"
+	"github.com/flaviodelgrosso/marky/internal/utils"
+	"github.com/flaviodelgrosso/marky/internal/worker"
 	"github.com/spf13/cobra"
 )
 
 func main() {
-	var output string
+	var output, chaptersDir, spoolDir, format, marker, splitBy, password string
+	var prettyTables, htmlTables, preview, formats, provenance, meta, partial, frontMatter, asJSON, progress bool
+	var maxCellWidth, headingOffset, maxHeadingDepth, attempts, summary int
+
+	// newMarky creates an instance converting with the conversion flags,
+	// shared by the commands.
+	newMarky := func() (*marky.Marky, error) {
+		options := []marky.Option{
+			marky.WithHeadingLevels(headingOffset, maxHeadingDepth),
+			marky.WithFrontMatter(frontMatter),
+		}
+		if prettyTables {
+			options = append(options, marky.WithTableStyle(marky.TablePretty))
+		}
+		if maxCellWidth > 0 {
+			options = append(options, marky.WithMaxCellWidth(maxCellWidth))
+		}
+		if htmlTables {
+			options = append(options, marky.WithHTMLTableFallback(120))
+		}
+		switch marker {
+		case "heading":
+			marker = marky.MarkerHeading
+		case "rule":
+			marker = marky.MarkerRule
+		case "comment":
+			marker = marky.MarkerComment
+		}
+		if provenance || marker != "" || password != "" || partial {
+			paged := marky.ConvertOptions{Provenance: provenance, Marker: marker, Password: password, Partial: partial}
+			options = append(options, marky.WithFormatOptions(
+				marky.PDFOptions{ConvertOptions: paged},
+				marky.PPTXOptions{ConvertOptions: paged},
+				marky.ExcelOptions{Provenance: provenance, Marker: marker, Password: password, Partial: partial},
+				marky.DocxOptions{ConvertOptions: marky.ConvertOptions{Password: password}, Provenance: provenance},
+			))
+		}
+		if progress {
+			options = append(options, marky.WithProgress(printProgress))
+		}
+		if summary > 0 {
+			options = append(options, marky.WithSummarizer(marky.ExtractiveSummarizer{Sentences: summary}))
+		}
+		return marky.New(options...)
+	}
 
 	cmd := &cobra.Command{
-		Use:   "marky <inputfile> [--output <outputfile>]",
+		Use:   "marky [<inputfile|url>] [--output <outputfile>] [--spool <dir>]",
 		Short: "Convert files to markdown",
-		Args:  cobra.ExactArgs(1),
+		Args:  cobra.RangeArgs(0, 1),
 		RunE: func(_ *cobra.Command, args []string) error {
-			input := args[0]
+			if formats {
+				md, err := marky.New()
+				if err != nil {
+					return err
+				}
+				listFormats(md.Registrations())
+				return nil
+			}
+
+			var input string
+			switch {
+			case len(args) == 1:
+				input = args[0]
+				if err := checkInput(input); err != nil {
+					return err
+				}
+				if spoolDir != "" {
+					return enqueue(spoolDir, input, output)
+				}
+			case spoolDir == "":
+				return errors.New("an input file or URL is required")
+			}
+
+			md, err := newMarky()
+			if err != nil {
+				return err
+			}
+			switch format {
+			case "markdown":
+			case "text":
+				md.SetOutputFormat(marky.FormatText)
+			case "json":
+				md.SetOutputFormat(marky.FormatJSON)
+			case "html":
+				md.SetOutputFormat(marky.FormatHTML)
+			default:
+				return fmt.Errorf("unknown output format %q, want markdown, text, json or html", format)
+			}
+			if preview {
+				md.SetOutputFormat(marky.FormatHTML)
+			}
+
+			if input == "" {
+				return runWorker(spoolDir, md, attempts)
+			}
+			if meta {
+				result, err := md.ConvertWithResult(input)
+				if err != nil {
+					return fmt.Errorf("failed to convert file: %w", err)
+				}
+				return printMetadata(result, format == "json")
+			}
+			if asJSON {
+				result, err := md.ConvertWithResult(input)
+				if err != nil {
+					return fmt.Errorf("failed to convert file: %w", err)
+				}
+				return writeJSON(result, output)
+			}
+			if chaptersDir != "" {
+				return writeChapters(md, input, chaptersDir)
+			}
+			if splitBy != "" {
+				return writeSections(md.Convert, input, splitBy, output, format)
+			}
 
-			// Check if input file exists
-			if _, err := os.Stat(input); os.IsNotExist(err) {
-				return fmt.Errorf("input file does not exist: %s", input)
+			if output != "console" && !preview && !partial {
+				if err := writeOutput(md, input, output); err != nil {
+					return err
+				}
+				log.Printf("Content written to %s\n", output)
+				return nil
 			}
 
-			md := marky.New()
-			result, err := md.Convert(input)
+			result, err := convertPartial(md, input, partial)
 			if err != nil {
 				return fmt.Errorf("failed to convert file: %w", err)
 			}
 
+			if preview {
+				return openPreview(result)
+			}
+
 			if output == "console" {
 				log.Println(result)
 				return nil
@@ -43,10 +174,449 @@ func main() {
 		},
 	}
 
+	cmd.PersistentFlags().BoolVar(&prettyTables, "pretty-tables", false, "Pad table cells so columns line up in plain text")
+	cmd.PersistentFlags().BoolVar(&htmlTables, "html-tables", false, "Write tables with multi-line or very wide cells as HTML")
+	cmd.PersistentFlags().IntVar(&maxCellWidth, "max-cell-width", 0, "Cut table cells wider than this many characters")
+	cmd.PersistentFlags().IntVar(&headingOffset, "heading-offset", 0, "Move headings down by this many levels")
+	cmd.PersistentFlags().IntVar(&maxHeadingDepth, "max-heading-depth", 0, "Cap heading levels at this depth (1-6)")
+	cmd.PersistentFlags().BoolVar(&frontMatter, "front-matter", false, "Start the document with YAML front matter of its title, author, dates, source and format")
+	cmd.PersistentFlags().IntVar(&summary, "summary", 0, "Start the document with a summary of this many of its sentences")
+	cmd.PersistentFlags().StringVar(&marker, "marker", "", "Mark slides, PDF pages and sheets with a heading, a rule, a comment, or a template with {n} and {name}")
+	cmd.PersistentFlags().StringVar(&password, "password", "", "Open encrypted PDF, DOCX, XLSX and PPTX files with this password")
+	cmd.PersistentFlags().BoolVar(&progress, "progress", false, "Show the progress through the pages, slides, sheets and chapters of the document on stderr")
+	cmd.PersistentFlags().BoolVar(&partial, "partial", false, "Leave out the PDF pages and sheets that cannot be read with a warning instead of failing")
+	cmd.PersistentFlags().BoolVar(&provenance, "provenance", false, "Mark the source page, slide, sheet range or paragraph of the output with comments")
+
 	cmd.Flags().StringVarP(&output, "output", "o", "console", "Specify the output file path")
+	cmd.Flags().StringVar(&format, "format", "markdown", "Output format: markdown, text, json or html")
+	cmd.Flags().BoolVar(&preview, "preview", false, "Open the converted document as HTML in the browser")
+	cmd.Flags().StringVar(&spoolDir, "spool", "", "Add the input to this job spool directory, or convert the jobs spooled there when no input is given")
+	cmd.Flags().IntVar(&attempts, "attempts", 3, "Number of times a spooled job is tried before it is recorded as failed")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Write the markdown, metadata, warnings and images of the conversion as one JSON object")
+	cmd.Flags().BoolVar(&meta, "meta", false, "Print the MIME type, converter, title, statistics and warnings of the conversion instead of writing it")
+	cmd.Flags().BoolVar(&formats, "formats", false, "List the supported formats and the capabilities of their converters")
+	cmd.Flags().StringVar(&chaptersDir, "chapters-dir", "", "Write one markdown file per chapter of an EPUB file to this directory")
+	cmd.Flags().StringVar(&splitBy, "split-by", "", "Write one markdown file per section starting at headings of this level, h1 to h6, with an index, to the --output directory")
+	// Each of these selects what is done with the input
+	cmd.MarkFlagsMutuallyExclusive("spool", "meta", "json", "chapters-dir", "split-by", "preview", "formats")
+
+	cmd.AddCommand(newServeCommand(newMarky), newScoreCommand(newMarky))
 
 	if err := cmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "%v\n", err)
 		os.Exit(1)
 	}
 }
+
+// printProgress rewrites a progress line such as "page 3/120" on stderr,
+// ending it once the last part is converted.
+func printProgress(event marky.ProgressEvent) {
+	fmt.Fprintf(os.Stderr, "\r%s %d/%d", event.Unit, event.Done, event.Total)
+	if event.Done == event.Total {
+		fmt.Fprintln(os.Stderr)
+	}
+}
+
+// printFidelity prints the fidelity of a conversion, as JSON or as a table.
+func printFidelity(f marky.Fidelity, asJSON bool) error {
+	if asJSON {
+		data, err := json.MarshalIndent(f, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "METRIC\tCONVERTED\tREFERENCE\tSCORE")
+	fmt.Fprintf(w, "headings\t%d\t%d\t%.2f\n", f.Headings, f.ReferenceHeadings, f.HeadingScore)
+	fmt.Fprintf(w, "table cells\t%d\t%d\t%.2f\n", f.TableCells, f.ReferenceTableCells, f.TableScore)
+	fmt.Fprintf(w, "words\t%d\t%d\t%.2f\n", f.Words, f.ReferenceWords, f.WordRetention)
+	fmt.Fprintf(w, "overall\t\t\t%.2f\n", f.Score)
+	return w.Flush()
+}
+
+// writeOutput streams the conversion of a document to a file, which is
+// removed when the conversion fails.
+func writeOutput(md marky.IMarky, input, output string) error {
+	f, err := os.OpenFile(output, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to write to output file: %w", err)
+	}
+	if err := md.ConvertTo(f, input); err != nil {
+		f.Close()
+		os.Remove(output)
+		return fmt.Errorf("failed to convert file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write to output file: %w", err)
+	}
+	return nil
+}
+
+// convertPartial converts a document, logging the warnings of the
+// conversion in partial mode so that the parts left out are known.
+func convertPartial(md marky.IMarky, input string, partial bool) (string, error) {
+	if !partial {
+		return md.Convert(input)
+	}
+	result, err := md.ConvertWithResult(input)
+	if err != nil {
+		return "", err
+	}
+	for _, warning := range result.Warnings {
+		log.Printf("Warning: %s\n", warning)
+	}
+	return result.Markdown, nil
+}
+
+// printMetadata prints what is known about a conversion, without the
+// converted document.
+func printMetadata(result *marky.ConversionResult, asJSON bool) error {
+	result.Markdown = ""
+	if asJSON {
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "mime type\t%s\n", result.MimeType)
+	fmt.Fprintf(w, "converter\t%s\n", result.Converter)
+	fmt.Fprintf(w, "title\t%s\n", result.Title)
+	if result.Parts > 0 {
+		fmt.Fprintf(w, "parts\t%d\n", result.Parts)
+	}
+	fmt.Fprintf(w, "words\t%d\n", result.Words)
+	fmt.Fprintf(w, "characters\t%d\n", result.Characters)
+	fmt.Fprintf(w, "reading time\t%d min\n", result.ReadingMinutes)
+	for _, warning := range result.Warnings {
+		fmt.Fprintf(w, "warning\t%s\n", warning)
+	}
+	return w.Flush()
+}
+
+// jsonDocument is the conversion written by --json, for scripts and web
+// frontends.
+type jsonDocument struct {
+	Markdown string         `json:"markdown"`
+	Metadata map[string]any `json:"metadata"`
+	Warnings []string       `json:"warnings"`
+	Assets   []utils.Image  `json:"assets"`
+}
+
+// writeJSON writes a conversion as a jsonDocument to the output file, or to
+// stdout for the console. The metadata holds the front matter fields of the
+// document along with its MIME type, converter and statistics.
+func writeJSON(result *marky.ConversionResult, output string) error {
+	fields, _ := utils.SplitFrontMatter(result.Markdown)
+	metadata := utils.FrontMatterMap(fields)
+	metadata["mime_type"] = result.MimeType
+	metadata["converter"] = result.Converter
+	if result.Title != "" {
+		metadata["title"] = result.Title
+	}
+	if result.Parts > 0 {
+		metadata["parts"] = result.Parts
+	}
+	metadata["words"] = result.Words
+	metadata["characters"] = result.Characters
+	metadata["reading_minutes"] = result.ReadingMinutes
+
+	// Empty lists are written as [] rather than null for frontends.
+	doc := jsonDocument{
+		Markdown: result.Markdown,
+		Metadata: metadata,
+		Warnings: append([]string{}, result.Warnings...),
+		Assets:   append([]utils.Image{}, utils.ExtractImages(result.Markdown)...),
+	}
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	if output == "console" {
+		fmt.Println(string(data))
+		return nil
+	}
+	if err := os.WriteFile(output, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write to output file: %w", err)
+	}
+	log.Printf("Content written to %s\n", output)
+	return nil
+}
+
+// listFormats prints the name and extensions of each converter with what it
+// can extract from documents.
+func listFormats(formats []marky.Registration) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tEXTENSIONS\tIMAGES\tTABLES\tMETADATA\tSTREAMING\tPASSWORD")
+	mark := func(ok bool) string {
+		if ok {
+			return "yes"
+		}
+		return "-"
+	}
+	for _, r := range formats {
+		caps := r.Converter.Capabilities()
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", r.Name, strings.Join(r.Converter.AcceptedExtensions(), " "),
+			mark(caps.Images), mark(caps.Tables), mark(caps.Metadata), mark(caps.Streaming), mark(caps.Password))
+	}
+	w.Flush()
+}
+
+// openPreview writes an HTML page to a temporary file and opens it in the
+// default browser.
+func openPreview(page string) error {
+	f, err := os.CreateTemp("", "marky-preview-*.html")
+	if err != nil {
+		return fmt.Errorf("failed to create preview file: %w", err)
+	}
+	if _, err := f.WriteString(page); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write preview file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write preview file: %w", err)
+	}
+
+	var open *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		open = exec.Command("open", f.Name())
+	case "windows":
+		open = exec.Command("rundll32", "url.dll,FileProtocolHandler", f.Name())
+	default:
+		open = exec.Command("xdg-open", f.Name())
+	}
+	if err := open.Start(); err != nil {
+		return fmt.Errorf("failed to open preview %s: %w", f.Name(), err)
+	}
+	log.Printf("Preview written to %s\n", f.Name())
+	return nil
+}
+
+// enqueue adds the conversion of input to a job spool. The markdown is
+// written next to the input unless an output file is given.
+func enqueue(dir, input, output string) error {
+	spool, err := worker.OpenSpool(dir)
+	if err != nil {
+		return err
+	}
+	if output == "console" {
+		output = ""
+	}
+	job, err := spool.Enqueue(worker.Job{Input: input, Output: output})
+	if err != nil {
+		return fmt.Errorf("failed to add job: %w", err)
+	}
+	log.Printf("Job %s added to %s\n", job.ID, dir)
+	return nil
+}
+
+// serve serves the job API of s on addr until interrupted, converting the
+// uploaded documents with a worker per CPU. Uploads and their markdown are
+// kept in a temporary directory removed on exit, once the conversions have
+// stopped.
+func serve(addr string, s *server.Server) error {
+	dir, err := os.MkdirTemp("", "marky-jobs-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	s.Dir = dir
+	s.Start(ctx)
+
+	httpServer := &http.Server{Addr: addr, Handler: s}
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		httpServer.Shutdown(shutdown)
+	}()
+	log.Printf("Serving the job API on %s\n", addr)
+	err = httpServer.ListenAndServe()
+	stop()
+	s.Wait()
+	if !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// newServeCommand creates the serve command, serving the job API with the
+// instances created by newMarky.
+func newServeCommand(newMarky func() (*marky.Marky, error)) *cobra.Command {
+	var token string
+	var callbackHosts []string
+	var jobTTL time.Duration
+	var attempts int
+
+	cmd := &cobra.Command{
+		Use:   "serve <addr>",
+		Short: "Serve the job API on an address, such as :8080, converting uploaded documents in the background",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			md, err := newMarky()
+			if err != nil {
+				return err
+			}
+			if token == "" {
+				log.Println("No --token or MARKY_TOKEN is set: the job API is open to anyone reaching the address")
+			}
+			return serve(args[0], &server.Server{
+				Converter:     md,
+				MaxAttempts:   attempts,
+				CallbackHosts: callbackHosts,
+				JobTTL:        jobTTL,
+				Token:         token,
+			})
+		},
+	}
+	cmd.Flags().StringVar(&token, "token", os.Getenv("MARKY_TOKEN"), "Bearer token the requests must carry, MARKY_TOKEN by default")
+	cmd.Flags().StringSliceVar(&callbackHosts, "callback-hosts", nil, "Host names the job API may post job statuses to; callbacks are refused when empty")
+	cmd.Flags().DurationVar(&jobTTL, "job-ttl", time.Hour, "How long finished jobs and their markdown are kept")
+	cmd.Flags().IntVar(&attempts, "attempts", 3, "Number of times a job is tried before it is recorded as failed")
+	return cmd
+}
+
+// newScoreCommand creates the score command, scoring the conversions of
+// the instances created by newMarky against reference markdown files.
+func newScoreCommand(newMarky func() (*marky.Marky, error)) *cobra.Command {
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "score <inputfile|url> <reference>",
+		Short: "Score the conversion of a document against a reference markdown file",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(_ *cobra.Command, args []string) error {
+			if err := checkInput(args[0]); err != nil {
+				return err
+			}
+			md, err := newMarky()
+			if err != nil {
+				return err
+			}
+			fidelity, err := md.Score(args[0], args[1])
+			if err != nil {
+				return fmt.Errorf("failed to score conversion: %w", err)
+			}
+			return printFidelity(fidelity, asJSON)
+		},
+	}
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print the scores as JSON")
+	return cmd
+}
+
+// checkInput checks that an input file exists. URLs and objects are
+// fetched when they are converted.
+func checkInput(input string) error {
+	if _, err := os.Stat(input); os.IsNotExist(err) && !converters.IsURL(input) && !converters.IsObjectURI(input) {
+		return fmt.Errorf("input file does not exist: %s", input)
+	}
+	return nil
+}
+
+// runWorker converts the jobs of a spool until none is left, first putting
+// back the jobs a stopped worker left unfinished.
+func runWorker(dir string, converter worker.Converter, attempts int) error {
+	spool, err := worker.OpenSpool(dir)
+	if err != nil {
+		return err
+	}
+	if n, err := spool.Recover(); err != nil {
+		return fmt.Errorf("failed to recover jobs: %w", err)
+	} else if n > 0 {
+		log.Printf("%d unfinished jobs put back in %s\n", n, dir)
+	}
+
+	// Documents are checked for duplicates against those of earlier runs
+	finished, err := spool.Finished()
+	if err != nil {
+		return fmt.Errorf("failed to read finished jobs: %w", err)
+	}
+	fingerprints := &worker.Fingerprints{}
+	for _, status := range finished {
+		fingerprints.Add(status)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	w := &worker.Worker{Queue: spool, Converter: converter, MaxAttempts: attempts, Fingerprints: fingerprints}
+	if err := w.Run(ctx); err != nil {
+		return fmt.Errorf("worker stopped: %w", err)
+	}
+	log.Printf("No jobs left in %s\n", dir)
+	return nil
+}
+
+// writeSections converts a document and writes its sections, split before
+// the headings of a level such as h1, to markdown files of their own in dir,
+// along with an index.md file linking them.
+func writeSections(convert func(string) (string, error), input, level, dir, format string) error {
+	n, err := strconv.Atoi(strings.TrimPrefix(strings.ToLower(level), "h"))
+	if err != nil || n < 1 || n > 6 || !strings.HasPrefix(strings.ToLower(level), "h") {
+		return fmt.Errorf("unknown heading level %q, want h1 to h6", level)
+	}
+	if dir == "console" {
+		return errors.New("--split-by requires an --output directory")
+	}
+	if format != "markdown" {
+		return errors.New("--split-by is only supported for markdown output")
+	}
+
+	result, err := convert(input)
+	if err != nil {
+		return fmt.Errorf("failed to convert file: %w", err)
+	}
+	sections, err := marky.WriteSections(dir, result, n)
+	if err != nil {
+		return fmt.Errorf("failed to write sections: %w", err)
+	}
+	log.Printf("%d sections written to %s\n", len(sections), dir)
+	return nil
+}
+
+// writeChapters converts the chapters of an EPUB file to markdown files of
+// their own, numbered in reading order and named after the chapter titles.
+// The EPUB converter registered with md is used, with its options.
+func writeChapters(md *marky.Marky, input, dir string) error {
+	if !strings.EqualFold(filepath.Ext(input), ".epub") {
+		return errors.New("--chapters-dir is only supported for EPUB files")
+	}
+
+	var epub *converters.EpubConverter
+	for _, r := range md.Registrations() {
+		if c, ok := r.Converter.(*converters.EpubConverter); ok {
+			epub = c
+			break
+		}
+	}
+	if epub == nil {
+		return errors.New("no EPUB converter is registered")
+	}
+
+	chapters, err := epub.LoadChapters(input)
+	if err != nil {
+		return fmt.Errorf("failed to convert file: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create chapters directory: %w", err)
+	}
+
+	width := len(strconv.Itoa(len(chapters)))
+	for i, chapter := range chapters {
+		name := fmt.Sprintf("%0*d-%s.md", width, i+1, cmp.Or(utils.Slug(chapter.Title), "chapter"))
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(chapter.Markdown), 0o644); err != nil {
+			return fmt.Errorf("failed to write to output file: %w", err)
+		}
+	}
+	log.Printf("%d chapters written to %s\n", len(chapters), dir)
+	return nil
+}
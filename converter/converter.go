@@ -0,0 +1,71 @@
+// Package converter lets other modules convert formats marky does not
+// support. A converter implements Converter, usually by embedding a
+// BaseConverter holding the extensions and MIME types it accepts, and is
+// registered with the RegisterConverter or Register method of a Marky, or
+// the WithConverter option of marky.New.
+package converter
+
+import "github.com/flaviodelgrosso/marky/internal/converters"
+
+// Converter converts the documents of a format to markdown.
+type Converter = converters.Converter
+
+// ContextConverter is a Converter whose conversions stop when a context is
+// done. Conversions by other converters keep running in the background
+// once the context of ConvertContext is done.
+type ContextConverter = converters.ContextConverter
+
+// BytesConverter is a Converter of documents held in memory. The documents
+// given to ConvertBytes are written to a temporary file for other
+// converters.
+type BytesConverter = converters.BytesConverter
+
+// WriterConverter is a Converter writing markdown to an io.Writer as it
+// converts a document. The documents of other converters given to ConvertTo
+// are converted as a whole before being written.
+type WriterConverter = converters.WriterConverter
+
+// LoggingConverter is a Converter logging diagnostics, such as files that
+// could not be closed, with the logger given to SetLogger by the Marky it is
+// registered with.
+type LoggingConverter = converters.LoggingConverter
+
+// ProgressConverter is a Converter reporting its progress through the
+// pages, slides, sheets or chapters of documents to the function given to
+// SetProgress by the Marky it is registered with. Calls are never
+// concurrent.
+type ProgressConverter = converters.ProgressConverter
+
+// ProgressEvent reports that one more part of a document was converted.
+type ProgressEvent = converters.ProgressEvent
+
+// ProgressFunc receives the progress of a conversion.
+type ProgressFunc = converters.ProgressFunc
+
+// MetadataReader is a Converter reading the title, author and dates of
+// documents for their front matter.
+type MetadataReader = converters.MetadataReader
+
+// DocumentMetadata holds the descriptive properties of a document.
+type DocumentMetadata = converters.DocumentMetadata
+
+// Capabilities describes what a converter can extract from documents.
+type Capabilities = converters.Capabilities
+
+// ErrCorruptDocument is wrapped by the errors of converters reading
+// malformed content, and ErrEncryptedDocument by those of encrypted
+// documents converted without their password.
+var (
+	ErrCorruptDocument   = converters.ErrCorruptDocument
+	ErrEncryptedDocument = converters.ErrEncryptedDocument
+)
+
+// BaseConverter implements the AcceptedExtensions, AcceptedMimeTypes and
+// Capabilities methods of a Converter, reporting no capabilities.
+type BaseConverter = converters.BaseConverter
+
+// NewBaseConverter creates a BaseConverter accepting files with the given
+// extensions, such as ".txt", and of the given MIME types.
+func NewBaseConverter(extensions []string, mimeTypes []string) BaseConverter {
+	return converters.NewBaseConverter(extensions, mimeTypes)
+}
@@ -0,0 +1,56 @@
+package converter_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/flaviodelgrosso/marky"
+	"github.com/flaviodelgrosso/marky/converter"
+)
+
+// upperConverter converts SHOUT files, starting with a "SHOUT" line, to their
+// uppercase text.
+type upperConverter struct {
+	converter.BaseConverter
+}
+
+func (c upperConverter) Load(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	return strings.ToUpper(strings.TrimPrefix(string(data), "SHOUT\n")), err
+}
+
+func TestRegisterConverter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "note.shout")
+	if err := os.WriteFile(path, []byte("SHOUT\nhello"), 0o644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	custom := upperConverter{converter.NewBaseConverter([]string{".shout"}, []string{"text/x-shout"})}
+
+	m, err := marky.New()
+	if err != nil {
+		t.Fatalf("New() returned unexpected error: %v", err)
+	}
+	m.RegisterConverter(custom)
+	m.RegisterSignature(marky.Signature{MimeType: "text/x-shout", Magic: []byte("SHOUT\n")})
+	got, err := m.Convert(path)
+	if err != nil {
+		t.Fatalf("Convert() returned unexpected error: %v", err)
+	}
+	if got != "HELLO" {
+		t.Errorf("Convert() = %q, want HELLO", got)
+	}
+
+	m, err = marky.New(marky.WithReplacedConverter("csv", custom))
+	if err != nil {
+		t.Fatalf("New() returned unexpected error: %v", err)
+	}
+	if err := m.Register("shout", custom, 0); err != nil {
+		t.Errorf("Register() returned unexpected error: %v", err)
+	}
+	if _, err := marky.New(marky.WithReplacedConverter("missing", custom)); !errors.Is(err, marky.ErrUnknownConverter) {
+		t.Errorf("New() with an unknown replaced converter error = %v, want ErrUnknownConverter", err)
+	}
+}
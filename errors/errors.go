@@ -0,0 +1,48 @@
+// Package errors defines the errors returned by the conversions of marky,
+// whatever the converter, so that callers can tell them apart with
+// errors.Is of the standard library:
+//
+//	_, err := m.Convert("report.docx")
+//	switch {
+//	case errors.Is(err, markyerrors.ErrUnsupportedFormat):
+//	case errors.Is(err, markyerrors.ErrCorruptDocument):
+//	case errors.Is(err, markyerrors.ErrPasswordProtected):
+//	case errors.Is(err, markyerrors.ErrPermissionDenied):
+//	}
+//
+// The errors are the same values as those of the marky package, such as
+// marky.ErrNoConverter and marky.ErrEncryptedDocument.
+package errors
+
+import (
+	"io/fs"
+
+	"github.com/flaviodelgrosso/marky/internal/converters"
+	"github.com/flaviodelgrosso/marky/internal/marky"
+)
+
+var (
+	// ErrUnsupportedFormat is returned for documents no registered
+	// converter accepts.
+	ErrUnsupportedFormat = marky.ErrNoConverter
+
+	// ErrCorruptDocument is returned for documents whose content cannot be
+	// read, such as truncated packages or malformed XML, JSON or CSV.
+	ErrCorruptDocument = converters.ErrCorruptDocument
+
+	// ErrPasswordProtected is returned for encrypted documents converted
+	// without their password, or with a wrong one.
+	ErrPasswordProtected = converters.ErrEncryptedDocument
+
+	// ErrPermissionDenied is returned for documents the process is not
+	// allowed to read.
+	ErrPermissionDenied = fs.ErrPermission
+
+	// ErrFileTooLarge is returned for files larger than the maximum file
+	// size.
+	ErrFileTooLarge = marky.ErrFileTooLarge
+
+	// ErrDiskWrite is returned for conversions needing to write to disk
+	// while in-memory operation is enabled.
+	ErrDiskWrite = converters.ErrDiskWrite
+)
@@ -0,0 +1,68 @@
+package errors_test
+
+import (
+	"archive/zip"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/flaviodelgrosso/marky"
+	markyerrors "github.com/flaviodelgrosso/marky/errors"
+)
+
+func TestConvert_Errors(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+		return path
+	}
+
+	m, err := marky.New()
+	if err != nil {
+		t.Fatalf("New() returned unexpected error: %v", err)
+	}
+	for _, tt := range []struct {
+		path string
+		want error
+	}{
+		{write("image.bin", "\x00\x01\x02\x03"), markyerrors.ErrUnsupportedFormat},
+		{writeTruncatedDocx(t, dir), markyerrors.ErrCorruptDocument},
+	} {
+		if _, err := m.Convert(tt.path); !errors.Is(err, tt.want) {
+			t.Errorf("Convert(%s) error = %v, want %v", filepath.Base(tt.path), err, tt.want)
+		}
+	}
+}
+
+// writeTruncatedDocx writes a DOCX file whose document part is cut short.
+func writeTruncatedDocx(t *testing.T, dir string) string {
+	t.Helper()
+	path := filepath.Join(dir, "report.docx")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	for name, content := range map[string]string{
+		"[Content_Types].xml": `<?xml version="1.0"?><Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"></Types>`,
+		"word/document.xml":   `<?xml version="1.0"?><w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body><w:p>`,
+	} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	return path
+}
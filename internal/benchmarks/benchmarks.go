@@ -0,0 +1,121 @@
+// Package benchmarks measures the converters on representative documents of
+// each format and checks the measures against performance budgets, so that
+// refactors made for performance can be validated and regressions caught.
+package benchmarks
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/flaviodelgrosso/marky/internal/converters"
+)
+
+// Fixture is a document converted by a benchmark.
+type Fixture struct {
+	// Name names the benchmark, such as "pdf".
+	Name string
+
+	Path      string
+	Converter converters.Converter
+}
+
+// Fixtures returns the fixtures of the formats with a test file in dir,
+// such as the test_files directory of the repository.
+func Fixtures(dir string) []Fixture {
+	fixture := func(name, file string, c converters.Converter) Fixture {
+		return Fixture{Name: name, Path: filepath.Join(dir, file), Converter: c}
+	}
+	return []Fixture{
+		fixture("csv", "test.csv", converters.NewCsvConverter()),
+		fixture("docx", "test.docx", converters.NewDocConverter()),
+		fixture("epub", "test.epub", converters.NewEpubConverter()),
+		fixture("excel", "test.xlsx", converters.NewExcelConverter()),
+		fixture("html", "test.html", converters.NewHTMLConverter()),
+		fixture("ipynb", "test.ipynb", converters.NewIpynbConverter()),
+		fixture("pdf", "test.pdf", converters.NewPdfConverter()),
+		fixture("pptx", "test.pptx", converters.NewPptxConverter()),
+	}
+}
+
+// Result is the measure of a fixture, as reported by go test -benchmem.
+type Result struct {
+	Name        string
+	NsPerOp     int64
+	AllocsPerOp int64
+	BytesPerOp  int64
+}
+
+// String formats the result like a line of go test -bench output.
+func (r Result) String() string {
+	return fmt.Sprintf("%-8s %12d ns/op %12d B/op %10d allocs/op", r.Name, r.NsPerOp, r.BytesPerOp, r.AllocsPerOp)
+}
+
+// Run measures the conversion of each fixture. A fixture that fails to
+// convert fails the run before it is measured.
+func Run(fixtures []Fixture) ([]Result, error) {
+	results := make([]Result, 0, len(fixtures))
+	for _, f := range fixtures {
+		if _, err := f.Converter.Load(f.Path); err != nil {
+			return nil, fmt.Errorf("unable to convert fixture %s: %w", f.Name, err)
+		}
+		r := testing.Benchmark(func(b *testing.B) { Convert(b, f) })
+		results = append(results, Result{
+			Name:        f.Name,
+			NsPerOp:     r.NsPerOp(),
+			AllocsPerOp: r.AllocsPerOp(),
+			BytesPerOp:  r.AllocedBytesPerOp(),
+		})
+	}
+	return results, nil
+}
+
+// Convert is the benchmark of a fixture, for use in Benchmark functions.
+func Convert(b *testing.B, f Fixture) {
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := f.Converter.Load(f.Path); err != nil {
+			b.Fatalf("unable to convert fixture %s: %v", f.Name, err)
+		}
+	}
+}
+
+// Budget is the most a conversion may take before it is a regression. Zero
+// fields are not checked.
+type Budget struct {
+	NsPerOp     int64
+	AllocsPerOp int64
+}
+
+// Budgets are the budgets of the fixtures returned by Fixtures. Times are
+// about five times their measure, leaving room for slower machines, while
+// allocations vary little between machines and are a quarter above theirs.
+var Budgets = map[string]Budget{
+	"csv":   {NsPerOp: 150_000, AllocsPerOp: 400},
+	"docx":  {NsPerOp: 8_000_000, AllocsPerOp: 7_500},
+	"epub":  {NsPerOp: 100_000_000, AllocsPerOp: 63_000},
+	"excel": {NsPerOp: 12_000_000, AllocsPerOp: 14_000},
+	"html":  {NsPerOp: 8_000_000, AllocsPerOp: 7_500},
+	"ipynb": {NsPerOp: 200_000, AllocsPerOp: 150},
+	"pdf":   {NsPerOp: 1_500_000_000, AllocsPerOp: 3_400_000},
+	"pptx":  {NsPerOp: 30_000_000, AllocsPerOp: 40_000},
+}
+
+// Check returns an error for each result over its budget. Results without a
+// budget are not checked.
+func Check(results []Result, budgets map[string]Budget) []error {
+	var errs []error
+	for _, r := range results {
+		budget, ok := budgets[r.Name]
+		if !ok {
+			continue
+		}
+		if budget.NsPerOp > 0 && r.NsPerOp > budget.NsPerOp {
+			errs = append(errs, fmt.Errorf("%s: %d ns/op over the budget of %d", r.Name, r.NsPerOp, budget.NsPerOp))
+		}
+		if budget.AllocsPerOp > 0 && r.AllocsPerOp > budget.AllocsPerOp {
+			errs = append(errs, fmt.Errorf("%s: %d allocs/op over the budget of %d", r.Name, r.AllocsPerOp, budget.AllocsPerOp))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,70 @@
+package benchmarks
+
+import (
+	"flag"
+	"path/filepath"
+	"testing"
+)
+
+var budgets = flag.Bool("budgets", false, "check the converters against their performance budgets")
+
+var testFiles = filepath.Join("..", "..", "test_files")
+
+func BenchmarkConverters(b *testing.B) {
+	for _, f := range Fixtures(testFiles) {
+		b.Run(f.Name, func(b *testing.B) { Convert(b, f) })
+	}
+}
+
+func TestBudgets(t *testing.T) {
+	if !*budgets {
+		t.Skip("run with -budgets to check the performance budgets")
+	}
+
+	results, err := Run(Fixtures(testFiles))
+	if err != nil {
+		t.Fatalf("Run() returned unexpected error: %v", err)
+	}
+	for _, r := range results {
+		t.Log(r)
+	}
+	for _, err := range Check(results, Budgets) {
+		t.Error(err)
+	}
+}
+
+func TestFixtures_HaveBudgets(t *testing.T) {
+	for _, f := range Fixtures(testFiles) {
+		if _, ok := Budgets[f.Name]; !ok {
+			t.Errorf("fixture %s has no budget", f.Name)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	results := []Result{
+		{Name: "fast", NsPerOp: 100, AllocsPerOp: 10},
+		{Name: "slow", NsPerOp: 300, AllocsPerOp: 10},
+		{Name: "greedy", NsPerOp: 100, AllocsPerOp: 30},
+		{Name: "unbudgeted", NsPerOp: 1000, AllocsPerOp: 1000},
+	}
+	limits := map[string]Budget{
+		"fast":   {NsPerOp: 200, AllocsPerOp: 20},
+		"slow":   {NsPerOp: 200, AllocsPerOp: 20},
+		"greedy": {AllocsPerOp: 20},
+	}
+
+	errs := Check(results, limits)
+	want := []string{
+		"slow: 300 ns/op over the budget of 200",
+		"greedy: 30 allocs/op over the budget of 20",
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("Check() = %v, want %q", errs, want)
+	}
+	for i, err := range errs {
+		if err.Error() != want[i] {
+			t.Errorf("Check()[%d] = %q, want %q", i, err, want[i])
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package converters
+
+import (
+	"archive/zip"
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// ArchiveLimits bounds the ZIP archives read by the DOCX, PPTX, Excel, EPUB
+// and ZIP converters, so that a crafted file cannot exhaust memory when its
+// members are decompressed. A limit is the default one when zero, and is
+// not checked when negative.
+type ArchiveLimits struct {
+	// MaxEntries is the number of members an archive may hold.
+	MaxEntries int
+
+	// MaxEntrySize is the uncompressed size in bytes of a single member.
+	MaxEntrySize int64
+
+	// MaxTotalSize is the uncompressed size in bytes of all members.
+	MaxTotalSize int64
+}
+
+// defaultArchiveLimits are the limits checked by the converters when none
+// are set, generous enough for large office documents and books.
+var defaultArchiveLimits = ArchiveLimits{
+	MaxEntries:   10_000,
+	MaxEntrySize: 512 << 20,
+	MaxTotalSize: 2 << 30,
+}
+
+// Merge returns the limits with the fields set in o replaced.
+func (base ArchiveLimits) Merge(o ArchiveLimits) ArchiveLimits {
+	base.MaxEntries = cmp.Or(o.MaxEntries, base.MaxEntries)
+	base.MaxEntrySize = cmp.Or(o.MaxEntrySize, base.MaxEntrySize)
+	base.MaxTotalSize = cmp.Or(o.MaxTotalSize, base.MaxTotalSize)
+	return base
+}
+
+// Errors wrapped by ArchiveError.
+var (
+	ErrTooManyEntries  = errors.New("too many archive members")
+	ErrEntryTooLarge   = errors.New("archive member too large")
+	ErrArchiveTooLarge = errors.New("archive too large when uncompressed")
+	ErrUnsafePath      = errors.New("path leaves the target directory")
+)
+
+// ArchiveError reports an archive exceeding its ArchiveLimits, or a path
+// in a document that would be written outside the target directory.
+type ArchiveError struct {
+	// Name is the archive member or path at fault, empty when the error
+	// concerns the whole archive.
+	Name string
+	Err  error
+}
+
+func (e *ArchiveError) Error() string {
+	if e.Name == "" {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%s: %v", e.Name, e.Err)
+}
+
+func (e *ArchiveError) Unwrap() error {
+	return e.Err
+}
+
+// openArchive opens a ZIP archive and checks it against limits.
+func openArchive(path string, limits ArchiveLimits) (*zip.ReadCloser, error) {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkArchive(&r.Reader, limits); err != nil {
+		r.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+// newArchiveReader reads a ZIP archive from r and checks it against limits.
+func newArchiveReader(r io.ReaderAt, size int64, limits ArchiveLimits) (*zip.Reader, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkArchive(zr, limits); err != nil {
+		return nil, err
+	}
+	return zr, nil
+}
+
+// checkArchive checks the number of members and their uncompressed sizes
+// declared in the central directory, and rejects member names that are not
+// local paths. The zip package fails reads of members that inflate past
+// their declared size, so the declared sizes bound what is decompressed.
+func checkArchive(r *zip.Reader, limits ArchiveLimits) error {
+	limits = defaultArchiveLimits.Merge(limits)
+	if limits.MaxEntries > 0 && len(r.File) > limits.MaxEntries {
+		return &ArchiveError{Err: fmt.Errorf("%w: %d, limit %d", ErrTooManyEntries, len(r.File), limits.MaxEntries)}
+	}
+
+	var total uint64
+	for _, f := range r.File {
+		if !localPath(f.Name) {
+			return &ArchiveError{Name: f.Name, Err: ErrUnsafePath}
+		}
+		if limits.MaxEntrySize > 0 && f.UncompressedSize64 > uint64(limits.MaxEntrySize) {
+			return &ArchiveError{Name: f.Name, Err: fmt.Errorf("%w: %d bytes, limit %d", ErrEntryTooLarge, f.UncompressedSize64, limits.MaxEntrySize)}
+		}
+		total += f.UncompressedSize64
+		if limits.MaxTotalSize > 0 && total > uint64(limits.MaxTotalSize) {
+			return &ArchiveError{Err: fmt.Errorf("%w: more than %d bytes", ErrArchiveTooLarge, limits.MaxTotalSize)}
+		}
+	}
+	return nil
+}
+
+// localPath reports whether a slash-separated path stays within the
+// directory it is resolved against: it is relative, has no ".." elements
+// leaving the directory and no backslashes that Windows would read as
+// separators.
+func localPath(name string) bool {
+	return !strings.Contains(name, `\`) && filepath.IsLocal(filepath.FromSlash(name))
+}
@@ -0,0 +1,141 @@
+package converters
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckArchive(t *testing.T) {
+	limits := ArchiveLimits{MaxEntries: 3, MaxEntrySize: 100, MaxTotalSize: 150}
+
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected error
+	}{
+		{"within limits", map[string]string{"a.xml": "a", "b/c.xml": strings.Repeat("c", 100)}, nil},
+		{"too many members", map[string]string{"a": "", "b": "", "c": "", "d": ""}, ErrTooManyEntries},
+		{"member too large", map[string]string{"a.xml": strings.Repeat("a", 101)}, ErrEntryTooLarge},
+		{"archive too large", map[string]string{"a.xml": strings.Repeat("a", 80), "b.xml": strings.Repeat("b", 80)}, ErrArchiveTooLarge},
+		{"parent directory", map[string]string{"../evil.xml": ""}, ErrUnsafePath},
+		{"absolute path", map[string]string{"/etc/evil.xml": ""}, ErrUnsafePath},
+		{"backslash", map[string]string{`..\evil.xml`: ""}, ErrUnsafePath},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			zw := zip.NewWriter(&buf)
+			for name, content := range tt.files {
+				w, err := zw.Create(name)
+				if err != nil {
+					t.Fatalf("Failed to create %s in test archive: %v", name, err)
+				}
+				w.Write([]byte(content))
+			}
+			if err := zw.Close(); err != nil {
+				t.Fatalf("Failed to finalize test archive: %v", err)
+			}
+			r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+			if err != nil {
+				t.Fatalf("Failed to read test archive: %v", err)
+			}
+
+			err = checkArchive(r, limits)
+			if !errors.Is(err, tt.expected) {
+				t.Fatalf("checkArchive() = %v, want %v", err, tt.expected)
+			}
+			var archiveErr *ArchiveError
+			if tt.expected != nil && !errors.As(err, &archiveErr) {
+				t.Errorf("checkArchive() = %T, want *ArchiveError", err)
+			}
+		})
+	}
+}
+
+func TestConverters_Load_ArchiveLimits(t *testing.T) {
+	options := ConvertOptions{ArchiveLimits: ArchiveLimits{MaxEntrySize: 1 << 10}}
+	bomb := strings.Repeat("0", 1<<20)
+	tests := []struct {
+		name      string
+		file      string
+		converter Converter
+	}{
+		{"docx", writeTestArchive(t, "bomb.docx", map[string]string{"word/document.xml": bomb}), NewDocConverter()},
+		{"xlsx", writeTestArchive(t, "bomb.xlsx", map[string]string{"xl/workbook.xml": bomb}), NewExcelConverter()},
+		{"pptx", writeTestArchive(t, "bomb.pptx", map[string]string{"ppt/presentation.xml": bomb}), NewPptxConverter()},
+		{"epub", writeTestArchive(t, "bomb.epub", map[string]string{"META-INF/container.xml": bomb}), NewEpubConverter()},
+		{"zip", writeTestArchive(t, "bomb.zip", map[string]string{"bomb.txt": bomb}), NewZipConverter()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Converters without limits set check the default ones
+			if _, err := tt.converter.Load(tt.file); errors.Is(err, ErrEntryTooLarge) {
+				t.Fatalf("Load() with the default limits error = %v", err)
+			}
+			options.Configure(tt.converter)
+			_, err := tt.converter.Load(tt.file)
+			var archiveErr *ArchiveError
+			if !errors.As(err, &archiveErr) || !errors.Is(err, ErrEntryTooLarge) {
+				t.Errorf("Load() error = %v, want *ArchiveError wrapping ErrEntryTooLarge", err)
+			}
+		})
+	}
+}
+
+func TestArchiveLimits_Merge(t *testing.T) {
+	limits := defaultArchiveLimits.Merge(ArchiveLimits{MaxEntries: -1, MaxTotalSize: 1 << 20})
+	expected := ArchiveLimits{MaxEntries: -1, MaxEntrySize: defaultArchiveLimits.MaxEntrySize, MaxTotalSize: 1 << 20}
+	if limits != expected {
+		t.Errorf("Merge() = %+v, want %+v", limits, expected)
+	}
+}
+
+func TestDocConverter_Load_ImageOutsideDirectory(t *testing.T) {
+	doc := `<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"` +
+		` xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main"` +
+		` xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<w:body><w:p><w:r><w:drawing><a:blip r:embed="rId1"/></w:drawing></w:r></w:p></w:body></w:document>`
+	rels := `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="image" Target="%s"/></Relationships>`
+
+	dir := t.TempDir()
+	t.Chdir(dir)
+	if err := os.Mkdir("out", 0o755); err != nil {
+		t.Fatalf("Failed to create output directory: %v", err)
+	}
+	t.Chdir("out")
+
+	safe := writeTestArchive(t, "safe.docx", map[string]string{
+		"word/document.xml":            doc,
+		"word/_rels/document.xml.rels": strings.Replace(rels, "%s", "media/image1.png", 1),
+		"word/media/image1.png":        "png",
+	})
+	download := &DocConverter{Options: ConvertOptions{Images: ImagesDownload}}
+	if _, err := download.Load(safe); err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join("media", "image1.png")); err != nil {
+		t.Errorf("Load() did not write the image: %v", err)
+	}
+
+	evil := writeTestArchive(t, "evil.docx", map[string]string{
+		"word/document.xml":            doc,
+		"word/_rels/document.xml.rels": strings.Replace(rels, "%s", "../evil.png", 1),
+		"word/../evil.png":             "png",
+	})
+	_, err := download.Load(evil)
+	var archiveErr *ArchiveError
+	if !errors.As(err, &archiveErr) || archiveErr.Name != "../evil.png" || !errors.Is(err, ErrUnsafePath) {
+		t.Errorf("Load() error = %v, want ErrUnsafePath for ../evil.png", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "evil.png")); err == nil {
+		t.Error("Load() wrote the image outside the working directory")
+	}
+}
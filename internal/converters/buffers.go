@@ -0,0 +1,31 @@
+package converters
+
+import (
+	"bytes"
+	"sync"
+)
+
+// bufferPool holds the buffers the DOCX and PPTX converters write the
+// markdown of nodes and slides to, so that batch conversions reuse them
+// rather than allocating new ones for every paragraph, run and slide.
+var bufferPool = sync.Pool{New: func() any { return new(bytes.Buffer) }}
+
+// maxPooledBuffer is the capacity above which buffers are left to the
+// garbage collector, so that a single large slide or table does not keep
+// its memory in the pool.
+const maxPooledBuffer = 64 << 10
+
+// getBuffer returns an empty buffer from the pool.
+func getBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+// putBuffer returns a buffer to the pool. Its content must not be used
+// afterwards.
+func putBuffer(b *bytes.Buffer) {
+	if b.Cap() > maxPooledBuffer {
+		return
+	}
+	b.Reset()
+	bufferPool.Put(b)
+}
@@ -0,0 +1,54 @@
+package converters
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestConverters_Load_PooledBuffers(t *testing.T) {
+	for _, tt := range []struct {
+		file      string
+		converter Converter
+	}{
+		{"test.docx", NewDocConverter()},
+		{"test.pptx", NewPptxConverter()},
+	} {
+		path := filepath.Join("..", "..", "test_files", tt.file)
+		want, err := tt.converter.Load(path)
+		if err != nil {
+			t.Fatalf("Load() returned unexpected error: %v", err)
+		}
+
+		// Conversions running at once must not share the buffers they
+		// take from the pool.
+		var wg sync.WaitGroup
+		results := make([]string, 8)
+		for i := range results {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				results[i], _ = tt.converter.Load(path)
+			}()
+		}
+		wg.Wait()
+		for i, got := range results {
+			if got != want {
+				t.Errorf("concurrent Load() %d of %s differs from Load()", i, tt.file)
+			}
+		}
+	}
+}
+
+func TestPutBuffer_DropsLargeBuffers(t *testing.T) {
+	b := getBuffer()
+	b.Grow(maxPooledBuffer + 1)
+	putBuffer(b)
+
+	small := getBuffer()
+	small.WriteString("text")
+	putBuffer(small)
+	if got := getBuffer(); got.Len() != 0 {
+		t.Errorf("getBuffer() returned a buffer holding %q", got.String())
+	}
+}
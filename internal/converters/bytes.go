@@ -0,0 +1,50 @@
+package converters
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// BytesConverter is implemented by the converters that convert documents
+// held in memory without reading or writing files, such as those of CSV
+// files, notebooks, web pages, emails, ZIP archives, EPUB books and PDF,
+// DOCX, XLSX and PPTX files.
+type BytesConverter interface {
+	Converter
+	LoadBytes(ctx context.Context, data []byte) (string, error)
+}
+
+// LoadBytes converts a document held in memory with a converter. The
+// documents of converters that do not implement BytesConverter are written
+// to a temporary file named after the first extension the converter
+// accepts.
+func LoadBytes(ctx context.Context, c Converter, data []byte) (string, error) {
+	if c, ok := c.(BytesConverter); ok {
+		return c.LoadBytes(ctx, data)
+	}
+	return loadTempFile(ctx, c, data)
+}
+
+// loadTempFile converts a document held in memory by writing it to a
+// temporary file, for converters that need a path.
+func loadTempFile(ctx context.Context, c Converter, data []byte) (string, error) {
+	dir, err := os.MkdirTemp("", "marky-")
+	if err != nil {
+		return "", fmt.Errorf("unable to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	name := "document"
+	if extensions := c.AcceptedExtensions(); len(extensions) > 0 {
+		name += extensions[0]
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return "", fmt.Errorf("unable to write temporary file: %w", err)
+	}
+	return LoadContext(ctx, c, path)
+}
+
+// memoryName names documents held in memory in errors.
+const memoryName = "(in memory)"
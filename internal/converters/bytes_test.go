@@ -0,0 +1,56 @@
+package converters
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadBytes(t *testing.T) {
+	tests := []struct {
+		file      string
+		converter Converter
+	}{
+		{"test.csv", NewCsvConverter()},
+		{"test.docx", NewDocConverter()},
+		{"test.html", NewHTMLConverter()},
+		{"test.ipynb", NewIpynbConverter()},
+		{"test.pptx", NewPptxConverter()},
+		{"test.xlsx", NewExcelConverter()},
+		{"test.pdf", NewPdfConverter()},
+		{"test.epub", NewEpubConverter()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			path := filepath.Join("..", "..", "test_files", tt.file)
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("Failed to read test file: %v", err)
+			}
+			want, err := tt.converter.Load(path)
+			if err != nil {
+				t.Fatalf("Load() returned unexpected error: %v", err)
+			}
+			got, err := LoadBytes(context.Background(), tt.converter, data)
+			if err != nil {
+				t.Fatalf("LoadBytes() returned unexpected error: %v", err)
+			}
+			if got != want {
+				t.Errorf("LoadBytes() differs from Load():\ngot  %q\nwant %q", got, want)
+			}
+		})
+	}
+}
+
+func TestLoadBytes_InMemory(t *testing.T) {
+	if _, err := LoadBytes(context.Background(), NewCsvConverter(), []byte("a,b\n1,2\n")); err != nil {
+		t.Errorf("LoadBytes() of a BytesConverter returned unexpected error: %v", err)
+	}
+	pdf := &PdfConverter{OCR: &fakeOCR{}, Options: ConvertOptions{InMemory: true}}
+	if _, err := LoadBytes(context.Background(), pdf, []byte("%PDF-1.4")); !errors.Is(err, ErrDiskWrite) {
+		t.Errorf("LoadBytes() error = %v, want ErrDiskWrite", err)
+	}
+}
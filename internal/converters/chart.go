@@ -0,0 +1,193 @@
+package converters
+
+import (
+	"encoding/xml"
+	"strings"
+
+	"github.com/flaviodelgrosso/marky/internal/utils"
+)
+
+// ChartSpace represents a DrawingML chart part, shared by PPTX and XLSX files.
+type ChartSpace struct {
+	Chart Chart `xml:"chart"`
+}
+
+type Chart struct {
+	Title    *ChartTitle `xml:"title"`
+	PlotArea PlotArea    `xml:"plotArea"`
+}
+
+type ChartTitle struct {
+	Tx ChartData `xml:"tx"`
+}
+
+// PlotArea holds the chart groups (barChart, lineChart, pieChart, ...) and axes.
+type PlotArea struct {
+	Groups []ChartGroup `xml:",any"`
+}
+
+type ChartGroup struct {
+	XMLName xml.Name
+	Series  []ChartSeries `xml:"ser"`
+}
+
+type ChartSeries struct {
+	Tx  ChartData `xml:"tx"`
+	Cat ChartData `xml:"cat"`
+	Val ChartData `xml:"val"`
+}
+
+// ChartData holds the cached values of a series name, category or value
+// reference, or its literal values. Titles may use rich text instead.
+type ChartData struct {
+	V      string       `xml:"v"`
+	Rich   *TextBody    `xml:"rich"`
+	StrF   string       `xml:"strRef>f"`
+	NumF   string       `xml:"numRef>f"`
+	StrRef []ChartPoint `xml:"strRef>strCache>pt"`
+	NumRef []ChartPoint `xml:"numRef>numCache>pt"`
+	StrLit []ChartPoint `xml:"strLit>pt"`
+	NumLit []ChartPoint `xml:"numLit>pt"`
+}
+
+type ChartPoint struct {
+	Idx int    `xml:"idx,attr"`
+	V   string `xml:"v"`
+}
+
+// values returns the data points ordered by index, leaving gaps empty.
+func (d ChartData) values() []string {
+	var points []ChartPoint
+	for _, p := range [][]ChartPoint{d.StrRef, d.NumRef, d.StrLit, d.NumLit} {
+		points = append(points, p...)
+	}
+
+	n := 0
+	for _, p := range points {
+		n = max(n, p.Idx+1)
+	}
+
+	values := make([]string, n)
+	for _, p := range points {
+		if p.Idx >= 0 {
+			values[p.Idx] = p.V
+		}
+	}
+	return values
+}
+
+// text returns the single value of a series name or title reference.
+func (d ChartData) text() string {
+	if d.Rich != nil {
+		return extractTextFromTextBody(d.Rich)
+	}
+	if d.V != "" {
+		return d.V
+	}
+	return strings.Join(d.values(), " ")
+}
+
+// ref returns the cell range the data refers to, such as Sheet1!$B$2:$B$5.
+func (d ChartData) ref() string {
+	if d.StrF != "" {
+		return d.StrF
+	}
+	return d.NumF
+}
+
+// series returns all data series of the chart across its chart groups.
+func (c *Chart) series() []ChartSeries {
+	var series []ChartSeries
+	for _, g := range c.PlotArea.Groups {
+		series = append(series, g.Series...)
+	}
+	return series
+}
+
+// title returns the chart title text, if any.
+func (c *Chart) title() string {
+	if c.Title == nil {
+		return ""
+	}
+	return strings.TrimSpace(c.Title.Tx.text())
+}
+
+// heading returns the chart heading with its title, if any.
+func (c *Chart) heading() string {
+	if title := c.title(); title != "" {
+		return "### Chart: " + title
+	}
+	return "### Chart"
+}
+
+// convertChartToMarkdown renders a chart as a heading with its title followed
+// by a table with one row per category and one column per series, written
+// with the options.
+func convertChartToMarkdown(chart *ChartSpace, tables utils.TableOptions) string {
+	var markdown strings.Builder
+
+	markdown.WriteString("\n\n" + chart.Chart.heading() + "\n\n")
+
+	series := chart.Chart.series()
+	if len(series) == 0 {
+		return markdown.String()
+	}
+
+	header := []string{"Category"}
+	for _, s := range series {
+		header = append(header, s.Tx.text())
+	}
+
+	values := make([][]string, len(series))
+	rowCount := 0
+	for i, s := range series {
+		values[i] = s.Val.values()
+		rowCount = max(rowCount, len(values[i]))
+	}
+	categories := series[0].Cat.values()
+	rowCount = max(rowCount, len(categories))
+
+	rows := [][]string{header}
+	for i := range rowCount {
+		row := make([]string, 0, len(header))
+		if i < len(categories) {
+			row = append(row, categories[i])
+		} else {
+			row = append(row, "")
+		}
+		for _, v := range values {
+			if i < len(v) {
+				row = append(row, v[i])
+			} else {
+				row = append(row, "")
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	markdown.WriteString(tables.ToMarkdown(rows))
+	return markdown.String()
+}
+
+// summarizeChart describes a chart by the type and cell ranges of its series,
+// for spreadsheets whose tables already hold the charted values. The series
+// table is written with the options.
+func summarizeChart(chart *ChartSpace, tables utils.TableOptions) string {
+	rows := [][]string{{"Series", "Type", "Categories", "Values"}}
+	for _, group := range chart.Chart.PlotArea.Groups {
+		kind := strings.TrimSuffix(group.XMLName.Local, "Chart")
+		for _, s := range group.Series {
+			name := s.Tx.text()
+			if name == "" {
+				name = s.Tx.ref()
+			}
+			rows = append(rows, []string{name, kind, s.Cat.ref(), s.Val.ref()})
+		}
+	}
+
+	summary := chart.Chart.heading() + "\n"
+	if len(rows) > 1 {
+		summary += "\n" + tables.ToMarkdown(rows)
+	}
+	return summary
+}
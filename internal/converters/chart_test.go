@@ -0,0 +1,70 @@
+package converters
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/flaviodelgrosso/marky/internal/utils"
+)
+
+const testChartXML = `<c:chartSpace xmlns:c="http://schemas.openxmlformats.org/drawingml/2006/chart" xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main">
+<c:chart>
+	<c:title><c:tx><c:rich><a:p><a:r><a:t>Sales</a:t></a:r></a:p></c:rich></c:tx></c:title>
+	<c:plotArea>
+		<c:barChart>
+			<c:ser>
+				<c:tx><c:strRef><c:f>Data!$B$1</c:f><c:strCache><c:pt idx="0"><c:v>North</c:v></c:pt></c:strCache></c:strRef></c:tx>
+				<c:cat><c:strRef><c:f>Data!$A$2:$A$3</c:f><c:strCache><c:pt idx="0"><c:v>Q1</c:v></c:pt><c:pt idx="1"><c:v>Q2</c:v></c:pt></c:strCache></c:strRef></c:cat>
+				<c:val><c:numRef><c:f>Data!$B$2:$B$3</c:f><c:numCache><c:pt idx="0"><c:v>10</c:v></c:pt><c:pt idx="1"><c:v>20</c:v></c:pt></c:numCache></c:numRef></c:val>
+			</c:ser>
+		</c:barChart>
+		<c:lineChart>
+			<c:ser>
+				<c:tx><c:v>South</c:v></c:tx>
+				<c:val><c:numRef><c:numCache><c:pt idx="1"><c:v>5</c:v></c:pt></c:numCache></c:numRef></c:val>
+			</c:ser>
+		</c:lineChart>
+		<c:catAx/>
+		<c:valAx/>
+	</c:plotArea>
+</c:chart>
+</c:chartSpace>`
+
+func TestConvertChartToMarkdown(t *testing.T) {
+	var chart ChartSpace
+	if err := xml.Unmarshal([]byte(testChartXML), &chart); err != nil {
+		t.Fatalf("Failed to parse chart XML: %v", err)
+	}
+
+	result := convertChartToMarkdown(&chart, utils.TableOptions{})
+	expected := "\n\n### Chart: Sales\n\n" +
+		"| Category | North | South |\n| --- | --- | --- |\n| Q1 | 10 |  |\n| Q2 | 20 | 5 |\n"
+
+	if result != expected {
+		t.Errorf("convertChartToMarkdown() = %q, want %q", result, expected)
+	}
+}
+
+func TestConvertChartToMarkdown_NoTitleNoSeries(t *testing.T) {
+	result := convertChartToMarkdown(&ChartSpace{}, utils.TableOptions{})
+	expected := "\n\n### Chart\n\n"
+
+	if result != expected {
+		t.Errorf("convertChartToMarkdown() = %q, want %q", result, expected)
+	}
+}
+
+func TestSummarizeChart(t *testing.T) {
+	var chart ChartSpace
+	if err := xml.Unmarshal([]byte(testChartXML), &chart); err != nil {
+		t.Fatalf("Failed to parse chart XML: %v", err)
+	}
+
+	result := summarizeChart(&chart, utils.TableOptions{})
+	expected := "### Chart: Sales\n\n| Series | Type | Categories | Values |\n| --- | --- | --- | --- |\n" +
+		"| North | bar | Data!$A$2:$A$3 | Data!$B$2:$B$3 |\n| South | line |  |  |\n"
+
+	if result != expected {
+		t.Errorf("summarizeChart() = %q, want %q", result, expected)
+	}
+}
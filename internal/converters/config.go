@@ -0,0 +1,300 @@
+package converters
+
+import (
+	"net/http"
+
+	"github.com/flaviodelgrosso/marky/internal/utils"
+)
+
+// FormatOptions configures the converter of one format. Each option type
+// holds the settings of its converter, replacing them as a whole when
+// applied, so that formats are configured independently of each other.
+type FormatOptions interface {
+	// Configure applies the options to c and reports whether c converts
+	// the format of the options.
+	Configure(c Converter) bool
+}
+
+// Configure applies the options to the converters holding ConvertOptions,
+// those of PDF, DOCX, PPTX, EPUB, HTML and notebook files. It lets the same
+// options be shared by these converters: only the fields set in o are
+// applied, so that the settings of a format, such as its page selection,
+// are kept. InMemory also applies to the converters of workbooks, emails
+// and ZIP archives, MmapThreshold to that of workbooks, ArchiveLimits to
+// those of workbooks and ZIP archives, and Tables to those of workbooks,
+// CSV and JSON files.
+func (o ConvertOptions) Configure(c Converter) bool {
+	switch c := c.(type) {
+	case *ExcelConverter:
+		c.InMemory = merge(c.InMemory, o.InMemory, o.Set&FieldInMemory != 0)
+		c.MmapThreshold = merge(c.MmapThreshold, o.MmapThreshold, o.Set&FieldMmapThreshold != 0)
+		c.ArchiveLimits = o.mergeArchiveLimits(c.ArchiveLimits)
+		c.Tables = o.mergeTables(c.Tables)
+		return o.InMemory || o.MmapThreshold != 0 || o.ArchiveLimits != (ArchiveLimits{}) || o.Tables != (utils.TableOptions{}) ||
+			o.Set&(FieldInMemory|FieldMmapThreshold|FieldArchiveLimits|FieldTables) != 0
+	case *CsvConverter:
+		c.Tables = o.mergeTables(c.Tables)
+		return o.Tables != (utils.TableOptions{}) || o.Set&FieldTables != 0
+	case *JSONConverter:
+		c.Tables = o.mergeTables(c.Tables)
+		return o.Tables != (utils.TableOptions{}) || o.Set&FieldTables != 0
+	case *EmlConverter:
+		c.InMemory = merge(c.InMemory, o.InMemory, o.Set&FieldInMemory != 0)
+		return o.InMemory || o.Set&FieldInMemory != 0
+	case *ZipConverter:
+		c.InMemory = merge(c.InMemory, o.InMemory, o.Set&FieldInMemory != 0)
+		c.ArchiveLimits = o.mergeArchiveLimits(c.ArchiveLimits)
+		return o.InMemory || o.ArchiveLimits != (ArchiveLimits{}) || o.Set&(FieldInMemory|FieldArchiveLimits) != 0
+	case *PdfConverter:
+		c.Options = c.Options.Merge(o)
+	case *DocConverter:
+		c.Options = c.Options.Merge(o)
+	case *PptxConverter:
+		c.Options = c.Options.Merge(o)
+	case *EpubConverter:
+		c.Options = c.Options.Merge(o)
+	case *HTMLConverter:
+		c.Options = c.Options.Merge(o)
+	case *IpynbConverter:
+		c.Options = c.Options.Merge(o)
+	default:
+		return false
+	}
+	return true
+}
+
+// Merge returns the options with the fields set in o replaced: those that
+// are not zero, and those listed in o.Set. Table options and archive limits
+// are merged field by field unless listed in o.Set. The fields listed in
+// o.Set are added to those of the result, so that merging the result into
+// other options applies them too.
+func (base ConvertOptions) Merge(o ConvertOptions) ConvertOptions {
+	base.KeepDataURIs = merge(base.KeepDataURIs, o.KeepDataURIs, o.Set&FieldKeepDataURIs != 0)
+	base.Slides = merge(base.Slides, o.Slides, o.Set&FieldSlides != 0)
+	base.Pages = merge(base.Pages, o.Pages, o.Set&FieldPages != 0)
+	base.PageMarkers = merge(base.PageMarkers, o.PageMarkers, o.Set&FieldPageMarkers != 0)
+	base.Marker = merge(base.Marker, o.Marker, o.Set&FieldMarker != 0)
+	base.Provenance = merge(base.Provenance, o.Provenance, o.Set&FieldProvenance != 0)
+	base.Chapters = merge(base.Chapters, o.Chapters, o.Set&FieldChapters != 0)
+	base.Password = merge(base.Password, o.Password, o.Set&FieldPassword != 0)
+	base.Partial = merge(base.Partial, o.Partial, o.Set&FieldPartial != 0)
+	base.Images = merge(base.Images, o.Images, o.Set&FieldImages != 0)
+	base.ImageDir = merge(base.ImageDir, o.ImageDir, o.Set&FieldImageDir != 0)
+	base.InMemory = merge(base.InMemory, o.InMemory, o.Set&FieldInMemory != 0)
+	base.MmapThreshold = merge(base.MmapThreshold, o.MmapThreshold, o.Set&FieldMmapThreshold != 0)
+	base.ArchiveLimits = o.mergeArchiveLimits(base.ArchiveLimits)
+	base.Tables = o.mergeTables(base.Tables)
+	base.Set |= o.Set
+	return base
+}
+
+// mergeArchiveLimits returns limits with those of o merged in.
+func (o ConvertOptions) mergeArchiveLimits(limits ArchiveLimits) ArchiveLimits {
+	if o.Set&FieldArchiveLimits != 0 {
+		return o.ArchiveLimits
+	}
+	return limits.Merge(o.ArchiveLimits)
+}
+
+// mergeTables returns tables with the table options of o merged in.
+func (o ConvertOptions) mergeTables(tables utils.TableOptions) utils.TableOptions {
+	if o.Set&FieldTables != 0 {
+		return o.Tables
+	}
+	return tables.Merge(o.Tables)
+}
+
+// merge returns v when it is set, being listed or not zero, and base
+// otherwise.
+func merge[T comparable](base, v T, set bool) T {
+	var zero T
+	if set || v != zero {
+		return v
+	}
+	return base
+}
+
+// PDFOptions configures the PDF converter.
+type PDFOptions struct {
+	// Pages, PageMarkers, Marker, Provenance, Password, Partial and Images
+	// apply to PDF files.
+	ConvertOptions
+
+	SkipTOC        bool
+	Comments       bool
+	SkipMetadata   bool
+	SkipFormFields bool
+	Engine         PdfEngine
+	OCR            OCREngine
+	Workers        int
+}
+
+// Configure applies the options to a *PdfConverter.
+func (o PDFOptions) Configure(c Converter) bool {
+	p, ok := c.(*PdfConverter)
+	if ok {
+		p.Options = o.ConvertOptions
+		p.SkipTOC, p.Comments = o.SkipTOC, o.Comments
+		p.SkipMetadata, p.SkipFormFields = o.SkipMetadata, o.SkipFormFields
+		p.Engine, p.OCR, p.Workers = o.Engine, o.OCR, o.Workers
+	}
+	return ok
+}
+
+// ExcelOptions configures the Excel converter of XLSX, XLSB and XLS files.
+type ExcelOptions struct {
+	Sheets            []string
+	Values            ValueMode
+	Comments          bool
+	Formulas          FormulaMode
+	MaxRows           int
+	MaxCellWidth      int
+	CellFootnotes     bool
+	AlignColumns      bool
+	SkipHiddenSheets  bool
+	SkipHiddenRows    bool
+	SkipHiddenColumns bool
+	Marker            string
+	Provenance        bool
+	Password          string
+	Partial           bool
+}
+
+// Configure applies the options to an *ExcelConverter.
+func (o ExcelOptions) Configure(c Converter) bool {
+	e, ok := c.(*ExcelConverter)
+	if ok {
+		e.Sheets, e.Values, e.Comments, e.Formulas = o.Sheets, o.Values, o.Comments, o.Formulas
+		e.MaxRows, e.MaxCellWidth, e.CellFootnotes, e.AlignColumns = o.MaxRows, o.MaxCellWidth, o.CellFootnotes, o.AlignColumns
+		e.SkipHiddenSheets, e.SkipHiddenRows, e.SkipHiddenColumns = o.SkipHiddenSheets, o.SkipHiddenRows, o.SkipHiddenColumns
+		e.Marker, e.Provenance, e.Password, e.Partial = o.Marker, o.Provenance, o.Password, o.Partial
+	}
+	return ok
+}
+
+// CSVOptions configures the CSV converter.
+type CSVOptions struct {
+	Header         HeaderMode
+	MaxRows        int
+	AlignColumns   bool
+	MaxCellWidth   int
+	CellFootnotes  bool
+	StripThousands bool
+}
+
+// Configure applies the options to a *CsvConverter.
+func (o CSVOptions) Configure(c Converter) bool {
+	v, ok := c.(*CsvConverter)
+	if ok {
+		v.Header, v.MaxRows, v.AlignColumns = o.Header, o.MaxRows, o.AlignColumns
+		v.MaxCellWidth, v.CellFootnotes, v.StripThousands = o.MaxCellWidth, o.CellFootnotes, o.StripThousands
+	}
+	return ok
+}
+
+// DocxOptions configures the DOCX converter.
+type DocxOptions struct {
+	// Images and ImageDir apply to the images of documents, and Password
+	// opens encrypted documents.
+	ConvertOptions
+
+	Flavor     Flavor
+	TOC        TOCMode
+	Provenance bool
+}
+
+// Configure applies the options to a *DocConverter.
+func (o DocxOptions) Configure(c Converter) bool {
+	d, ok := c.(*DocConverter)
+	if ok {
+		d.Options, d.Flavor, d.TOC, d.Provenance = o.ConvertOptions, o.Flavor, o.TOC, o.Provenance
+	}
+	return ok
+}
+
+// PPTXOptions configures the PPTX converter.
+type PPTXOptions struct {
+	// Slides, KeepDataURIs, Marker, Provenance, Password and Images apply
+	// to presentations.
+	ConvertOptions
+}
+
+// Configure applies the options to a *PptxConverter.
+func (o PPTXOptions) Configure(c Converter) bool {
+	p, ok := c.(*PptxConverter)
+	if ok {
+		p.Options = o.ConvertOptions
+	}
+	return ok
+}
+
+// EPUBOptions configures the EPUB converter.
+type EPUBOptions struct {
+	// Chapters and Images apply to books.
+	ConvertOptions
+}
+
+// Configure applies the options to an *EpubConverter.
+func (o EPUBOptions) Configure(c Converter) bool {
+	e, ok := c.(*EpubConverter)
+	if ok {
+		e.Options = o.ConvertOptions
+	}
+	return ok
+}
+
+// HTMLOptions configures the HTML converter of files and web pages.
+type HTMLOptions struct {
+	// Images applies to the images of pages.
+	ConvertOptions
+
+	Client          *http.Client
+	SkipMetadata    bool
+	KeepTags        []string
+	RemoveSelectors []string
+}
+
+// Configure applies the options to an *HTMLConverter.
+func (o HTMLOptions) Configure(c Converter) bool {
+	h, ok := c.(*HTMLConverter)
+	if ok {
+		h.Options, h.Client, h.SkipMetadata = o.ConvertOptions, o.Client, o.SkipMetadata
+		h.KeepTags, h.RemoveSelectors = o.KeepTags, o.RemoveSelectors
+	}
+	return ok
+}
+
+// NotebookOptions configures the Jupyter notebook converter.
+type NotebookOptions struct {
+	// Images applies to the images of cell outputs.
+	ConvertOptions
+
+	SkipOutputs     bool
+	SkipCode        bool
+	MarkdownOnly    bool
+	ExecutionCounts bool
+}
+
+// Configure applies the options to an *IpynbConverter.
+func (o NotebookOptions) Configure(c Converter) bool {
+	n, ok := c.(*IpynbConverter)
+	if ok {
+		n.Options = o.ConvertOptions
+		n.SkipOutputs, n.SkipCode, n.MarkdownOnly, n.ExecutionCounts = o.SkipOutputs, o.SkipCode, o.MarkdownOnly, o.ExecutionCounts
+	}
+	return ok
+}
+
+// Configure applies the limits to the converters of emails and ZIP
+// archives.
+func (o ContainerLimits) Configure(c Converter) bool {
+	switch c := c.(type) {
+	case *EmlConverter:
+		c.Limits = o
+	case *ZipConverter:
+		c.Limits = o
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,149 @@
+package converters
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/flaviodelgrosso/marky/internal/utils"
+)
+
+// ConvertFunc converts the document at a path to markdown.
+type ConvertFunc func(ctx context.Context, path string) (string, error)
+
+// ContainerConverter is implemented by the converters of emails and
+// archives, which convert the files they hold with the function given to
+// SetConverter, such as the conversion of the Marky they are registered
+// with. Files are only listed when no function is set.
+type ContainerConverter interface {
+	Converter
+	SetConverter(convert ConvertFunc)
+}
+
+// ContainerLimits bounds the conversion of the files held by emails and
+// archives.
+type ContainerLimits struct {
+	// MaxDepth is the number of containers that may be nested, such as an
+	// archive attached to an email, 3 when zero. The files of containers
+	// nested deeper are listed without being converted.
+	MaxDepth int
+
+	// MaxSize is the size in bytes above which files are listed without
+	// being converted, 20 MiB when zero.
+	MaxSize int64
+
+	// MaxFiles is the number of files of a container that are converted,
+	// 100 when zero. The others are counted.
+	MaxFiles int
+}
+
+// attachment is a file held by a container.
+type attachment struct {
+	Name string
+	Size int64
+
+	// read returns the content of the file, called only for the files
+	// that are converted.
+	read func() ([]byte, error)
+}
+
+type depthKey struct{}
+
+// containerDepth returns the number of containers the document converted
+// with ctx is nested in.
+func containerDepth(ctx context.Context) int {
+	depth, _ := ctx.Value(depthKey{}).(int)
+	return depth
+}
+
+// writeAttachments writes the files of a container under a section heading,
+// each under a heading of its own followed by its markdown, with headings
+// moved below that of the file, or a note telling why it was not converted.
+func writeAttachments(ctx context.Context, w *strings.Builder, section string, files []attachment, limits ContainerLimits, inMemory bool, convert ConvertFunc) error {
+	if len(files) == 0 {
+		return nil
+	}
+	fmt.Fprintf(w, "\n## %s\n", section)
+
+	depth := containerDepth(ctx) + 1
+	maxFiles := cmp.Or(limits.MaxFiles, 100)
+	var dir string
+	defer func() {
+		if dir != "" {
+			os.RemoveAll(dir)
+		}
+	}()
+
+	for i, file := range files {
+		if i == maxFiles {
+			if skipped := len(files) - maxFiles; skipped == 1 {
+				w.WriteString("\n_… 1 more file_\n")
+			} else {
+				fmt.Fprintf(w, "\n_… %d more files_\n", skipped)
+			}
+			break
+		}
+		fmt.Fprintf(w, "\n### %s\n\n", utils.EscapeMarkdown(file.Name, utils.FlavorGFM))
+
+		var reason string
+		switch {
+		case convert == nil:
+			reason = "no converter"
+		case depth > cmp.Or(limits.MaxDepth, 3):
+			reason = "nested too deeply"
+		case file.Size > cmp.Or(limits.MaxSize, 20<<20):
+			reason = fmt.Sprintf("larger than %d bytes", cmp.Or(limits.MaxSize, 20<<20))
+		case inMemory:
+			reason = "files cannot be written in memory mode"
+		}
+		if reason != "" {
+			fmt.Fprintf(w, "_Not converted: %s._\n", reason)
+			continue
+		}
+
+		if dir == "" {
+			var err error
+			if dir, err = os.MkdirTemp("", "marky-attachments-"); err != nil {
+				return err
+			}
+		}
+		markdown, err := convertAttachment(context.WithValue(ctx, depthKey{}, depth), dir, i, file, convert)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil {
+			fmt.Fprintf(w, "_Not converted: %v._\n", err)
+			continue
+		}
+		_, body := utils.SplitFrontMatter(markdown)
+		if body = strings.TrimSpace(utils.ShiftHeadings(body, 3, 0)); body != "" {
+			w.WriteString(body + "\n")
+		}
+	}
+	return nil
+}
+
+// convertAttachment writes a file of a container to a directory of its own
+// in dir, keeping its base name so that its extension selects the
+// converter, and converts it.
+func convertAttachment(ctx context.Context, dir string, i int, file attachment, convert ConvertFunc) (string, error) {
+	data, err := file.read()
+	if err != nil {
+		return "", err
+	}
+	name := filepath.Base(filepath.FromSlash(file.Name))
+	if !localPath(name) {
+		name = "attachment"
+	}
+	path := filepath.Join(dir, fmt.Sprint(i), name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return "", err
+	}
+	return convert(ctx, path)
+}
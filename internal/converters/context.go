@@ -0,0 +1,50 @@
+package converters
+
+import "context"
+
+// ContextConverter is implemented by the converters whose conversions stop
+// when a context is done, such as those of PDF files, documents,
+// presentations, workbooks, CSV files, books and web pages. Their Load
+// converts with a background context.
+type ContextConverter interface {
+	Converter
+	LoadContext(ctx context.Context, path string) (string, error)
+}
+
+// LoadContext converts a document with a converter, returning the error of
+// ctx once it is done. Conversions by converters that do not implement
+// ContextConverter are abandoned rather than cancelled, as runContext does.
+func LoadContext(ctx context.Context, c Converter, path string) (string, error) {
+	if c, ok := c.(ContextConverter); ok {
+		return c.LoadContext(ctx, path)
+	}
+	return runContext(ctx, func() (string, error) {
+		return c.Load(path)
+	})
+}
+
+// runContext runs a conversion that cannot be cancelled, returning early
+// with the error of ctx once it is done. It abandons the conversion rather
+// than cancelling it: the conversion keeps running in the background, using
+// its CPU and memory until it finishes, and its result is dropped. It only
+// serves converters that cannot check ctx themselves.
+func runContext(ctx context.Context, convert func() (string, error)) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	type result struct {
+		markdown string
+		err      error
+	}
+	done := make(chan result, 1)
+	go func() {
+		markdown, err := convert()
+		done <- result{markdown, err}
+	}()
+	select {
+	case r := <-done:
+		return r.markdown, r.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
@@ -0,0 +1,71 @@
+package converters
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestLoadContext_Cancelled(t *testing.T) {
+	tempDir := t.TempDir()
+	csvFile := filepath.Join(tempDir, "data.csv")
+	if err := os.WriteFile(csvFile, []byte("a,b\n1,2\n"), 0o644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	excelFile := filepath.Join(tempDir, "data.xlsx")
+	f := excelize.NewFile()
+	defer f.Close()
+	f.SetSheetRow("Sheet1", "A1", &[]string{"a", "b"})
+	if err := f.SaveAs(excelFile); err != nil {
+		t.Fatalf("Failed to create test Excel file: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name      string
+		converter Converter
+		path      string
+	}{
+		{"csv", NewCsvConverter(), csvFile},
+		{"excel", &ExcelConverter{}, excelFile},
+		{"docx", NewDocConverter(), filepath.Join("..", "..", "test_files", "test.docx")},
+		{"epub", NewEpubConverter(), filepath.Join("..", "..", "test_files", "test.epub")},
+	}
+	for _, tt := range tests {
+		if _, ok := tt.converter.(ContextConverter); !ok {
+			t.Errorf("%s converter does not implement ContextConverter", tt.name)
+		}
+		if _, err := LoadContext(ctx, tt.converter, tt.path); !errors.Is(err, context.Canceled) {
+			t.Errorf("LoadContext() of %s error = %v, want context.Canceled", tt.name, err)
+		}
+		if _, err := LoadContext(context.Background(), tt.converter, tt.path); err != nil {
+			t.Errorf("LoadContext() of %s returned unexpected error: %v", tt.name, err)
+		}
+	}
+}
+
+func TestLoadBytes_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := map[string]BytesConverter{
+		"csv":  NewCsvConverter().(BytesConverter),
+		"docx": NewDocConverter().(BytesConverter),
+		"epub": NewEpubConverter().(BytesConverter),
+	}
+	for name, converter := range tests {
+		data, err := os.ReadFile(filepath.Join("..", "..", "test_files", "test."+name))
+		if err != nil {
+			t.Fatalf("Failed to read test file: %v", err)
+		}
+		if _, err := converter.LoadBytes(ctx, data); !errors.Is(err, context.Canceled) {
+			t.Errorf("LoadBytes() of %s error = %v, want context.Canceled", name, err)
+		}
+	}
+}
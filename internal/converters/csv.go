@@ -1,18 +1,75 @@
 package converters
 
 import (
+	"bytes"
+	"context"
 	"encoding/csv"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"regexp"
+	"slices"
+	"strings"
 
 	"github.com/flaviodelgrosso/marky/internal/utils"
 )
 
 // CsvConverter handles loading and converting CSV files to markdown tables.
+// Records are streamed into the table as they are parsed.
 type CsvConverter struct {
 	BaseConverter
+
+	// Header controls whether the first record is the table header,
+	// HeaderDetect by default.
+	Header HeaderMode
+
+	// MaxRows limits the number of data rows written, followed by a note
+	// with the number of rows left out. Rows are not limited when zero.
+	MaxRows int
+
+	// AlignColumns right-aligns columns of numbers and centers columns of
+	// dates. Column types are inferred from the first records.
+	AlignColumns bool
+
+	// MaxCellWidth cuts values wider than that many characters, ending them
+	// with an ellipsis. Values are not cut when zero.
+	MaxCellWidth int
+
+	// CellFootnotes writes the full text of the values cut by MaxCellWidth
+	// as footnotes beneath the table.
+	CellFootnotes bool
+
+	// StripThousands removes the thousands separators of numbers, writing
+	// "1,234.5" as "1234.5".
+	StripThousands bool
+
+	// Tables sets the layout of the table, as set by the Tables field of
+	// ConvertOptions. MaxCellWidth takes precedence over its cell width.
+	Tables utils.TableOptions
 }
 
+// HeaderMode controls which row of a CSV file becomes the table header.
+type HeaderMode int
+
+const (
+	// HeaderDetect makes the first record the header unless the values of
+	// the next records suggest it is data, such as a number in a column of
+	// numbers.
+	HeaderDetect HeaderMode = iota
+
+	// HeaderFirstRow always makes the first record the header.
+	HeaderFirstRow
+
+	// HeaderNone writes generated column names, Col1 to ColN, as the header
+	// and every record as data.
+	HeaderNone
+)
+
+// csvSampleRows is the number of records after the first one that header
+// detection looks at.
+const csvSampleRows = 20
+
 // NewCsvConverter creates a new CSV converter with appropriate MIME types and extensions.
 func NewCsvConverter() Converter {
 	return &CsvConverter{
@@ -23,29 +80,200 @@ func NewCsvConverter() Converter {
 	}
 }
 
-// Load reads a CSV file and converts it to a markdown table.
-func (*CsvConverter) Load(path string) (string, error) {
-	records, err := readCsvFile(path)
+// Capabilities returns what the converter can extract from CSV files.
+func (c *CsvConverter) Capabilities() Capabilities {
+	return Capabilities{Tables: true, Streaming: true}
+}
+
+// Load reads a CSV file and converts it to a markdown table. The first
+// records are held back until the header is known.
+func (c *CsvConverter) Load(path string) (string, error) {
+	return c.LoadContext(context.Background(), path)
+}
+
+// LoadContext converts a CSV file like Load, stopping before the next record
+// once ctx is done.
+func (c *CsvConverter) LoadContext(ctx context.Context, path string) (string, error) {
+	return c.convert(ctx, func(fn func(record []string) error) error {
+		return streamCsvFile(path, fn)
+	})
+}
+
+// LoadBytes converts CSV data held in memory like LoadContext.
+func (c *CsvConverter) LoadBytes(ctx context.Context, data []byte) (string, error) {
+	return c.convert(ctx, func(fn func(record []string) error) error {
+		return streamCsv(bytes.NewReader(data), memoryName, fn)
+	})
+}
+
+// convert converts the records passed to fn by stream to a markdown table,
+// stopping once ctx is done.
+func (c *CsvConverter) convert(ctx context.Context, stream func(fn func(record []string) error) error) (string, error) {
+	var buf strings.Builder
+	var sample [][]string
+	var table *csvTable
+
+	err := stream(func(record []string) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if table != nil {
+			table.write(record)
+			return nil
+		}
+		sample = append(sample, slices.Clone(record))
+		if len(sample) > csvSampleRows {
+			table = c.startTable(&buf, sample)
+		}
+		return nil
+	})
 	if err != nil {
-		return "", fmt.Errorf("failed to load CSV file: %w", err)
+		return "", fmt.Errorf("failed to load CSV file: %w", corrupt(err))
+	}
+
+	if table == nil && len(sample) > 0 {
+		table = c.startTable(&buf, sample)
+	}
+	if table != nil {
+		table.finish()
+	}
+	return buf.String(), nil
+}
+
+// startTable writes the header and the records read so far.
+func (c *CsvConverter) startTable(w *strings.Builder, sample [][]string) *csvTable {
+	table := &csvTable{w: w, TableWriter: c.Tables.NewTableWriter(w), limit: c.MaxRows, stripThousands: c.StripThousands}
+	table.InferAlign = c.AlignColumns
+	if c.MaxCellWidth > 0 {
+		table.MaxCellWidth = c.MaxCellWidth
 	}
+	if c.CellFootnotes {
+		table.Footnotes = new(int)
+	}
+	header := c.Header == HeaderFirstRow || (c.Header == HeaderDetect && hasHeader(sample))
+
+	if !header {
+		columns := make([]string, len(sample[0]))
+		for i := range columns {
+			columns[i] = fmt.Sprintf("Col%d", i+1)
+		}
+		table.write(columns)
+	}
+	for _, record := range sample {
+		table.write(record)
+	}
+	return table
+}
+
+// csvTable writes the rows of a table up to the row limit and counts the
+// rows left out.
+type csvTable struct {
+	*utils.TableWriter
+	w              *strings.Builder
+	rows           int
+	limit          int
+	skipped        int
+	stripThousands bool
+}
+
+// write writes the header on the first call and a data row afterwards.
+func (t *csvTable) write(record []string) {
+	if t.limit > 0 && t.rows > t.limit {
+		t.skipped++
+		return
+	}
+	if t.stripThousands && t.rows > 0 {
+		for i, value := range record {
+			if thousandsNumber.MatchString(strings.TrimSpace(value)) {
+				record[i] = strings.ReplaceAll(strings.TrimSpace(value), ",", "")
+			}
+		}
+	}
+	for i, value := range record {
+		record[i] = utils.EscapeMarkdown(value, utils.FlavorGFM)
+	}
+	t.WriteRow(record)
+	t.rows++
+}
+
+// finish writes the rows held by the table and the note with the number of
+// rows left out.
+func (t *csvTable) finish() {
+	t.Flush()
+	switch {
+	case t.skipped == 1:
+		t.w.WriteString("\n_… 1 more row_\n")
+	case t.skipped > 1:
+		fmt.Fprintf(t.w, "\n_… %d more rows_\n", t.skipped)
+	}
+}
+
+// hasHeader guesses whether the first record is a header from the values
+// below it. Each column of numbers or dates votes: for a header when its
+// first value is text, and against it when the first value is a number or
+// date too. Ties, such as in text-only files, keep the first record as the
+// header.
+func hasHeader(records [][]string) bool {
+	if len(records) < 2 {
+		return true
+	}
+	header, rows := records[0], records[1:]
 
-	return utils.ToMarkdownTable(records), nil
+	votes := 0
+	for col, name := range header {
+		switch kind := utils.ColumnKind(rows, col); {
+		case kind == utils.KindText:
+		case utils.KindOf(name) == kind:
+			votes--
+		default:
+			votes++
+		}
+	}
+	return votes >= 0
 }
 
+// thousandsNumber matches numbers with comma thousands separators.
+var thousandsNumber = regexp.MustCompile(`^[+-]?\d{1,3}(,\d{3})+(\.\d+)?$`)
+
 // readCsvFile reads and parses a CSV file, returning all records.
 func readCsvFile(path string) ([][]string, error) {
-	f, err := os.Open(path)
+	var records [][]string
+	err := streamCsvFile(path, func(record []string) error {
+		records = append(records, slices.Clone(record))
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("unable to open file %s: %w", path, err)
+		return nil, err
 	}
-	defer f.Close()
+	return records, nil
+}
 
-	csvReader := csv.NewReader(f)
-	records, err := csvReader.ReadAll()
+// streamCsvFile parses a CSV file, passing each record to fn as it is read.
+// The record slice is only valid until fn returns, and parsing stops with
+// the error fn returns.
+func streamCsvFile(path string, fn func(record []string) error) error {
+	f, err := os.Open(path)
 	if err != nil {
-		return nil, fmt.Errorf("unable to parse CSV file %s: %w", path, err)
+		return fmt.Errorf("unable to open file %s: %w", path, err)
 	}
+	defer f.Close()
+	return streamCsv(f, path, fn)
+}
 
-	return records, nil
+// streamCsv parses CSV data like streamCsvFile, naming it name in errors.
+func streamCsv(r io.Reader, name string, fn func(record []string) error) error {
+	csvReader := csv.NewReader(r)
+	csvReader.ReuseRecord = true
+	for {
+		record, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("unable to parse CSV file %s: %w", name, corrupt(err))
+		}
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
 }
@@ -1,11 +1,14 @@
 package converters
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
+
+	"github.com/flaviodelgrosso/marky/internal/utils"
 )
 
 func TestNewCsvConverter(t *testing.T) {
@@ -311,3 +314,189 @@ func TestReadCsvFile_InvalidCSV(t *testing.T) {
 		t.Errorf("readCsvFile() error should mention CSV parsing failure")
 	}
 }
+
+func TestCsvConverter_Load_MaxRows(t *testing.T) {
+	csvFile := filepath.Join(t.TempDir(), "large.csv")
+	var content strings.Builder
+	content.WriteString("ID,Name\n")
+	for i := 1; i <= 10; i++ {
+		fmt.Fprintf(&content, "%d,Item %d\n", i, i)
+	}
+	if err := os.WriteFile(csvFile, []byte(content.String()), 0o644); err != nil {
+		t.Fatalf("Failed to create test CSV file: %v", err)
+	}
+
+	tests := []struct {
+		maxRows  int
+		expected string
+	}{
+		{maxRows: 2, expected: "| ID | Name |\n| --- | --- |\n| 1 | Item 1 |\n| 2 | Item 2 |\n\n_… 8 more rows_\n"},
+		{maxRows: 9, expected: "| ID | Name |\n| --- | --- |\n" + csvRows(1, 9) + "\n_… 1 more row_\n"},
+		{maxRows: 10, expected: "| ID | Name |\n| --- | --- |\n" + csvRows(1, 10)},
+		{maxRows: 0, expected: "| ID | Name |\n| --- | --- |\n" + csvRows(1, 10)},
+	}
+	for _, tt := range tests {
+		converter := &CsvConverter{MaxRows: tt.maxRows}
+		result, err := converter.Load(csvFile)
+		if err != nil {
+			t.Fatalf("Load() returned unexpected error: %v", err)
+		}
+		if result != tt.expected {
+			t.Errorf("Load() with MaxRows %d = %q, want %q", tt.maxRows, result, tt.expected)
+		}
+	}
+}
+
+// csvRows returns the table rows written for the items from and to.
+func csvRows(from, to int) string {
+	var rows strings.Builder
+	for i := from; i <= to; i++ {
+		fmt.Fprintf(&rows, "| %d | Item %d |\n", i, i)
+	}
+	return rows.String()
+}
+
+func TestCsvConverter_Load_HeaderModes(t *testing.T) {
+	csvFile := filepath.Join(t.TempDir(), "readings.csv")
+	if err := os.WriteFile(csvFile, []byte("2024-01-01,12.5,A1\n2024-01-02,13,B2\n2024-01-03,11.25,C3\n"), 0o644); err != nil {
+		t.Fatalf("Failed to create test CSV file: %v", err)
+	}
+
+	tests := []struct {
+		header   HeaderMode
+		expected string
+	}{
+		{
+			header: HeaderDetect,
+			expected: "| Col1 | Col2 | Col3 |\n| --- | --- | --- |\n| 2024-01-01 | 12.5 | A1 |\n" +
+				"| 2024-01-02 | 13 | B2 |\n| 2024-01-03 | 11.25 | C3 |\n",
+		},
+		{
+			header:   HeaderFirstRow,
+			expected: "| 2024-01-01 | 12.5 | A1 |\n| --- | --- | --- |\n| 2024-01-02 | 13 | B2 |\n| 2024-01-03 | 11.25 | C3 |\n",
+		},
+	}
+	for _, tt := range tests {
+		converter := &CsvConverter{Header: tt.header}
+		result, err := converter.Load(csvFile)
+		if err != nil {
+			t.Fatalf("Load() returned unexpected error: %v", err)
+		}
+		if result != tt.expected {
+			t.Errorf("Load() with header mode %d = %q, want %q", tt.header, result, tt.expected)
+		}
+	}
+
+	converter := &CsvConverter{Header: HeaderNone, MaxRows: 1}
+	result, err := converter.Load(csvFile)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	expected := "| Col1 | Col2 | Col3 |\n| --- | --- | --- |\n| 2024-01-01 | 12.5 | A1 |\n\n_… 2 more rows_\n"
+	if result != expected {
+		t.Errorf("Load() with HeaderNone = %q, want %q", result, expected)
+	}
+}
+
+func TestHasHeader(t *testing.T) {
+	tests := []struct {
+		name    string
+		records [][]string
+		want    bool
+	}{
+		{"single record", [][]string{{"1", "2"}}, true},
+		{"names over numbers", [][]string{{"Name", "Age"}, {"John", "30"}, {"Jane", "25"}}, true},
+		{"numbers over numbers", [][]string{{"1", "30"}, {"2", "25"}}, false},
+		{"dates and amounts", [][]string{{"2024-01-01", "Opening", "100"}, {"2024-01-02", "Sale", "12.5"}}, false},
+		{"mixed column", [][]string{{"ID", "Value"}, {"1", "n/a"}, {"x", "3"}}, true},
+		{"text over numbers", [][]string{{"1", "2"}, {"a", "3"}, {"4", "5"}}, false},
+		{"more numeric columns than not", [][]string{{"1", "Total", "2"}, {"3", "4", "5"}}, false},
+		{"text only", [][]string{{"Name", "City"}, {"John", "New York"}, {"Jane", "Los Angeles"}}, true},
+	}
+	for _, tt := range tests {
+		if got := hasHeader(tt.records); got != tt.want {
+			t.Errorf("hasHeader() for %s = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCsvConverter_Load_AlignColumns(t *testing.T) {
+	csvFile := filepath.Join(t.TempDir(), "expenses.csv")
+	content := "Date,Item,Amount,Ref\n2024-01-01,Rent,\"1,200.00\",A-1\n2024-01-15,Coffee,3.5,\n2024-02-01,Laptop,\"2,499\",B-7\n"
+	if err := os.WriteFile(csvFile, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to create test CSV file: %v", err)
+	}
+
+	converter := &CsvConverter{AlignColumns: true}
+	result, err := converter.Load(csvFile)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	expected := "| Date | Item | Amount | Ref |\n| :---: | --- | ---: | --- |\n| 2024-01-01 | Rent | 1,200.00 | A-1 |\n" +
+		"| 2024-01-15 | Coffee | 3.5 |  |\n| 2024-02-01 | Laptop | 2,499 | B-7 |\n"
+	if result != expected {
+		t.Errorf("Load() with AlignColumns = %q, want %q", result, expected)
+	}
+
+	converter = &CsvConverter{StripThousands: true}
+	result, err = converter.Load(csvFile)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	expected = "| Date | Item | Amount | Ref |\n| --- | --- | --- | --- |\n| 2024-01-01 | Rent | 1200.00 | A-1 |\n" +
+		"| 2024-01-15 | Coffee | 3.5 |  |\n| 2024-02-01 | Laptop | 2499 | B-7 |\n"
+	if result != expected {
+		t.Errorf("Load() with StripThousands = %q, want %q", result, expected)
+	}
+}
+
+func TestCsvConverter_Load_PrettyTables(t *testing.T) {
+	csvFile := filepath.Join(t.TempDir(), "cities.csv")
+	if err := os.WriteFile(csvFile, []byte("City,Population\nRome,2800000\nOslo,700000\nBern,140000\n"), 0o644); err != nil {
+		t.Fatalf("Failed to create test CSV file: %v", err)
+	}
+
+	converter := &CsvConverter{MaxRows: 2, AlignColumns: true, Tables: utils.TableOptions{Style: utils.TablePretty}}
+	result, err := converter.Load(csvFile)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	expected := "| City | Population |\n| ---- | ---------: |\n| Rome |    2800000 |\n| Oslo |     700000 |\n\n_… 1 more row_\n"
+	if result != expected {
+		t.Errorf("Load() with pretty tables = %q, want %q", result, expected)
+	}
+}
+
+func TestCsvConverter_Load_MaxCellWidth(t *testing.T) {
+	csvFile := filepath.Join(t.TempDir(), "notes.csv")
+	if err := os.WriteFile(csvFile, []byte("ID,Note\n1,Short\n2,A note that goes on and on\n3,Another long note\n"), 0o644); err != nil {
+		t.Fatalf("Failed to create test CSV file: %v", err)
+	}
+
+	converter := &CsvConverter{MaxCellWidth: 10, CellFootnotes: true, MaxRows: 2}
+	result, err := converter.Load(csvFile)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	expected := "| ID | Note |\n| --- | --- |\n| 1 | Short |\n| 2 | A note th…[^1] |\n" +
+		"\n[^1]: A note that goes on and on\n\n_… 1 more row_\n"
+	if result != expected {
+		t.Errorf("Load() with MaxCellWidth = %q, want %q", result, expected)
+	}
+}
+
+func TestCsvConverter_Load_EscapesMarkdown(t *testing.T) {
+	csvFile := filepath.Join(t.TempDir(), "formatting.csv")
+	if err := os.WriteFile(csvFile, []byte("Name,Pattern\nfile_name,*.go\n[draft],a|b\n"), 0o644); err != nil {
+		t.Fatalf("Failed to create test CSV file: %v", err)
+	}
+
+	result, err := NewCsvConverter().Load(csvFile)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	expected := "| Name | Pattern |\n| --- | --- |\n| file_name | \\*.go |\n| \\[draft\\] | a\\|b |\n"
+	if result != expected {
+		t.Errorf("Load() = %q, want %q", result, expected)
+	}
+}
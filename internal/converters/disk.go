@@ -0,0 +1,15 @@
+package converters
+
+import "errors"
+
+// ErrDiskWrite is returned for conversions needing to write to disk with
+// the InMemory option set.
+var ErrDiskWrite = errors.New("writing to disk is disabled")
+
+// checkDiskWrite returns ErrDiskWrite when the InMemory option is set.
+func (o ConvertOptions) checkDiskWrite() error {
+	if o.InMemory {
+		return ErrDiskWrite
+	}
+	return nil
+}
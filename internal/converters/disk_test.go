@@ -0,0 +1,40 @@
+package converters
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestInMemory(t *testing.T) {
+	dir := t.TempDir()
+	workbook := filepath.Join(t.TempDir(), "test.xlsx")
+	f := excelize.NewFile()
+	f.SetCellValue("Sheet1", "A1", "Name")
+	if err := f.SaveAs(workbook); err != nil {
+		t.Fatalf("Failed to create test Excel file: %v", err)
+	}
+	f.Close()
+
+	options := ConvertOptions{InMemory: true, ImageDir: dir}
+	excel := NewExcelConverter()
+	if !options.Configure(excel) || !excel.(*ExcelConverter).InMemory {
+		t.Error("Configure() did not set InMemory on the Excel converter")
+	}
+	if result, err := excel.Load(workbook); err != nil || result != "## Sheet1\n\n| Name |\n| --- |\n" {
+		t.Errorf("ExcelConverter.Load() = %q, %v", result, err)
+	}
+
+	if _, err := saveImage(options, "image.png", "image/png", []byte("png"), make(map[string]bool)); !errors.Is(err, ErrDiskWrite) {
+		t.Errorf("saveImage() error = %v, want ErrDiskWrite", err)
+	}
+	if _, err := (MutoolEngine{}).Extract("report.pdf", options); !errors.Is(err, ErrDiskWrite) {
+		t.Errorf("MutoolEngine.Extract() error = %v, want ErrDiskWrite", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) > 0 {
+		t.Errorf("%d files written while InMemory is set", len(entries))
+	}
+}
@@ -3,7 +3,7 @@ package converters
 import (
 	"archive/zip"
 	"bytes"
-	"encoding/base64"
+	"context"
 	"encoding/xml"
 	"errors"
 	"fmt"
@@ -11,6 +11,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -20,8 +21,40 @@ import (
 // DocConverter handles loading and converting DOC and DOCX files to markdown.
 type DocConverter struct {
 	BaseConverter
+
+	// Flavor controls how underline, superscript, subscript and highlight
+	// runs are rendered.
+	Flavor Flavor
+
+	// TOC controls how table of contents fields are converted.
+	TOC TOCMode
+
+	// Options configures the image policy and directory. Images are
+	// embedded in the document, so ImagesLink keeps links to their path in
+	// the package, such as media/image1.png, and ImagesDownload writes them
+	// under that path in ImageDir.
+	Options ConvertOptions
+
+	// Provenance writes a <!-- source: paragraph N --> comment before each
+	// paragraph of the document body, and <!-- source: table N --> before
+	// each table, numbered from 1 in document order.
+	Provenance bool
 }
 
+// TOCMode controls how DOCX table of contents fields are converted.
+type TOCMode int
+
+const (
+	// TOCRegenerate replaces the field with a list built from the document headings.
+	TOCRegenerate TOCMode = iota
+
+	// TOCStrip drops the field and its cached entries entirely.
+	TOCStrip
+)
+
+// tocPlaceholder marks where a TOC field stood until the headings are known.
+const tocPlaceholder = "\x00toc\x00"
+
 // NewDocConverter creates a new DOC converter with appropriate MIME types and extensions.
 func NewDocConverter() Converter {
 	return &DocConverter{
@@ -36,13 +69,55 @@ func NewDocConverter() Converter {
 	}
 }
 
+// Capabilities returns what the converter can extract from DOCX files.
+func (d *DocConverter) Capabilities() Capabilities {
+	return Capabilities{Images: true, Tables: true, Password: true}
+}
+
 // Load reads a DOC or DOCX file and converts it to markdown.
-func (*DocConverter) Load(filePath string) (string, error) {
-	content, err := convertDocxToMarkdown(filePath)
+func (d *DocConverter) Load(filePath string) (string, error) {
+	return d.LoadContext(context.Background(), filePath)
+}
+
+// LoadContext converts a DOC or DOCX file like Load, stopping before the
+// next paragraph or table of the body once ctx is done.
+func (d *DocConverter) LoadContext(ctx context.Context, filePath string) (string, error) {
+	content, err := convertDocxToMarkdown(ctx, filePath, d)
+	if err != nil {
+		return "", fmt.Errorf("failed to convert document: %w", corrupt(err))
+	}
+
+	return content, nil
+}
+
+// LoadTo converts a DOC or DOCX file like Load, writing the markdown to w
+// as the document is walked.
+func (d *DocConverter) LoadTo(ctx context.Context, w io.Writer, filePath string) error {
+	r, err := openPackage(filePath, d.Options.Password, d.Options.MmapThreshold, d.Options.ArchiveLimits)
 	if err != nil {
-		return "", fmt.Errorf("failed to convert document: %w", err)
+		return fmt.Errorf("failed to convert document: %w", corrupt(err))
+	}
+	defer r.Close()
+	if err := writeDocx(ctx, r.Reader, d, w); err != nil {
+		return fmt.Errorf("failed to convert document: %w", corrupt(err))
 	}
+	return nil
+}
 
+// LoadBytes converts a DOCX document held in memory like Load.
+func (d *DocConverter) LoadBytes(ctx context.Context, data []byte) (string, error) {
+	data, err := decryptPackage(data, d.Options.Password)
+	if err != nil {
+		return "", fmt.Errorf("failed to convert document: %w", corrupt(err))
+	}
+	r, err := newArchiveReader(bytes.NewReader(data), int64(len(data)), d.Options.ArchiveLimits)
+	if err != nil {
+		return "", fmt.Errorf("failed to convert document: %w", corrupt(err))
+	}
+	content, err := convertDocx(ctx, r, d)
+	if err != nil {
+		return "", fmt.Errorf("failed to convert document: %w", corrupt(err))
+	}
 	return content, nil
 }
 
@@ -137,15 +212,53 @@ type Numbering struct {
 		Text          string  `xml:",chardata"`
 		NumID         string  `xml:"numId,attr"`
 		AbstractNumID TextVal `xml:"abstractNumId"`
+		LvlOverride   []struct {
+			Ilvl          string  `xml:"ilvl,attr"`
+			StartOverride TextVal `xml:"startOverride"`
+		} `xml:"lvlOverride"`
 	} `xml:"num"`
 }
 
 type file struct {
-	rels  Relationships
-	num   Numbering
-	r     *zip.ReadCloser
-	embed bool
-	list  map[string]int
+	// ctx stops the walk of the body once it is done. It is not checked
+	// when nil.
+	ctx context.Context
+
+	rels      Relationships
+	num       Numbering
+	r         *zip.Reader
+	list      map[listKey]int
+	restarted map[string]bool
+	flavor    Flavor
+
+	// provenance writes source comments before the blocks of the body.
+	provenance bool
+
+	// images holds the image policy and directory.
+	images ConvertOptions
+
+	// fields is the stack of complex fields currently being walked.
+	fields []string
+	// tocTouched is set when the current paragraph is part of a TOC field.
+	tocTouched bool
+	// tocEnded is set when a TOC field closes and its placeholder is pending.
+	tocEnded bool
+}
+
+// listKey identifies a list counter. Word shares counters between all num
+// instances that reference the same abstract numbering definition.
+type listKey struct {
+	abstractNumID string
+	ilvl          int
+}
+
+// numberingLevel is the resolved format of a single list level.
+type numberingLevel struct {
+	abstractNumID string
+	numFmt        string
+	start         int
+	ind           int
+	override      bool
 }
 
 // Node is
@@ -164,20 +277,7 @@ func (n *Node) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 	return d.DecodeElement((*node)(n), &start)
 }
 
-func escape(s, set string) string {
-	replacer := []string{}
-	for _, r := range set {
-		rs := string(r)
-		replacer = append(replacer, rs, `\`+rs)
-	}
-	return strings.NewReplacer(replacer...).Replace(s)
-}
-
 func (zf *file) extract(rel *Relationship, w io.Writer) error {
-	err := os.MkdirAll(filepath.Dir(rel.Target), 0o755)
-	if err != nil {
-		return err
-	}
 	for _, f := range zf.r.File {
 		if f.Name != "word/"+rel.Target {
 			continue
@@ -188,20 +288,41 @@ func (zf *file) extract(rel *Relationship, w io.Writer) error {
 		}
 		defer rc.Close()
 
-		b := make([]byte, f.UncompressedSize64)
-		n, err := rc.Read(b)
-		if err != nil && !errors.Is(err, io.EOF) {
-			return err
-		}
-		if zf.embed {
-			fmt.Fprintf(w, "![](data:image/png;base64,%s)",
-				base64.StdEncoding.EncodeToString(b[:n]))
-		} else {
-			err = os.WriteFile(rel.Target, b, 0o644)
+		switch zf.images.Images {
+		case ImagesDrop:
+		case ImagesInline:
+			b, err := io.ReadAll(rc)
 			if err != nil {
 				return err
 			}
-			fmt.Fprintf(w, "![](%s)", escape(rel.Target, "()"))
+			mediaType, ok := imageMediaType(rel.Target, b)
+			if !ok {
+				mediaType = "application/octet-stream"
+			}
+			fmt.Fprintf(w, "![](%s)", dataURI(mediaType, b))
+		case ImagesDownload:
+			// The target names the file written under the image directory,
+			// so it must not climb out of it.
+			if !localPath(rel.Target) {
+				return &ArchiveError{Name: rel.Target, Err: ErrUnsafePath}
+			}
+			if err := zf.images.checkDiskWrite(); err != nil {
+				return err
+			}
+			b, err := io.ReadAll(rc)
+			if err != nil {
+				return err
+			}
+			file := filepath.Join(zf.images.ImageDir, rel.Target)
+			if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+				return err
+			}
+			if err := os.WriteFile(file, b, 0o644); err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "![](%s)", utils.EscapeURL(filepath.ToSlash(file)))
+		default:
+			fmt.Fprintf(w, "![](%s)", utils.EscapeURL(rel.Target))
 		}
 		break
 	}
@@ -217,12 +338,32 @@ func attr(attrs []xml.Attr, name string) (string, bool) {
 	return "", false
 }
 
+// err returns the error of the context of the walk once it is done.
+func (zf *file) err() error {
+	if zf.ctx == nil {
+		return nil
+	}
+	return zf.ctx.Err()
+}
+
 func (zf *file) walk(node *Node, w io.Writer) error {
 	switch node.XMLName.Local {
+	case "body":
+		if zf.provenance {
+			return zf.handleBody(node, w)
+		}
+		for _, n := range node.Nodes {
+			if err := zf.err(); err != nil {
+				return err
+			}
+			if err := zf.walk(&n, w); err != nil {
+				return err
+			}
+		}
 	case "hyperlink":
 		return zf.handleHyperlink(node, w)
 	case "t":
-		fmt.Fprint(w, string(node.Content))
+		fmt.Fprint(w, utils.EscapeMarkdown(string(node.Content), zf.flavor))
 	case "pPr":
 		return zf.handlePPr(node, w)
 	case "tbl":
@@ -230,20 +371,27 @@ func (zf *file) walk(node *Node, w io.Writer) error {
 	case "r":
 		return zf.handleR(node, w)
 	case "p":
-		for _, n := range node.Nodes {
-			if err := zf.walk(&n, w); err != nil {
-				return err
-			}
+		return zf.handleP(node, w)
+	case "fldChar":
+		zf.handleFldChar(node)
+	case "instrText":
+		if len(zf.fields) > 0 {
+			zf.fields[len(zf.fields)-1] += string(node.Content)
+		}
+		if zf.inTOCField() {
+			zf.tocTouched = true
 		}
-		fmt.Fprintln(w)
+	case "fldSimple":
+		return zf.handleFldSimple(node, w)
 	case "blip":
 		return zf.handleBlip(node, w)
 	case "Fallback":
 		// no-op
 	case "txbxContent":
-		var cbuf bytes.Buffer
+		cbuf := getBuffer()
+		defer putBuffer(cbuf)
 		for _, n := range node.Nodes {
-			if err := zf.walk(&n, &cbuf); err != nil {
+			if err := zf.walk(&n, cbuf); err != nil {
 				return err
 			}
 		}
@@ -260,22 +408,170 @@ func (zf *file) walk(node *Node, w io.Writer) error {
 
 // --- Helper methods for walk ---
 
+// handleBody walks the blocks of the document body, writing a source comment
+// before each paragraph and table that has any output.
+func (zf *file) handleBody(node *Node, w io.Writer) error {
+	paragraphs, tables := 0, 0
+	cbuf := getBuffer()
+	defer putBuffer(cbuf)
+	for _, n := range node.Nodes {
+		if err := zf.err(); err != nil {
+			return err
+		}
+		var location string
+		switch n.XMLName.Local {
+		case "p":
+			paragraphs++
+			location = fmt.Sprintf("paragraph %d", paragraphs)
+		case "tbl":
+			tables++
+			location = fmt.Sprintf("table %d", tables)
+		}
+
+		cbuf.Reset()
+		if err := zf.walk(&n, cbuf); err != nil {
+			return err
+		}
+		text := cbuf.Bytes()
+		if location != "" && len(bytes.TrimSpace(text)) > 0 && !bytes.Contains(text, []byte(tocPlaceholder)) {
+			fmt.Fprint(w, sourceComment(location))
+		}
+		w.Write(text)
+	}
+	return nil
+}
+
+func (zf *file) handleP(node *Node, w io.Writer) error {
+	inTOC := zf.inTOCField()
+	outerTouched := zf.tocTouched
+	zf.tocTouched = false
+	defer func() { zf.tocTouched = outerTouched }()
+
+	cbuf := getBuffer()
+	defer putBuffer(cbuf)
+	for i := 0; i < len(node.Nodes); i++ {
+		n := &node.Nodes[i]
+		if n.XMLName.Local != "r" {
+			if err := zf.walk(n, cbuf); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// Word often splits a single formatted span (notably RTL text) into
+		// several runs; merge them so emphasis markers wrap the whole span.
+		j := i + 1
+		style := parseRunStyle(n)
+		for j < len(node.Nodes) && node.Nodes[j].XMLName.Local == "r" && parseRunStyle(&node.Nodes[j]) == style {
+			j++
+		}
+		if err := zf.writeRuns(node.Nodes[i:j], style, cbuf); err != nil {
+			return err
+		}
+		i = j - 1
+	}
+
+	// Paragraphs holding cached TOC entries are dropped in favor of the placeholder
+	if inTOC || zf.tocTouched {
+		if zf.tocEnded {
+			zf.tocEnded = false
+			fmt.Fprintln(w, tocPlaceholder)
+		}
+		return nil
+	}
+
+	if isBidiParagraph(node) {
+		fmt.Fprintf(w, "<div dir=\"rtl\">\n\n%s\n\n</div>\n", strings.TrimRight(cbuf.String(), "\n"))
+		return nil
+	}
+
+	fmt.Fprintln(w, cbuf.String())
+	return nil
+}
+
+// isBidiParagraph reports whether the paragraph properties declare w:bidi.
+func isBidiParagraph(node *Node) bool {
+	for _, n := range node.Nodes {
+		if n.XMLName.Local != "pPr" {
+			continue
+		}
+		for _, nn := range n.Nodes {
+			if nn.XMLName.Local == "bidi" {
+				return isOn(&nn)
+			}
+		}
+	}
+	return false
+}
+
+// isOn reports whether a boolean property element is enabled. OOXML toggles
+// are on when present unless w:val is explicitly false.
+func isOn(n *Node) bool {
+	val, ok := attr(n.Attrs, "val")
+	return !ok || (val != "0" && val != "false" && val != "off")
+}
+
+func (zf *file) handleFldChar(node *Node) {
+	typ, _ := attr(node.Attrs, "fldCharType")
+	switch typ {
+	case "begin":
+		zf.fields = append(zf.fields, "")
+	case "end":
+		if len(zf.fields) == 0 {
+			return
+		}
+		instr := zf.fields[len(zf.fields)-1]
+		zf.fields = zf.fields[:len(zf.fields)-1]
+		if isTOCInstr(instr) {
+			zf.tocTouched = true
+			zf.tocEnded = true
+		}
+	}
+	if zf.inTOCField() {
+		zf.tocTouched = true
+	}
+}
+
+func (zf *file) handleFldSimple(node *Node, w io.Writer) error {
+	if instr, _ := attr(node.Attrs, "instr"); isTOCInstr(instr) {
+		zf.tocTouched = true
+		zf.tocEnded = true
+		return nil
+	}
+	for _, n := range node.Nodes {
+		if err := zf.walk(&n, w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (zf *file) inTOCField() bool {
+	return slices.ContainsFunc(zf.fields, isTOCInstr)
+}
+
+func isTOCInstr(instr string) bool {
+	f := strings.Fields(instr)
+	return len(f) > 0 && f[0] == "TOC"
+}
+
 func (zf *file) handleHyperlink(node *Node, w io.Writer) error {
 	fmt.Fprint(w, "[")
-	var cbuf bytes.Buffer
+	cbuf := getBuffer()
+	defer putBuffer(cbuf)
 	for _, n := range node.Nodes {
-		if err := zf.walk(&n, &cbuf); err != nil {
+		if err := zf.walk(&n, cbuf); err != nil {
 			return err
 		}
 	}
-	fmt.Fprint(w, escape(cbuf.String(), "[]"))
+	w.Write(cbuf.Bytes())
 	fmt.Fprint(w, "]")
 
 	fmt.Fprint(w, "(")
 	if id, ok := attr(node.Attrs, "id"); ok {
 		for _, rel := range zf.rels.Relationship {
 			if id == rel.ID {
-				fmt.Fprint(w, escape(rel.Target, "()"))
+				fmt.Fprint(w, utils.EscapeURL(rel.Target))
 				break
 			}
 		}
@@ -357,8 +653,13 @@ func writeNumericHeading(val string, w io.Writer) {
 
 func (zf *file) handleNumPr(n *Node, w io.Writer) {
 	numID, ilvl := extractNumProperties(n)
-	numFmt, start, ind := zf.findNumberingFormat(numID, ilvl)
-	zf.writeNumbering(numID, numFmt, start, ind, w)
+	// numId 0 removes numbering from a paragraph that inherits it from its style
+	if numID == "" || numID == "0" {
+		return
+	}
+
+	lvl := zf.findNumberingFormat(numID, ilvl)
+	zf.writeNumbering(numID, ilvl, lvl, w)
 }
 
 func extractNumProperties(n *Node) (numID, ilvl string) {
@@ -374,35 +675,46 @@ func extractNumProperties(n *Node) (numID, ilvl string) {
 			}
 		}
 	}
+	if ilvl == "" {
+		ilvl = "0"
+	}
 	return numID, ilvl
 }
 
-func (zf *file) findNumberingFormat(numID, ilvl string) (numFmt string, start, ind int) {
-	start = 1
-	ind = 0
+func (zf *file) findNumberingFormat(numID, ilvl string) numberingLevel {
+	// Fall back to a private counter for num instances missing from numbering.xml
+	lvl := numberingLevel{abstractNumID: "num:" + numID, start: 1}
 
 	for _, num := range zf.num.Num {
 		if numID != num.NumID {
 			continue
 		}
-		numFmt, start, ind = zf.processAbstractNum(num.AbstractNumID.Val, ilvl)
+		lvl = zf.processAbstractNum(num.AbstractNumID.Val, ilvl)
+		for _, o := range num.LvlOverride {
+			if o.Ilvl != ilvl {
+				continue
+			}
+			if i, err := strconv.Atoi(o.StartOverride.Val); err == nil {
+				lvl.start = i
+				lvl.override = true
+			}
+		}
 		break
 	}
-	return numFmt, start, ind
+	return lvl
 }
 
-func (zf *file) processAbstractNum(abstractNumID, ilvl string) (numFmt string, start, ind int) {
-	start = 1
-	ind = 0
+func (zf *file) processAbstractNum(abstractNumID, ilvl string) numberingLevel {
+	lvl := numberingLevel{abstractNumID: abstractNumID, start: 1}
 
 	for _, abnum := range zf.num.AbstractNum {
 		if abnum.AbstractNumID != abstractNumID {
 			continue
 		}
-		numFmt, start, ind = processAbstractNumLevel(abnum.Lvl, ilvl)
+		lvl.numFmt, lvl.start, lvl.ind = processAbstractNumLevel(abnum.Lvl, ilvl)
 		break
 	}
-	return numFmt, start, ind
+	return lvl
 }
 
 func processAbstractNumLevel(levels []NumberingLvl, ilvl string) (numFmt string, start, ind int) {
@@ -425,18 +737,36 @@ func processAbstractNumLevel(levels []NumberingLvl, ilvl string) (numFmt string,
 	return numFmt, start, ind
 }
 
-func (zf *file) writeNumbering(numID, numFmt string, start, ind int, w io.Writer) {
-	fmt.Fprint(w, strings.Repeat("  ", ind))
-	switch numFmt {
+func (zf *file) writeNumbering(numID, ilvl string, lvl numberingLevel, w io.Writer) {
+	level, _ := strconv.Atoi(ilvl)
+	key := listKey{abstractNumID: lvl.abstractNumID, ilvl: level}
+
+	// A startOverride restarts the list the first time its num instance is used
+	if lvl.override {
+		restartKey := numID + ":" + ilvl
+		if !zf.restarted[restartKey] {
+			zf.restarted[restartKey] = true
+			delete(zf.list, key)
+		}
+	}
+
+	// Moving back up to a level restarts every deeper level of the same list
+	for k := range zf.list {
+		if k.abstractNumID == key.abstractNumID && k.ilvl > key.ilvl {
+			delete(zf.list, k)
+		}
+	}
+
+	fmt.Fprint(w, strings.Repeat("  ", lvl.ind))
+	switch lvl.numFmt {
 	case "decimal", "aiueoFullWidth":
-		zf.writeOrderedList(numID, start, ind, w)
+		zf.writeOrderedList(key, lvl.start, w)
 	case "bullet":
 		fmt.Fprint(w, "* ")
 	}
 }
 
-func (zf *file) writeOrderedList(numID string, start, ind int, w io.Writer) {
-	key := fmt.Sprintf("%s:%d", numID, ind)
+func (zf *file) writeOrderedList(key listKey, start int, w io.Writer) {
 	cur, ok := zf.list[key]
 	if !ok {
 		zf.list[key] = start
@@ -476,12 +806,14 @@ func (zf *file) extractTableRows(node *Node) [][]string {
 func (zf *file) extractTableColumns(tr *Node) []string {
 	// Pre-allocate slice with estimated capacity based on number of child nodes
 	cols := make([]string, 0, len(tr.Nodes))
+	cbuf := getBuffer()
+	defer putBuffer(cbuf)
 	for _, tc := range tr.Nodes {
 		if tc.XMLName.Local != "tc" {
 			continue
 		}
-		var cbuf bytes.Buffer
-		if err := zf.walk(&tc, &cbuf); err != nil {
+		cbuf.Reset()
+		if err := zf.walk(&tc, cbuf); err != nil {
 			// Continue processing other columns even if one fails
 			cols = append(cols, "")
 			continue
@@ -547,7 +879,7 @@ func writeTableRow(row []string, widths []int, maxcol int, w io.Writer) {
 		fmt.Fprint(w, "|")
 		if j < len(row) {
 			width := utils.StringWidth(row[j])
-			fmt.Fprint(w, escape(row[j], "|"))
+			fmt.Fprint(w, utils.EscapeCell(row[j]))
 			fmt.Fprint(w, strings.Repeat(" ", widths[j]-width))
 		} else {
 			fmt.Fprint(w, strings.Repeat(" ", widths[j]))
@@ -556,50 +888,128 @@ func writeTableRow(row []string, widths []int, maxcol int, w io.Writer) {
 	fmt.Fprint(w, "|\n")
 }
 
-func (zf *file) handleR(node *Node, w io.Writer) error {
-	bold := false
-	italic := false
-	strike := false
+// runStyle collects the character formatting declared in a run's rPr.
+type runStyle struct {
+	bold      bool
+	italic    bool
+	strike    bool
+	underline bool
+	highlight bool
+	rtl       bool
+	vertAlign string
+}
+
+func parseRunStyle(node *Node) runStyle {
+	var style runStyle
 	for _, n := range node.Nodes {
 		if n.XMLName.Local != "rPr" {
 			continue
 		}
 		for _, nn := range n.Nodes {
+			val, _ := attr(nn.Attrs, "val")
 			switch nn.XMLName.Local {
 			case "b":
-				bold = true
+				style.bold = isOn(&nn)
 			case "i":
-				italic = true
+				style.italic = isOn(&nn)
 			case "strike":
-				strike = true
+				style.strike = isOn(&nn)
+			case "u":
+				style.underline = val != "none"
+			case "highlight":
+				style.highlight = val != "none"
+			case "vertAlign":
+				style.vertAlign = val
+			case "rtl":
+				style.rtl = isOn(&nn)
 			}
 		}
 	}
-	if strike {
-		fmt.Fprint(w, "~~")
+	return style
+}
+
+// markers returns the opening and closing markers for the style, outermost
+// first. Closing markers must be written in reverse order.
+func (s runStyle) markers(flavor Flavor) (open, closing []string) {
+	add := func(o, c string) {
+		open = append(open, o)
+		closing = append(closing, c)
+	}
+
+	if s.highlight {
+		if flavor == FlavorExtended {
+			add("==", "==")
+		} else {
+			add("<mark>", "</mark>")
+		}
+	}
+	if s.underline {
+		add("<u>", "</u>")
 	}
-	if bold {
-		fmt.Fprint(w, "**")
+	if s.strike {
+		add("~~", "~~")
 	}
-	if italic {
-		fmt.Fprint(w, "*")
+	if s.bold {
+		add("**", "**")
 	}
-	var cbuf bytes.Buffer
-	for _, n := range node.Nodes {
-		if err := zf.walk(&n, &cbuf); err != nil {
-			return err
+	if s.italic {
+		add("*", "*")
+	}
+	switch s.vertAlign {
+	case "superscript":
+		if flavor == FlavorExtended {
+			add("^", "^")
+		} else {
+			add("<sup>", "</sup>")
+		}
+	case "subscript":
+		if flavor == FlavorExtended {
+			add("~", "~")
+		} else {
+			add("<sub>", "</sub>")
+		}
+	}
+	return open, closing
+}
+
+func (zf *file) handleR(node *Node, w io.Writer) error {
+	return zf.writeRuns([]Node{*node}, parseRunStyle(node), w)
+}
+
+// writeRuns writes the content of consecutive runs sharing the same style,
+// wrapped once in the style's markers. Surrounding whitespace is kept outside
+// the markers so that emphasis is not broken.
+func (zf *file) writeRuns(runs []Node, style runStyle, w io.Writer) error {
+	cbuf := getBuffer()
+	defer putBuffer(cbuf)
+	for _, r := range runs {
+		for _, n := range r.Nodes {
+			if err := zf.walk(&n, cbuf); err != nil {
+				return err
+			}
 		}
 	}
-	fmt.Fprint(w, escape(cbuf.String(), `*~\`))
-	if italic {
-		fmt.Fprint(w, "*")
+
+	text := cbuf.String()
+	open, closing := style.markers(zf.flavor)
+	core := strings.TrimSpace(text)
+	if len(open) == 0 || core == "" {
+		fmt.Fprint(w, text)
+		return nil
 	}
-	if bold {
-		fmt.Fprint(w, "**")
+
+	lead := text[:strings.Index(text, core)]
+	trail := text[len(lead)+len(core):]
+
+	fmt.Fprint(w, lead)
+	for _, m := range open {
+		fmt.Fprint(w, m)
 	}
-	if strike {
-		fmt.Fprint(w, "~~")
+	fmt.Fprint(w, core)
+	for i := len(closing) - 1; i >= 0; i-- {
+		fmt.Fprint(w, closing[i])
 	}
+	fmt.Fprint(w, trail)
 	return nil
 }
 
@@ -647,13 +1057,29 @@ func findFile(files []*zip.File, target string) *zip.File {
 	return nil
 }
 
-func convertDocxToMarkdown(filePath string) (string, error) {
-	r, err := zip.OpenReader(filePath)
+func convertDocxToMarkdown(ctx context.Context, filePath string, d *DocConverter) (string, error) {
+	r, err := openPackage(filePath, d.Options.Password, d.Options.MmapThreshold, d.Options.ArchiveLimits)
 	if err != nil {
 		return "", err
 	}
 	defer r.Close()
+	return convertDocx(ctx, r.Reader, d)
+}
+
+// convertDocx converts the document of the archive of a DOCX file.
+func convertDocx(ctx context.Context, r *zip.Reader, d *DocConverter) (string, error) {
+	var buf strings.Builder
+	if err := writeDocx(ctx, r, d, &buf); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
 
+// writeDocx writes the markdown of the document of the archive of a DOCX
+// file to w as it walks the document. Documents with a table of contents
+// to regenerate are converted as a whole first, as the headings the table
+// lists come after it. The walk stops once ctx is done.
+func writeDocx(ctx context.Context, r *zip.Reader, d *DocConverter, w io.Writer) error {
 	var rels Relationships
 	var num Numbering
 
@@ -665,12 +1091,12 @@ func convertDocxToMarkdown(filePath string) (string, error) {
 
 			b, _ := io.ReadAll(rc)
 			if err != nil {
-				return "", err
+				return err
 			}
 
 			err = xml.Unmarshal(b, &rels)
 			if err != nil {
-				return "", err
+				return err
 			}
 		case "word/numbering.xml":
 			rc, err := f.Open()
@@ -678,36 +1104,108 @@ func convertDocxToMarkdown(filePath string) (string, error) {
 
 			b, _ := io.ReadAll(rc)
 			if err != nil {
-				return "", err
+				return err
 			}
 
 			err = xml.Unmarshal(b, &num)
 			if err != nil {
-				return "", err
+				return err
 			}
 		}
 	}
 
 	f := findFile(r.File, "word/document*.xml")
 	if f == nil {
-		return "", errors.New("incorrect document")
+		return errors.New("incorrect document")
 	}
 	node, err := readDocFile(f)
 	if err != nil {
-		return "", err
+		return err
 	}
 
-	var buf bytes.Buffer
 	zf := &file{
-		r:    r,
-		rels: rels,
-		num:  num,
-		list: make(map[string]int),
+		ctx:        ctx,
+		r:          r,
+		rels:       rels,
+		num:        num,
+		list:       make(map[listKey]int),
+		restarted:  make(map[string]bool),
+		flavor:     d.Flavor,
+		provenance: d.Provenance,
+		images:     d.Options,
 	}
-	err = zf.walk(node, &buf)
-	if err != nil {
-		return "", err
+	if d.TOC == TOCRegenerate && hasTOCField(node) {
+		var buf bytes.Buffer
+		if err := zf.walk(node, &buf); err != nil {
+			return err
+		}
+		_, err := io.WriteString(w, replaceTOCPlaceholder(buf.String(), d.TOC))
+		return err
 	}
 
-	return buf.String(), nil
+	ew := &errWriter{w: w}
+	if err := zf.walk(node, &tocStripWriter{w: ew}); err != nil {
+		return err
+	}
+	return ew.err
+}
+
+// hasTOCField reports whether a document may hold a TOC field, from the
+// instructions of its fields.
+func hasTOCField(node *Node) bool {
+	var instructions strings.Builder
+	var collect func(n *Node)
+	collect = func(n *Node) {
+		switch n.XMLName.Local {
+		case "instrText":
+			instructions.Write(n.Content)
+		case "fldSimple":
+			instr, _ := attr(n.Attrs, "instr")
+			instructions.WriteString(instr)
+		}
+		for i := range n.Nodes {
+			collect(&n.Nodes[i])
+		}
+	}
+	collect(node)
+	return strings.Contains(instructions.String(), "TOC")
+}
+
+// tocStripWriter writes to w with the TOC placeholders stripped like
+// replaceTOCPlaceholder does with TOCStrip. Placeholders are written whole
+// by a single write.
+type tocStripWriter struct {
+	w        io.Writer
+	replaced bool
+}
+
+func (t *tocStripWriter) Write(p []byte) (int, error) {
+	if !bytes.Contains(p, []byte(tocPlaceholder)) {
+		return t.w.Write(p)
+	}
+	text := string(p)
+	if !t.replaced {
+		text = strings.Replace(text, tocPlaceholder, "", 1)
+		t.replaced = true
+	}
+	if _, err := io.WriteString(t.w, strings.ReplaceAll(text, tocPlaceholder+"\n", "")); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// replaceTOCPlaceholder substitutes the first TOC placeholder with a list of
+// the document headings, or strips it, and removes any other placeholders.
+func replaceTOCPlaceholder(markdown string, tocMode TOCMode) string {
+	if !strings.Contains(markdown, tocPlaceholder) {
+		return markdown
+	}
+
+	var toc string
+	if tocMode == TOCRegenerate {
+		toc = strings.TrimSuffix(utils.TableOfContents(strings.ReplaceAll(markdown, tocPlaceholder, "")), "\n")
+	}
+
+	markdown = strings.Replace(markdown, tocPlaceholder, toc, 1)
+	return strings.ReplaceAll(markdown, tocPlaceholder+"\n", "")
 }
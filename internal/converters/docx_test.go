@@ -1,6 +1,8 @@
 package converters
 
 import (
+	"bytes"
+	"encoding/xml"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -250,3 +252,225 @@ func TestTextValStruct(t *testing.T) {
 		t.Errorf("TextVal.Val = %v, want 'sample value'", tv.Val)
 	}
 }
+
+func TestDocHandleR_RunFormatting(t *testing.T) {
+	testCases := []struct {
+		name     string
+		rPr      string
+		flavor   Flavor
+		expected string
+	}{
+		{"Underline", `<w:u w:val="single"/>`, FlavorGFM, "<u>text</u>"},
+		{"Underline none", `<w:u w:val="none"/>`, FlavorGFM, "text"},
+		{"Superscript GFM", `<w:vertAlign w:val="superscript"/>`, FlavorGFM, "<sup>text</sup>"},
+		{"Subscript GFM", `<w:vertAlign w:val="subscript"/>`, FlavorGFM, "<sub>text</sub>"},
+		{"Highlight GFM", `<w:highlight w:val="yellow"/>`, FlavorGFM, "<mark>text</mark>"},
+		{"Superscript extended", `<w:vertAlign w:val="superscript"/>`, FlavorExtended, "^text^"},
+		{"Subscript extended", `<w:vertAlign w:val="subscript"/>`, FlavorExtended, "~text~"},
+		{"Highlight extended", `<w:highlight w:val="yellow"/>`, FlavorExtended, "==text=="},
+		{"Bold underline", `<w:b/><w:u w:val="single"/>`, FlavorGFM, "<u>**text**</u>"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			src := `<w:r xmlns:w="w"><w:rPr>` + tc.rPr + `</w:rPr><w:t>text</w:t></w:r>`
+
+			var node Node
+			if err := xml.Unmarshal([]byte(src), &node); err != nil {
+				t.Fatalf("Failed to parse run XML: %v", err)
+			}
+
+			var buf bytes.Buffer
+			zf := &file{list: make(map[listKey]int), flavor: tc.flavor}
+			if err := zf.handleR(&node, &buf); err != nil {
+				t.Fatalf("handleR() returned unexpected error: %v", err)
+			}
+
+			if buf.String() != tc.expected {
+				t.Errorf("handleR() = %q, want %q", buf.String(), tc.expected)
+			}
+		})
+	}
+}
+
+func TestDocNumbering_RestartAndContinuation(t *testing.T) {
+	numberingXML := `<w:numbering xmlns:w="w">
+		<w:abstractNum w:abstractNumId="0">
+			<w:lvl w:ilvl="0"><w:start w:val="1"/><w:numFmt w:val="decimal"/></w:lvl>
+			<w:lvl w:ilvl="1"><w:start w:val="1"/><w:numFmt w:val="decimal"/></w:lvl>
+		</w:abstractNum>
+		<w:num w:numId="1"><w:abstractNumId w:val="0"/></w:num>
+		<w:num w:numId="2"><w:abstractNumId w:val="0"/>
+			<w:lvlOverride w:ilvl="0"><w:startOverride w:val="1"/></w:lvlOverride>
+		</w:num>
+	</w:numbering>`
+
+	var num Numbering
+	if err := xml.Unmarshal([]byte(numberingXML), &num); err != nil {
+		t.Fatalf("Failed to parse numbering XML: %v", err)
+	}
+
+	items := []struct {
+		numID, ilvl string
+	}{
+		{"1", "0"}, {"1", "1"}, {"1", "1"}, {"1", "0"}, {"1", "1"},
+		{"2", "0"}, {"2", "0"},
+	}
+
+	zf := &file{num: num, list: make(map[listKey]int), restarted: make(map[string]bool)}
+
+	var got []string
+	for _, item := range items {
+		var buf bytes.Buffer
+		zf.writeNumbering(item.numID, item.ilvl, zf.findNumberingFormat(item.numID, item.ilvl), &buf)
+		got = append(got, buf.String())
+	}
+
+	expected := []string{"1. ", "1. ", "2. ", "2. ", "1. ", "1. ", "2. "}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("writeNumbering() sequence = %q, want %q", got, expected)
+	}
+}
+
+// writeTestDocx creates a minimal DOCX archive containing the given document body.
+func writeTestDocx(t *testing.T, body string) string {
+	t.Helper()
+
+	doc := `<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>` +
+		body + `</w:body></w:document>`
+	return writeTestArchive(t, "test.docx", map[string]string{"word/document.xml": doc})
+}
+
+func TestDocConverter_Load_TOCField(t *testing.T) {
+	body := `<w:p><w:r><w:fldChar w:fldCharType="begin"/></w:r>` +
+		`<w:r><w:instrText> TOC \o "1-3" \h \z \u </w:instrText></w:r>` +
+		`<w:r><w:fldChar w:fldCharType="separate"/></w:r>` +
+		`<w:r><w:t>Introduction 1</w:t></w:r></w:p>` +
+		`<w:p><w:r><w:t>Details 2</w:t></w:r><w:r><w:fldChar w:fldCharType="end"/></w:r></w:p>` +
+		`<w:p><w:pPr><w:pStyle w:val="Heading1"/></w:pPr><w:r><w:t>Introduction</w:t></w:r></w:p>` +
+		`<w:p><w:pPr><w:pStyle w:val="Heading2"/></w:pPr><w:r><w:t>Details</w:t></w:r></w:p>`
+	docxFile := writeTestDocx(t, body)
+
+	testCases := []struct {
+		name     string
+		mode     TOCMode
+		expected string
+	}{
+		{
+			name:     "Regenerate",
+			mode:     TOCRegenerate,
+			expected: "- [Introduction](#introduction)\n  - [Details](#details)\n# Introduction\n## Details\n",
+		},
+		{
+			name:     "Strip",
+			mode:     TOCStrip,
+			expected: "\n# Introduction\n## Details\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			converter := &DocConverter{TOC: tc.mode}
+			result, err := converter.Load(docxFile)
+			if err != nil {
+				t.Fatalf("Load() returned unexpected error: %v", err)
+			}
+
+			if result != tc.expected {
+				t.Errorf("Load() = %q, want %q", result, tc.expected)
+			}
+		})
+	}
+}
+
+func TestDocConverter_Load_BidiParagraph(t *testing.T) {
+	body := `<w:p><w:pPr><w:bidi/></w:pPr>` +
+		`<w:r><w:rPr><w:b/><w:rtl/></w:rPr><w:t xml:space="preserve">مرحبا </w:t></w:r>` +
+		`<w:r><w:rPr><w:b/><w:rtl/></w:rPr><w:t>بالعالم</w:t></w:r>` +
+		`<w:r><w:rPr><w:rtl/></w:rPr><w:t xml:space="preserve"> نص</w:t></w:r></w:p>` +
+		`<w:p><w:r><w:rPr><w:b w:val="0"/></w:rPr><w:t>plain</w:t></w:r></w:p>`
+	docxFile := writeTestDocx(t, body)
+
+	result, err := NewDocConverter().Load(docxFile)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+
+	expected := "<div dir=\"rtl\">\n\n**مرحبا بالعالم** نص\n\n</div>\nplain\n"
+	if result != expected {
+		t.Errorf("Load() = %q, want %q", result, expected)
+	}
+}
+
+func TestDocConverter_Load_Provenance(t *testing.T) {
+	body := `<w:p><w:pPr><w:pStyle w:val="Heading1"/></w:pPr><w:r><w:t>Title</w:t></w:r></w:p>` +
+		`<w:p></w:p>` +
+		`<w:p><w:r><w:t>Body text</w:t></w:r></w:p>` +
+		`<w:tbl><w:tr><w:tc><w:p><w:r><w:t>A</w:t></w:r></w:p></w:tc></w:tr></w:tbl>`
+	docxFile := writeTestDocx(t, body)
+
+	result, err := (&DocConverter{Provenance: true}).Load(docxFile)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+
+	for _, comment := range []string{
+		"<!-- source: paragraph 1 -->\n# Title",
+		"<!-- source: paragraph 3 -->\nBody text",
+		"<!-- source: table 1 -->\n",
+	} {
+		if !strings.Contains(result, comment) {
+			t.Errorf("Load() = %q, want it to contain %q", result, comment)
+		}
+	}
+	if strings.Contains(result, "paragraph 2") {
+		t.Errorf("Load() = %q, want no comment for the empty paragraph", result)
+	}
+}
+
+func TestDocConverter_Load_Images(t *testing.T) {
+	doc := `<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"` +
+		` xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main"` +
+		` xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<w:body><w:p><w:r><w:drawing><a:blip r:embed="rId1"/></w:drawing></w:r></w:p></w:body></w:document>`
+	rels := `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="image" Target="media/image1.gif"/></Relationships>`
+	docxFile := writeTestArchive(t, "test.docx", map[string]string{
+		"word/document.xml":            doc,
+		"word/_rels/document.xml.rels": rels,
+		"word/media/image1.gif":        "GIF89a",
+	})
+
+	t.Chdir(t.TempDir())
+	imageDir := filepath.Join(t.TempDir(), "images")
+
+	tests := []struct {
+		name     string
+		options  ConvertOptions
+		expected string
+	}{
+		{"link", ConvertOptions{}, "![](media/image1.gif)"},
+		{"inline", ConvertOptions{Images: ImagesInline}, "![](data:image/gif;base64,R0lGODlh)"},
+		{"drop", ConvertOptions{Images: ImagesDrop}, ""},
+		{"download", ConvertOptions{Images: ImagesDownload, ImageDir: imageDir}, "![](" + filepath.ToSlash(filepath.Join(imageDir, "media", "image1.gif")) + ")"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := (&DocConverter{Options: tt.options}).Load(docxFile)
+			if err != nil {
+				t.Fatalf("Load() returned unexpected error: %v", err)
+			}
+			if strings.TrimSpace(result) != tt.expected {
+				t.Errorf("Load() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+
+	if entries, _ := os.ReadDir("."); len(entries) > 0 {
+		t.Errorf("Load() wrote %d files to the working directory", len(entries))
+	}
+	if _, err := os.Stat(filepath.Join(imageDir, "media", "image1.gif")); err != nil {
+		t.Errorf("Load() did not download the image: %v", err)
+	}
+}
@@ -0,0 +1,249 @@
+package converters
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/flaviodelgrosso/marky/internal/mimetypes"
+	"github.com/flaviodelgrosso/marky/internal/utils"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+)
+
+// EmlConverter handles loading and converting email messages to markdown.
+// The HTML body is preferred over the plain text one, and attachments,
+// including forwarded messages, are converted with the function given to
+// SetConverter under an "Attachments" section.
+type EmlConverter struct {
+	BaseConverter
+
+	// Limits bounds the conversion of attachments.
+	Limits ContainerLimits
+
+	// InMemory lists the files without converting them, since they are
+	// written to temporary files to be converted. It is set by the
+	// InMemory field of ConvertOptions.
+	InMemory bool
+
+	convert ConvertFunc
+}
+
+// NewEmlConverter creates a new email converter with appropriate MIME types and extensions.
+func NewEmlConverter() Converter {
+	return &EmlConverter{
+		BaseConverter: NewBaseConverter(
+			[]string{".eml"},
+			[]string{"message/rfc822"},
+		),
+	}
+}
+
+// Capabilities returns what the converter can extract from email messages.
+func (c *EmlConverter) Capabilities() Capabilities {
+	return Capabilities{Metadata: true}
+}
+
+// SetConverter sets the function converting attachments.
+func (c *EmlConverter) SetConverter(convert ConvertFunc) {
+	c.convert = convert
+}
+
+// Load converts an email message to markdown, preceded by a front matter
+// with its subject, sender, recipients and date.
+func (c *EmlConverter) Load(path string) (string, error) {
+	return c.LoadContext(context.Background(), path)
+}
+
+// LoadContext converts an email message like Load, stopping the conversion
+// of attachments once ctx is done.
+func (c *EmlConverter) LoadContext(ctx context.Context, path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open email: %w", err)
+	}
+	defer f.Close()
+	return c.loadMessage(ctx, f)
+}
+
+// LoadBytes converts an email message held in memory like Load.
+func (c *EmlConverter) LoadBytes(ctx context.Context, data []byte) (string, error) {
+	return c.loadMessage(ctx, bytes.NewReader(data))
+}
+
+// loadMessage converts the message read from r.
+func (c *EmlConverter) loadMessage(ctx context.Context, r io.Reader) (string, error) {
+	msg, err := mail.ReadMessage(r)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse email: %w", corrupt(err))
+	}
+	var parts emlParts
+	if err := parts.walk(textproto.MIMEHeader(msg.Header), msg.Body); err != nil {
+		return "", fmt.Errorf("failed to read email: %w", corrupt(err))
+	}
+
+	subject := decodeHeader(msg.Header.Get("Subject"))
+	var date string
+	if t, err := msg.Header.Date(); err == nil {
+		date = t.Format(time.RFC3339)
+	}
+	var buf strings.Builder
+	buf.WriteString(utils.FrontMatter([]utils.FrontMatterField{
+		{Key: "title", Value: subject},
+		{Key: "from", Value: decodeHeader(msg.Header.Get("From"))},
+		{Key: "to", Value: decodeHeader(msg.Header.Get("To"))},
+		{Key: "cc", Value: decodeHeader(msg.Header.Get("Cc"))},
+		{Key: "date", Value: date},
+	}))
+	if buf.Len() > 0 {
+		buf.WriteString("\n")
+	}
+	if subject != "" {
+		fmt.Fprintf(&buf, "# %s\n", utils.EscapeMarkdown(subject, utils.FlavorGFM))
+	}
+
+	body, err := parts.markdown()
+	if err != nil {
+		return "", err
+	}
+	if body != "" {
+		if buf.Len() > 0 {
+			buf.WriteString("\n")
+		}
+		buf.WriteString(body + "\n")
+	}
+	if err := writeAttachments(ctx, &buf, "Attachments", parts.files, c.Limits, c.InMemory, c.convert); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// emlParts collects the bodies and attachments of a message.
+type emlParts struct {
+	html, text string
+	files      []attachment
+}
+
+// walk reads a part of a message, descending into multipart ones. The first
+// HTML and plain text parts are the bodies, and parts with a file name,
+// parts marked as attachments and forwarded messages are attachments.
+func (p *emlParts) walk(header textproto.MIMEHeader, body io.Reader) error {
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		mediaType, params = "text/plain", nil
+	}
+	disposition, dispositionParams, _ := mime.ParseMediaType(header.Get("Content-Disposition"))
+	name := decodeHeader(cmp.Or(dispositionParams["filename"], params["name"]))
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		r := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := r.NextRawPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if err := p.walk(part.Header, part); err != nil {
+				return err
+			}
+		}
+	}
+
+	body = decodeTransferEncoding(header.Get("Content-Transfer-Encoding"), body)
+	switch {
+	case name != "" || disposition == "attachment" || mediaType == "message/rfc822":
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return err
+		}
+		if name == "" {
+			name = "attachment"
+			if mediaType == "message/rfc822" {
+				name = "message.eml"
+			} else {
+				name += mimetypes.Extension(mediaType)
+			}
+		}
+		p.files = append(p.files, attachment{
+			Name: name,
+			Size: int64(len(data)),
+			read: func() ([]byte, error) { return data, nil },
+		})
+	case mediaType == "text/html" && p.html == "":
+		text, err := readText(body, params["charset"])
+		if err != nil {
+			return err
+		}
+		p.html = text
+	case mediaType == "text/plain" && p.text == "":
+		text, err := readText(body, params["charset"])
+		if err != nil {
+			return err
+		}
+		p.text = text
+	}
+	return nil
+}
+
+// markdown returns the HTML body converted to markdown, or else the plain
+// text body.
+func (p *emlParts) markdown() (string, error) {
+	if p.html == "" {
+		return strings.TrimSpace(strings.ReplaceAll(p.text, "\r\n", "\n")), nil
+	}
+	doc, err := html.Parse(strings.NewReader(p.html))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse HTML body: %w", err)
+	}
+	markdown, err := (&HTMLConverter{}).markdownConverter().ConvertNode(doc)
+	if err != nil {
+		return "", fmt.Errorf("failed to convert HTML body to markdown: %w", err)
+	}
+	return strings.TrimSpace(string(markdown)), nil
+}
+
+// decodeTransferEncoding decodes the base64 and quoted-printable content of
+// a part.
+func decodeTransferEncoding(encoding string, r io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, r)
+	case "quoted-printable":
+		return quotedprintable.NewReader(r)
+	}
+	return r
+}
+
+// readText reads a text part, converting it from its charset to UTF-8.
+func readText(r io.Reader, label string) (string, error) {
+	if label != "" && !strings.EqualFold(label, "utf-8") && !strings.EqualFold(label, "us-ascii") {
+		if decoded, err := charset.NewReaderLabel(label, r); err == nil {
+			r = decoded
+		}
+	}
+	data, err := io.ReadAll(r)
+	return string(data), err
+}
+
+// decodeHeader decodes the encoded words of a header, such as
+// "=?UTF-8?B?...?=", in any charset.
+func decodeHeader(value string) string {
+	decoder := mime.WordDecoder{CharsetReader: charset.NewReaderLabel}
+	if decoded, err := decoder.DecodeHeader(value); err == nil {
+		return strings.TrimSpace(decoded)
+	}
+	return strings.TrimSpace(value)
+}
@@ -0,0 +1,81 @@
+package converters
+
+import (
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEmlConverter_Load(t *testing.T) {
+	csv := base64.StdEncoding.EncodeToString([]byte("name,age\nAda,36\n"))
+	message := strings.Join([]string{
+		"From: Ada <ada@example.com>",
+		"To: Bob <bob@example.com>",
+		"Subject: =?UTF-8?Q?Quarterly_r=C3=A9sum=C3=A9?=",
+		"Date: Mon, 02 Jan 2006 15:04:05 +0000",
+		"MIME-Version: 1.0",
+		`Content-Type: multipart/mixed; boundary="outer"`,
+		"",
+		"--outer",
+		`Content-Type: multipart/alternative; boundary="inner"`,
+		"",
+		"--inner",
+		"Content-Type: text/plain; charset=utf-8",
+		"",
+		"Plain body",
+		"--inner",
+		"Content-Type: text/html; charset=iso-8859-1",
+		"Content-Transfer-Encoding: quoted-printable",
+		"",
+		"<p>See the <b>figures</b> attached. Caf=E9</p>",
+		"--inner--",
+		"--outer",
+		`Content-Type: text/csv; name="data.csv"`,
+		`Content-Disposition: attachment; filename="data.csv"`,
+		"Content-Transfer-Encoding: base64",
+		"",
+		csv,
+		"--outer--",
+		"",
+	}, "\r\n")
+	path := filepath.Join(t.TempDir(), "mail.eml")
+	if err := os.WriteFile(path, []byte(message), 0o644); err != nil {
+		t.Fatalf("Failed to create test email: %v", err)
+	}
+
+	c := NewEmlConverter().(*EmlConverter)
+	c.SetConverter(func(ctx context.Context, path string) (string, error) {
+		return NewCsvConverter().Load(path)
+	})
+	markdown, err := c.Load(path)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+
+	for _, want := range []string{
+		"title: Quarterly résumé",
+		"from: Ada <ada@example.com>",
+		"date: 2006-01-02T15:04:05Z",
+		"# Quarterly résumé\n",
+		"See the **figures** attached. Café",
+		"## Attachments\n\n### data.csv\n\n| name | age |",
+	} {
+		if !strings.Contains(markdown, want) {
+			t.Errorf("Load() = %q, want it to contain %q", markdown, want)
+		}
+	}
+	if strings.Contains(markdown, "Plain body") {
+		t.Errorf("Load() = %q, want the HTML body preferred", markdown)
+	}
+
+	c.SetConverter(nil)
+	if markdown, err = c.Load(path); err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	if !strings.Contains(markdown, "### data.csv\n\n_Not converted: no converter._") {
+		t.Errorf("Load() = %q, want the attachment listed", markdown)
+	}
+}
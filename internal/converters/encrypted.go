@@ -0,0 +1,150 @@
+package converters
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/richardlehane/mscfb"
+	"github.com/xuri/excelize/v2"
+)
+
+// ErrEncryptedDocument is returned for password-protected documents opened
+// without their password, or with a wrong one.
+var ErrEncryptedDocument = errors.New("document is encrypted")
+
+// oleSignature starts the OLE compound files wrapping the encrypted
+// packages of DOCX, XLSX and PPTX files.
+var oleSignature = []byte{0xd0, 0xcf, 0x11, 0xe0, 0xa1, 0xb1, 0x1a, 0xe1}
+
+// IsEncryptedPackage reports whether a file is a password-protected DOCX,
+// XLSX or PPTX file: an OLE compound file holding the EncryptionInfo and
+// EncryptedPackage streams rather than a ZIP archive.
+func IsEncryptedPackage(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	return isEncryptedPackage(f)
+}
+
+// IsEncryptedData reports whether data, such as an object of a cloud store,
+// is an encrypted DOCX, XLSX or PPTX file, like IsEncryptedPackage.
+func IsEncryptedData(data []byte) bool {
+	return isEncryptedPackage(bytes.NewReader(data))
+}
+
+// isEncryptedPackage reports whether r holds an encrypted OOXML package.
+func isEncryptedPackage(r io.ReaderAt) bool {
+	header := make([]byte, len(oleSignature))
+	if _, err := r.ReadAt(header, 0); err != nil || !bytes.Equal(header, oleSignature) {
+		return false
+	}
+	doc, err := mscfb.New(r)
+	if err != nil {
+		return false
+	}
+	var info, pkg bool
+	for entry, err := doc.Next(); err == nil; entry, err = doc.Next() {
+		switch entry.Name {
+		case "EncryptionInfo":
+			info = true
+		case "EncryptedPackage":
+			pkg = true
+		}
+	}
+	return info && pkg
+}
+
+// decryptPackage returns the ZIP archive of an encrypted OOXML package,
+// decrypted with password, or data itself when it is not encrypted. Agile
+// and standard encryption are supported.
+func decryptPackage(data []byte, password string) ([]byte, error) {
+	if !isEncryptedPackage(bytes.NewReader(data)) {
+		return data, nil
+	}
+	if password == "" {
+		return nil, fmt.Errorf("%w, a password is required", ErrEncryptedDocument)
+	}
+	plain, err := excelize.Decrypt(data, &excelize.Options{Password: password})
+	if err != nil {
+		return nil, fmt.Errorf("%w, unable to decrypt it: %v", ErrEncryptedDocument, err)
+	}
+	// Decrypting with a wrong password yields noise rather than an error
+	// for standard encryption.
+	if _, err := zip.NewReader(bytes.NewReader(plain), int64(len(plain))); err != nil {
+		return nil, fmt.Errorf("%w, wrong password", ErrEncryptedDocument)
+	}
+	return plain, nil
+}
+
+// packageReader reads the ZIP archive of an OOXML file, decrypted in
+// memory when the file is encrypted.
+type packageReader struct {
+	*zip.Reader
+	close func() error
+}
+
+// Close closes the file of the archive.
+func (r *packageReader) Close() error {
+	return r.close()
+}
+
+// openPackage opens the ZIP archive of a DOCX, XLSX or PPTX file like
+// openArchive, decrypting it with password when the file is encrypted. Files
+// of threshold bytes or more are mapped into memory, as readFile does, and
+// the archive is checked against limits.
+func openPackage(path, password string, threshold int64, limits ArchiveLimits) (*packageReader, error) {
+	if !IsEncryptedPackage(path) {
+		return openZipPackage(path, threshold, limits)
+	}
+
+	file, err := readFile(path, threshold)
+	if err != nil {
+		return nil, err
+	}
+	data, err := decryptPackage(file.Data, password)
+	file.Close()
+	if err != nil {
+		return nil, err
+	}
+	r, err := newArchiveReader(bytes.NewReader(data), int64(len(data)), limits)
+	if err != nil {
+		return nil, err
+	}
+	return &packageReader{Reader: r, close: func() error { return nil }}, nil
+}
+
+// openZipPackage opens the archive of a package that is not encrypted,
+// reading it from a mapping of the file from threshold bytes.
+func openZipPackage(path string, threshold int64, limits ArchiveLimits) (*packageReader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	m := mapLarge(f, info.Size(), threshold)
+	f.Close()
+
+	if m == nil {
+		r, err := openArchive(path, limits)
+		if err != nil {
+			return nil, err
+		}
+		return &packageReader{Reader: &r.Reader, close: r.Close}, nil
+	}
+	r, err := newArchiveReader(bytes.NewReader(m.Data), int64(len(m.Data)), limits)
+	if err != nil {
+		m.Close()
+		return nil, err
+	}
+	return &packageReader{Reader: r, close: m.Close}, nil
+}
@@ -0,0 +1,76 @@
+package converters
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// writeEncryptedFile encrypts a test file with a password into a temporary
+// directory and returns its path.
+func writeEncryptedFile(t *testing.T, source, password string) string {
+	t.Helper()
+
+	raw, err := os.ReadFile(source)
+	if err != nil {
+		t.Fatalf("Failed to read test file: %v", err)
+	}
+	encrypted, err := excelize.Encrypt(raw, &excelize.Options{Password: password})
+	if err != nil {
+		t.Fatalf("Failed to encrypt test file: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), filepath.Base(source))
+	if err := os.WriteFile(path, encrypted, 0o644); err != nil {
+		t.Fatalf("Failed to write encrypted test file: %v", err)
+	}
+	return path
+}
+
+func TestEncryptedPackage(t *testing.T) {
+	tests := []struct {
+		source    string
+		converter func(password string) Converter
+		expected  string
+	}{
+		{"test.docx", func(password string) Converter {
+			return &DocConverter{Options: ConvertOptions{Password: password}}
+		}, "AutoGen"},
+		{"test.pptx", func(password string) Converter {
+			return &PptxConverter{Options: ConvertOptions{Password: password}}
+		}, "<!-- Slide number: 1 -->"},
+		{"test.xlsx", func(password string) Converter {
+			return &ExcelConverter{Password: password}
+		}, "## "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			path := writeEncryptedFile(t, filepath.Join("..", "..", "test_files", tt.source), "secret")
+			if !IsEncryptedPackage(path) {
+				t.Error("IsEncryptedPackage() = false, want true")
+			}
+
+			for _, password := range []string{"", "wrong"} {
+				if _, err := tt.converter(password).Load(path); !errors.Is(err, ErrEncryptedDocument) {
+					t.Errorf("Load() with password %q error = %v, want ErrEncryptedDocument", password, err)
+				}
+			}
+
+			markdown, err := tt.converter("secret").Load(path)
+			if err != nil {
+				t.Fatalf("Load() returned unexpected error: %v", err)
+			}
+			if !strings.Contains(markdown, tt.expected) {
+				t.Errorf("Load() = %q, want it to contain %q", markdown, tt.expected)
+			}
+		})
+	}
+
+	if IsEncryptedPackage(filepath.Join("..", "..", "test_files", "test.docx")) {
+		t.Error("IsEncryptedPackage() of a ZIP archive = true, want false")
+	}
+}
@@ -2,19 +2,32 @@ package converters
 
 import (
 	"archive/zip"
+	"bytes"
+	"context"
 	"encoding/xml"
 	"errors"
 	"fmt"
 	"io"
+	"net/url"
+	"path"
 	"path/filepath"
 	"strings"
 
-	html2md "github.com/JohannesKaufmann/html-to-markdown/v2"
+	"github.com/flaviodelgrosso/marky/internal/utils"
+	"golang.org/x/net/html"
 )
 
 // EpubConverter handles loading and converting EPUB files to markdown.
 type EpubConverter struct {
 	BaseConverter
+
+	// Options configures the conversion: the chapter selection and the image
+	// policy and directory. Images are embedded in the book, so ImagesLink
+	// keeps links relative to the content documents.
+	Options ConvertOptions
+
+	// Progress, when set, is called after each chapter of the spine.
+	Progress ProgressFunc
 }
 
 // NewEpubConverter creates a new EPUB converter with appropriate MIME types and extensions.
@@ -63,12 +76,14 @@ type Manifest struct {
 }
 
 type Item struct {
-	ID        string `xml:"id,attr"`
-	Href      string `xml:"href,attr"`
-	MediaType string `xml:"media-type,attr"`
+	ID         string `xml:"id,attr"`
+	Href       string `xml:"href,attr"`
+	MediaType  string `xml:"media-type,attr"`
+	Properties string `xml:"properties,attr"`
 }
 
 type Spine struct {
+	Toc   string      `xml:"toc,attr"`
 	Items []SpineItem `xml:"itemref"`
 }
 
@@ -76,40 +91,122 @@ type SpineItem struct {
 	IDRef string `xml:"idref,attr"`
 }
 
-// Load reads an EPUB file and converts it to markdown.
-func (*EpubConverter) Load(path string) (string, error) {
-	// Open the EPUB file as a ZIP archive
-	reader, err := zip.OpenReader(path)
+// EpubChapter is a content document of a book converted to markdown.
+type EpubChapter struct {
+	// Title is the title of the chapter in the table of contents, or its
+	// first heading. It is empty for chapters without either.
+	Title string
+
+	// Markdown is the text of the chapter followed by its footnotes.
+	Markdown string
+}
+
+// epubChapter is a converted content document with the footnotes of the
+// notes it references.
+type epubChapter struct {
+	title     string
+	markdown  string
+	footnotes []string
+}
+
+// SetProgress sets the function called after each chapter.
+func (c *EpubConverter) SetProgress(progress ProgressFunc) {
+	c.Progress = progress
+}
+
+// Capabilities returns what the converter can extract from EPUB files.
+func (c *EpubConverter) Capabilities() Capabilities {
+	return Capabilities{Images: true, Tables: true, Metadata: true}
+}
+
+// Load reads an EPUB file and converts it to markdown. The entries of the
+// table of contents become headings nested at their level.
+func (c *EpubConverter) Load(path string) (string, error) {
+	return c.LoadContext(context.Background(), path)
+}
+
+// LoadContext converts an EPUB file like Load, stopping before the next
+// chapter once ctx is done.
+func (c *EpubConverter) LoadContext(ctx context.Context, path string) (string, error) {
+	metadata, chapters, err := c.readBook(ctx, path)
 	if err != nil {
-		return "", fmt.Errorf("failed to open EPUB file: %w", err)
+		return "", err
 	}
-	defer reader.Close()
+	return joinBook(metadata, chapters), nil
+}
 
-	// Find and parse container.xml
-	containerFile, err := findFileInZip(&reader.Reader, "META-INF/container.xml")
+// LoadBytes converts a book held in memory like Load.
+func (c *EpubConverter) LoadBytes(ctx context.Context, data []byte) (string, error) {
+	reader, err := newArchiveReader(bytes.NewReader(data), int64(len(data)), c.Options.ArchiveLimits)
 	if err != nil {
-		return "", fmt.Errorf("failed to find container.xml: %w", err)
+		return "", fmt.Errorf("failed to open EPUB file: %w", corrupt(err))
 	}
+	metadata, chapters, err := c.readArchive(ctx, reader)
+	if err != nil {
+		return "", err
+	}
+	return joinBook(metadata, chapters), nil
+}
 
-	var container Container
-	if err := parseXMLFile(containerFile, &container); err != nil {
-		return "", fmt.Errorf("failed to parse container.xml: %w", err)
+// joinBook joins the metadata and chapters of a book, followed by their
+// footnotes.
+func joinBook(metadata string, chapters []epubChapter) string {
+	var markdownParts, footnotes []string
+	if metadata != "" {
+		markdownParts = append(markdownParts, metadata)
 	}
+	for _, chapter := range chapters {
+		markdownParts = append(markdownParts, chapter.markdown)
+		footnotes = append(footnotes, chapter.footnotes...)
+	}
+	if len(footnotes) > 0 {
+		markdownParts = append(markdownParts, strings.Join(footnotes, "\n"))
+	}
+	return strings.Join(markdownParts, "\n\n")
+}
 
-	if len(container.Rootfiles) == 0 {
-		return "", errors.New("no rootfiles found in container.xml")
+// LoadChapters reads an EPUB file and converts each chapter to markdown of
+// its own, in reading order, leaving out the book metadata.
+func (c *EpubConverter) LoadChapters(path string) ([]EpubChapter, error) {
+	_, chapters, err := c.readBook(context.Background(), path)
+	if err != nil {
+		return nil, err
 	}
 
-	// Parse the OPF file
-	opfPath := container.Rootfiles[0].FullPath
-	opfFile, err := findFileInZip(&reader.Reader, opfPath)
+	result := make([]EpubChapter, 0, len(chapters))
+	for _, chapter := range chapters {
+		markdown := chapter.markdown
+		if len(chapter.footnotes) > 0 {
+			markdown += "\n\n" + strings.Join(chapter.footnotes, "\n")
+		}
+		result = append(result, EpubChapter{Title: chapter.title, Markdown: markdown})
+	}
+	return result, nil
+}
+
+// readBook reads an EPUB file and returns its formatted metadata and the
+// selected chapters that have text, stopping once ctx is done.
+func (c *EpubConverter) readBook(ctx context.Context, path string) (string, []epubChapter, error) {
+	// Open the EPUB file as a ZIP archive
+	reader, err := openArchive(path, c.Options.ArchiveLimits)
 	if err != nil {
-		return "", fmt.Errorf("failed to find OPF file %s: %w", opfPath, err)
+		return "", nil, fmt.Errorf("failed to open EPUB file: %w", corrupt(err))
 	}
+	defer reader.Close()
+	return c.readArchive(ctx, &reader.Reader)
+}
 
-	var pkg Package
-	if err := parseXMLFile(opfFile, &pkg); err != nil {
-		return "", fmt.Errorf("failed to parse OPF file: %w", err)
+// readArchive reads the metadata and selected chapters of an EPUB archive
+// like readBook.
+func (c *EpubConverter) readArchive(ctx context.Context, reader *zip.Reader) (string, []epubChapter, error) {
+	selection, err := utils.ParseNumberRange(c.Options.Chapters)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid chapter selection: %w", err)
+	}
+
+	pkg, opfPath, err := readPackage(reader)
+	if err != nil {
+		return "", nil, err
 	}
 
 	// Create a map of item IDs to hrefs
@@ -121,19 +218,18 @@ func (*EpubConverter) Load(path string) (string, error) {
 	// Get the base directory of the OPF file
 	baseDir := filepath.Dir(opfPath)
 
-	// Process spine items in order
-	var markdownParts []string
-
-	// Add metadata as header
-	metadata := formatMetadata(pkg.Metadata)
-	if metadata != "" {
-		markdownParts = append(markdownParts, metadata)
+	// Group the table of contents entries by content document
+	navigation := make(map[string][]epubNavPoint)
+	for _, point := range readNavigation(reader, &pkg, filepath.ToSlash(baseDir)) {
+		navigation[point.File] = append(navigation[point.File], point)
 	}
 
-	// Convert content files
-	for _, spineItem := range pkg.Spine.Items {
+	// Parse the content documents of the selected chapters
+	book := &epubBook{reader: reader, docs: make(map[string]*html.Node)}
+	var names []string
+	for i, spineItem := range pkg.Spine.Items {
 		href, exists := manifestMap[spineItem.IDRef]
-		if !exists {
+		if !exists || !selection.Contains(i+1) {
 			continue
 		}
 
@@ -141,25 +237,75 @@ func (*EpubConverter) Load(path string) (string, error) {
 			href = filepath.Join(baseDir, href)
 		}
 
-		// Find and convert the content file
-		contentFile, err := findFileInZip(&reader.Reader, href)
-		if err != nil {
-			// Skip missing files
+		// Find and parse the content file, skipping missing files
+		doc := book.document(href)
+		if doc == nil {
 			continue
 		}
+		placeNavHeadings(doc, navigation[href])
+		names = append(names, href)
+	}
+	footnotes := resolveBookLinks(book, names)
 
-		markdown, err := convertHTMLToMarkdown(contentFile)
+	// Convert content files
+	conv := epubMarkdownConverter()
+	images := epubImages{links: make(map[string]string), names: make(map[string]bool)}
+	var chapters []epubChapter
+	progress := newProgressCounter(c.Progress, "chapter", len(names))
+	for _, name := range names {
+		if err := ctx.Err(); err != nil {
+			return "", nil, err
+		}
+		if c.Options.Images != ImagesLink {
+			c.rewriteImages(book.reader, book.docs[name], name, images)
+		}
+		title := chapterTitle(book.docs[name], navigation[name])
+		markdown, err := conv.ConvertNode(book.docs[name])
+		progress.step(name)
 		if err != nil {
 			// Skip files that can't be converted
 			continue
 		}
 
-		if strings.TrimSpace(markdown) != "" {
-			markdownParts = append(markdownParts, markdown)
+		if markdown := strings.TrimSpace(string(markdown)); markdown != "" {
+			chapters = append(chapters, epubChapter{title: title, markdown: markdown, footnotes: footnotes[name]})
 		}
 	}
 
-	return strings.Join(markdownParts, "\n\n"), nil
+	return formatMetadata(pkg.Metadata), chapters, nil
+}
+
+// readPackage parses the OPF package document of an EPUB archive, returning
+// it with its path in the archive.
+func readPackage(reader *zip.Reader) (Package, string, error) {
+	var pkg Package
+
+	// Find and parse container.xml
+	containerFile, err := findFileInZip(reader, "META-INF/container.xml")
+	if err != nil {
+		return pkg, "", fmt.Errorf("failed to find container.xml: %w", err)
+	}
+
+	var container Container
+	if err := parseXMLFile(containerFile, &container); err != nil {
+		return pkg, "", fmt.Errorf("failed to parse container.xml: %w", corrupt(err))
+	}
+
+	if len(container.Rootfiles) == 0 {
+		return pkg, "", errors.New("no rootfiles found in container.xml")
+	}
+
+	// Parse the OPF file
+	opfPath := container.Rootfiles[0].FullPath
+	opfFile, err := findFileInZip(reader, opfPath)
+	if err != nil {
+		return pkg, "", fmt.Errorf("failed to find OPF file %s: %w", opfPath, err)
+	}
+
+	if err := parseXMLFile(opfFile, &pkg); err != nil {
+		return pkg, "", fmt.Errorf("failed to parse OPF file: %w", corrupt(err))
+	}
+	return pkg, opfPath, nil
 }
 
 func findFileInZip(reader *zip.Reader, filename string) (*zip.File, error) {
@@ -186,25 +332,75 @@ func parseXMLFile(file *zip.File, v any) error {
 	return xml.Unmarshal(data, v)
 }
 
-func convertHTMLToMarkdown(file *zip.File) (string, error) {
+// epubImages holds the links of the images of a book already written, so
+// that images used by several chapters are written once.
+type epubImages struct {
+	links map[string]string
+	names map[string]bool
+}
+
+// rewriteImages applies the image policy to the <img> elements of a content
+// document. Images missing from the book keep their link.
+func (c *EpubConverter) rewriteImages(reader *zip.Reader, doc *html.Node, name string, images epubImages) {
+	var imgs []*html.Node
+	for n := range doc.Descendants() {
+		if n.Type == html.ElementNode && n.Data == "img" {
+			imgs = append(imgs, n)
+		}
+	}
+
+	for _, img := range imgs {
+		if c.Options.Images == ImagesDrop {
+			img.Parent.RemoveChild(img)
+			continue
+		}
+		ref, err := url.Parse(strings.TrimSpace(htmlAttr(img, "src")))
+		if err != nil || ref.Scheme != "" || ref.Host != "" || ref.Path == "" {
+			continue
+		}
+
+		target := path.Join(path.Dir(name), ref.Path)
+		link, ok := images.links[target]
+		if !ok {
+			link = c.imageLink(reader, target, images.names)
+			images.links[target] = link
+		}
+		if link != "" {
+			setHTMLAttr(img, "src", link)
+		}
+	}
+}
+
+// imageLink reads an image of the book and returns it as a data URI or
+// writes it to the image directory and returns the link to the file. It
+// returns an empty string when the image cannot be read or written.
+func (c *EpubConverter) imageLink(reader *zip.Reader, name string, names map[string]bool) string {
+	file, err := findFileInZip(reader, name)
+	if err != nil {
+		return ""
+	}
 	rc, err := file.Open()
 	if err != nil {
-		return "", err
+		return ""
 	}
 	defer rc.Close()
-
-	content, err := io.ReadAll(rc)
-	if err != nil {
-		return "", err
+	data, err := io.ReadAll(io.LimitReader(rc, maxResourceSize+1))
+	if err != nil || len(data) > maxResourceSize {
+		return ""
 	}
 
-	// Convert HTML to Markdown
-	markdown, err := html2md.ConvertString(string(content))
+	mediaType, ok := imageMediaType(name, data)
+	if !ok {
+		return ""
+	}
+	if c.Options.Images == ImagesInline {
+		return dataURI(mediaType, data)
+	}
+	link, err := saveImage(c.Options, path.Base(name), mediaType, data, names)
 	if err != nil {
-		return "", err
+		return ""
 	}
-
-	return strings.TrimSpace(markdown), nil
+	return link
 }
 
 func formatMetadata(metadata Metadata) string {
@@ -1,12 +1,73 @@
 package converters
 
 import (
+	"archive/zip"
+	"encoding/base64"
+	"fmt"
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 )
 
+// testEpub describes a test book. Paths are relative to the OEBPS
+// directory of the package document.
+type testEpub struct {
+	// Chapters lists the content documents of the spine in order.
+	Chapters []string
+
+	// Manifest holds extra manifest items.
+	Manifest string
+
+	// Toc is the id of the NCX manifest item, for EPUB 2 books.
+	Toc string
+
+	// Files holds the content of the files of the book.
+	Files map[string]string
+}
+
+// writeTestEpub writes a book to a temporary file and returns its path.
+func writeTestEpub(t *testing.T, book testEpub) string {
+	t.Helper()
+	var manifest, spine strings.Builder
+	for i, chapter := range book.Chapters {
+		fmt.Fprintf(&manifest, `<item id="c%d" href="%s" media-type="application/xhtml+xml"/>`, i+1, chapter)
+		fmt.Fprintf(&spine, `<itemref idref="c%d"/>`, i+1)
+	}
+	files := map[string]string{
+		"META-INF/container.xml": `<?xml version="1.0"?><container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">` +
+			`<rootfiles><rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/></rootfiles></container>`,
+		"OEBPS/content.opf": `<?xml version="1.0"?><package xmlns="http://www.idpf.org/2007/opf" version="3.0">` +
+			`<metadata xmlns:dc="http://purl.org/dc/elements/1.1/"></metadata>` +
+			`<manifest>` + manifest.String() + book.Manifest + `</manifest><spine toc="` + book.Toc + `">` + spine.String() + `</spine></package>`,
+	}
+	for name, content := range book.Files {
+		files["OEBPS/"+name] = content
+	}
+
+	path := filepath.Join(t.TempDir(), "book.epub")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create test EPUB file: %v", err)
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("Failed to create test EPUB file: %v", err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatalf("Failed to create test EPUB file: %v", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("Failed to create test EPUB file: %v", err)
+	}
+	return path
+}
+
 func TestNewEpubConverter(t *testing.T) {
 	converter := NewEpubConverter()
 
@@ -253,3 +314,175 @@ func TestEpubConverter_LoadingScenarios(t *testing.T) {
 		})
 	}
 }
+
+func TestEpubConverter_Load_Images(t *testing.T) {
+	book := writeTestEpub(t, testEpub{
+		Chapters: []string{"text/one.xhtml", "text/two.xhtml"},
+		Files: map[string]string{
+			"text/one.xhtml":   `<html><body><p><img src="../images/map%201.png" alt="Map"></p></body></html>`,
+			"text/two.xhtml":   `<html><body><p><img src="../images/map%201.png" alt="Again"><img src="missing.png" alt="Missing"></p></body></html>`,
+			"images/map 1.png": testPNG,
+		},
+	})
+
+	result, err := NewEpubConverter().Load(book)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	if !strings.Contains(result, "![Map](../images/map%201.png)") {
+		t.Errorf("Load() = %q, want the image link kept", result)
+	}
+
+	converter := &EpubConverter{Options: ConvertOptions{Images: ImagesInline}}
+	if result, err = converter.Load(book); err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	uri := "data:image/png;base64," + base64.StdEncoding.EncodeToString([]byte(testPNG))
+	if !strings.Contains(result, "![Map]("+uri+")") || !strings.Contains(result, "![Again]("+uri+")") {
+		t.Errorf("Load() with ImagesInline = %q, want data URIs", result)
+	}
+	if !strings.Contains(result, "![Missing](missing.png)") {
+		t.Errorf("Load() with ImagesInline = %q, want the missing image link kept", result)
+	}
+
+	dir := filepath.Join(t.TempDir(), "assets")
+	converter = &EpubConverter{Options: ConvertOptions{Images: ImagesDownload, ImageDir: dir}}
+	if result, err = converter.Load(book); err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	link := filepath.ToSlash(filepath.Join(dir, "map%201.png"))
+	if strings.Count(result, link) != 2 {
+		t.Errorf("Load() with ImagesDownload = %q, want two links to %s", result, link)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("ImagesDownload wrote %d files, want 1", len(entries))
+	}
+
+	converter = &EpubConverter{Options: ConvertOptions{Images: ImagesDrop}}
+	if result, err = converter.Load(book); err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	if strings.Contains(result, "![") {
+		t.Errorf("Load() with ImagesDrop = %q, want no images", result)
+	}
+}
+
+func TestEpubConverter_Load_Navigation(t *testing.T) {
+	chapters := map[string]string{
+		"one.xhtml": `<html><body><h1>Chapter One</h1><p>First.</p>` +
+			`<section id="s1"><h3>A Section</h3><p>Nested.</p></section></body></html>`,
+		"two.xhtml": `<html><body><p>Untitled start.</p><p id="end">The end.</p></body></html>`,
+	}
+
+	nav := `<html xmlns:epub="http://www.idpf.org/2007/ops"><body>` +
+		`<nav epub:type="landmarks"><ol><li><a href="two.xhtml">Ignored</a></li></ol></nav>` +
+		`<nav epub:type="toc"><ol>` +
+		`<li><a href="one.xhtml">Chapter One</a><ol><li><a href="one.xhtml#s1">A Section</a></li></ol></li>` +
+		`<li><a href="two.xhtml">Chapter Two</a><ol><li><a href="two.xhtml#end">Epilogue</a></li></ol></li>` +
+		`</ol></nav></body></html>`
+	files := map[string]string{"nav.xhtml": nav}
+	for name, content := range chapters {
+		files[name] = content
+	}
+	epub3 := writeTestEpub(t, testEpub{
+		Chapters: []string{"one.xhtml", "two.xhtml"},
+		Manifest: `<item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>`,
+		Files:    files,
+	})
+
+	ncx := `<?xml version="1.0"?><ncx xmlns="http://www.daisy.org/z3986/2005/ncx/"><navMap>` +
+		`<navPoint id="p1"><navLabel><text>Chapter One</text></navLabel><content src="one.xhtml"/>` +
+		`<navPoint id="p2"><navLabel><text>A Section</text></navLabel><content src="one.xhtml#s1"/></navPoint></navPoint>` +
+		`<navPoint id="p3"><navLabel><text>Chapter Two</text></navLabel><content src="two.xhtml"/>` +
+		`<navPoint id="p4"><navLabel><text>Epilogue</text></navLabel><content src="two.xhtml#end"/></navPoint></navPoint>` +
+		`</navMap></ncx>`
+	files = map[string]string{"toc.ncx": ncx}
+	for name, content := range chapters {
+		files[name] = content
+	}
+	epub2 := writeTestEpub(t, testEpub{
+		Chapters: []string{"one.xhtml", "two.xhtml"},
+		Manifest: `<item id="ncx" href="toc.ncx" media-type="application/x-dtbncx+xml"/>`,
+		Toc:      "ncx",
+		Files:    files,
+	})
+
+	expected := "# Chapter One\n\nFirst.\n\n## A Section\n\nNested.\n\n" +
+		"# Chapter Two\n\nUntitled start.\n\n## Epilogue\n\nThe end."
+	for name, book := range map[string]string{"nav": epub3, "ncx": epub2} {
+		result, err := NewEpubConverter().Load(book)
+		if err != nil {
+			t.Fatalf("Load() with %s returned unexpected error: %v", name, err)
+		}
+		if result != expected {
+			t.Errorf("Load() with %s = %q, want %q", name, result, expected)
+		}
+	}
+
+	converter := &EpubConverter{Options: ConvertOptions{Chapters: "2"}}
+	result, err := converter.Load(epub3)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	if want := "# Chapter Two\n\nUntitled start.\n\n## Epilogue\n\nThe end."; result != want {
+		t.Errorf("Load() with Chapters = %q, want %q", result, want)
+	}
+
+	converter = &EpubConverter{Options: ConvertOptions{Chapters: "x"}}
+	if _, err := converter.Load(epub3); err == nil || !strings.Contains(err.Error(), "invalid chapter selection") {
+		t.Errorf("Load() with an invalid selection error = %v, want an invalid chapter selection error", err)
+	}
+}
+
+func TestEpubConverter_Load_FootnotesAndLinks(t *testing.T) {
+	book := writeTestEpub(t, testEpub{
+		Chapters: []string{"one.xhtml", "two.xhtml", "notes.xhtml"},
+		Files: map[string]string{
+			"one.xhtml": `<html xmlns:epub="http://www.idpf.org/2007/ops"><body><h1>Start</h1>` +
+				`<p>A claim<a epub:type="noteref" id="r1" href="#n1">1</a> and <a href="two.xhtml#later">a later point</a>.</p>` +
+				`<aside epub:type="footnote" id="n1"><p><a href="#r1">1.</a> Inline note.</p></aside></body></html>`,
+			"two.xhtml": `<html xmlns:epub="http://www.idpf.org/2007/ops"><body><h1>Next Part</h1><p>Intro.</p>` +
+				`<h2>Details</h2><p id="later">Later<sup id="r2"><a href="notes.xhtml#e2">2</a></sup>.</p>` +
+				`<p>Back to <a href="one.xhtml">the start</a> or <a href="missing.xhtml#x">nowhere</a>.</p></body></html>`,
+			"notes.xhtml": `<html xmlns:epub="http://www.idpf.org/2007/ops"><body><section epub:type="endnotes"><h1>Notes</h1><ol>` +
+				`<li epub:type="endnote" id="e2"><p>An endnote. <a href="two.xhtml#r2">Back</a></p></li>` +
+				`</ol></section></body></html>`,
+		},
+	})
+
+	result, err := NewEpubConverter().Load(book)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	expected := "# Start\n\nA claim[^1] and [a later point](#details).\n\n" +
+		"# Next Part\n\nIntro.\n\n## Details\n\nLater[^2].\n\nBack to [the start](#start) or nowhere.\n\n" +
+		"# Notes\n\n" +
+		"[^1]: Inline note.\n[^2]: An endnote."
+	if result != expected {
+		t.Errorf("Load() = %q, want %q", result, expected)
+	}
+}
+
+func TestEpubConverter_LoadChapters(t *testing.T) {
+	book := writeTestEpub(t, testEpub{
+		Chapters: []string{"one.xhtml", "two.xhtml", "empty.xhtml"},
+		Files: map[string]string{
+			"one.xhtml": `<html xmlns:epub="http://www.idpf.org/2007/ops"><body><h1>First</h1>` +
+				`<p>Text<a epub:type="noteref" href="#n1">1</a>.</p><aside epub:type="footnote" id="n1">A note.</aside></body></html>`,
+			"two.xhtml":   `<html><body><p>No heading.</p></body></html>`,
+			"empty.xhtml": `<html><body></body></html>`,
+		},
+	})
+
+	chapters, err := (&EpubConverter{}).LoadChapters(book)
+	if err != nil {
+		t.Fatalf("LoadChapters() returned unexpected error: %v", err)
+	}
+	expected := []EpubChapter{
+		{Title: "First", Markdown: "# First\n\nText[^1].\n\n[^1]: A note."},
+		{Title: "", Markdown: "No heading."},
+	}
+	if !reflect.DeepEqual(chapters, expected) {
+		t.Errorf("LoadChapters() = %q, want %q", chapters, expected)
+	}
+}
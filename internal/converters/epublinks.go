@@ -0,0 +1,269 @@
+package converters
+
+import (
+	"archive/zip"
+	"cmp"
+	"fmt"
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/JohannesKaufmann/html-to-markdown/v2/converter"
+	"github.com/JohannesKaufmann/html-to-markdown/v2/plugin/base"
+	"github.com/JohannesKaufmann/html-to-markdown/v2/plugin/commonmark"
+	"github.com/flaviodelgrosso/marky/internal/utils"
+	"golang.org/x/net/html"
+)
+
+// epubBook holds the content documents of a book parsed so far, by name in
+// the archive.
+type epubBook struct {
+	reader *zip.Reader
+	docs   map[string]*html.Node
+}
+
+// document returns a parsed content document, or nil when it is missing or
+// cannot be parsed.
+func (b *epubBook) document(name string) *html.Node {
+	if doc, ok := b.docs[name]; ok {
+		return doc
+	}
+	var doc *html.Node
+	if file, err := findFileInZip(b.reader, name); err == nil {
+		if rc, err := file.Open(); err == nil {
+			doc, _ = html.Parse(rc)
+			rc.Close()
+		}
+	}
+	b.docs[name] = doc
+	return doc
+}
+
+// footnoteRefTag is the element note references are replaced with, rendered
+// as a markdown footnote reference.
+const footnoteRefTag = "marky-footnote-ref"
+
+// epubMarkdownConverter returns an html-to-markdown converter rendering
+// footnote references.
+func epubMarkdownConverter() *converter.Converter {
+	conv := converter.NewConverter(converter.WithPlugins(base.NewBasePlugin(), commonmark.NewCommonmarkPlugin()))
+	conv.Register.RendererFor(footnoteRefTag, converter.TagTypeInline, func(_ converter.Context, w converter.Writer, n *html.Node) converter.RenderStatus {
+		w.WriteString("[^" + htmlAttr(n, "label") + "]")
+		return converter.RenderSuccess
+	}, converter.PriorityEarly)
+	return conv
+}
+
+// resolveBookLinks rewrites the links between the content documents of the
+// selected chapters. References to notes become footnote references and the
+// notes are removed from the text; the footnotes are returned in order, by
+// the chapter that first references them.
+// Other links point to the anchor of the heading of the section they target,
+// or are unwrapped when the target is not part of the output.
+func resolveBookLinks(book *epubBook, chapters []string) map[string][]string {
+	anchors := headingAnchors(book, chapters)
+
+	// Resolve the links before changing the documents, so that the links
+	// back from notes to their references are recognized.
+	type bookRef struct {
+		link  *html.Node
+		key   string
+		label string
+	}
+	var refs []bookRef
+	footnotes := make(map[string][]string)
+	var notes []*html.Node
+	labels := make(map[*html.Node]string)
+	for _, name := range chapters {
+		for link := range book.docs[name].Descendants() {
+			if link.Type != html.ElementNode || link.Data != "a" || htmlAttr(link, "href") == "" {
+				continue
+			}
+			file, fragment, ok := bookLink(name, htmlAttr(link, "href"))
+			if !ok {
+				continue
+			}
+
+			var target *html.Node
+			if doc := book.document(file); doc != nil && fragment != "" {
+				target = elementByID(doc, fragment)
+			}
+			if target == nil || !hasEpubType(link, "noteref") && noteContainer(target) == nil {
+				key := file
+				if fragment != "" {
+					key += "#" + fragment
+				}
+				refs = append(refs, bookRef{link: link, key: key})
+				continue
+			}
+
+			note := cmp.Or(noteContainer(target), target)
+			label, ok := labels[note]
+			if !ok {
+				label = strconv.Itoa(len(labels) + 1)
+				labels[note] = label
+				footnotes[name] = append(footnotes[name], fmt.Sprintf("[^%s]: %s", label, noteText(note, file, name, link)))
+				notes = append(notes, note)
+			}
+			refs = append(refs, bookRef{link: link, label: label})
+		}
+	}
+
+	for _, ref := range refs {
+		switch {
+		case ref.label != "":
+			footnote := &html.Node{Type: html.ElementNode, Data: footnoteRefTag, Attr: []html.Attribute{{Key: "label", Val: ref.label}}}
+			ref.link.Parent.InsertBefore(footnote, ref.link)
+			ref.link.Parent.RemoveChild(ref.link)
+		case anchors[ref.key] != "":
+			ref.link.Attr = []html.Attribute{{Key: "href", Val: "#" + anchors[ref.key]}}
+		default:
+			unwrap(ref.link)
+		}
+	}
+	for _, note := range notes {
+		if note.Parent != nil {
+			note.Parent.RemoveChild(note)
+		}
+	}
+	return footnotes
+}
+
+// headingAnchors maps the documents of the selected chapters and the ids of
+// their elements to the anchor of the heading of their section, numbering
+// repeated anchors like GitHub does. Documents map to their first heading.
+func headingAnchors(book *epubBook, chapters []string) map[string]string {
+	anchors := make(map[string]string)
+	seen := make(map[string]int)
+	for _, name := range chapters {
+		current := ""
+		for n := range book.docs[name].Descendants() {
+			if n.Type != html.ElementNode {
+				continue
+			}
+			if isHeadingTag(n.Data) {
+				if text := strings.Join(strings.Fields(htmlText(n)), " "); text != "" {
+					current = utils.Slug(text)
+					if count := seen[current]; count > 0 {
+						seen[current] = count + 1
+						current = fmt.Sprintf("%s-%d", current, count)
+					} else {
+						seen[current] = 1
+					}
+					if anchors[name] == "" {
+						anchors[name] = current
+					}
+				}
+			}
+			if id := elementID(n); id != "" && current != "" {
+				anchors[name+"#"+id] = current
+			}
+		}
+	}
+	return anchors
+}
+
+// bookLink resolves a link of a content document to the name of the target
+// document in the archive and the fragment. It reports false for links out
+// of the book.
+func bookLink(name, href string) (string, string, bool) {
+	ref, err := url.Parse(strings.TrimSpace(href))
+	if err != nil || ref.Scheme != "" || ref.Host != "" {
+		return "", "", false
+	}
+	if ref.Path == "" {
+		return name, ref.Fragment, true
+	}
+	return path.Join(path.Dir(name), ref.Path), ref.Fragment, true
+}
+
+// elementID returns the id of an element, or the name of an <a> anchor.
+func elementID(n *html.Node) string {
+	if id := htmlAttr(n, "id"); id != "" {
+		return id
+	}
+	if n.Data == "a" {
+		return htmlAttr(n, "name")
+	}
+	return ""
+}
+
+// elementByID returns the element of a document with an id, or nil.
+func elementByID(doc *html.Node, id string) *html.Node {
+	for n := range doc.Descendants() {
+		if n.Type == html.ElementNode && elementID(n) == id {
+			return n
+		}
+	}
+	return nil
+}
+
+// hasEpubType reports whether an element has a structural semantics type,
+// given by epub:type or the equivalent DPUB-ARIA role.
+func hasEpubType(n *html.Node, semantic string) bool {
+	return slices.Contains(strings.Fields(htmlAttr(n, "epub:type")), semantic) ||
+		slices.Contains(strings.Fields(htmlAttr(n, "role")), "doc-"+semantic)
+}
+
+// noteContainer returns the footnote or endnote element an element is part
+// of, or nil.
+func noteContainer(n *html.Node) *html.Node {
+	for p := n; p != nil; p = p.Parent {
+		if p.Type == html.ElementNode && (hasEpubType(p, "footnote") || hasEpubType(p, "endnote") || hasEpubType(p, "rearnote")) {
+			return p
+		}
+	}
+	return nil
+}
+
+// noteText returns the text of a note, leaving out the links back to the
+// reference.
+func noteText(note *html.Node, noteFile, refFile string, ref *html.Node) string {
+	var b strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		switch {
+		case n.Type == html.TextNode:
+			b.WriteString(n.Data)
+			return
+		case n.Type == html.ElementNode && n.Data == "a" && isBacklink(n, noteFile, refFile, ref):
+			return
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(note)
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// isBacklink reports whether a link of a note points back to the reference,
+// or to an element containing it.
+func isBacklink(link *html.Node, noteFile, refFile string, ref *html.Node) bool {
+	if hasEpubType(link, "backlink") {
+		return true
+	}
+	file, fragment, ok := bookLink(noteFile, htmlAttr(link, "href"))
+	if !ok || fragment == "" || file != refFile {
+		return false
+	}
+	for p := ref; p != nil; p = p.Parent {
+		if p.Type == html.ElementNode && elementID(p) == fragment {
+			return true
+		}
+	}
+	return false
+}
+
+// unwrap replaces an element with its children.
+func unwrap(n *html.Node) {
+	for child := n.FirstChild; child != nil; {
+		next := child.NextSibling
+		n.RemoveChild(child)
+		n.Parent.InsertBefore(child, n)
+		child = next
+	}
+	n.Parent.RemoveChild(n)
+}
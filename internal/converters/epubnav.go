@@ -0,0 +1,252 @@
+package converters
+
+import (
+	"archive/zip"
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// epubNavPoint is an entry of the table of contents of a book.
+type epubNavPoint struct {
+	Title string
+
+	// File is the name of the content document in the archive and Fragment
+	// the id of the element the entry points to, empty for the start of the
+	// document.
+	File     string
+	Fragment string
+
+	// Level is the nesting depth of the entry, 1 for top-level entries.
+	Level int
+}
+
+// ncxNavPoint is a navPoint element of an EPUB 2 NCX document.
+type ncxNavPoint struct {
+	Label   string `xml:"navLabel>text"`
+	Content struct {
+		Src string `xml:"src,attr"`
+	} `xml:"content"`
+	Points []ncxNavPoint `xml:"navPoint"`
+}
+
+// ncxDocument is the NCX table of contents of an EPUB 2 book.
+type ncxDocument struct {
+	Points []ncxNavPoint `xml:"navMap>navPoint"`
+}
+
+// readNavigation returns the table of contents of a book in reading order,
+// from the EPUB 3 navigation document or, for EPUB 2 books, the NCX
+// document. Books without a readable table of contents have no entries.
+func readNavigation(reader *zip.Reader, pkg *Package, baseDir string) []epubNavPoint {
+	var nav, ncx string
+	for _, item := range pkg.Manifest.Items {
+		switch {
+		case slices.Contains(strings.Fields(item.Properties), "nav"):
+			nav = item.Href
+		case item.MediaType == "application/x-dtbncx+xml" && (ncx == "" || item.ID == pkg.Spine.Toc):
+			ncx = item.Href
+		}
+	}
+
+	if nav != "" {
+		if points := readNavDocument(reader, path.Join(baseDir, nav)); len(points) > 0 {
+			return points
+		}
+	}
+	if ncx != "" {
+		return readNcxDocument(reader, path.Join(baseDir, ncx))
+	}
+	return nil
+}
+
+// readNavDocument reads the toc nav element of an EPUB 3 navigation
+// document, or its first nav element when none is typed as toc.
+func readNavDocument(reader *zip.Reader, name string) []epubNavPoint {
+	file, err := findFileInZip(reader, name)
+	if err != nil {
+		return nil
+	}
+	rc, err := file.Open()
+	if err != nil {
+		return nil
+	}
+	defer rc.Close()
+	doc, err := html.Parse(rc)
+	if err != nil {
+		return nil
+	}
+
+	var toc *html.Node
+	for n := range doc.Descendants() {
+		if n.Type != html.ElementNode || n.Data != "nav" {
+			continue
+		}
+		if toc == nil {
+			toc = n
+		}
+		if slices.Contains(strings.Fields(htmlAttr(n, "epub:type")), "toc") {
+			toc = n
+			break
+		}
+	}
+	if toc == nil {
+		return nil
+	}
+
+	var points []epubNavPoint
+	var walk func(list *html.Node, level int)
+	walk = func(list *html.Node, level int) {
+		for li := list.FirstChild; li != nil; li = li.NextSibling {
+			if li.Type != html.ElementNode || li.Data != "li" {
+				continue
+			}
+			for child := li.FirstChild; child != nil; child = child.NextSibling {
+				if child.Type != html.ElementNode {
+					continue
+				}
+				switch child.Data {
+				case "a":
+					if point, ok := navPoint(name, htmlAttr(child, "href"), htmlText(child), level); ok {
+						points = append(points, point)
+					}
+				case "ol", "ul":
+					walk(child, level+1)
+				}
+			}
+		}
+	}
+	for n := range toc.Descendants() {
+		if n.Type == html.ElementNode && (n.Data == "ol" || n.Data == "ul") {
+			walk(n, 1)
+			break
+		}
+	}
+	return points
+}
+
+// readNcxDocument reads the navigation map of an EPUB 2 NCX document.
+func readNcxDocument(reader *zip.Reader, name string) []epubNavPoint {
+	file, err := findFileInZip(reader, name)
+	if err != nil {
+		return nil
+	}
+	var ncx ncxDocument
+	if err := parseXMLFile(file, &ncx); err != nil {
+		return nil
+	}
+
+	var points []epubNavPoint
+	var walk func(list []ncxNavPoint, level int)
+	walk = func(list []ncxNavPoint, level int) {
+		for _, p := range list {
+			if point, ok := navPoint(name, p.Content.Src, p.Label, level); ok {
+				points = append(points, point)
+			}
+			walk(p.Points, level+1)
+		}
+	}
+	walk(ncx.Points, 1)
+	return points
+}
+
+// navPoint builds a table of contents entry from a link relative to the
+// navigation document. Entries without a title or target are left out.
+func navPoint(nav, href, title string, level int) (epubNavPoint, bool) {
+	title = strings.Join(strings.Fields(title), " ")
+	ref, err := url.Parse(strings.TrimSpace(href))
+	if err != nil || title == "" || ref.Scheme != "" || ref.Path == "" {
+		return epubNavPoint{}, false
+	}
+	return epubNavPoint{
+		Title:    title,
+		File:     path.Join(path.Dir(nav), ref.Path),
+		Fragment: ref.Fragment,
+		Level:    level,
+	}, true
+}
+
+// placeNavHeadings marks the entries of the table of contents that point to
+// a content document as headings at their nesting level. A heading starting
+// the target element takes the level of the entry; otherwise a heading with
+// the entry title is inserted before it. Entries pointing to a missing
+// element start the document.
+func placeNavHeadings(doc *html.Node, points []epubNavPoint) {
+	body := doc
+	for n := range doc.Descendants() {
+		if n.Type == html.ElementNode && n.Data == "body" {
+			body = n
+			break
+		}
+	}
+
+	for _, point := range points {
+		target := body
+		if point.Fragment != "" {
+			for n := range body.Descendants() {
+				if n.Type == html.ElementNode && (htmlAttr(n, "id") == point.Fragment || n.Data == "a" && htmlAttr(n, "name") == point.Fragment) {
+					target = n
+					break
+				}
+			}
+		}
+
+		tag := "h" + strconv.Itoa(min(point.Level, 6))
+		if heading := leadingHeading(target); heading != nil {
+			heading.Data, heading.DataAtom = tag, atom.Lookup([]byte(tag))
+			continue
+		}
+		heading := &html.Node{Type: html.ElementNode, Data: tag, DataAtom: atom.Lookup([]byte(tag))}
+		heading.AppendChild(&html.Node{Type: html.TextNode, Data: point.Title})
+		if target == body || target.Parent == nil {
+			body.InsertBefore(heading, body.FirstChild)
+		} else {
+			target.Parent.InsertBefore(heading, target)
+		}
+	}
+}
+
+// leadingHeading returns the heading an element is part of, or the first
+// heading it contains when no text comes before it.
+func leadingHeading(n *html.Node) *html.Node {
+	for p := n; p != nil; p = p.Parent {
+		if p.Type == html.ElementNode && isHeadingTag(p.Data) {
+			return p
+		}
+	}
+	for d := range n.Descendants() {
+		switch {
+		case d.Type == html.ElementNode && isHeadingTag(d.Data):
+			return d
+		case d.Type == html.TextNode && strings.TrimSpace(d.Data) != "":
+			return nil
+		}
+	}
+	return nil
+}
+
+// isHeadingTag reports whether a tag name is h1 to h6.
+func isHeadingTag(tag string) bool {
+	return len(tag) == 2 && tag[0] == 'h' && tag[1] >= '1' && tag[1] <= '6'
+}
+
+// chapterTitle returns the title of the first table of contents entry of a
+// content document, or the text of its first heading.
+func chapterTitle(doc *html.Node, points []epubNavPoint) string {
+	if len(points) > 0 {
+		return points[0].Title
+	}
+	for n := range doc.Descendants() {
+		if n.Type == html.ElementNode && isHeadingTag(n.Data) {
+			if title := strings.Join(strings.Fields(htmlText(n)), " "); title != "" {
+				return title
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,48 @@
+package converters
+
+import (
+	"archive/zip"
+	"compress/flate"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// ErrCorruptDocument is returned for documents whose content cannot be
+// read, such as truncated packages or malformed XML, JSON or CSV.
+var ErrCorruptDocument = errors.New("document is corrupt")
+
+// corrupt wraps the errors of malformed content with ErrCorruptDocument,
+// returning other errors, such as those of missing files or wrong
+// passwords, as they are.
+func corrupt(err error) error {
+	if err == nil || errors.Is(err, ErrCorruptDocument) || !malformed(err) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", ErrCorruptDocument, err)
+}
+
+// malformed reports whether err comes from reading malformed content.
+func malformed(err error) bool {
+	var (
+		xmlErr      *xml.SyntaxError
+		jsonErr     *json.SyntaxError
+		jsonTypeErr *json.UnmarshalTypeError
+		csvErr      *csv.ParseError
+		flateErr    flate.CorruptInputError
+		mimeErr     textproto.ProtocolError
+	)
+	switch {
+	case errors.Is(err, zip.ErrFormat), errors.Is(err, zip.ErrChecksum), errors.Is(err, zip.ErrAlgorithm),
+		errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, excelize.ErrWorkbookFileFormat):
+		return true
+	}
+	return errors.As(err, &xmlErr) || errors.As(err, &jsonErr) || errors.As(err, &jsonTypeErr) ||
+		errors.As(err, &csvErr) || errors.As(err, &flateErr) || errors.As(err, &mimeErr)
+}
@@ -0,0 +1,60 @@
+package converters
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConverters_Load_CorruptDocument(t *testing.T) {
+	testFiles := filepath.Join("..", "..", "test_files")
+	for _, tt := range []struct {
+		name      string
+		path      string
+		converter Converter
+	}{
+		{"docx not a package", writeGarbage(t, "doc.docx"), NewDocConverter()},
+		{"docx truncated part", writeCorruptFile(t, filepath.Join(testFiles, "test.docx"), "word/document.xml"), NewDocConverter()},
+		{"pptx not a package", writeGarbage(t, "deck.pptx"), NewPptxConverter()},
+		{"pptx truncated presentation", writeCorruptFile(t, filepath.Join(testFiles, "test.pptx"), "ppt/presentation.xml"), NewPptxConverter()},
+		{"xlsx not a package", writeGarbage(t, "book.xlsx"), NewExcelConverter()},
+		{"epub not a package", writeGarbage(t, "book.epub"), NewEpubConverter()},
+		{"pdf", writeGarbage(t, "doc.pdf"), NewPdfConverter()},
+		{"ipynb", writeGarbage(t, "notebook.ipynb"), NewIpynbConverter()},
+		{"zip", writeGarbage(t, "files.zip"), NewZipConverter()},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.converter.Load(tt.path)
+			if !errors.Is(err, ErrCorruptDocument) {
+				t.Errorf("Load() error = %v, want ErrCorruptDocument", err)
+			}
+		})
+	}
+}
+
+func TestConverters_Load_NotCorrupt(t *testing.T) {
+	_, err := NewDocConverter().Load("missing.docx")
+	if !errors.Is(err, fs.ErrNotExist) || errors.Is(err, ErrCorruptDocument) {
+		t.Errorf("Load() error = %v, want fs.ErrNotExist only", err)
+	}
+
+	if err := corrupt(nil); err != nil {
+		t.Errorf("corrupt(nil) = %v, want nil", err)
+	}
+	if err := corrupt(ErrEncryptedDocument); errors.Is(err, ErrCorruptDocument) {
+		t.Errorf("corrupt() = %v, want ErrEncryptedDocument unchanged", err)
+	}
+}
+
+// writeGarbage writes a file of the given name whose content is not a
+// document of any format.
+func writeGarbage(t *testing.T, name string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte("{ this is not a document"), 0o644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	return path
+}
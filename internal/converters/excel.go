@@ -1,64 +1,1200 @@
 package converters
 
 import (
+	"archive/zip"
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/xml"
+	"errors"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/flaviodelgrosso/marky/internal/utils"
 	"github.com/xuri/excelize/v2"
 )
 
 // ExcelConverter handles loading and converting Excel files to markdown tables.
+// Every sheet is rendered as a level-two heading followed by its table. Binary
+// XLSB workbooks are read natively and show formulas as their cached values.
 type ExcelConverter struct {
 	BaseConverter
+
+	// Sheets selects the sheets to convert, each entry being a sheet name or a
+	// 1-based sheet index. All sheets are converted when empty.
+	Sheets []string
+
+	// Values controls how cell values are written, ValuesFormatted by default.
+	Values ValueMode
+
+	// Comments includes cell comments and notes as footnotes beneath each table.
+	Comments bool
+
+	// Formulas controls whether formula cells show their cached value, their
+	// formula text or both. FormulasValue by default. Formula text is looked up
+	// per cell, which loads the whole worksheet into memory.
+	Formulas FormulaMode
+
+	// MaxRows limits the number of data rows written per sheet, followed by a
+	// truncation notice. Rows are not limited when zero.
+	MaxRows int
+
+	// MaxCellWidth cuts cell values wider than that many characters, ending
+	// them with an ellipsis. Values are not cut when zero.
+	MaxCellWidth int
+
+	// CellFootnotes writes the full text of the values cut by MaxCellWidth
+	// as footnotes beneath each table, numbered with the comments.
+	CellFootnotes bool
+
+	// AlignColumns right-aligns columns of numbers, such as amounts in
+	// financial exports, and centers columns of dates. Column types are
+	// inferred from the first rows of each sheet.
+	AlignColumns bool
+
+	// SkipHiddenSheets, SkipHiddenRows and SkipHiddenColumns leave out hidden
+	// sheets, rows and columns, which are converted by default.
+	SkipHiddenSheets  bool
+	SkipHiddenRows    bool
+	SkipHiddenColumns bool
+
+	// Marker is the template of the marker written before the table of each
+	// sheet, MarkerHeading when empty.
+	Marker string
+
+	// Provenance writes a <!-- source: sheet Name!A1:D20 --> comment under
+	// each sheet heading, with the range of the cells of the table. Tables
+	// are then held in memory until their last row is read.
+	Provenance bool
+
+	// Password opens encrypted workbooks.
+	Password string
+
+	// Partial keeps converting the sheets after one that cannot be read,
+	// leaving it out with a warning in the report instead of failing the
+	// whole conversion. The rows read before the error are kept.
+	Partial bool
+
+	// InMemory unzips workbooks in memory whatever the size of their
+	// worksheets, rather than unzipping the largest to temporary files. It
+	// is set by the InMemory field of ConvertOptions.
+	InMemory bool
+
+	// MmapThreshold is the size from which workbooks are mapped into memory
+	// rather than read, as set by the MmapThreshold field of ConvertOptions.
+	MmapThreshold int64
+
+	// ArchiveLimits bounds the archives of workbooks, as set by the
+	// ArchiveLimits field of ConvertOptions.
+	ArchiveLimits ArchiveLimits
+
+	// Tables sets the layout of the tables of the sheets, as set by the
+	// Tables field of ConvertOptions. MaxCellWidth takes precedence over its
+	// cell width.
+	Tables utils.TableOptions
+
+	// Logger receives the problems that do not fail conversions, such as
+	// workbooks that could not be closed. slog.Default is used when nil.
+	Logger *slog.Logger
+
+	// Progress, when set, is called after each selected sheet.
+	Progress ProgressFunc
+}
+
+// SetLogger sets the logger of the problems that do not fail conversions.
+func (e *ExcelConverter) SetLogger(logger *slog.Logger) {
+	e.Logger = logger
 }
 
+// SetProgress sets the function called after each sheet.
+func (e *ExcelConverter) SetProgress(progress ProgressFunc) {
+	e.Progress = progress
+}
+
+// ValueMode controls how Excel cell values are written to the markdown tables.
+type ValueMode int
+
+const (
+	// ValuesFormatted applies the number format of each cell, as spreadsheet
+	// applications display it.
+	ValuesFormatted ValueMode = iota
+
+	// ValuesISODates writes date and time cells as ISO 8601 and applies the
+	// number format of every other cell. Cell styles are looked up per cell,
+	// which loads the whole worksheet into memory.
+	ValuesISODates
+
+	// ValuesRaw writes the stored cell values without any number format.
+	ValuesRaw
+)
+
 // NewExcelConverter creates a new Excel converter with appropriate MIME types and extensions.
 func NewExcelConverter() Converter {
 	return &ExcelConverter{
 		BaseConverter: NewBaseConverter(
-			[]string{".xlsx", ".xls"},
+			[]string{".xlsx", ".xls", ".xlsb"},
 			[]string{
 				"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
 				"application/vnd.openxmlformats-officedocument.spreadsheetml",
 				"application/vnd.ms-excel",
+				"application/vnd.ms-excel.sheet.binary.macroenabled.12",
 			},
 		),
 	}
 }
 
-// Load reads an Excel file and converts it to a markdown table.
-func (*ExcelConverter) Load(path string) (string, error) {
-	rows, err := readExcelFile(path)
+// Capabilities returns what the converter can extract from Excel workbooks.
+func (e *ExcelConverter) Capabilities() Capabilities {
+	return Capabilities{Tables: true, Streaming: true, Password: true}
+}
+
+// Load reads an Excel file and converts its sheets to markdown tables.
+// Sheets without any rows are skipped.
+func (e *ExcelConverter) Load(path string) (string, error) {
+	return e.LoadContext(context.Background(), path)
+}
+
+// LoadContext converts an Excel file like Load, stopping before the next row
+// once ctx is done.
+func (e *ExcelConverter) LoadContext(ctx context.Context, path string) (string, error) {
+	var markdown strings.Builder
+	if err := e.writeExcelFile(ctx, path, &markdown, nil); err != nil {
+		return "", fmt.Errorf("failed to load Excel file: %w", corrupt(err))
+	}
+
+	return markdown.String(), nil
+}
+
+// LoadBytes converts an Excel workbook held in memory like Load.
+func (e *ExcelConverter) LoadBytes(ctx context.Context, data []byte) (string, error) {
+	data, err := decryptPackage(data, e.Password)
+	if err != nil {
+		return "", fmt.Errorf("failed to load Excel file: %w", corrupt(err))
+	}
+	zipReader, err := newArchiveReader(bytes.NewReader(data), int64(len(data)), e.ArchiveLimits)
+	if err != nil {
+		return "", fmt.Errorf("failed to load Excel file: %w", corrupt(err))
+	}
+
+	var markdown strings.Builder
+	err = e.writeWorkbook(ctx, zipReader, memoryName, &markdown, nil, func(options excelize.Options) (*excelize.File, error) {
+		return excelize.OpenReader(bytes.NewReader(data), options)
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to load Excel file: %w", corrupt(err))
+	}
+	return markdown.String(), nil
+}
+
+// LoadReport converts an Excel file like Load and reports its sheet count,
+// with a warning for each hidden sheet left out and each sheet with rows
+// beyond MaxRows.
+func (e *ExcelConverter) LoadReport(ctx context.Context, path string) (string, *Report, error) {
+	var markdown strings.Builder
+	report := &Report{}
+	if err := e.writeExcelFile(ctx, path, &markdown, report); err != nil {
+		return "", nil, fmt.Errorf("failed to load Excel file: %w", corrupt(err))
+	}
+
+	return markdown.String(), report, nil
+}
+
+// FormulaMode controls how Excel formula cells are written.
+type FormulaMode int
+
+const (
+	// FormulasValue writes the cached value of formula cells.
+	FormulasValue FormulaMode = iota
+
+	// FormulasText writes the formula text, such as =SUM(A1:B1), instead of the value.
+	FormulasText
+
+	// FormulasBoth writes the cached value followed by the formula text, as
+	// in 3 (=SUM(A1:B1)).
+	FormulasBoth
+)
+
+// excelSheet holds what is known about a worksheet before its rows are
+// streamed: the hyperlinks and comment footnotes of its cells.
+type excelSheet struct {
+	Name      string
+	Marks     cellMarks
+	Footnotes []string
+
+	// Summaries describe the charts drawn on the sheet and its pivot tables.
+	Summaries []string
+
+	// HiddenColumns holds the 1-based numbers of the hidden columns.
+	HiddenColumns map[int]bool
+
+	// FootnoteCount numbers the footnotes across sheets.
+	FootnoteCount *int
+
+	// Number is the 1-based position of the sheet in the workbook.
+	Number int
+
+	// Report collects the warnings of the workbook, when not nil.
+	Report *Report
+}
+
+// writeExcelFile streams the selected sheets of an Excel file to w in
+// workbook order, one row at a time. The sheet count and the warnings are
+// added to report when it is not nil.
+func (e *ExcelConverter) writeExcelFile(ctx context.Context, path string, w *strings.Builder, report *Report) error {
+	zipReader, err := openPackage(path, e.Password, e.MmapThreshold, e.ArchiveLimits)
 	if err != nil {
-		return "", fmt.Errorf("failed to load Excel file: %w", err)
+		return fmt.Errorf("unable to open Excel file %s: %w", path, err)
 	}
+	defer zipReader.Close()
 
-	return utils.ToMarkdownTable(rows), nil
+	return e.writeWorkbook(ctx, zipReader.Reader, path, w, report, func(options excelize.Options) (*excelize.File, error) {
+		return excelize.OpenFile(path, options)
+	})
 }
 
-// readExcelFile reads and parses an Excel file, returning all records from the first sheet.
-func readExcelFile(path string) ([][]string, error) {
-	f, err := excelize.OpenFile(path)
+// writeWorkbook streams the selected sheets of the workbook read by
+// zipReader to w like writeExcelFile, opening it with open unless it is an
+// XLSB workbook. The workbook is named path in errors.
+func (e *ExcelConverter) writeWorkbook(ctx context.Context, zipReader *zip.Reader, path string, w *strings.Builder, report *Report, open func(excelize.Options) (*excelize.File, error)) error {
+	if _, err := findFileInZip(zipReader, xlsbWorkbookPart); err == nil {
+		if err := e.writeXlsbFile(ctx, zipReader, w, report); err != nil {
+			return fmt.Errorf("unable to read XLSB file %s: %w", path, err)
+		}
+		return nil
+	}
+
+	options := excelize.Options{Password: e.Password}
+	if e.InMemory {
+		// Worksheets larger than UnzipXMLSizeLimit are unzipped to
+		// temporary files, so the limit is raised to the size of the archive.
+		options.UnzipXMLSizeLimit = unzipLimit(zipReader)
+	}
+	f, err := open(options)
 	if err != nil {
-		return nil, fmt.Errorf("unable to open Excel file %s: %w", path, err)
+		return fmt.Errorf("unable to open Excel file %s: %w", path, err)
 	}
 	defer func() {
 		if closeErr := f.Close(); closeErr != nil {
 			// Log the close error, but don't override the main error
-			log.Printf("Warning: failed to close Excel file %s: %v\n", path, closeErr)
+			loggerOrDefault(e.Logger).Warn("failed to close Excel file", "path", path, "error", closeErr)
 		}
 	}()
 
 	sheets := f.GetSheetList()
 	if len(sheets) == 0 {
-		return nil, fmt.Errorf("no sheets found in Excel file %s", path)
+		return fmt.Errorf("no sheets found in Excel file %s", path)
+	}
+
+	names, err := selectSheets(sheets, e.Sheets)
+	if err != nil {
+		return err
+	}
+	if report != nil {
+		report.Parts = len(sheets)
+	}
+	parts := worksheetParts(zipReader)
+	rich, err := richSharedStrings(zipReader)
+	if err != nil {
+		return fmt.Errorf("unable to read shared strings in file %s: %w", path, err)
+	}
+
+	footnotes := 0
+	progress := newProgressCounter(e.Progress, "sheet", len(names))
+	for _, name := range names {
+		if visible, err := f.GetSheetVisible(name); err == nil && !visible && e.SkipHiddenSheets {
+			report.warn("hidden sheet %s left out", name)
+			progress.step(name)
+			continue
+		}
+		sheet := excelSheet{
+			Name:          name,
+			Number:        slices.Index(sheets, name) + 1,
+			Marks:         make(cellMarks),
+			Summaries:     sheetSummaries(zipReader, parts[name], e.Tables),
+			HiddenColumns: make(map[int]bool),
+			FootnoteCount: &footnotes,
+			Report:        report,
+		}
+
+		if err := e.loadSheet(ctx, f, zipReader, parts[name], &sheet, rich, path, w); err != nil {
+			if !e.Partial || ctx.Err() != nil {
+				return err
+			}
+			report.warn("sheet %s left out: %v", name, errors.Unwrap(err))
+		}
+		progress.step(name)
+	}
+
+	return nil
+}
+
+// loadSheet scans the worksheet part of a sheet and writes its table to w.
+// The workbook is named path in errors.
+func (e *ExcelConverter) loadSheet(ctx context.Context, f *excelize.File, zipReader *zip.Reader, part string, sheet *excelSheet, rich map[int]string, path string, w *strings.Builder) error {
+	if err := scanWorksheet(zipReader, part, sheet, rich); err != nil {
+		return fmt.Errorf("unable to scan sheet %s in file %s: %w", sheet.Name, path, err)
+	}
+	if e.Comments {
+		if err := annotateCells(f, sheet, sheet.FootnoteCount); err != nil {
+			return fmt.Errorf("unable to read comments from sheet %s in file %s: %w", sheet.Name, path, err)
+		}
+	}
+	if err := e.writeSheet(ctx, f, sheet, w); err != nil {
+		return fmt.Errorf("unable to read rows from sheet %s in file %s: %w", sheet.Name, path, err)
+	}
+	return nil
+}
+
+// unzipLimit returns a size above the uncompressed size of every member of
+// an archive.
+func unzipLimit(r *zip.Reader) int64 {
+	var largest uint64
+	for _, f := range r.File {
+		largest = max(largest, f.UncompressedSize64)
+	}
+	return int64(largest) + 1
+}
+
+// writeSheet streams the rows of a sheet into a markdown table under the
+// sheet heading. Blank rows before the header are dropped, and rows beyond
+// MaxRows are counted for the truncation notice without being written.
+func (e *ExcelConverter) writeSheet(ctx context.Context, f *excelize.File, sheet *excelSheet, w *strings.Builder) error {
+	rows, err := f.Rows(sheet.Name)
+	if err != nil {
+		return err
+	}
+
+	var opts []excelize.Options
+	if e.Values == ValuesRaw {
+		opts = append(opts, excelize.Options{RawCellValue: true})
+	}
+	var formats *numberFormats
+	if e.Values == ValuesISODates {
+		formats = newNumberFormats(f)
+	}
+
+	return e.writeRows(ctx, rows, f, sheet, formats, opts, w)
+}
+
+// sheetRows iterates over the rows of a sheet. It is implemented by excelize
+// for XLSX files and by xlsbRows for XLSB files.
+type sheetRows interface {
+	Next() bool
+	Columns(opts ...excelize.Options) ([]string, error)
+	GetRowOpts() excelize.RowOpts
+	Close() error
+}
+
+// writeRows writes the rows of a sheet and closes them, stopping once ctx is
+// done. f may be nil when the workbook is not read through excelize, which
+// disables formula lookups.
+func (e *ExcelConverter) writeRows(ctx context.Context, rows sheetRows, f *excelize.File, sheet *excelSheet, formats *numberFormats, opts []excelize.Options, w *strings.Builder) error {
+	var table *sheetTable
+	number := 0
+	for rows.Next() {
+		if err := ctx.Err(); err != nil {
+			rows.Close()
+			return err
+		}
+		number++
+		row, err := rows.Columns(opts...)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		if e.SkipHiddenRows && rows.GetRowOpts().Hidden {
+			continue
+		}
+		if len(row) == 0 && len(sheet.Marks[number]) == 0 {
+			if table != nil {
+				table.blank++
+			}
+			continue
+		}
+
+		if table == nil {
+			table = e.newSheetTable(w, sheet)
+		} else if !table.next() {
+			continue
+		}
+
+		table.span(number, len(row))
+		if row, err = e.decorateRow(f, sheet, formats, row, number); err != nil {
+			rows.Close()
+			return err
+		}
+		table.WriteRow(row)
+	}
+	if err := rows.Close(); err != nil {
+		return err
+	}
+
+	if table == nil && len(sheet.Summaries) > 0 {
+		table = e.newSheetTable(w, sheet)
+	}
+	if table != nil {
+		table.finish(w, sheet)
+	}
+	return nil
+}
+
+// sheetTable writes the table of a sheet as its rows arrive. Blank rows are
+// held back until a later row has values, so trailing blank rows are dropped.
+type sheetTable struct {
+	*utils.TableWriter
+	blank int
+	rows  int
+	limit int
+
+	// body holds the table until its range is known, for the provenance
+	// comment, which needs the first and last rows and the widest row.
+	body                *strings.Builder
+	first, last, widest int
+}
+
+// newSheetTable writes the sheet heading and starts its table.
+func (e *ExcelConverter) newSheetTable(w *strings.Builder, sheet *excelSheet) *sheetTable {
+	if w.Len() > 0 {
+		w.WriteString("\n")
+	}
+	w.WriteString(expandMarker(cmp.Or(e.Marker, defaultSheetMarker), sheet.Number, sheet.Name) + "\n\n")
+	table := &sheetTable{limit: e.MaxRows}
+	if e.Provenance {
+		table.body = &strings.Builder{}
+		table.TableWriter = e.Tables.NewTableWriter(table.body)
+	} else {
+		table.TableWriter = e.Tables.NewTableWriter(w)
+	}
+	table.InferAlign = e.AlignColumns
+	// Values are cut by cutCells, before links are added to them
+	table.MaxCellWidth = 0
+	return table
+}
+
+// next counts a data row, writing the blank rows held back before it, and
+// reports whether the row is within the row limit.
+func (t *sheetTable) next() bool {
+	for ; t.blank > 0; t.blank-- {
+		if t.rows++; t.within() {
+			t.WriteRow(nil)
+		}
+	}
+	t.rows++
+	return t.within()
+}
+
+// span extends the range of the table to a written row, given its 1-based
+// number and its number of cells.
+func (t *sheetTable) span(number, cells int) {
+	if t.first == 0 {
+		t.first = number
+	}
+	t.last = number
+	t.widest = max(t.widest, cells)
+}
+
+func (t *sheetTable) within() bool {
+	return t.limit <= 0 || t.rows <= t.limit
+}
+
+// finish writes the rows held by the table, then the truncation notice, the
+// footnotes and the chart and pivot table summaries beneath the table.
+func (t *sheetTable) finish(w *strings.Builder, sheet *excelSheet) {
+	t.Flush()
+	if t.body != nil {
+		dimension := ""
+		if t.first > 0 && t.widest > 0 {
+			column, _ := excelize.ColumnNumberToName(t.widest)
+			dimension = fmt.Sprintf("A%d:%s%d", t.first, column, t.last)
+		}
+		w.WriteString(sourceComment(sheetLocation(sheet.Name, dimension)))
+		if t.body.Len() > 0 {
+			w.WriteString("\n" + t.body.String())
+		}
+	}
+	if !t.within() {
+		fmt.Fprintf(w, "\n_Showing the first %d of %d rows._\n", t.limit, t.rows)
+		sheet.Report.warn("sheet %s: %d of %d rows left out", sheet.Name, t.rows-t.limit, t.rows)
+	}
+	if len(sheet.Footnotes) > 0 {
+		fmt.Fprintf(w, "\n%s\n", strings.Join(sheet.Footnotes, "\n"))
+	}
+	for _, summary := range sheet.Summaries {
+		if !strings.HasSuffix(w.String(), "\n\n") {
+			w.WriteString("\n")
+		}
+		w.WriteString(summary)
+	}
+}
+
+// cutCells cuts the values of a row wider than MaxCellWidth, or the cell
+// width of Tables, and collects their full text as footnotes when
+// CellFootnotes is set. It returns the footnote references of the cut
+// values by column, to be added once the values are escaped.
+func (e *ExcelConverter) cutCells(sheet *excelSheet, row []string) map[int]string {
+	width := cmp.Or(e.MaxCellWidth, e.Tables.MaxCellWidth)
+	refs := make(map[int]string)
+	for i, value := range row {
+		cut, ok := utils.TruncateCell(strings.TrimSpace(value), width)
+		if !ok {
+			continue
+		}
+		if e.CellFootnotes {
+			*sheet.FootnoteCount++
+			refs[i] = fmt.Sprintf("[^%d]", *sheet.FootnoteCount)
+			sheet.Footnotes = append(sheet.Footnotes, fmt.Sprintf("%s: %s", refs[i], strings.Join(strings.Fields(value), " ")))
+		}
+		row[i] = cut
+	}
+	return refs
+}
+
+// decorateRow applies the value mode, formula mode, cell width, markdown
+// escaping, hyperlinks and footnote references to a row. number is the 1-based row number in the sheet.
+func (e *ExcelConverter) decorateRow(f *excelize.File, sheet *excelSheet, formats *numberFormats, row []string, number int) ([]string, error) {
+	for col := range sheet.Marks[number] {
+		for len(row) < col {
+			row = append(row, "")
+		}
+	}
+
+	if formats != nil || (f != nil && e.Formulas != FormulasValue) {
+		for c := range row {
+			cell, err := excelize.CoordinatesToCellName(c+1, number)
+			if err != nil {
+				return nil, err
+			}
+			if formats != nil {
+				if row[c], err = formats.isoValue(sheet.Name, cell, row[c]); err != nil {
+					return nil, err
+				}
+			}
+			if f != nil && e.Formulas != FormulasValue {
+				if row[c], err = e.formulaValue(f, sheet.Name, cell, row[c]); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	refs := e.cutCells(sheet, row)
+	for c, value := range row {
+		row[c] = utils.EscapeMarkdown(value, FlavorGFM) + refs[c]
+	}
+	for col, mark := range sheet.Marks[number] {
+		row[col-1] = mark.apply(row[col-1])
+	}
+
+	if e.SkipHiddenColumns && len(sheet.HiddenColumns) > 0 {
+		visible := row[:0]
+		for c, value := range row {
+			if !sheet.HiddenColumns[c+1] {
+				visible = append(visible, value)
+			}
+		}
+		row = visible
+	}
+	return row, nil
+}
+
+// formulaValue returns the text of a formula cell according to the formula mode.
+func (e *ExcelConverter) formulaValue(f *excelize.File, sheet, cell, value string) (string, error) {
+	formula, err := f.GetCellFormula(sheet, cell)
+	if err != nil || formula == "" {
+		return value, err
+	}
+	if !strings.HasPrefix(formula, "=") {
+		formula = "=" + formula
+	}
+
+	if e.Formulas == FormulasBoth && value != "" {
+		return fmt.Sprintf("%s (%s)", value, formula), nil
+	}
+	return formula, nil
+}
+
+// cellMarks holds the hyperlink targets and footnote references of cells,
+// indexed by row and then column number.
+type cellMarks map[int]map[int]*cellMark
+
+type cellMark struct {
+	Link     string
+	Footnote string
+
+	// Rich is the markdown of a rich text cell, replacing its plain text.
+	Rich string
+}
+
+// mark applies fn to the marks of every cell of a cell or range reference.
+func (m cellMarks) mark(ref string, fn func(*cellMark)) error {
+	first, last, isRange := strings.Cut(ref, ":")
+	if !isRange {
+		last = first
+	}
+	startCol, startRow, err := excelize.CellNameToCoordinates(first)
+	if err != nil {
+		return err
+	}
+	endCol, endRow, err := excelize.CellNameToCoordinates(last)
+	if err != nil {
+		return err
+	}
+
+	for r := startRow; r <= endRow; r++ {
+		if m[r] == nil {
+			m[r] = make(map[int]*cellMark)
+		}
+		for c := startCol; c <= endCol; c++ {
+			if m[r][c] == nil {
+				m[r][c] = &cellMark{}
+			}
+			fn(m[r][c])
+		}
+	}
+	return nil
+}
+
+// apply replaces the cell text with its rich text, turns it into a link and
+// appends its footnote reference.
+func (c *cellMark) apply(text string) string {
+	if c.Rich != "" {
+		text = c.Rich
+	}
+	if c.Link != "" {
+		if text == "" {
+			text = c.Link
+		}
+		text = fmt.Sprintf("[%s](%s)", text, c.Link)
+	}
+	return text + c.Footnote
+}
+
+// Workbook represents the sheet list of xl/workbook.xml.
+type Workbook struct {
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		ID   string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+// worksheetParts maps the sheet names of a workbook to their worksheet parts.
+func worksheetParts(zipReader *zip.Reader) map[string]string {
+	parts := make(map[string]string)
+
+	file, err := findFileInZip(zipReader, "xl/workbook.xml")
+	if err != nil {
+		return parts
+	}
+	var workbook Workbook
+	if err := parseXMLFile(file, &workbook); err != nil {
+		return parts
+	}
+
+	rels := partRelationships(zipReader, "xl/workbook.xml")
+	for _, sheet := range workbook.Sheets {
+		if rel, ok := rels[sheet.ID]; ok {
+			parts[sheet.Name] = rel.Target
+		}
+	}
+	return parts
+}
+
+const (
+	relTypeDrawing    = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/drawing"
+	relTypeChart      = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/chart"
+	relTypePivotTable = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/pivotTable"
+	relTypePivotCache = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/pivotCacheDefinition"
+
+	relTypeSharedStrings = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/sharedStrings"
+)
+
+// sheetSummaries describes the charts drawn on a worksheet or chartsheet part
+// and the pivot tables placed on it, with chart tables written with the
+// options. Parts that cannot be parsed are skipped.
+func sheetSummaries(zipReader *zip.Reader, part string, tables utils.TableOptions) []string {
+	var summaries []string
+	for _, rel := range sortedRelationships(partRelationships(zipReader, part)) {
+		switch rel.Type {
+		case relTypeDrawing:
+			for _, drawingRel := range sortedRelationships(partRelationships(zipReader, rel.Target)) {
+				if drawingRel.Type != relTypeChart {
+					continue
+				}
+				var chart ChartSpace
+				if loadXMLPart(zipReader, drawingRel.Target, &chart) {
+					summaries = append(summaries, summarizeChart(&chart, tables))
+				}
+			}
+		case relTypePivotTable:
+			var pivot PivotTableDefinition
+			if !loadXMLPart(zipReader, rel.Target, &pivot) {
+				continue
+			}
+			var cache PivotCacheDefinition
+			for _, pivotRel := range partRelationships(zipReader, rel.Target) {
+				if pivotRel.Type == relTypePivotCache {
+					loadXMLPart(zipReader, pivotRel.Target, &cache)
+				}
+			}
+			summaries = append(summaries, summarizePivotTable(&pivot, &cache))
+		}
+	}
+	return summaries
+}
+
+// loadXMLPart parses a package part into v and reports whether it succeeded.
+func loadXMLPart(zipReader *zip.Reader, part string, v any) bool {
+	file, err := findFileInZip(zipReader, part)
+	if err != nil {
+		return false
+	}
+	return parseXMLFile(file, v) == nil
+}
+
+// sortedRelationships returns relationships in ID order, so that rId2 sorts
+// before rId10.
+func sortedRelationships(rels map[string]Relationship) []Relationship {
+	sorted := make([]Relationship, 0, len(rels))
+	for _, rel := range rels {
+		sorted = append(sorted, rel)
 	}
+	slices.SortFunc(sorted, func(a, b Relationship) int {
+		if len(a.ID) != len(b.ID) {
+			return len(a.ID) - len(b.ID)
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return sorted
+}
+
+// Hyperlink represents a hyperlink element of a worksheet.
+type Hyperlink struct {
+	Ref      string `xml:"ref,attr"`
+	ID       string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	Location string `xml:"location,attr"`
+}
 
-	rows, err := f.GetRows(sheets[0])
+// WorksheetColumn represents the width and visibility settings of a column range.
+type WorksheetColumn struct {
+	Min    int  `xml:"min,attr"`
+	Max    int  `xml:"max,attr"`
+	Hidden bool `xml:"hidden,attr"`
+}
+
+// scanWorksheet marks the hyperlinked and rich text cells and records the
+// hidden columns of a worksheet part. Only string cells are decoded, so large
+// sheets are not loaded into memory just to find their links.
+func scanWorksheet(zipReader *zip.Reader, part string, sheet *excelSheet, rich map[int]string) error {
+	file, err := findFileInZip(zipReader, part)
 	if err != nil {
-		return nil, fmt.Errorf("unable to read rows from sheet %s in file %s: %w", sheets[0], path, err)
+		return nil
 	}
+	rc, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	var rels map[string]Relationship
+	decoder := xml.NewDecoder(rc)
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "c":
+			if err := markRichCell(decoder, &start, sheet, rich); err != nil {
+				return err
+			}
+		case "col":
+			var col WorksheetColumn
+			if err := decoder.DecodeElement(&col, &start); err != nil {
+				return err
+			}
+			for c := col.Min; col.Hidden && c <= col.Max; c++ {
+				sheet.HiddenColumns[c] = true
+			}
+		case "hyperlink":
+			var link Hyperlink
+			if err := decoder.DecodeElement(&link, &start); err != nil {
+				return err
+			}
+
+			target := link.Location
+			if link.ID != "" {
+				if rels == nil {
+					rels = partRelationships(zipReader, part)
+				}
+				target = rels[link.ID].Target
+			}
+			if target = hyperlinkTarget(target); target == "" {
+				continue
+			}
+			if err := sheet.Marks.mark(link.Ref, func(m *cellMark) { m.Link = target }); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// WorksheetCell represents a string cell of a worksheet, holding either the
+// index of a shared string or an inline string.
+type WorksheetCell struct {
+	Ref    string    `xml:"r,attr"`
+	Type   string    `xml:"t,attr"`
+	Value  string    `xml:"v"`
+	Inline *RichText `xml:"is"`
+}
 
-	return rows, nil
+// markRichCell marks a cell whose shared or inline string has bold or italic
+// runs. Cells of other types are skipped without being decoded.
+func markRichCell(decoder *xml.Decoder, start *xml.StartElement, sheet *excelSheet, rich map[int]string) error {
+	var cellType string
+	for _, attr := range start.Attr {
+		if attr.Name.Local == "t" {
+			cellType = attr.Value
+		}
+	}
+	if cellType != "inlineStr" && (cellType != "s" || len(rich) == 0) {
+		return decoder.Skip()
+	}
+
+	var cell WorksheetCell
+	if err := decoder.DecodeElement(&cell, start); err != nil {
+		return err
+	}
+	var text string
+	if cell.Inline != nil {
+		text = cell.Inline.markdown()
+	} else if index, err := strconv.Atoi(cell.Value); err == nil {
+		text = rich[index]
+	}
+	if text == "" || cell.Ref == "" {
+		return nil
+	}
+	return sheet.Marks.mark(cell.Ref, func(m *cellMark) { m.Rich = text })
+}
+
+// RichText represents a shared string item or inline string, either plain
+// text or a sequence of formatted runs.
+type RichText struct {
+	Text string        `xml:"t"`
+	Runs []RichTextRun `xml:"r"`
+}
+
+// RichTextRun represents a run of a rich text string and its font settings.
+type RichTextRun struct {
+	Text string `xml:"t"`
+	RPr  *struct {
+		B *FontFlag `xml:"b"`
+		I *FontFlag `xml:"i"`
+	} `xml:"rPr"`
+}
+
+// FontFlag represents a boolean font setting, which is on unless its val
+// attribute turns it off.
+type FontFlag struct {
+	Val string `xml:"val,attr"`
+}
+
+func (f *FontFlag) on() bool {
+	return f != nil && f.Val != "0" && f.Val != "false"
+}
+
+// markdown renders the runs of a rich text string with bold and italic
+// markers, merging neighboring runs of the same style. It returns an empty
+// string when no run is bold or italic, as the plain cell value is enough.
+func (r *RichText) markdown() string {
+	type span struct {
+		text  string
+		style runStyle
+	}
+	var spans []span
+	styled := false
+	for _, run := range r.Runs {
+		var style runStyle
+		if run.RPr != nil {
+			style = runStyle{bold: run.RPr.B.on(), italic: run.RPr.I.on()}
+		}
+		styled = styled || style.bold || style.italic
+		if n := len(spans); n > 0 && spans[n-1].style == style {
+			spans[n-1].text += run.Text
+			continue
+		}
+		spans = append(spans, span{run.Text, style})
+	}
+	if !styled {
+		return ""
+	}
+
+	var text strings.Builder
+	for _, s := range spans {
+		s.text = utils.EscapeMarkdown(s.text, FlavorGFM)
+		open, closing := s.style.markers(FlavorGFM)
+		core := strings.TrimSpace(s.text)
+		if len(open) == 0 || core == "" {
+			text.WriteString(s.text)
+			continue
+		}
+		lead := s.text[:strings.Index(s.text, core)]
+		text.WriteString(lead)
+		text.WriteString(strings.Join(open, ""))
+		text.WriteString(core)
+		slices.Reverse(closing)
+		text.WriteString(strings.Join(closing, ""))
+		text.WriteString(s.text[len(lead)+len(core):])
+	}
+	return text.String()
+}
+
+// richSharedStrings returns the markdown of the shared strings that have bold
+// or italic runs, indexed by their position in the shared string table.
+func richSharedStrings(zipReader *zip.Reader) (map[int]string, error) {
+	rich := make(map[int]string)
+	part := ""
+	for _, rel := range partRelationships(zipReader, "xl/workbook.xml") {
+		if rel.Type == relTypeSharedStrings {
+			part = rel.Target
+		}
+	}
+	file, err := findFileInZip(zipReader, part)
+	if err != nil {
+		return rich, nil
+	}
+	rc, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	decoder := xml.NewDecoder(rc)
+	for index := 0; ; {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return rich, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "si" {
+			continue
+		}
+		var item RichText
+		if err := decoder.DecodeElement(&item, &start); err != nil {
+			return nil, err
+		}
+		if text := item.markdown(); text != "" {
+			rich[index] = text
+		}
+		index++
+	}
+}
+
+// hyperlinkTarget returns the markdown link target of a cell hyperlink.
+// Locations such as 'Q1 Sales'!A1 become anchors to the sheet heading, other
+// locations like defined names have no anchor and yield an empty target.
+func hyperlinkTarget(target string) string {
+	if strings.Contains(target, "://") || strings.HasPrefix(target, "mailto:") {
+		return target
+	}
+
+	sheet, _, found := strings.Cut(strings.TrimPrefix(target, "#"), "!")
+	if !found {
+		return ""
+	}
+	if unquoted, ok := strings.CutPrefix(sheet, "'"); ok {
+		sheet = strings.ReplaceAll(strings.TrimSuffix(unquoted, "'"), "''", "'")
+	}
+	return "#" + utils.Slug(sheet)
+}
+
+// annotateCells adds footnote references to commented cells and collects the
+// comments as footnotes. The counter numbers footnotes across sheets.
+func annotateCells(f *excelize.File, sheet *excelSheet, counter *int) error {
+	comments, err := f.GetComments(sheet.Name)
+	if err != nil {
+		return err
+	}
+
+	for _, comment := range comments {
+		text := comment.Text
+		for _, run := range comment.Paragraph {
+			text += run.Text
+		}
+		text = strings.Join(strings.Fields(text), " ")
+		if text == "" {
+			continue
+		}
+
+		*counter++
+		label := fmt.Sprintf("[^%d]", *counter)
+		if err := sheet.Marks.mark(comment.Cell, func(m *cellMark) { m.Footnote += label }); err != nil {
+			return err
+		}
+
+		note := fmt.Sprintf("%s: %s", comment.Cell, text)
+		if comment.Author != "" {
+			note = fmt.Sprintf("%s (%s): %s", comment.Cell, comment.Author, text)
+		}
+		sheet.Footnotes = append(sheet.Footnotes, fmt.Sprintf("%s: %s", label, note))
+	}
+	return nil
+}
+
+// numberFormats classifies cell number formats, caching the result per style.
+type numberFormats struct {
+	file     *excelize.File
+	date1904 bool
+	dates    map[int]bool
+}
+
+func newNumberFormats(f *excelize.File) *numberFormats {
+	formats := &numberFormats{file: f, dates: make(map[int]bool)}
+	if props, err := f.GetWorkbookProps(); err == nil && props.Date1904 != nil {
+		formats.date1904 = *props.Date1904
+	}
+	return formats
+}
+
+// isDate reports whether the cell has a date or time number format.
+func (n *numberFormats) isDate(sheet, cell string) bool {
+	styleID, err := n.file.GetCellStyle(sheet, cell)
+	if err != nil {
+		return false
+	}
+	if date, ok := n.dates[styleID]; ok {
+		return date
+	}
+
+	var date bool
+	if style, err := n.file.GetStyle(styleID); err == nil {
+		if style.CustomNumFmt != nil {
+			date = isDateFormatCode(*style.CustomNumFmt)
+		} else {
+			date = isBuiltInDateFormat(style.NumFmt)
+		}
+	}
+	n.dates[styleID] = date
+	return date
+}
+
+// isoValue returns the ISO 8601 form of a date or time cell, and the value
+// unchanged for every other cell.
+func (n *numberFormats) isoValue(sheet, cell, value string) (string, error) {
+	if value == "" || !n.isDate(sheet, cell) {
+		return value, nil
+	}
+
+	raw, err := n.file.GetCellValue(sheet, cell, excelize.Options{RawCellValue: true})
+	if err != nil {
+		return "", fmt.Errorf("unable to read cell %s: %w", cell, err)
+	}
+	if serial, err := strconv.ParseFloat(raw, 64); err == nil {
+		return isoDate(serial, n.date1904), nil
+	}
+	return value, nil
+}
+
+// isoDate formats a date serial number as an ISO 8601 date, time or both.
+func isoDate(serial float64, date1904 bool) string {
+	t, err := excelize.ExcelDateToTime(serial, date1904)
+	if err != nil {
+		return strconv.FormatFloat(serial, 'f', -1, 64)
+	}
+	t = t.Round(time.Second)
+
+	switch {
+	case serial < 1:
+		return t.Format(time.TimeOnly)
+	case t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0:
+		return t.Format(time.DateOnly)
+	default:
+		return t.Format("2006-01-02T15:04:05")
+	}
+}
+
+// isBuiltInDateFormat reports whether a built-in number format ID is a date or
+// time format, including the East Asian date formats.
+func isBuiltInDateFormat(id int) bool {
+	return (id >= 14 && id <= 22) || (id >= 27 && id <= 36) || (id >= 45 && id <= 47) || (id >= 50 && id <= 58)
+}
+
+// isDateFormatCode reports whether a custom number format code formats dates
+// or times. Quoted literals, escaped characters and bracketed sections such as
+// colors and locales are ignored, except elapsed time like [h].
+func isDateFormatCode(code string) bool {
+	for i := 0; i < len(code); i++ {
+		switch c := code[i]; c {
+		case '"':
+			if end := strings.IndexByte(code[i+1:], '"'); end >= 0 {
+				i += end + 1
+			} else {
+				return false
+			}
+		case '\\', '_', '*':
+			i++
+		case '[':
+			end := strings.IndexByte(code[i:], ']')
+			if end < 0 {
+				return false
+			}
+			section := strings.ToLower(code[i+1 : i+end])
+			if section != "" && strings.Trim(section, "hms") == "" {
+				return true
+			}
+			i += end
+		case 'y', 'Y', 'd', 'D', 'h', 'H', 's', 'S', 'm', 'M':
+			return true
+		case ';':
+			// Only the first section decides how positive values render.
+			return false
+		}
+	}
+	return false
+}
+
+// selectSheets resolves a selection of sheet names or 1-based indexes against
+// the workbook sheets, preserving workbook order.
+func selectSheets(sheets, selection []string) ([]string, error) {
+	if len(selection) == 0 {
+		return sheets, nil
+	}
+
+	selected := make(map[string]bool, len(selection))
+	for _, entry := range selection {
+		entry = strings.TrimSpace(entry)
+		if slices.Contains(sheets, entry) {
+			selected[entry] = true
+			continue
+		}
+		index, err := strconv.Atoi(entry)
+		if err != nil || index < 1 || index > len(sheets) {
+			return nil, fmt.Errorf("sheet %q not found", entry)
+		}
+		selected[sheets[index-1]] = true
+	}
+
+	names := make([]string, 0, len(selected))
+	for _, name := range sheets {
+		if selected[name] {
+			names = append(names, name)
+		}
+	}
+	return names, nil
 }
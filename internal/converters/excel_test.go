@@ -1,6 +1,8 @@
 package converters
 
 import (
+	"context"
+	"fmt"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -13,11 +15,12 @@ import (
 func TestNewExcelConverter(t *testing.T) {
 	converter := NewExcelConverter()
 
-	expectedExtensions := []string{".xlsx", ".xls"}
+	expectedExtensions := []string{".xlsx", ".xls", ".xlsb"}
 	expectedMimeTypes := []string{
 		"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
 		"application/vnd.openxmlformats-officedocument.spreadsheetml",
 		"application/vnd.ms-excel",
+		"application/vnd.ms-excel.sheet.binary.macroenabled.12",
 	}
 
 	if !reflect.DeepEqual(converter.AcceptedExtensions(), expectedExtensions) {
@@ -63,7 +66,7 @@ func TestExcelConverter_Load_ValidFile(t *testing.T) {
 		t.Errorf("Load() returned unexpected error: %v", err)
 	}
 
-	expected := "| Name | Age | City |\n| --- | --- | --- |\n| John | 30 | New York |\n| Jane | 25 | Los Angeles |\n"
+	expected := "## Sheet1\n\n| Name | Age | City |\n| --- | --- | --- |\n| John | 30 | New York |\n| Jane | 25 | Los Angeles |\n"
 	if result != expected {
 		t.Errorf("Load() = %v, want %v", result, expected)
 	}
@@ -118,7 +121,7 @@ func TestExcelConverter_Load_OnlyHeader(t *testing.T) {
 		t.Errorf("Load() returned unexpected error: %v", err)
 	}
 
-	expected := "| Name | Age | City |\n| --- | --- | --- |\n"
+	expected := "## Sheet1\n\n| Name | Age | City |\n| --- | --- | --- |\n"
 	if result != expected {
 		t.Errorf("Load() = %v, want %v", result, expected)
 	}
@@ -269,7 +272,7 @@ func TestExcelConverter_Load_InvalidFile(t *testing.T) {
 	}
 }
 
-func TestReadExcelFile_ValidFile(t *testing.T) {
+func TestWriteExcelFile_ValidFile(t *testing.T) {
 	// Create a temporary Excel file
 	tempDir := t.TempDir()
 	excelFile := filepath.Join(tempDir, "test.xlsx")
@@ -288,34 +291,32 @@ func TestReadExcelFile_ValidFile(t *testing.T) {
 		t.Fatalf("Failed to create test Excel file: %v", err)
 	}
 
-	rows, err := readExcelFile(excelFile)
+	var markdown strings.Builder
+	err = (&ExcelConverter{}).writeExcelFile(context.Background(), excelFile, &markdown, nil)
 	if err != nil {
-		t.Errorf("readExcelFile() returned unexpected error: %v", err)
+		t.Errorf("writeExcelFile() returned unexpected error: %v", err)
 	}
 
-	expectedRows := [][]string{
-		{"Name", "Age"},
-		{"John", "30"},
-	}
-
-	if !reflect.DeepEqual(rows, expectedRows) {
-		t.Errorf("readExcelFile() = %v, want %v", rows, expectedRows)
+	expected := "## Sheet1\n\n| Name | Age |\n| --- | --- |\n| John | 30 |\n"
+	if markdown.String() != expected {
+		t.Errorf("writeExcelFile() = %q, want %q", markdown.String(), expected)
 	}
 }
 
-func TestReadExcelFile_NonExistentFile(t *testing.T) {
-	_, err := readExcelFile("/nonexistent/file.xlsx")
+func TestWriteExcelFile_NonExistentFile(t *testing.T) {
+	var markdown strings.Builder
+	err := (&ExcelConverter{}).writeExcelFile(context.Background(), "/nonexistent/file.xlsx", &markdown, nil)
 
 	if err == nil {
-		t.Errorf("readExcelFile() should return error for non-existent file")
+		t.Errorf("writeExcelFile() should return error for non-existent file")
 	}
 
 	if !strings.Contains(err.Error(), "unable to open Excel file") {
-		t.Errorf("readExcelFile() error should mention Excel file opening failure")
+		t.Errorf("writeExcelFile() error should mention Excel file opening failure")
 	}
 }
 
-func TestReadExcelFile_EmptyWorkbook(t *testing.T) {
+func TestWriteExcelFile_EmptyWorkbook(t *testing.T) {
 	// Create an Excel file with no sheets (though this is unlikely in practice)
 	// We'll test with a regular empty sheet instead
 	tempDir := t.TempDir()
@@ -329,13 +330,544 @@ func TestReadExcelFile_EmptyWorkbook(t *testing.T) {
 		t.Fatalf("Failed to create test Excel file: %v", err)
 	}
 
-	rows, err := readExcelFile(excelFile)
+	var markdown strings.Builder
+	err = (&ExcelConverter{}).writeExcelFile(context.Background(), excelFile, &markdown, nil)
+	if err != nil {
+		t.Errorf("writeExcelFile() returned unexpected error: %v", err)
+	}
+
+	// Empty sheet should write nothing
+	if markdown.Len() != 0 {
+		t.Errorf("writeExcelFile() with empty sheet should write nothing, got %q", markdown.String())
+	}
+}
+
+func TestExcelConverter_Load_AllSheets(t *testing.T) {
+	tempDir := t.TempDir()
+	excelFile := filepath.Join(tempDir, "sheets.xlsx")
+
+	f := excelize.NewFile()
+	defer f.Close()
+
+	f.SetCellValue("Sheet1", "A1", "Region")
+	f.SetCellValue("Sheet1", "A2", "North")
+	f.NewSheet("Empty")
+	f.NewSheet("Totals")
+	f.SetCellValue("Totals", "A1", "Sum")
+	f.SetCellValue("Totals", "A2", 42)
+
+	if err := f.SaveAs(excelFile); err != nil {
+		t.Fatalf("Failed to create test Excel file: %v", err)
+	}
+
+	result, err := NewExcelConverter().Load(excelFile)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+
+	expected := "## Sheet1\n\n| Region |\n| --- |\n| North |\n\n## Totals\n\n| Sum |\n| --- |\n| 42 |\n"
+	if result != expected {
+		t.Errorf("Load() = %q, want %q", result, expected)
+	}
+
+	converter := &ExcelConverter{Sheets: []string{"3"}}
+	result, err = converter.Load(excelFile)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	if result != "## Totals\n\n| Sum |\n| --- |\n| 42 |\n" {
+		t.Errorf("Load() with sheet index selection = %q", result)
+	}
+
+	converter.Sheets = []string{"Totals", "Sheet1"}
+	result, err = converter.Load(excelFile)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	if result != expected {
+		t.Errorf("Load() with sheet name selection = %q, want %q", result, expected)
+	}
+
+	converter.Sheets = []string{"Missing"}
+	if _, err := converter.Load(excelFile); err == nil || !strings.Contains(err.Error(), `sheet "Missing" not found`) {
+		t.Errorf("Load() with unknown sheet should return error, got %v", err)
+	}
+}
+
+func TestExcelConverter_Load_NumberFormats(t *testing.T) {
+	tempDir := t.TempDir()
+	excelFile := filepath.Join(tempDir, "formats.xlsx")
+
+	f := excelize.NewFile()
+	defer f.Close()
+
+	dateTime := "dd/mm/yyyy hh:mm"
+	styles := []*excelize.Style{{NumFmt: 14}, {CustomNumFmt: &dateTime}, {NumFmt: 4}, {NumFmt: 10}}
+	values := []any{45356, 45356.75, 1234.5678, 0.25}
+	for i, value := range values {
+		cell, _ := excelize.CoordinatesToCellName(i+1, 2)
+		style, err := f.NewStyle(styles[i])
+		if err != nil {
+			t.Fatalf("Failed to create style: %v", err)
+		}
+		f.SetCellValue("Sheet1", cell, value)
+		f.SetCellStyle("Sheet1", cell, cell, style)
+	}
+	f.SetSheetRow("Sheet1", "A1", &[]string{"Date", "Timestamp", "Amount", "Share"})
+
+	if err := f.SaveAs(excelFile); err != nil {
+		t.Fatalf("Failed to create test Excel file: %v", err)
+	}
+
+	tests := []struct {
+		mode ValueMode
+		want string
+	}{
+		{ValuesFormatted, "| 03-05-24 | 05/03/2024 18:00 | 1,234.57 | 25.00% |"},
+		{ValuesISODates, "| 2024-03-05 | 2024-03-05T18:00:00 | 1,234.57 | 25.00% |"},
+		{ValuesRaw, "| 45356 | 45356.75 | 1234.5678 | 0.25 |"},
+	}
+	for _, tt := range tests {
+		converter := &ExcelConverter{Values: tt.mode}
+		result, err := converter.Load(excelFile)
+		if err != nil {
+			t.Fatalf("Load() returned unexpected error: %v", err)
+		}
+		if !strings.Contains(result, tt.want) {
+			t.Errorf("Load() with value mode %d = %q, want row %q", tt.mode, result, tt.want)
+		}
+	}
+}
+
+func TestIsDateFormatCode(t *testing.T) {
+	tests := map[string]bool{
+		"yyyy-mm-dd":           true,
+		"[h]:mm:ss":            true,
+		"[$-409]d-mmm;@":       true,
+		"#,##0.00":             false,
+		`0.0 "days"`:           false,
+		"[Red]0.00;[Blue]-0.0": false,
+		`\d0`:                  false,
+		"@":                    false,
+	}
+	for code, want := range tests {
+		if got := isDateFormatCode(code); got != want {
+			t.Errorf("isDateFormatCode(%q) = %v, want %v", code, got, want)
+		}
+	}
+}
+
+func TestExcelConverter_Load_HyperlinksAndComments(t *testing.T) {
+	tempDir := t.TempDir()
+	excelFile := filepath.Join(tempDir, "links.xlsx")
+
+	f := excelize.NewFile()
+	defer f.Close()
+
+	f.SetSheetRow("Sheet1", "A1", &[]string{"Name", "Source"})
+	f.SetSheetRow("Sheet1", "A2", &[]string{"Widget", "Catalog"})
+	f.SetCellHyperLink("Sheet1", "B2", "https://example.com/catalog", "External")
+	f.SetCellValue("Sheet1", "B3", "Totals")
+	f.SetCellHyperLink("Sheet1", "B3", "'Q1 Totals'!A1", "Location")
+	f.NewSheet("Q1 Totals")
+	f.SetCellValue("Q1 Totals", "A1", "Sum")
+	if err := f.AddComment("Sheet1", excelize.Comment{Cell: "A2", Author: "Ann", Text: "Discontinued\nin March"}); err != nil {
+		t.Fatalf("Failed to add comment: %v", err)
+	}
+
+	if err := f.SaveAs(excelFile); err != nil {
+		t.Fatalf("Failed to create test Excel file: %v", err)
+	}
+
+	result, err := NewExcelConverter().Load(excelFile)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	expected := "## Sheet1\n\n| Name | Source |\n| --- | --- |\n" +
+		"| Widget | [Catalog](https://example.com/catalog) |\n|  | [Totals](#q1-totals) |\n\n" +
+		"## Q1 Totals\n\n| Sum |\n| --- |\n"
+	if result != expected {
+		t.Errorf("Load() = %q, want %q", result, expected)
+	}
+
+	converter := &ExcelConverter{Comments: true}
+	result, err = converter.Load(excelFile)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	if !strings.Contains(result, "| Widget[^1] |") || !strings.Contains(result, "\n[^1]: A2 (Ann): Discontinued in March\n") {
+		t.Errorf("Load() with comments = %q", result)
+	}
+}
+
+func TestExcelConverter_Load_FormulaModes(t *testing.T) {
+	tempDir := t.TempDir()
+	excelFile := filepath.Join(tempDir, "formulas.xlsx")
+
+	f := excelize.NewFile()
+	defer f.Close()
+
+	f.SetSheetRow("Sheet1", "A1", &[]string{"A", "B", "Sum"})
+	f.SetSheetRow("Sheet1", "A2", &[]any{1, 2, 3})
+	f.SetCellFormula("Sheet1", "C2", "SUM(A2:B2)")
+	f.SetSheetRow("Sheet1", "A3", &[]any{4, 5})
+	f.SetCellFormula("Sheet1", "C3", "=A3+B3")
+
+	if err := f.SaveAs(excelFile); err != nil {
+		t.Fatalf("Failed to create test Excel file: %v", err)
+	}
+
+	tests := []struct {
+		mode FormulaMode
+		want string
+	}{
+		{FormulasValue, "| 1 | 2 | 3 |\n| 4 | 5 |  |\n"},
+		{FormulasText, "| 1 | 2 | =SUM(A2:B2) |\n| 4 | 5 | =A3+B3 |\n"},
+		{FormulasBoth, "| 1 | 2 | 3 (=SUM(A2:B2)) |\n| 4 | 5 | =A3+B3 |\n"},
+	}
+	for _, tt := range tests {
+		converter := &ExcelConverter{Formulas: tt.mode}
+		result, err := converter.Load(excelFile)
+		if err != nil {
+			t.Fatalf("Load() returned unexpected error: %v", err)
+		}
+		if !strings.HasSuffix(result, tt.want) {
+			t.Errorf("Load() with formula mode %d = %q, want rows %q", tt.mode, result, tt.want)
+		}
+	}
+}
+
+func TestExcelConverter_Load_MaxRowsAndBlankRows(t *testing.T) {
+	tempDir := t.TempDir()
+	excelFile := filepath.Join(tempDir, "large.xlsx")
+
+	f := excelize.NewFile()
+	defer f.Close()
+
+	// The table starts below a blank first row and has a blank row inside it.
+	f.SetCellValue("Sheet1", "A2", "N")
+	for i := 1; i <= 5; i++ {
+		if i != 2 {
+			f.SetCellValue("Sheet1", fmt.Sprintf("A%d", i+2), i)
+		}
+	}
+
+	if err := f.SaveAs(excelFile); err != nil {
+		t.Fatalf("Failed to create test Excel file: %v", err)
+	}
+
+	result, err := NewExcelConverter().Load(excelFile)
 	if err != nil {
-		t.Errorf("readExcelFile() returned unexpected error: %v", err)
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	expected := "## Sheet1\n\n| N |\n| --- |\n| 1 |\n|  |\n| 3 |\n| 4 |\n| 5 |\n"
+	if result != expected {
+		t.Errorf("Load() = %q, want %q", result, expected)
 	}
 
-	// Empty sheet should return empty rows
-	if len(rows) != 0 {
-		t.Errorf("readExcelFile() with empty sheet should return empty rows, got %d rows", len(rows))
+	converter := &ExcelConverter{MaxRows: 3}
+	result, err = converter.Load(excelFile)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	expected = "## Sheet1\n\n| N |\n| --- |\n| 1 |\n|  |\n| 3 |\n\n_Showing the first 3 of 5 rows._\n"
+	if result != expected {
+		t.Errorf("Load() with MaxRows = %q, want %q", result, expected)
+	}
+}
+
+func TestExcelConverter_Load_AlignColumns(t *testing.T) {
+	excelFile := filepath.Join(t.TempDir(), "ledger.xlsx")
+
+	f := excelize.NewFile()
+	defer f.Close()
+	rows := [][]any{
+		{"Date", "Account", "Amount"},
+		{"2024-01-01", "Rent", "$1,200.00"},
+		{"2024-01-15", "Refund", "(35.50)"},
+	}
+	for i, row := range rows {
+		if err := f.SetSheetRow("Sheet1", fmt.Sprintf("A%d", i+1), &row); err != nil {
+			t.Fatalf("Failed to set row: %v", err)
+		}
+	}
+	if err := f.SaveAs(excelFile); err != nil {
+		t.Fatalf("Failed to create test Excel file: %v", err)
+	}
+
+	converter := &ExcelConverter{AlignColumns: true, MaxRows: 1}
+	result, err := converter.Load(excelFile)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	expected := "## Sheet1\n\n| Date | Account | Amount |\n| :---: | --- | ---: |\n| 2024-01-01 | Rent | $1,200.00 |\n" +
+		"\n_Showing the first 1 of 2 rows._\n"
+	if result != expected {
+		t.Errorf("Load() with AlignColumns = %q, want %q", result, expected)
+	}
+}
+
+func TestExcelConverter_Load_MaxCellWidth(t *testing.T) {
+	excelFile := filepath.Join(t.TempDir(), "notes.xlsx")
+
+	f := excelize.NewFile()
+	defer f.Close()
+	f.SetCellValue("Sheet1", "A1", "Item")
+	f.SetCellValue("Sheet1", "A2", "A product with a long name")
+	f.SetCellHyperLink("Sheet1", "A2", "https://example.com", "External")
+	f.AddComment("Sheet1", excelize.Comment{Cell: "A2", Author: "Ann", Paragraph: []excelize.RichTextRun{{Text: "Check"}}})
+	if err := f.SaveAs(excelFile); err != nil {
+		t.Fatalf("Failed to create test Excel file: %v", err)
+	}
+
+	converter := &ExcelConverter{MaxCellWidth: 10, CellFootnotes: true, Comments: true}
+	result, err := converter.Load(excelFile)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	expected := "## Sheet1\n\n| Item |\n| --- |\n| [A product…[^2]](https://example.com)[^1] |\n" +
+		"\n[^1]: A2 (Ann): Check\n[^2]: A product with a long name\n"
+	if result != expected {
+		t.Errorf("Load() with MaxCellWidth = %q, want %q", result, expected)
+	}
+}
+
+func TestExcelConverter_Load_ChartAndPivotSummaries(t *testing.T) {
+	tempDir := t.TempDir()
+	excelFile := filepath.Join(tempDir, "analysis.xlsx")
+
+	f := excelize.NewFile()
+	defer f.Close()
+
+	f.SetSheetRow("Sheet1", "A1", &[]any{"Region", "Q1", "Q2"})
+	f.SetSheetRow("Sheet1", "A2", &[]any{"North", 10, 20})
+	f.SetSheetRow("Sheet1", "A3", &[]any{"South", 30, 40})
+	err := f.AddChart("Sheet1", "E1", &excelize.Chart{
+		Type:   excelize.Col,
+		Series: []excelize.ChartSeries{{Name: "Sheet1!$B$1", Categories: "Sheet1!$A$2:$A$3", Values: "Sheet1!$B$2:$B$3"}},
+		Title:  []excelize.RichTextRun{{Text: "Quarterly"}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to add chart: %v", err)
+	}
+	f.NewSheet("Pivot")
+	err = f.AddPivotTable(&excelize.PivotTableOptions{
+		DataRange:       "Sheet1!A1:C3",
+		PivotTableRange: "Pivot!A3:C10",
+		Rows:            []excelize.PivotTableField{{Data: "Region"}},
+		Data:            []excelize.PivotTableField{{Data: "Q1", Subtotal: "Sum", Name: "Total Q1"}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to add pivot table: %v", err)
+	}
+
+	if err := f.SaveAs(excelFile); err != nil {
+		t.Fatalf("Failed to create test Excel file: %v", err)
+	}
+
+	result, err := NewExcelConverter().Load(excelFile)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+
+	expected := "## Sheet1\n\n| Region | Q1 | Q2 |\n| --- | --- | --- |\n| North | 10 | 20 |\n| South | 30 | 40 |\n\n" +
+		"### Chart: Quarterly\n\n| Series | Type | Categories | Values |\n| --- | --- | --- | --- |\n" +
+		"| Sheet1!$B$1 | bar | Sheet1!$A$2:$A$3 | Sheet1!$B$2:$B$3 |\n\n" +
+		"## Pivot\n\n### Pivot table: PivotTable1\n\n- Location: A3:C10\n- Source: Sheet1!A1:C3\n" +
+		"- Rows: Region\n- Values: Total Q1 (sum of Q1)\n"
+	if result != expected {
+		t.Errorf("Load() = %q, want %q", result, expected)
+	}
+}
+
+func TestExcelConverter_Load_HiddenContent(t *testing.T) {
+	tempDir := t.TempDir()
+	excelFile := filepath.Join(tempDir, "hidden.xlsx")
+
+	f := excelize.NewFile()
+	defer f.Close()
+
+	f.SetSheetRow("Sheet1", "A1", &[]string{"Name", "Cost", "Price"})
+	f.SetSheetRow("Sheet1", "A2", &[]string{"Widget", "3", "5"})
+	f.SetSheetRow("Sheet1", "A3", &[]string{"Draft", "1", "2"})
+	f.SetSheetRow("Sheet1", "A4", &[]string{"Gadget", "7", "9"})
+	f.SetColVisible("Sheet1", "B", false)
+	f.SetRowVisible("Sheet1", 3, false)
+	f.NewSheet("Scratch")
+	f.SetCellValue("Scratch", "A1", "Working")
+	f.SetSheetVisible("Scratch", false)
+
+	if err := f.SaveAs(excelFile); err != nil {
+		t.Fatalf("Failed to create test Excel file: %v", err)
+	}
+
+	result, err := NewExcelConverter().Load(excelFile)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	if !strings.Contains(result, "| Draft | 1 | 2 |") || !strings.Contains(result, "## Scratch") {
+		t.Errorf("Load() should convert hidden content by default, got %q", result)
+	}
+
+	converter := &ExcelConverter{SkipHiddenSheets: true, SkipHiddenRows: true, SkipHiddenColumns: true}
+	result, err = converter.Load(excelFile)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	expected := "## Sheet1\n\n| Name | Price |\n| --- | --- |\n| Widget | 5 |\n| Gadget | 9 |\n"
+	if result != expected {
+		t.Errorf("Load() skipping hidden content = %q, want %q", result, expected)
+	}
+}
+
+func TestExcelConverter_Load_RichTextAndLineBreaks(t *testing.T) {
+	tempDir := t.TempDir()
+	excelFile := filepath.Join(tempDir, "rich.xlsx")
+
+	f := excelize.NewFile()
+	defer f.Close()
+
+	f.SetSheetRow("Sheet1", "A1", &[]string{"Task", "Notes"})
+	f.SetCellValue("Sheet1", "A2", "Ship")
+	f.SetCellValue("Sheet1", "B2", "Line one\nLine two")
+	f.SetCellValue("Sheet1", "A3", "Review")
+	err := f.SetCellRichText("Sheet1", "B3", []excelize.RichTextRun{
+		{Text: "Due ", Font: &excelize.Font{Bold: true}},
+		{Text: "Friday", Font: &excelize.Font{Bold: true, Italic: true}},
+		{Text: ", see "},
+		{Text: "notes", Font: &excelize.Font{Italic: true}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to set rich text: %v", err)
+	}
+	f.SetCellRichText("Sheet1", "B4", []excelize.RichTextRun{{Text: "plain"}, {Text: " runs"}})
+
+	if err := f.SaveAs(excelFile); err != nil {
+		t.Fatalf("Failed to create test Excel file: %v", err)
+	}
+
+	result, err := NewExcelConverter().Load(excelFile)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+
+	expected := "## Sheet1\n\n| Task | Notes |\n| --- | --- |\n| Ship | Line one<br>Line two |\n" +
+		"| Review | **Due** ***Friday***, see *notes* |\n|  | plain runs |\n"
+	if result != expected {
+		t.Errorf("Load() = %q, want %q", result, expected)
+	}
+}
+
+func TestExcelConverter_Load_Provenance(t *testing.T) {
+	excelFile := filepath.Join(t.TempDir(), "test.xlsx")
+	f := excelize.NewFile()
+	defer f.Close()
+	f.SetSheetName("Sheet1", "Q1 Sales")
+	f.SetCellValue("Q1 Sales", "A1", "Region")
+	f.SetCellValue("Q1 Sales", "B1", "Total")
+	f.SetCellValue("Q1 Sales", "A2", "North")
+	f.SetCellValue("Q1 Sales", "B2", 10)
+	if err := f.SaveAs(excelFile); err != nil {
+		t.Fatalf("Failed to create test Excel file: %v", err)
+	}
+
+	converter := &ExcelConverter{Provenance: true}
+	result, err := converter.Load(excelFile)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+
+	expected := "## Q1 Sales\n\n<!-- source: sheet 'Q1 Sales'!A1:B2 -->\n\n| Region | Total |\n| --- | --- |\n| North | 10 |\n"
+	if result != expected {
+		t.Errorf("Load() = %q, want %q", result, expected)
+	}
+}
+
+func TestSheetLocation(t *testing.T) {
+	tests := []struct{ name, dimension, expected string }{
+		{"Sheet1", "A1:C3", "sheet Sheet1!A1:C3"},
+		{"Bob's data", "B2", "sheet 'Bob''s data'!B2"},
+		{"Données", "", "sheet Données"},
+	}
+	for _, tt := range tests {
+		if got := sheetLocation(tt.name, tt.dimension); got != tt.expected {
+			t.Errorf("sheetLocation(%q, %q) = %q, want %q", tt.name, tt.dimension, got, tt.expected)
+		}
+	}
+}
+
+func TestExcelConverter_Load_Marker(t *testing.T) {
+	excelFile := filepath.Join(t.TempDir(), "test.xlsx")
+	f := excelize.NewFile()
+	defer f.Close()
+	f.NewSheet("Costs")
+	f.SetCellValue("Sheet1", "A1", "Revenue")
+	f.SetCellValue("Costs", "A1", "Cost")
+	if err := f.SaveAs(excelFile); err != nil {
+		t.Fatalf("Failed to create test Excel file: %v", err)
+	}
+
+	converter := &ExcelConverter{Marker: "<!-- Sheet {n}: {name} -->", Sheets: []string{"2"}}
+	result, err := converter.Load(excelFile)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+
+	expected := "<!-- Sheet 2: Costs -->\n\n| Cost |\n| --- |\n"
+	if result != expected {
+		t.Errorf("Load() = %q, want %q", result, expected)
+	}
+}
+
+func TestExcelConverter_LoadReport(t *testing.T) {
+	tempDir := t.TempDir()
+	excelFile := filepath.Join(tempDir, "report.xlsx")
+
+	f := excelize.NewFile()
+	defer f.Close()
+
+	for row := 1; row <= 4; row++ {
+		cell, _ := excelize.CoordinatesToCellName(1, row)
+		f.SetCellValue("Sheet1", cell, row)
+	}
+	f.NewSheet("Hidden")
+	f.SetCellValue("Hidden", "A1", "secret")
+	f.SetSheetVisible("Hidden", false)
+
+	if err := f.SaveAs(excelFile); err != nil {
+		t.Fatalf("Failed to create test Excel file: %v", err)
+	}
+
+	converter := &ExcelConverter{MaxRows: 2, SkipHiddenSheets: true}
+	_, report, err := converter.LoadReport(context.Background(), excelFile)
+	if err != nil {
+		t.Fatalf("LoadReport() returned unexpected error: %v", err)
+	}
+	if report.Parts != 2 {
+		t.Errorf("LoadReport() parts = %d, want 2", report.Parts)
+	}
+	want := []string{"sheet Sheet1: 1 of 3 rows left out", "hidden sheet Hidden left out"}
+	if !reflect.DeepEqual(report.Warnings, want) {
+		t.Errorf("LoadReport() warnings = %q, want %q", report.Warnings, want)
+	}
+}
+
+func TestExcelConverter_Load_Partial(t *testing.T) {
+	path := writeCorruptFile(t, filepath.Join("..", "..", "test_files", "test.xlsx"), "xl/worksheets/sheet1.xml")
+
+	if _, err := (&ExcelConverter{}).Load(path); err == nil {
+		t.Fatal("Load() of a corrupt sheet returned no error")
+	}
+
+	converter := &ExcelConverter{Partial: true}
+	markdown, report, err := converter.LoadReport(context.Background(), path)
+	if err != nil {
+		t.Fatalf("LoadReport() returned unexpected error: %v", err)
+	}
+	if !strings.Contains(markdown, "## 09060124-b5e7-4717-9d07-3c046eb") {
+		t.Errorf("LoadReport() = %q, want it to contain the second sheet", markdown)
+	}
+	if len(report.Warnings) != 1 || !strings.HasPrefix(report.Warnings[0], "sheet ") {
+		t.Errorf("LoadReport() warnings = %q, want one for the corrupt sheet", report.Warnings)
 	}
 }
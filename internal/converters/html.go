@@ -1,17 +1,67 @@
 package converters
 
 import (
+	"cmp"
+	"context"
+	"encoding/base64"
 	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
 	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
-	html2md "github.com/JohannesKaufmann/html-to-markdown/v2"
+	"github.com/JohannesKaufmann/html-to-markdown/v2/converter"
+	"github.com/JohannesKaufmann/html-to-markdown/v2/plugin/base"
+	"github.com/JohannesKaufmann/html-to-markdown/v2/plugin/commonmark"
+	"github.com/flaviodelgrosso/marky/internal/mimetypes"
+	"golang.org/x/net/html"
 )
 
 // HTMLConverter handles loading and converting HTML files to markdown.
+// Pages are also fetched from http and https URLs, with relative links
+// resolved against the page URL.
 type HTMLConverter struct {
 	BaseConverter
+
+	// Options configures the conversion: the image policy and directory.
+	Options ConvertOptions
+
+	// Client fetches remote pages and images. A client with a 30 second
+	// timeout is used when nil.
+	Client *http.Client
+
+	// SkipMetadata leaves out the front matter with the page title,
+	// description, canonical URL and Open Graph properties.
+	SkipMetadata bool
+
+	// Plugins are html-to-markdown plugins registered after the base and
+	// CommonMark plugins, such as the table or strikethrough plugins.
+	Plugins []converter.Plugin
+
+	// Rules render elements by tag name in place of the plugins, such as
+	// custom handling for <figure>. A rule returning RenderTryNext falls
+	// back to the default rendering.
+	Rules map[string]converter.HandleRenderFunc
+
+	// KeepTags lists the elements kept as raw HTML, such as "table".
+	KeepTags []string
+
+	// RemoveSelectors lists the elements removed before the conversion, as
+	// a tag name with optional classes and id: "nav", "div.ad" or
+	// "#comments".
+	RemoveSelectors []string
 }
 
+// maxResourceSize bounds the size of fetched pages and images, and of images
+// read from archives.
+const maxResourceSize = 32 << 20
+
 // NewHTMLConverter creates a new HTML converter with appropriate MIME types and extensions.
 func NewHTMLConverter() Converter {
 	return &HTMLConverter{
@@ -22,17 +72,427 @@ func NewHTMLConverter() Converter {
 	}
 }
 
-// Load reads an HTML file and converts it to markdown.
-func (*HTMLConverter) Load(path string) (string, error) {
-	input, err := os.ReadFile(path)
+// IsURL reports whether a path is an http or https URL rather than a file.
+func IsURL(path string) bool {
+	u, err := url.Parse(path)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// Capabilities returns what the converter can extract from HTML files and web pages.
+func (c *HTMLConverter) Capabilities() Capabilities {
+	return Capabilities{Images: true, Tables: true, Metadata: true}
+}
+
+// Load reads an HTML file, or fetches a page when given a URL, and converts
+// it to markdown, preceded by a front matter with the page metadata.
+func (c *HTMLConverter) Load(path string) (string, error) {
+	return c.LoadContext(context.Background(), path)
+}
+
+// LoadContext converts an HTML file or web page like Load, cancelling the
+// requests of the page and its images once ctx is done.
+func (c *HTMLConverter) LoadContext(ctx context.Context, path string) (string, error) {
+	var input []byte
+	var base *url.URL
+	var err error
+	if IsURL(path) {
+		var final string
+		if input, final, err = c.fetch(ctx, path, "text/html", "application/xhtml+xml"); err != nil {
+			return "", fmt.Errorf("failed to fetch HTML page: %w", err)
+		}
+		base, _ = url.Parse(final)
+	} else {
+		if input, err = os.ReadFile(path); err != nil {
+			return "", fmt.Errorf("failed to read HTML file: %w", err)
+		}
+	}
+	return c.convert(ctx, input, path, base)
+}
+
+// LoadBytes converts an HTML page held in memory like Load. Its relative
+// image links are kept, having no file or URL to be resolved against.
+func (c *HTMLConverter) LoadBytes(ctx context.Context, data []byte) (string, error) {
+	return c.convert(ctx, data, "", nil)
+}
+
+// convert converts the HTML of a page read from a file, or fetched from the
+// base URL when it is not nil.
+func (c *HTMLConverter) convert(ctx context.Context, input []byte, path string, base *url.URL) (string, error) {
+	selectors, err := parseSelectors(c.RemoveSelectors)
+	if err != nil {
+		return "", err
+	}
+
+	doc, err := html.Parse(strings.NewReader(string(input)))
 	if err != nil {
-		return "", fmt.Errorf("failed to read HTML file: %w", err)
+		return "", fmt.Errorf("failed to parse HTML: %w", err)
+	}
+	if base != nil {
+		base = documentBase(doc, base)
+	}
+	var frontMatter string
+	if !c.SkipMetadata {
+		frontMatter = readHTMLMetadata(doc, base).frontMatter()
+	}
+	removeSelected(doc, selectors)
+	if base != nil {
+		resolveLinks(doc, base)
+	}
+	c.rewriteImages(ctx, doc, path, base)
+	if err := ctx.Err(); err != nil {
+		return "", err
 	}
 
-	markdown, err := html2md.ConvertString(string(input))
+	markdown, err := c.markdownConverter().ConvertNode(doc)
 	if err != nil {
 		return "", fmt.Errorf("failed to convert HTML to markdown: %w", err)
 	}
+	if frontMatter != "" && len(markdown) > 0 {
+		return frontMatter + "\n" + string(markdown), nil
+	}
+	return frontMatter + string(markdown), nil
+}
+
+// markdownConverter returns an html-to-markdown converter with the plugins
+// and rules of the converter.
+func (c *HTMLConverter) markdownConverter() *converter.Converter {
+	plugins := append([]converter.Plugin{base.NewBasePlugin(), commonmark.NewCommonmarkPlugin()}, c.Plugins...)
+	conv := converter.NewConverter(converter.WithPlugins(plugins...))
+	for _, tag := range c.KeepTags {
+		conv.Register.RendererFor(strings.ToLower(tag), converter.TagTypeBlock, renderRawHTML, converter.PriorityEarly)
+	}
+	for tag, rule := range c.Rules {
+		conv.Register.RendererFor(strings.ToLower(tag), converter.TagTypeBlock, rule, converter.PriorityEarly)
+	}
+	return conv
+}
+
+// renderRawHTML writes an element as an HTML block. Blank lines are dropped,
+// as they would end the block.
+func renderRawHTML(_ converter.Context, w converter.Writer, n *html.Node) converter.RenderStatus {
+	var buf strings.Builder
+	if err := html.Render(&buf, n); err != nil {
+		return converter.RenderTryNext
+	}
+	var lines []string
+	for line := range strings.Lines(buf.String()) {
+		if line = strings.TrimRight(line, " \t\r\n"); strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	w.WriteString("\n\n")
+	w.WriteString(strings.Join(lines, "\n"))
+	w.WriteString("\n\n")
+	return converter.RenderSuccess
+}
+
+// htmlSelector matches elements by tag name, classes and id. Empty parts
+// match any element.
+type htmlSelector struct {
+	Tag     string
+	ID      string
+	Classes []string
+}
+
+// parseSelectors parses selectors made of a tag name, classes and an id.
+func parseSelectors(selectors []string) ([]htmlSelector, error) {
+	parsed := make([]htmlSelector, 0, len(selectors))
+	for _, selector := range selectors {
+		sel, ok := parseSelector(strings.TrimSpace(selector))
+		if !ok {
+			return nil, fmt.Errorf("invalid selector %q", selector)
+		}
+		parsed = append(parsed, sel)
+	}
+	return parsed, nil
+}
+
+// parseSelector parses a selector such as "div.ad#top".
+func parseSelector(selector string) (htmlSelector, bool) {
+	var sel htmlSelector
+	rest, kind := selector, byte(0)
+	for {
+		end := strings.IndexAny(rest, ".#")
+		if end < 0 {
+			end = len(rest)
+		}
+		name := rest[:end]
+		if strings.ContainsFunc(name, invalidSelectorRune) || name == "" && kind != 0 {
+			return sel, false
+		}
+		switch kind {
+		case 0:
+			sel.Tag = strings.ToLower(name)
+		case '.':
+			sel.Classes = append(sel.Classes, name)
+		case '#':
+			sel.ID = name
+		}
+		if end == len(rest) {
+			break
+		}
+		kind, rest = rest[end], rest[end+1:]
+	}
+	return sel, sel.Tag != "" || sel.ID != "" || len(sel.Classes) > 0
+}
+
+// invalidSelectorRune reports whether a rune cannot appear in a tag name,
+// class or id of a selector.
+func invalidSelectorRune(r rune) bool {
+	return !(r == '-' || r == '_' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r > 0x7f)
+}
+
+// matches reports whether an element matches the selector.
+func (s htmlSelector) matches(n *html.Node) bool {
+	if n.Type != html.ElementNode || s.Tag != "" && n.Data != s.Tag {
+		return false
+	}
+	if s.ID != "" && htmlAttr(n, "id") != s.ID {
+		return false
+	}
+	classes := strings.Fields(htmlAttr(n, "class"))
+	for _, class := range s.Classes {
+		if !slices.Contains(classes, class) {
+			return false
+		}
+	}
+	return true
+}
+
+// removeSelected removes the elements matching any of the selectors.
+func removeSelected(doc *html.Node, selectors []htmlSelector) {
+	if len(selectors) == 0 {
+		return
+	}
+	var matched []*html.Node
+	for n := range doc.Descendants() {
+		if slices.ContainsFunc(selectors, func(s htmlSelector) bool { return s.matches(n) }) {
+			matched = append(matched, n)
+		}
+	}
+	for _, n := range matched {
+		if n.Parent != nil {
+			n.Parent.RemoveChild(n)
+		}
+	}
+}
+
+// client returns the HTTP client of the converter.
+func (c *HTMLConverter) client() *http.Client {
+	if c.Client != nil {
+		return c.Client
+	}
+	return &http.Client{Timeout: 30 * time.Second}
+}
+
+// fetch downloads a resource and returns its content and final URL after
+// redirects. When types are given, the response content type must be one
+// of them.
+func (c *HTMLConverter) fetch(ctx context.Context, rawURL string, types ...string) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	resp, err := c.client().Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("unexpected status %s for %s", resp.Status, rawURL)
+	}
+	if len(types) > 0 {
+		mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+		if mediaType != "" && !slices.Contains(types, mediaType) {
+			return nil, "", fmt.Errorf("unexpected content type %s for %s", mediaType, rawURL)
+		}
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResourceSize+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if len(data) > maxResourceSize {
+		return nil, "", fmt.Errorf("%s is larger than %d bytes", rawURL, maxResourceSize)
+	}
+	return data, resp.Request.URL.String(), nil
+}
+
+// documentBase applies the href of the <base> element, if any, to the page
+// URL.
+func documentBase(doc *html.Node, page *url.URL) *url.URL {
+	for n := range doc.Descendants() {
+		if n.Type == html.ElementNode && n.Data == "base" {
+			if ref, err := url.Parse(htmlAttr(n, "href")); err == nil && htmlAttr(n, "href") != "" {
+				return page.ResolveReference(ref)
+			}
+			break
+		}
+	}
+	return page
+}
+
+// resolveLinks makes the link targets of a page absolute. Image sources are
+// resolved by rewriteImages.
+func resolveLinks(doc *html.Node, base *url.URL) {
+	for n := range doc.Descendants() {
+		if n.Type != html.ElementNode || n.Data != "a" {
+			continue
+		}
+		href := strings.TrimSpace(htmlAttr(n, "href"))
+		if ref, err := url.Parse(href); err == nil && href != "" {
+			setHTMLAttr(n, "href", base.ResolveReference(ref).String())
+		}
+	}
+}
+
+// rewriteImages applies the image policy to the <img> elements of a page.
+// Images that cannot be fetched keep their link.
+func (c *HTMLConverter) rewriteImages(ctx context.Context, doc *html.Node, file string, base *url.URL) {
+	var images []*html.Node
+	for n := range doc.Descendants() {
+		if n.Type == html.ElementNode && n.Data == "img" {
+			images = append(images, n)
+		}
+	}
+
+	names := make(map[string]bool)
+	for _, img := range images {
+		src := strings.TrimSpace(htmlAttr(img, "src"))
+		if c.Options.Images == ImagesDrop {
+			img.Parent.RemoveChild(img)
+			continue
+		}
+		if src == "" || strings.HasPrefix(src, "data:") {
+			continue
+		}
+
+		ref, err := url.Parse(src)
+		if err != nil {
+			continue
+		}
+		if base != nil {
+			ref = base.ResolveReference(ref)
+			setHTMLAttr(img, "src", ref.String())
+		}
+		if c.Options.Images == ImagesLink {
+			continue
+		}
+
+		data, mediaType, err := c.readImage(ctx, ref, file)
+		if err != nil {
+			continue
+		}
+		switch c.Options.Images {
+		case ImagesInline:
+			setHTMLAttr(img, "src", dataURI(mediaType, data))
+		case ImagesDownload:
+			if link, err := saveImage(c.Options, path.Base(ref.Path), mediaType, data, names); err == nil {
+				setHTMLAttr(img, "src", link)
+			}
+		}
+	}
+}
+
+// readImage returns the content and media type of an image, fetched when
+// remote or read relative to the HTML file otherwise.
+func (c *HTMLConverter) readImage(ctx context.Context, ref *url.URL, file string) ([]byte, string, error) {
+	var data []byte
+	var err error
+	switch {
+	case ref.Scheme == "http" || ref.Scheme == "https":
+		data, _, err = c.fetch(ctx, ref.String())
+	case ref.Scheme == "" && ref.Host == "" && file != "":
+		data, err = os.ReadFile(filepath.Join(filepath.Dir(file), filepath.FromSlash(ref.Path)))
+	default:
+		err = fmt.Errorf("unsupported image URL %s", ref)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	mediaType, ok := imageMediaType(ref.Path, data)
+	if !ok {
+		return nil, "", fmt.Errorf("%s is not an image", ref)
+	}
+	return data, mediaType, nil
+}
+
+// imageMediaType returns the media type of an image from its file name, or
+// its content when the name has no image extension. It reports false when
+// the data is not an image.
+func imageMediaType(name string, data []byte) (string, bool) {
+	mediaType := mimetypes.TypeByExtension(path.Ext(name))
+	if !strings.HasPrefix(mediaType, "image/") {
+		mediaType = mimetypes.DetectData(data)
+	}
+	if !strings.HasPrefix(mediaType, "image/") {
+		return "", false
+	}
+	mediaType, _, _ = strings.Cut(mediaType, ";")
+	return mediaType, true
+}
+
+// dataURI returns an image as a data URI.
+func dataURI(mediaType string, data []byte) string {
+	return fmt.Sprintf("data:%s;base64,%s", mediaType, base64.StdEncoding.EncodeToString(data))
+}
 
-	return markdown, nil
+// saveImage writes an image to the ImageDir of the options, the current
+// directory when empty, under a name not used yet and returns the link to
+// the file.
+func saveImage(options ConvertOptions, name, mediaType string, data []byte, names map[string]bool) (string, error) {
+	if name == "." || name == ".." || name == "/" || name == "" {
+		name = "image"
+	}
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) || r < ' ' {
+			return '_'
+		}
+		return r
+	}, name)
+	if path.Ext(name) == "" {
+		name += mimetypes.Extension(mediaType)
+	}
+
+	stem, ext := strings.TrimSuffix(name, path.Ext(name)), path.Ext(name)
+	for i := 2; names[name]; i++ {
+		name = fmt.Sprintf("%s-%d%s", stem, i, ext)
+	}
+	names[name] = true
+
+	if err := options.checkDiskWrite(); err != nil {
+		return "", err
+	}
+	dir := cmp.Or(options.ImageDir, ".")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	file := filepath.Join(dir, name)
+	if err := os.WriteFile(file, data, 0o644); err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(file), nil
+}
+
+// htmlAttr returns the value of an attribute of an element.
+func htmlAttr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// setHTMLAttr sets the value of an attribute of an element.
+func setHTMLAttr(n *html.Node, key, value string) {
+	for i, a := range n.Attr {
+		if a.Key == key {
+			n.Attr[i].Val = value
+			return
+		}
+	}
+	n.Attr = append(n.Attr, html.Attribute{Key: key, Val: value})
 }
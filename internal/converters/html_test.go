@@ -1,11 +1,19 @@
 package converters
 
 import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
+
+	"github.com/JohannesKaufmann/html-to-markdown/v2/converter"
+	"github.com/JohannesKaufmann/html-to-markdown/v2/plugin/strikethrough"
+	"golang.org/x/net/html"
 )
 
 func TestNewHTMLConverter(t *testing.T) {
@@ -320,3 +328,241 @@ func TestHTMLConverter_Load_UnicodeHTML(t *testing.T) {
 		t.Errorf("Load() should preserve Arabic characters")
 	}
 }
+
+// testPNG is the signature and header chunk of a PNG image, enough for
+// content sniffing.
+const testPNG = "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01\x00\x00\x00\x01\x08\x06\x00\x00\x00"
+
+// newTestSite serves a page linking to relative pages and images.
+func newTestSite(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/docs/page.html", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, `<html><body><h1>Guide</h1>`+
+			`<p>See <a href="next.html">the next page</a>.</p>`+
+			`<p><img src="img/logo.png" alt="Logo"> <img src="/missing.png" alt="Missing"></p></body></html>`)
+	})
+	mux.HandleFunc("/docs/img/logo.png", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		fmt.Fprint(w, testPNG)
+	})
+	mux.HandleFunc("/data.json", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{}`)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestHTMLConverter_Load_URL(t *testing.T) {
+	server := newTestSite(t)
+
+	result, err := NewHTMLConverter().Load(server.URL + "/docs/page.html")
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	expected := "# Guide\n\nSee [the next page](" + server.URL + "/docs/next.html).\n\n" +
+		"![Logo](" + server.URL + "/docs/img/logo.png) ![Missing](" + server.URL + "/missing.png)"
+	if result != expected {
+		t.Errorf("Load() = %q, want %q", result, expected)
+	}
+
+	if _, err := NewHTMLConverter().Load(server.URL + "/data.json"); err == nil || !strings.Contains(err.Error(), "unexpected content type") {
+		t.Errorf("Load() of a JSON URL error = %v, want an unexpected content type error", err)
+	}
+	if _, err := NewHTMLConverter().Load(server.URL + "/nowhere.html"); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Load() of a missing page error = %v, want a 404 error", err)
+	}
+}
+
+func TestHTMLConverter_Load_ImagePolicies(t *testing.T) {
+	server := newTestSite(t)
+	page := server.URL + "/docs/page.html"
+	dataURI := "data:image/png;base64," + base64.StdEncoding.EncodeToString([]byte(testPNG))
+
+	converter := &HTMLConverter{Options: ConvertOptions{Images: ImagesInline}}
+	result, err := converter.Load(page)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	if !strings.Contains(result, "![Logo]("+dataURI+")") || !strings.Contains(result, "![Missing]("+server.URL+"/missing.png)") {
+		t.Errorf("Load() with ImagesInline = %q, want the logo inlined and the missing image linked", result)
+	}
+
+	dir := filepath.Join(t.TempDir(), "assets")
+	converter = &HTMLConverter{Options: ConvertOptions{Images: ImagesDownload, ImageDir: dir}}
+	if result, err = converter.Load(page); err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	file := filepath.Join(dir, "logo.png")
+	if !strings.Contains(result, "![Logo]("+filepath.ToSlash(file)+")") {
+		t.Errorf("Load() with ImagesDownload = %q, want a link to %s", result, file)
+	}
+	if data, err := os.ReadFile(file); err != nil || string(data) != testPNG {
+		t.Errorf("downloaded image = %q, %v, want the image content", data, err)
+	}
+
+	converter = &HTMLConverter{Options: ConvertOptions{Images: ImagesDrop}}
+	if result, err = converter.Load(page); err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	if strings.Contains(result, "![") {
+		t.Errorf("Load() with ImagesDrop = %q, want no images", result)
+	}
+}
+
+func TestHTMLConverter_Load_InlineLocalImages(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "chart.png"), []byte(testPNG), 0o644); err != nil {
+		t.Fatalf("Failed to create test image: %v", err)
+	}
+	htmlFile := filepath.Join(dir, "report.html")
+	if err := os.WriteFile(htmlFile, []byte(`<p><img src="chart.png" alt="Chart"></p>`), 0o644); err != nil {
+		t.Fatalf("Failed to create test HTML file: %v", err)
+	}
+
+	converter := &HTMLConverter{Options: ConvertOptions{Images: ImagesInline}}
+	result, err := converter.Load(htmlFile)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	expected := "![Chart](data:image/png;base64," + base64.StdEncoding.EncodeToString([]byte(testPNG)) + ")"
+	if result != expected {
+		t.Errorf("Load() = %q, want %q", result, expected)
+	}
+}
+
+func TestHTMLConverter_Load_Rules(t *testing.T) {
+	htmlFile := filepath.Join(t.TempDir(), "rules.html")
+	content := `<nav>Menu</nav>
+<div class="ad banner">Buy now</div>
+<p id="comments">First!</p>
+<figure><img src="cat.png" alt="Cat"><figcaption>A cat</figcaption></figure>
+<table>
+  <tr><td>A</td><td>B</td></tr>
+
+  <tr><td>C</td><td>D</td></tr>
+</table>
+<p>Body text</p>`
+	if err := os.WriteFile(htmlFile, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to create test HTML file: %v", err)
+	}
+
+	conv := &HTMLConverter{
+		KeepTags:        []string{"table"},
+		RemoveSelectors: []string{"nav", ".ad", "p#comments"},
+		Rules: map[string]converter.HandleRenderFunc{
+			"figure": func(_ converter.Context, w converter.Writer, n *html.Node) converter.RenderStatus {
+				for d := range n.Descendants() {
+					if d.Type == html.ElementNode && d.Data == "figcaption" && d.FirstChild != nil {
+						w.WriteString("\n\nFigure: " + d.FirstChild.Data + "\n\n")
+						return converter.RenderSuccess
+					}
+				}
+				return converter.RenderTryNext
+			},
+		},
+	}
+	result, err := conv.Load(htmlFile)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+
+	expected := "Figure: A cat\n\n<table><tbody><tr><td>A</td><td>B</td></tr> <tr><td>C</td><td>D</td></tr></tbody></table>\n\nBody text"
+	if result != expected {
+		t.Errorf("Load() = %q, want %q", result, expected)
+	}
+}
+
+func TestHTMLConverter_Load_Plugins(t *testing.T) {
+	htmlFile := filepath.Join(t.TempDir(), "plugins.html")
+	if err := os.WriteFile(htmlFile, []byte(`<p><del>old</del> new</p>`), 0o644); err != nil {
+		t.Fatalf("Failed to create test HTML file: %v", err)
+	}
+
+	conv := &HTMLConverter{Plugins: []converter.Plugin{strikethrough.NewStrikethroughPlugin()}}
+	result, err := conv.Load(htmlFile)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	if result != "~~old~~ new" {
+		t.Errorf("Load() = %q, want %q", result, "~~old~~ new")
+	}
+}
+
+func TestHTMLConverter_Load_InvalidSelector(t *testing.T) {
+	htmlFile := filepath.Join(t.TempDir(), "page.html")
+	if err := os.WriteFile(htmlFile, []byte(`<p>Text</p>`), 0o644); err != nil {
+		t.Fatalf("Failed to create test HTML file: %v", err)
+	}
+
+	for _, selector := range []string{"", "div > p", "div.", "[href]"} {
+		conv := &HTMLConverter{RemoveSelectors: []string{selector}}
+		if _, err := conv.Load(htmlFile); err == nil || !strings.Contains(err.Error(), "invalid selector") {
+			t.Errorf("Load() with selector %q error = %v, want an invalid selector error", selector, err)
+		}
+	}
+}
+
+func TestHTMLConverter_Load_Metadata(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/blog/post.html", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<html><head>
+<title> Release notes:  v2 </title>
+<meta name="description" content="What changed in v2">
+<meta property="og:title" content="Ignored title">
+<meta property="og:site_name" content="Example Blog">
+<meta property="og:type" content="article">
+<meta property="og:image" content="/img/cover.png">
+<link rel="canonical" href="/blog/v2">
+</head><body><p>Hello</p></body></html>`)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	result, err := NewHTMLConverter().Load(server.URL + "/blog/post.html")
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	expected := "---\n" +
+		"title: \"Release notes: v2\"\n" +
+		"description: What changed in v2\n" +
+		"url: " + server.URL + "/blog/v2\n" +
+		"site_name: Example Blog\n" +
+		"type: article\n" +
+		"image: " + server.URL + "/img/cover.png\n" +
+		"---\n\nHello"
+	if result != expected {
+		t.Errorf("Load() = %q, want %q", result, expected)
+	}
+
+	result, err = (&HTMLConverter{SkipMetadata: true}).Load(server.URL + "/blog/post.html")
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	if result != "Hello" {
+		t.Errorf("Load() with SkipMetadata = %q, want %q", result, "Hello")
+	}
+}
+
+func TestHTMLConverter_Load_OpenGraphFallback(t *testing.T) {
+	htmlFile := filepath.Join(t.TempDir(), "page.html")
+	content := `<head><meta property="og:title" content="Shared title">` +
+		`<meta property="og:description" content="Shared description">` +
+		`<meta property="og:url" content="https://example.com/page"></head><body><p>Text</p></body>`
+	if err := os.WriteFile(htmlFile, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to create test HTML file: %v", err)
+	}
+
+	result, err := NewHTMLConverter().Load(htmlFile)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	expected := "---\ntitle: Shared title\ndescription: Shared description\nurl: https://example.com/page\n---\n\nText"
+	if result != expected {
+		t.Errorf("Load() = %q, want %q", result, expected)
+	}
+}
@@ -0,0 +1,111 @@
+package converters
+
+import (
+	"cmp"
+	"net/url"
+	"strings"
+
+	"github.com/flaviodelgrosso/marky/internal/utils"
+	"golang.org/x/net/html"
+)
+
+// htmlMetadata is the descriptive metadata of an HTML page, from its head.
+type htmlMetadata struct {
+	Title       string
+	Description string
+	URL         string
+	SiteName    string
+	Type        string
+	Image       string
+}
+
+// frontMatter returns the metadata as a YAML front matter block, empty when
+// the page has no metadata.
+func (m htmlMetadata) frontMatter() string {
+	return utils.FrontMatter([]utils.FrontMatterField{
+		{Key: "title", Value: m.Title},
+		{Key: "description", Value: m.Description},
+		{Key: "url", Value: m.URL},
+		{Key: "site_name", Value: m.SiteName},
+		{Key: "type", Value: m.Type},
+		{Key: "image", Value: m.Image},
+	})
+}
+
+// readHTMLMetadata reads the title, meta description, canonical link and
+// Open Graph properties of a page. The title and description elements take
+// precedence over their Open Graph counterparts. Links are resolved against
+// the base URL of remote pages.
+func readHTMLMetadata(doc *html.Node, base *url.URL) htmlMetadata {
+	var title, description, canonical string
+	og := make(map[string]string)
+	for n := range doc.Descendants() {
+		if n.Type != html.ElementNode {
+			continue
+		}
+		if n.Data == "body" {
+			// Metadata elements belong to the head.
+			break
+		}
+		switch n.Data {
+		case "title":
+			if title == "" {
+				title = htmlText(n)
+			}
+		case "meta":
+			content := strings.TrimSpace(htmlAttr(n, "content"))
+			if strings.EqualFold(htmlAttr(n, "name"), "description") && description == "" {
+				description = content
+			}
+			if property, ok := strings.CutPrefix(htmlAttr(n, "property"), "og:"); ok && og[property] == "" {
+				og[property] = content
+			}
+		case "link":
+			if relContains(htmlAttr(n, "rel"), "canonical") && canonical == "" {
+				canonical = strings.TrimSpace(htmlAttr(n, "href"))
+			}
+		}
+	}
+
+	return htmlMetadata{
+		Title:       strings.Join(strings.Fields(cmp.Or(title, og["title"])), " "),
+		Description: strings.Join(strings.Fields(cmp.Or(description, og["description"])), " "),
+		URL:         resolveURL(cmp.Or(canonical, og["url"]), base),
+		SiteName:    og["site_name"],
+		Type:        og["type"],
+		Image:       resolveURL(og["image"], base),
+	}
+}
+
+// htmlText returns the text content of an element.
+func htmlText(n *html.Node) string {
+	var b strings.Builder
+	for d := range n.Descendants() {
+		if d.Type == html.TextNode {
+			b.WriteString(d.Data)
+		}
+	}
+	return b.String()
+}
+
+// relContains reports whether a rel attribute lists a link type.
+func relContains(rel, linkType string) bool {
+	for _, t := range strings.Fields(rel) {
+		if strings.EqualFold(t, linkType) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveURL resolves a link against the base URL, when there is one.
+func resolveURL(link string, base *url.URL) string {
+	if link == "" || base == nil {
+		return link
+	}
+	ref, err := url.Parse(link)
+	if err != nil {
+		return link
+	}
+	return base.ResolveReference(ref).String()
+}
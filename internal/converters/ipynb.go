@@ -1,15 +1,45 @@
 package converters
 
 import (
+	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"maps"
+	"net/url"
 	"os"
+	"path"
+	"regexp"
+	"slices"
 	"strings"
+
+	html2md "github.com/JohannesKaufmann/html-to-markdown/v2"
 )
 
 // IpynbConverter handles loading and converting Jupyter Notebook (.ipynb) files to markdown.
 type IpynbConverter struct {
 	BaseConverter
+
+	// Options configures the conversion: the image policy and directory of
+	// output images and cell attachments. Notebooks store their images, so
+	// ImagesLink embeds them as data URIs like ImagesInline.
+	Options ConvertOptions
+
+	// SkipOutputs leaves out the outputs of code cells: stream text, results,
+	// rich display data and errors.
+	SkipOutputs bool
+
+	// SkipCode leaves out the source of code cells, keeping their outputs
+	// unless SkipOutputs is set.
+	SkipCode bool
+
+	// MarkdownOnly keeps only the markdown cells, for conversions of the
+	// prose of a notebook.
+	MarkdownOnly bool
+
+	// ExecutionCounts writes "In [N]:" and "Out [N]:" captions before the
+	// code cells and their results, as in the notebook interface.
+	ExecutionCounts bool
 }
 
 // NewIpynbConverter creates a new Jupyter Notebook converter with appropriate MIME types and extensions.
@@ -17,20 +47,96 @@ func NewIpynbConverter() Converter {
 	return &IpynbConverter{
 		BaseConverter: NewBaseConverter(
 			[]string{".ipynb"},
-			[]string{"application/x-ipynb+json", "application/json"},
+			[]string{"application/x-ipynb+json"},
 		),
 	}
 }
 
+// MultilineString is a notebook text field, stored either as a single
+// string or as a list of lines.
+type MultilineString []string
+
+// UnmarshalJSON accepts a string, a list of strings or null.
+func (m *MultilineString) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*m = MultilineString{text}
+		return nil
+	}
+	var lines []string
+	if err := json.Unmarshal(data, &lines); err != nil {
+		return err
+	}
+	*m = lines
+	return nil
+}
+
+// String returns the text with the lines joined.
+func (m MultilineString) String() string {
+	return strings.Join(m, "")
+}
+
 // NotebookCell represents a cell in a Jupyter notebook.
 type NotebookCell struct {
-	CellType string   `json:"cell_type"`
-	Source   []string `json:"source"`
+	CellType       string           `json:"cell_type"`
+	Source         MultilineString  `json:"source"`
+	Outputs        []NotebookOutput `json:"outputs,omitempty"`
+	ExecutionCount *int             `json:"execution_count,omitempty"`
+
+	// Attachments holds the files pasted into markdown cells, by name and
+	// media type.
+	Attachments map[string]map[string]MultilineString `json:"attachments,omitempty"`
+}
+
+// NotebookOutput represents an output of a code cell: stream text, an
+// execution result, display data or an error.
+type NotebookOutput struct {
+	OutputType string `json:"output_type"`
+
+	// ExecutionCount is the execution count of execution results.
+	ExecutionCount *int `json:"execution_count,omitempty"`
+
+	// Name is the stream of stream outputs, stdout or stderr.
+	Name string          `json:"name,omitempty"`
+	Text MultilineString `json:"text,omitempty"`
+
+	// Data holds the representations of results and display data by media
+	// type.
+	Data map[string]json.RawMessage `json:"data,omitempty"`
+
+	Ename     string   `json:"ename,omitempty"`
+	Evalue    string   `json:"evalue,omitempty"`
+	Traceback []string `json:"traceback,omitempty"`
 }
 
 // NotebookMetadata represents the metadata section of a Jupyter notebook.
 type NotebookMetadata struct {
-	Title string `json:"title,omitempty"`
+	Title        string               `json:"title,omitempty"`
+	KernelSpec   NotebookKernelSpec   `json:"kernelspec,omitempty"`
+	LanguageInfo NotebookLanguageInfo `json:"language_info,omitempty"`
+}
+
+// NotebookKernelSpec describes the kernel a notebook runs on.
+type NotebookKernelSpec struct {
+	Name     string `json:"name,omitempty"`
+	Language string `json:"language,omitempty"`
+}
+
+// NotebookLanguageInfo describes the programming language of a notebook.
+type NotebookLanguageInfo struct {
+	Name string `json:"name,omitempty"`
+}
+
+// Language returns the programming language of the code cells, from the
+// language info or the kernel spec, lowercase. Notebooks that declare
+// neither are assumed to be Python.
+func (m NotebookMetadata) Language() string {
+	for _, language := range []string{m.LanguageInfo.Name, m.KernelSpec.Language} {
+		if language = strings.ToLower(strings.TrimSpace(language)); language != "" && !strings.ContainsAny(language, " `") {
+			return language
+		}
+	}
+	return "python"
 }
 
 // JupyterNotebook represents the structure of a Jupyter notebook file.
@@ -41,33 +147,50 @@ type JupyterNotebook struct {
 	Metadata      NotebookMetadata `json:"metadata"`
 }
 
+// Capabilities returns what the converter can extract from Jupyter notebooks.
+func (c *IpynbConverter) Capabilities() Capabilities {
+	return Capabilities{Images: true}
+}
+
 // Load reads a Jupyter notebook file and converts it to markdown.
-func (*IpynbConverter) Load(path string) (string, error) {
+func (c *IpynbConverter) Load(path string) (string, error) {
 	content, err := os.ReadFile(path)
 	if err != nil {
 		return "", fmt.Errorf("failed to read ipynb file: %w", err)
 	}
+	return c.LoadBytes(context.Background(), content)
+}
 
+// LoadBytes converts a notebook held in memory like Load.
+func (c *IpynbConverter) LoadBytes(_ context.Context, content []byte) (string, error) {
 	// Parse the JSON content
 	var notebook JupyterNotebook
 	if err := json.Unmarshal(content, &notebook); err != nil {
-		return "", fmt.Errorf("failed to parse ipynb file: %w", err)
+		return "", fmt.Errorf("failed to parse ipynb file: %w", corrupt(err))
+	}
+	if notebook.NBFormat < 4 && len(notebook.Cells) == 0 {
+		var err error
+		if notebook, err = upgradeNotebookV3(content); err != nil {
+			return "", fmt.Errorf("failed to parse ipynb file: %w", corrupt(err))
+		}
 	}
 
-	return convertNotebookToMarkdown(notebook), nil
+	return c.convertNotebookToMarkdown(notebook), nil
 }
 
 // convertNotebookToMarkdown converts a Jupyter notebook to markdown format.
-func convertNotebookToMarkdown(notebook JupyterNotebook) string {
+func (c *IpynbConverter) convertNotebookToMarkdown(notebook JupyterNotebook) string {
 	var mdParts []string
 	var title string
+	language := notebook.Metadata.Language()
+	images := make(map[string]bool)
 
 	for _, cell := range notebook.Cells {
-		cellContent := strings.Join(cell.Source, "")
+		cellContent := cell.Source.String()
 
 		switch cell.CellType {
 		case "markdown":
-			mdParts = append(mdParts, cellContent)
+			mdParts = append(mdParts, c.resolveAttachments(cellContent, cell.Attachments, images))
 
 			// Extract the first # heading as title if not already found
 			if title == "" {
@@ -82,15 +205,36 @@ func convertNotebookToMarkdown(notebook JupyterNotebook) string {
 			}
 
 		case "code":
+			if c.MarkdownOnly {
+				continue
+			}
 			// Code cells are wrapped in Markdown code blocks
-			if strings.TrimSpace(cellContent) != "" {
-				mdParts = append(mdParts, fmt.Sprintf("```python\n%s\n```", cellContent))
+			if !c.SkipCode && strings.TrimSpace(cellContent) != "" {
+				if c.ExecutionCounts {
+					mdParts = append(mdParts, executionCaption("In", cell.ExecutionCount))
+				}
+				mdParts = append(mdParts, codeFence(language, cellContent))
+			}
+			if !c.SkipOutputs {
+				for _, output := range cell.Outputs {
+					markdown := c.convertOutput(output, images)
+					if markdown == "" {
+						continue
+					}
+					if c.ExecutionCounts && output.OutputType == "execute_result" {
+						mdParts = append(mdParts, executionCaption("Out", output.ExecutionCount))
+					}
+					mdParts = append(mdParts, markdown)
+				}
 			}
 
 		case "raw":
+			if c.MarkdownOnly {
+				continue
+			}
 			// Raw cells are wrapped in plain code blocks
 			if strings.TrimSpace(cellContent) != "" {
-				mdParts = append(mdParts, fmt.Sprintf("```\n%s\n```", cellContent))
+				mdParts = append(mdParts, codeFence("", cellContent))
 			}
 		}
 	}
@@ -108,3 +252,172 @@ func convertNotebookToMarkdown(notebook JupyterNotebook) string {
 
 	return markdown
 }
+
+// executionCaption returns the caption of a code cell or result, with a
+// blank count for cells that were not run.
+func executionCaption(prompt string, count *int) string {
+	if count == nil {
+		return fmt.Sprintf("_%s [ ]:_", prompt)
+	}
+	return fmt.Sprintf("_%s [%d]:_", prompt, *count)
+}
+
+// outputMediaTypes lists the representations of rich outputs in order of
+// preference.
+var outputMediaTypes = []string{
+	"text/markdown",
+	"image/png",
+	"image/jpeg",
+	"image/gif",
+	"image/svg+xml",
+	"text/html",
+	"text/latex",
+	"text/plain",
+}
+
+// ansiEscape matches the terminal color codes of error tracebacks.
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
+
+// convertOutput converts an output of a code cell to markdown. Text outputs
+// and errors are fenced, rich outputs use their best representation.
+func (c *IpynbConverter) convertOutput(output NotebookOutput, images map[string]bool) string {
+	switch output.OutputType {
+	case "stream":
+		return codeFence("", strings.TrimRight(output.Text.String(), "\n"))
+	case "error":
+		traceback := ansiEscape.ReplaceAllString(strings.Join(output.Traceback, "\n"), "")
+		if strings.TrimSpace(traceback) == "" {
+			traceback = output.Ename + ": " + output.Evalue
+		}
+		return codeFence("", strings.TrimRight(traceback, "\n"))
+	case "execute_result", "display_data":
+		for _, mediaType := range outputMediaTypes {
+			raw, ok := output.Data[mediaType]
+			if !ok {
+				continue
+			}
+			var value MultilineString
+			if err := json.Unmarshal(raw, &value); err != nil {
+				continue
+			}
+			if markdown, ok := c.convertOutputData(mediaType, value.String(), images); ok {
+				return markdown
+			}
+		}
+	}
+	return ""
+}
+
+// convertOutputData converts a representation of a rich output. It reports
+// false when the representation cannot be converted.
+func (c *IpynbConverter) convertOutputData(mediaType, value string, images map[string]bool) (string, bool) {
+	switch mediaType {
+	case "text/markdown":
+		return strings.TrimSpace(value), true
+	case "text/html":
+		markdown, err := html2md.ConvertString(value)
+		return strings.TrimSpace(markdown), err == nil
+	case "text/latex":
+		return strings.TrimSpace(value), true
+	case "text/plain":
+		return codeFence("", strings.TrimRight(value, "\n")), true
+	}
+
+	if c.Options.Images == ImagesDrop {
+		return "", true
+	}
+	link, err := c.notebookImage("output", mediaType, value, images)
+	if err != nil {
+		return "", false
+	}
+	return fmt.Sprintf("![output](%s)", link), true
+}
+
+// notebookImage decodes an image stored in a notebook and returns it as a
+// data URI, or writes it to the image directory and returns the link to the
+// file.
+func (c *IpynbConverter) notebookImage(name, mediaType, value string, images map[string]bool) (string, error) {
+	// Images other than SVG are stored base64 encoded.
+	data := []byte(value)
+	if mediaType != "image/svg+xml" {
+		var err error
+		if data, err = base64.StdEncoding.DecodeString(strings.Join(strings.Fields(value), "")); err != nil {
+			return "", err
+		}
+	}
+	if c.Options.Images == ImagesDownload {
+		return saveImage(c.Options, name, mediaType, data, images)
+	}
+	return dataURI(mediaType, data), nil
+}
+
+// attachmentImage matches the markdown images and <img> elements referring
+// to cell attachments.
+var attachmentImage = regexp.MustCompile(`!\[([^\]]*)\]\(attachment:([^)\s]+)(?:\s+"[^"]*")?\)|<img\b[^>]*\bsrc=["']attachment:([^"']+)["'][^>]*>`)
+
+// resolveAttachments replaces the references to the attachments of a
+// markdown cell with data URIs or links to the extracted files. References
+// to missing attachments are kept.
+func (c *IpynbConverter) resolveAttachments(source string, attachments map[string]map[string]MultilineString, images map[string]bool) string {
+	if len(attachments) == 0 {
+		return source
+	}
+	links := make(map[string]string)
+	return attachmentImage.ReplaceAllStringFunc(source, func(match string) string {
+		groups := attachmentImage.FindStringSubmatch(match)
+		name := groups[2] + groups[3]
+		if unescaped, err := url.PathUnescape(name); err == nil {
+			name = unescaped
+		}
+		bundle, ok := attachments[name]
+		if !ok {
+			return match
+		}
+		mediaType := attachmentMediaType(bundle)
+		if mediaType == "" {
+			return match
+		}
+		if c.Options.Images == ImagesDrop {
+			return ""
+		}
+
+		link, ok := links[name]
+		if !ok {
+			var err error
+			if link, err = c.notebookImage(path.Base(name), mediaType, bundle[mediaType].String(), images); err != nil {
+				return match
+			}
+			links[name] = link
+		}
+		if groups[2] != "" {
+			// Markdown link destinations cannot contain spaces.
+			link = strings.ReplaceAll(link, " ", "%20")
+		}
+		return strings.Replace(match, "attachment:"+groups[2]+groups[3], link, 1)
+	})
+}
+
+// attachmentMediaType returns the image type of an attachment, preferring
+// raster formats, or an empty string when it has no image.
+func attachmentMediaType(bundle map[string]MultilineString) string {
+	for _, mediaType := range []string{"image/png", "image/jpeg", "image/gif", "image/webp", "image/svg+xml"} {
+		if _, ok := bundle[mediaType]; ok {
+			return mediaType
+		}
+	}
+	types := slices.Sorted(maps.Keys(bundle))
+	if i := slices.IndexFunc(types, func(t string) bool { return strings.HasPrefix(t, "image/") }); i >= 0 {
+		return types[i]
+	}
+	return ""
+}
+
+// codeFence wraps text in a fenced code block, with a fence longer than any
+// backtick run of the text.
+func codeFence(language, text string) string {
+	fence := "```"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+	return fence + language + "\n" + text + "\n" + fence
+}
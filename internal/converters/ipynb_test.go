@@ -1,6 +1,7 @@
 package converters
 
 import (
+	"encoding/base64"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -12,7 +13,7 @@ func TestNewIpynbConverter(t *testing.T) {
 	converter := NewIpynbConverter()
 
 	expectedExtensions := []string{".ipynb"}
-	expectedMimeTypes := []string{"application/x-ipynb+json", "application/json"}
+	expectedMimeTypes := []string{"application/x-ipynb+json"}
 
 	if !reflect.DeepEqual(converter.AcceptedExtensions(), expectedExtensions) {
 		t.Errorf("NewIpynbConverter() extensions = %v, want %v", converter.AcceptedExtensions(), expectedExtensions)
@@ -281,8 +282,8 @@ func TestIpynbConverter_Interface(t *testing.T) {
 
 	// Test AcceptedMimeTypes
 	mimeTypes := converter.AcceptedMimeTypes()
-	if len(mimeTypes) != 2 {
-		t.Errorf("AcceptedMimeTypes() should return 2 MIME types, got %d", len(mimeTypes))
+	if len(mimeTypes) != 1 {
+		t.Errorf("AcceptedMimeTypes() should return 1 MIME type, got %d", len(mimeTypes))
 	}
 }
 
@@ -396,3 +397,250 @@ func TestIpynbConverter_LoadingScenarios(t *testing.T) {
 		})
 	}
 }
+
+// writeTestNotebook writes a notebook to a temporary file and returns its path.
+func writeTestNotebook(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "notebook.ipynb")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to create test notebook file: %v", err)
+	}
+	return path
+}
+
+func TestIpynbConverter_Load_Outputs(t *testing.T) {
+	png := base64.StdEncoding.EncodeToString([]byte(testPNG))
+	notebook := writeTestNotebook(t, `{
+ "cells": [
+  {
+   "cell_type": "code",
+   "source": "print('hi')\n1 + 1",
+   "outputs": [
+    {"output_type": "stream", "name": "stdout", "text": ["hi\n"]},
+    {"output_type": "execute_result", "execution_count": 1, "data": {"text/plain": ["2"]}, "metadata": {}}
+   ]
+  },
+  {
+   "cell_type": "code",
+   "source": ["df"],
+   "outputs": [
+    {"output_type": "display_data", "data": {
+      "text/html": ["<b>Bold</b> table"],
+      "text/plain": ["   a\n0  1"]
+    }},
+    {"output_type": "display_data", "data": {
+      "image/png": "`+png[:8]+`\n`+png[8:]+`",
+      "text/plain": ["<Figure>"],
+      "application/json": {"ignored": true}
+    }}
+   ]
+  },
+  {
+   "cell_type": "code",
+   "source": ["1 / 0"],
+   "outputs": [
+    {"output_type": "error", "ename": "ZeroDivisionError", "evalue": "division by zero",
+     "traceback": ["\u001b[0;31mZeroDivisionError\u001b[0m: division by zero"]}
+   ]
+  }
+ ],
+ "metadata": {},
+ "nbformat": 4,
+ "nbformat_minor": 5
+}`)
+
+	result, err := NewIpynbConverter().Load(notebook)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	expected := "```python\nprint('hi')\n1 + 1\n```\n\n```\nhi\n```\n\n```\n2\n```\n\n" +
+		"```python\ndf\n```\n\n**Bold** table\n\n![output](data:image/png;base64," + png + ")\n\n" +
+		"```python\n1 / 0\n```\n\n```\nZeroDivisionError: division by zero\n```"
+	if result != expected {
+		t.Errorf("Load() = %q, want %q", result, expected)
+	}
+
+	dir := t.TempDir()
+	converter := &IpynbConverter{Options: ConvertOptions{Images: ImagesDownload, ImageDir: dir}}
+	if result, err = converter.Load(notebook); err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	file := filepath.Join(dir, "output.png")
+	if !strings.Contains(result, "![output]("+filepath.ToSlash(file)+")") {
+		t.Errorf("Load() with ImagesDownload = %q, want a link to %s", result, file)
+	}
+	if data, err := os.ReadFile(file); err != nil || string(data) != testPNG {
+		t.Errorf("extracted image = %q, %v, want the image content", data, err)
+	}
+
+	converter = &IpynbConverter{SkipOutputs: true}
+	if result, err = converter.Load(notebook); err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	if want := "```python\nprint('hi')\n1 + 1\n```\n\n```python\ndf\n```\n\n```python\n1 / 0\n```"; result != want {
+		t.Errorf("Load() with SkipOutputs = %q, want %q", result, want)
+	}
+}
+
+func TestIpynbConverter_Load_Language(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata string
+		expected string
+	}{
+		{"language info", `{"language_info": {"name": "R"}, "kernelspec": {"language": "python"}}`, "```r\n"},
+		{"kernel spec", `{"kernelspec": {"name": "julia-1.9", "language": "julia"}}`, "```julia\n"},
+		{"scala", `{"language_info": {"name": "scala"}}`, "```scala\n"},
+		{"default", `{}`, "```python\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notebook := writeTestNotebook(t, `{"cells": [{"cell_type": "code", "source": "x <- 1"}], "metadata": `+tt.metadata+`, "nbformat": 4, "nbformat_minor": 5}`)
+			result, err := NewIpynbConverter().Load(notebook)
+			if err != nil {
+				t.Fatalf("Load() returned unexpected error: %v", err)
+			}
+			if !strings.HasPrefix(result, tt.expected) {
+				t.Errorf("Load() = %q, want a fence starting with %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIpynbConverter_Load_Attachments(t *testing.T) {
+	png := base64.StdEncoding.EncodeToString([]byte(testPNG))
+	notebook := writeTestNotebook(t, `{
+ "cells": [
+  {
+   "cell_type": "markdown",
+   "source": ["Screenshot: ![shot](attachment:screen%20shot.png \"Title\")\n", "<img src=\"attachment:screen shot.png\" width=\"200\">\n", "![gone](attachment:missing.png)"],
+   "attachments": {"screen shot.png": {"image/png": "`+png+`"}}
+  }
+ ],
+ "metadata": {},
+ "nbformat": 4,
+ "nbformat_minor": 5
+}`)
+
+	result, err := NewIpynbConverter().Load(notebook)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	uri := "data:image/png;base64," + png
+	expected := "Screenshot: ![shot](" + uri + " \"Title\")\n<img src=\"" + uri + "\" width=\"200\">\n![gone](attachment:missing.png)"
+	if result != expected {
+		t.Errorf("Load() = %q, want %q", result, expected)
+	}
+
+	dir := t.TempDir()
+	converter := &IpynbConverter{Options: ConvertOptions{Images: ImagesDownload, ImageDir: dir}}
+	if result, err = converter.Load(notebook); err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	link := filepath.ToSlash(filepath.Join(dir, "screen shot.png"))
+	if !strings.Contains(result, "![shot]("+strings.ReplaceAll(link, " ", "%20")+" \"Title\")") ||
+		!strings.Contains(result, "<img src=\""+link+"\"") {
+		t.Errorf("Load() with ImagesDownload = %q, want links to %s", result, link)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("ImagesDownload wrote %d files, want 1", len(entries))
+	}
+
+	converter = &IpynbConverter{Options: ConvertOptions{Images: ImagesDrop}}
+	if result, err = converter.Load(notebook); err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	if want := "Screenshot: \n\n![gone](attachment:missing.png)"; result != want {
+		t.Errorf("Load() with ImagesDrop = %q, want %q", result, want)
+	}
+}
+
+func TestIpynbConverter_Load_CellFilters(t *testing.T) {
+	notebook := writeTestNotebook(t, `{
+ "cells": [
+  {"cell_type": "markdown", "source": "Intro"},
+  {"cell_type": "code", "execution_count": 3, "source": "1 + 1",
+   "outputs": [
+    {"output_type": "stream", "name": "stdout", "text": "log\n"},
+    {"output_type": "execute_result", "execution_count": 3, "data": {"text/plain": "2"}}
+   ]},
+  {"cell_type": "code", "execution_count": null, "source": "x = 1", "outputs": []},
+  {"cell_type": "raw", "source": "raw text"}
+ ],
+ "metadata": {},
+ "nbformat": 4,
+ "nbformat_minor": 5
+}`)
+
+	tests := []struct {
+		name      string
+		converter *IpynbConverter
+		expected  string
+	}{
+		{
+			"skip code",
+			&IpynbConverter{SkipCode: true},
+			"Intro\n\n```\nlog\n```\n\n```\n2\n```\n\n```\nraw text\n```",
+		},
+		{
+			"markdown only",
+			&IpynbConverter{MarkdownOnly: true},
+			"Intro",
+		},
+		{
+			"execution counts",
+			&IpynbConverter{ExecutionCounts: true},
+			"Intro\n\n_In [3]:_\n\n```python\n1 + 1\n```\n\n```\nlog\n```\n\n_Out [3]:_\n\n```\n2\n```\n\n" +
+				"_In [ ]:_\n\n```python\nx = 1\n```\n\n```\nraw text\n```",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.converter.Load(notebook)
+			if err != nil {
+				t.Fatalf("Load() returned unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Load() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIpynbConverter_Load_NBFormat3(t *testing.T) {
+	png := base64.StdEncoding.EncodeToString([]byte(testPNG))
+	notebook := writeTestNotebook(t, `{
+ "metadata": {"name": "legacy"},
+ "nbformat": 3,
+ "nbformat_minor": 0,
+ "worksheets": [
+  {
+   "cells": [
+    {"cell_type": "heading", "level": 2, "metadata": {}, "source": ["Analysis"]},
+    {"cell_type": "markdown", "metadata": {}, "source": ["Some prose."]},
+    {"cell_type": "code", "collapsed": false, "input": ["x <- 2\n", "x * 21"], "language": "r", "prompt_number": 4,
+     "outputs": [
+      {"output_type": "stream", "stream": "stdout", "text": ["computing\n"]},
+      {"output_type": "pyout", "prompt_number": 4, "text": ["42"], "metadata": {}},
+      {"output_type": "display_data", "png": "`+png+`", "text": ["<plot>"], "metadata": {}},
+      {"output_type": "pyerr", "ename": "Error", "evalue": "failed", "traceback": ["Error: failed"]}
+     ]}
+   ],
+   "metadata": {}
+  }
+ ]
+}`)
+
+	converter := &IpynbConverter{ExecutionCounts: true}
+	result, err := converter.Load(notebook)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	expected := "## Analysis\n\nSome prose.\n\n_In [4]:_\n\n```r\nx <- 2\nx * 21\n```\n\n```\ncomputing\n```\n\n" +
+		"_Out [4]:_\n\n```\n42\n```\n\n![output](data:image/png;base64," + png + ")\n\n```\nError: failed\n```"
+	if result != expected {
+		t.Errorf("Load() = %q, want %q", result, expected)
+	}
+}
@@ -0,0 +1,118 @@
+package converters
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// notebookV3 is a notebook in the nbformat 3 layout, with the cells nested
+// under worksheets.
+type notebookV3 struct {
+	Metadata   NotebookMetadata `json:"metadata"`
+	Worksheets []struct {
+		Cells []cellV3 `json:"cells"`
+	} `json:"worksheets"`
+}
+
+// cellV3 is a cell of an nbformat 3 notebook. Code cells hold their source
+// in Input and heading cells are a cell type of their own.
+type cellV3 struct {
+	CellType     string                       `json:"cell_type"`
+	Source       MultilineString              `json:"source"`
+	Input        MultilineString              `json:"input"`
+	Language     string                       `json:"language"`
+	Level        int                          `json:"level"`
+	PromptNumber *int                         `json:"prompt_number"`
+	Outputs      []map[string]json.RawMessage `json:"outputs"`
+}
+
+// outputTypesV3 maps the nbformat 3 output types to their current names.
+var outputTypesV3 = map[string]string{
+	"pyout": "execute_result",
+	"pyerr": "error",
+}
+
+// mediaTypesV3 maps the representation keys of nbformat 3 outputs to media
+// types.
+var mediaTypesV3 = map[string]string{
+	"text":       "text/plain",
+	"html":       "text/html",
+	"markdown":   "text/markdown",
+	"latex":      "text/latex",
+	"png":        "image/png",
+	"jpeg":       "image/jpeg",
+	"svg":        "image/svg+xml",
+	"json":       "application/json",
+	"javascript": "application/javascript",
+}
+
+// upgradeNotebookV3 converts an nbformat 3 notebook to the current layout:
+// the worksheets are flattened, heading cells become markdown headings and
+// outputs take the current field names. The language of the first code
+// cell is the notebook language when the metadata has none.
+func upgradeNotebookV3(content []byte) (JupyterNotebook, error) {
+	var legacy notebookV3
+	if err := json.Unmarshal(content, &legacy); err != nil {
+		return JupyterNotebook{}, err
+	}
+
+	notebook := JupyterNotebook{NBFormat: 4, Metadata: legacy.Metadata}
+	for _, worksheet := range legacy.Worksheets {
+		for _, cell := range worksheet.Cells {
+			switch cell.CellType {
+			case "heading":
+				level := min(max(cell.Level, 1), 6)
+				notebook.Cells = append(notebook.Cells, NotebookCell{
+					CellType: "markdown",
+					Source:   MultilineString{strings.Repeat("#", level) + " " + cell.Source.String()},
+				})
+			case "code":
+				if notebook.Metadata.LanguageInfo.Name == "" && notebook.Metadata.KernelSpec.Language == "" {
+					notebook.Metadata.LanguageInfo.Name = cell.Language
+				}
+				upgraded := NotebookCell{CellType: "code", Source: cell.Input, ExecutionCount: cell.PromptNumber}
+				for _, output := range cell.Outputs {
+					upgraded.Outputs = append(upgraded.Outputs, upgradeOutputV3(output))
+				}
+				notebook.Cells = append(notebook.Cells, upgraded)
+			default:
+				notebook.Cells = append(notebook.Cells, NotebookCell{CellType: cell.CellType, Source: cell.Source})
+			}
+		}
+	}
+	return notebook, nil
+}
+
+// upgradeOutputV3 converts an nbformat 3 output, whose representations are
+// fields of the output itself.
+func upgradeOutputV3(fields map[string]json.RawMessage) NotebookOutput {
+	var output NotebookOutput
+	decode := func(key string, v any) {
+		if raw, ok := fields[key]; ok {
+			_ = json.Unmarshal(raw, v)
+		}
+	}
+	decode("output_type", &output.OutputType)
+	if current, ok := outputTypesV3[output.OutputType]; ok {
+		output.OutputType = current
+	}
+
+	switch output.OutputType {
+	case "stream":
+		decode("stream", &output.Name)
+		decode("text", &output.Text)
+	case "error":
+		decode("ename", &output.Ename)
+		decode("evalue", &output.Evalue)
+		decode("traceback", &output.Traceback)
+	default:
+		decode("prompt_number", &output.ExecutionCount)
+		output.Data = make(map[string]json.RawMessage)
+		for key, mediaType := range mediaTypesV3 {
+			if raw, ok := fields[key]; ok {
+				output.Data[mediaType] = raw
+			}
+		}
+	}
+	return output
+}
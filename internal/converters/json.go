@@ -0,0 +1,203 @@
+package converters
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/flaviodelgrosso/marky/internal/utils"
+)
+
+// JSONConverter handles loading and converting JSON and JSON Lines files to
+// markdown. Arrays of objects, and JSON Lines files of objects, whose values
+// are strings, numbers, booleans or null become tables of records with a
+// column per key. Other documents are written as a fenced code block of
+// indented JSON.
+type JSONConverter struct {
+	BaseConverter
+
+	// Tables sets the layout of the tables of records, as set by the Tables
+	// field of ConvertOptions.
+	Tables utils.TableOptions
+}
+
+// NewJSONConverter creates a new JSON converter with appropriate MIME types and extensions.
+func NewJSONConverter() Converter {
+	return &JSONConverter{
+		BaseConverter: NewBaseConverter(
+			[]string{".json", ".jsonl", ".ndjson"},
+			[]string{"application/json", "application/x-ndjson"},
+		),
+	}
+}
+
+// Capabilities returns what the converter can extract from JSON files.
+func (c *JSONConverter) Capabilities() Capabilities {
+	return Capabilities{Tables: true}
+}
+
+// Load reads a JSON or JSON Lines file and converts it to markdown.
+func (c *JSONConverter) Load(path string) (string, error) {
+	return c.LoadContext(context.Background(), path)
+}
+
+// LoadContext converts a JSON file like Load, stopping before the next value
+// of JSON Lines files once ctx is done.
+func (c *JSONConverter) LoadContext(ctx context.Context, path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("unable to read file %s: %w", path, err)
+	}
+	return c.convert(ctx, data, path)
+}
+
+// LoadBytes converts JSON data held in memory like LoadContext.
+func (c *JSONConverter) LoadBytes(ctx context.Context, data []byte) (string, error) {
+	return c.convert(ctx, data, memoryName)
+}
+
+// convert converts JSON data, naming it name in errors.
+func (c *JSONConverter) convert(ctx context.Context, data []byte, name string) (string, error) {
+	values, err := decodeJSONValues(ctx, data)
+	if err != nil {
+		return "", fmt.Errorf("unable to parse JSON file %s: %w", name, corrupt(err))
+	}
+	if len(values) == 0 {
+		return "", nil
+	}
+
+	records := values
+	if len(values) == 1 {
+		records = nil
+		if err := json.Unmarshal(values[0], &records); err != nil {
+			records = nil
+		}
+	}
+	if table, ok := c.recordTable(records); ok {
+		return table, nil
+	}
+
+	var buf bytes.Buffer
+	if len(values) == 1 {
+		if err := json.Indent(&buf, values[0], "", "  "); err != nil {
+			return "", fmt.Errorf("unable to parse JSON file %s: %w", name, corrupt(err))
+		}
+	} else {
+		for n, value := range values {
+			if n > 0 {
+				buf.WriteByte('\n')
+			}
+			if err := json.Compact(&buf, value); err != nil {
+				return "", fmt.Errorf("unable to parse JSON file %s: %w", name, corrupt(err))
+			}
+		}
+	}
+	return codeFence("json", buf.String()) + "\n", nil
+}
+
+// recordTable writes records as a markdown table with a column per key, in
+// the order the keys first appear. It returns false when there are no
+// records or one is not an object of scalar values.
+func (c *JSONConverter) recordTable(records []json.RawMessage) (string, bool) {
+	if len(records) == 0 {
+		return "", false
+	}
+	var columns []string
+	index := make(map[string]int)
+	rows := make([]map[string]string, 0, len(records))
+	for _, record := range records {
+		fields, keys, ok := scalarFields(record)
+		if !ok {
+			return "", false
+		}
+		for _, key := range keys {
+			if _, ok := index[key]; !ok {
+				index[key] = len(columns)
+				columns = append(columns, key)
+			}
+		}
+		rows = append(rows, fields)
+	}
+	if len(columns) == 0 {
+		return "", false
+	}
+
+	var buf strings.Builder
+	table := c.Tables.NewTableWriter(&buf)
+	table.WriteRow(columns)
+	for _, fields := range rows {
+		row := make([]string, len(columns))
+		for n, column := range columns {
+			row[n] = fields[column]
+		}
+		table.WriteRow(row)
+	}
+	table.Flush()
+	return buf.String(), true
+}
+
+// decodeJSONValues decodes the values of a JSON document, or of the lines
+// of a JSON Lines file, stopping once ctx is done.
+func decodeJSONValues(ctx context.Context, data []byte) ([]json.RawMessage, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	var values []json.RawMessage
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		var value json.RawMessage
+		err := dec.Decode(&value)
+		if errors.Is(err, io.EOF) {
+			return values, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+}
+
+// scalarFields returns the values of the fields of a JSON object, keyed by
+// name, and the names in order. It returns false when the value is not an
+// object or a field holds an object or an array.
+func scalarFields(raw json.RawMessage) (map[string]string, []string, bool) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, nil, false
+	}
+	fields := make(map[string]string)
+	var keys []string
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return nil, nil, false
+		}
+		value, err := dec.Token()
+		if err != nil {
+			return nil, nil, false
+		}
+		var cell string
+		switch value := value.(type) {
+		case json.Delim:
+			return nil, nil, false
+		case string:
+			cell = value
+		case json.Number:
+			cell = value.String()
+		case bool:
+			cell = fmt.Sprint(value)
+		}
+		name := key.(string)
+		if _, ok := fields[name]; !ok {
+			keys = append(keys, name)
+		}
+		fields[name] = cell
+	}
+	return fields, keys, true
+}
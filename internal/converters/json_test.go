@@ -0,0 +1,109 @@
+package converters
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestNewJSONConverter(t *testing.T) {
+	converter := NewJSONConverter()
+
+	expectedExtensions := []string{".json", ".jsonl", ".ndjson"}
+	expectedMimeTypes := []string{"application/json", "application/x-ndjson"}
+
+	if !reflect.DeepEqual(converter.AcceptedExtensions(), expectedExtensions) {
+		t.Errorf("NewJSONConverter() extensions = %v, want %v", converter.AcceptedExtensions(), expectedExtensions)
+	}
+
+	if !reflect.DeepEqual(converter.AcceptedMimeTypes(), expectedMimeTypes) {
+		t.Errorf("NewJSONConverter() mimeTypes = %v, want %v", converter.AcceptedMimeTypes(), expectedMimeTypes)
+	}
+}
+
+func TestJSONConverter_Load(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		expected string
+	}{
+		{
+			name:     "array of records",
+			file:     "people.json",
+			content:  `[{"name": "Ada", "age": 36, "admin": true}, {"name": "Alan", "email": null, "age": 41.5}]`,
+			expected: "| name | age | admin | email |\n| --- | --- | --- | --- |\n| Ada | 36 | true |  |\n| Alan | 41.5 |  |  |\n",
+		},
+		{
+			name:     "json lines records",
+			file:     "events.jsonl",
+			content:  "{\"id\": 1, \"event\": \"start\"}\n\n{\"id\": 2, \"event\": \"a|b\"}\n",
+			expected: "| id | event |\n| --- | --- |\n| 1 | start |\n| 2 | a\\|b |\n",
+		},
+		{
+			name:     "nested object",
+			file:     "config.json",
+			content:  `{"server": {"port": 8080}, "tags": ["a", "b"]}`,
+			expected: "```json\n{\n  \"server\": {\n    \"port\": 8080\n  },\n  \"tags\": [\n    \"a\",\n    \"b\"\n  ]\n}\n```\n",
+		},
+		{
+			name:     "records with nested values",
+			file:     "nested.ndjson",
+			content:  "{\"id\": 1, \"tags\": [\"a\"]}\n{\"id\": 2}\n",
+			expected: "```json\n{\"id\":1,\"tags\":[\"a\"]}\n{\"id\":2}\n```\n",
+		},
+		{
+			name:     "array of scalars",
+			file:     "numbers.json",
+			content:  `[1, 2]`,
+			expected: "```json\n[\n  1,\n  2\n]\n```\n",
+		},
+		{
+			name:     "empty file",
+			file:     "empty.json",
+			content:  "",
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+
+			result, err := NewJSONConverter().Load(path)
+			if err != nil {
+				t.Fatalf("Load() returned unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Load() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestJSONConverter_Load_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "broken.json")
+	if err := os.WriteFile(path, []byte(`{"name": "Ada",`), 0o644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	if _, err := NewJSONConverter().Load(path); !errors.Is(err, ErrCorruptDocument) {
+		t.Errorf("Load() error = %v, want ErrCorruptDocument", err)
+	}
+}
+
+func TestJSONConverter_LoadBytes_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	c := NewJSONConverter().(*JSONConverter)
+	if _, err := c.LoadBytes(ctx, []byte("{\"id\": 1}\n{\"id\": 2}\n")); !errors.Is(err, context.Canceled) {
+		t.Errorf("LoadBytes() error = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,21 @@
+package converters
+
+import "log/slog"
+
+// LoggingConverter is implemented by converters reporting problems that do
+// not fail conversions, such as files that could not be closed. They are
+// logged with the logger given to SetLogger, such as the logger of the
+// Marky the converter is registered with, or with slog.Default when none
+// is set.
+type LoggingConverter interface {
+	Converter
+	SetLogger(logger *slog.Logger)
+}
+
+// loggerOrDefault returns logger, or slog.Default when it is nil.
+func loggerOrDefault(logger *slog.Logger) *slog.Logger {
+	if logger == nil {
+		return slog.Default()
+	}
+	return logger
+}
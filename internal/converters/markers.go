@@ -0,0 +1,35 @@
+package converters
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Marker templates for the slides of presentations, the pages of PDF files
+// and the sheets of workbooks. In a template, {n} stands for the 1-based
+// number of the slide, page or sheet, and {name} for the name of the sheet,
+// or "Slide N" and "Page N". Any other template, such as
+// "<!-- Slide {n} of deck -->", is written as it is after the substitution.
+const (
+	// MarkerHeading starts each part with a level-two heading.
+	MarkerHeading = "## {name}"
+
+	// MarkerRule separates the parts with a horizontal rule.
+	MarkerRule = "---"
+
+	// MarkerComment starts each part with an HTML comment, hidden when the
+	// markdown is rendered.
+	MarkerComment = "<!-- {name} -->"
+)
+
+// Default markers of the converters.
+const (
+	defaultSlideMarker = "<!-- Slide number: {n} -->"
+	defaultPageMarker  = "<!-- Page {n} -->"
+	defaultSheetMarker = MarkerHeading
+)
+
+// expandMarker returns the marker of a part from its template.
+func expandMarker(template string, number int, name string) string {
+	return strings.NewReplacer("{n}", strconv.Itoa(number), "{name}", name).Replace(template)
+}
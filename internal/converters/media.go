@@ -0,0 +1,95 @@
+package converters
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// mp4Duration reads the presentation duration from the movie header (mvhd)
+// of an ISO base media file (MP4, M4A, MOV). Boxes other than moov are
+// skipped without being read into memory.
+func mp4Duration(r io.Reader) (time.Duration, error) {
+	box, size, err := findBox(r, "moov", -1)
+	if err != nil {
+		return 0, err
+	}
+	box, size, err = findBox(box, "mvhd", size)
+	if err != nil {
+		return 0, err
+	}
+	if size < 4 {
+		return 0, errors.New("truncated mvhd box")
+	}
+
+	header := make([]byte, min(size, 32))
+	if _, err := io.ReadFull(box, header); err != nil {
+		return 0, fmt.Errorf("unable to read mvhd box: %w", err)
+	}
+
+	var timescale uint32
+	var duration uint64
+	switch {
+	case header[0] == 1 && len(header) >= 32:
+		timescale = binary.BigEndian.Uint32(header[20:24])
+		duration = binary.BigEndian.Uint64(header[24:32])
+	case header[0] == 0 && len(header) >= 20:
+		timescale = binary.BigEndian.Uint32(header[12:16])
+		duration = uint64(binary.BigEndian.Uint32(header[16:20]))
+	default:
+		return 0, errors.New("unsupported mvhd box")
+	}
+	if timescale == 0 {
+		return 0, errors.New("invalid mvhd timescale")
+	}
+
+	return time.Duration(duration) * time.Second / time.Duration(timescale), nil
+}
+
+// findBox scans sibling boxes for the given type and returns a reader limited
+// to its payload. A limit below zero scans until the end of the reader.
+func findBox(r io.Reader, boxType string, limit int64) (io.Reader, int64, error) {
+	header := make([]byte, 8)
+	for limit < 0 || limit >= 8 {
+		if _, err := io.ReadFull(r, header); err != nil {
+			return nil, 0, fmt.Errorf("box %s not found: %w", boxType, err)
+		}
+
+		size := int64(binary.BigEndian.Uint32(header[:4]))
+		typ := string(header[4:8])
+		headerSize := int64(8)
+		if size == 1 {
+			if _, err := io.ReadFull(r, header); err != nil {
+				return nil, 0, fmt.Errorf("unable to read box size: %w", err)
+			}
+			size = int64(binary.BigEndian.Uint64(header))
+			headerSize = 16
+		}
+		if size < headerSize {
+			return nil, 0, errors.New("invalid box size")
+		}
+
+		if typ == boxType {
+			return io.LimitReader(r, size-headerSize), size - headerSize, nil
+		}
+		if _, err := io.CopyN(io.Discard, r, size-headerSize); err != nil {
+			return nil, 0, fmt.Errorf("box %s not found: %w", boxType, err)
+		}
+		if limit >= 0 {
+			limit -= size
+		}
+	}
+	return nil, 0, fmt.Errorf("box %s not found", boxType)
+}
+
+// formatDuration formats a media duration as m:ss or h:mm:ss.
+func formatDuration(d time.Duration) string {
+	total := int(d.Round(time.Second) / time.Second)
+	h, m, s := total/3600, total/60%60, total%60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, s)
+	}
+	return fmt.Sprintf("%d:%02d", m, s)
+}
@@ -0,0 +1,61 @@
+package converters
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+// testBox encodes an ISO base media box with the given type and payload.
+func testBox(boxType string, payload []byte) []byte {
+	box := make([]byte, 8, 8+len(payload))
+	binary.BigEndian.PutUint32(box, uint32(8+len(payload)))
+	copy(box[4:], boxType)
+	return append(box, payload...)
+}
+
+// testMP4 builds a minimal MP4 file whose movie header declares the duration.
+func testMP4(timescale, duration uint32) []byte {
+	mvhd := make([]byte, 20)
+	binary.BigEndian.PutUint32(mvhd[12:], timescale)
+	binary.BigEndian.PutUint32(mvhd[16:], duration)
+
+	var buf bytes.Buffer
+	buf.Write(testBox("ftyp", []byte("isom\x00\x00\x02\x00")))
+	buf.Write(testBox("free", make([]byte, 16)))
+	buf.Write(testBox("moov", append(testBox("udta", nil), testBox("mvhd", mvhd)...)))
+	return buf.Bytes()
+}
+
+func TestMp4Duration(t *testing.T) {
+	d, err := mp4Duration(bytes.NewReader(testMP4(1000, 83500)))
+	if err != nil {
+		t.Fatalf("mp4Duration() returned unexpected error: %v", err)
+	}
+	if d != 83500*time.Millisecond {
+		t.Errorf("mp4Duration() = %v, want %v", d, 83500*time.Millisecond)
+	}
+}
+
+func TestMp4Duration_NotMP4(t *testing.T) {
+	if _, err := mp4Duration(bytes.NewReader([]byte("ID3\x03\x00 not an mp4 file"))); err == nil {
+		t.Error("mp4Duration() expected error for non-MP4 data")
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		in   time.Duration
+		want string
+	}{
+		{12 * time.Second, "0:12"},
+		{83500 * time.Millisecond, "1:24"},
+		{time.Hour + 2*time.Minute + 3*time.Second, "1:02:03"},
+	}
+	for _, tt := range tests {
+		if got := formatDuration(tt.in); got != tt.want {
+			t.Errorf("formatDuration(%v) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package converters
+
+import (
+	"archive/zip"
+	"fmt"
+	"strings"
+)
+
+// DocumentMetadata holds the descriptive properties of a document. Dates
+// are ISO 8601 strings as the document records them.
+type DocumentMetadata struct {
+	Title    string
+	Author   string
+	Created  string
+	Modified string
+}
+
+// MetadataReader is implemented by converters that read the descriptive
+// properties of documents, such as the core properties of office packages,
+// for the front matter of converted documents.
+type MetadataReader interface {
+	Converter
+	ReadMetadata(path string) (DocumentMetadata, error)
+}
+
+// coreProperties is the docProps/core.xml part of DOCX, XLSX and PPTX
+// packages.
+type coreProperties struct {
+	Title    string `xml:"title"`
+	Creator  string `xml:"creator"`
+	Created  string `xml:"created"`
+	Modified string `xml:"modified"`
+}
+
+// readCoreProperties reads the core properties of an office package. A
+// package without them has no metadata.
+func readCoreProperties(r *zip.Reader) (DocumentMetadata, error) {
+	file, err := findFileInZip(r, "docProps/core.xml")
+	if err != nil {
+		return DocumentMetadata{}, nil
+	}
+
+	var props coreProperties
+	if err := parseXMLFile(file, &props); err != nil {
+		return DocumentMetadata{}, fmt.Errorf("failed to parse core properties: %w", corrupt(err))
+	}
+	return DocumentMetadata{
+		Title:    strings.TrimSpace(props.Title),
+		Author:   strings.TrimSpace(props.Creator),
+		Created:  strings.TrimSpace(props.Created),
+		Modified: strings.TrimSpace(props.Modified),
+	}, nil
+}
+
+// readPackageMetadata reads the core properties of a DOCX, XLSX or PPTX
+// file, decrypting it with password when it is encrypted.
+func readPackageMetadata(path, password string, threshold int64, limits ArchiveLimits) (DocumentMetadata, error) {
+	r, err := openPackage(path, password, threshold, limits)
+	if err != nil {
+		return DocumentMetadata{}, fmt.Errorf("failed to open %s: %w", path, corrupt(err))
+	}
+	defer r.Close()
+	return readCoreProperties(r.Reader)
+}
+
+// ReadMetadata reads the core properties of a DOCX file.
+func (d *DocConverter) ReadMetadata(path string) (DocumentMetadata, error) {
+	return readPackageMetadata(path, d.Options.Password, d.Options.MmapThreshold, d.Options.ArchiveLimits)
+}
+
+// ReadMetadata reads the core properties of a PPTX file.
+func (p *PptxConverter) ReadMetadata(path string) (DocumentMetadata, error) {
+	return readPackageMetadata(path, p.Options.Password, p.Options.MmapThreshold, p.Options.ArchiveLimits)
+}
+
+// ReadMetadata reads the core properties of an XLSX file.
+func (e *ExcelConverter) ReadMetadata(path string) (DocumentMetadata, error) {
+	return readPackageMetadata(path, e.Password, e.MmapThreshold, e.ArchiveLimits)
+}
+
+// ReadMetadata reads the document information dictionary and XMP metadata
+// of a PDF file.
+func (c *PdfConverter) ReadMetadata(path string) (DocumentMetadata, error) {
+	closer, r, err := openPdf(path, c.Options.Password, c.Options.MmapThreshold)
+	if err != nil {
+		return DocumentMetadata{}, err
+	}
+	defer closer.Close()
+
+	meta := readMetadata(r)
+	return DocumentMetadata{
+		Title:    meta.Title,
+		Author:   meta.Author,
+		Created:  meta.Created,
+		Modified: meta.Modified,
+	}, nil
+}
+
+// ReadMetadata reads the Dublin Core metadata of the package document of an
+// EPUB file.
+func (c *EpubConverter) ReadMetadata(path string) (DocumentMetadata, error) {
+	reader, err := openArchive(path, c.Options.ArchiveLimits)
+	if err != nil {
+		return DocumentMetadata{}, fmt.Errorf("failed to open EPUB file: %w", corrupt(err))
+	}
+	defer reader.Close()
+
+	pkg, _, err := readPackage(&reader.Reader)
+	if err != nil {
+		return DocumentMetadata{}, err
+	}
+	var authors []string
+	for _, creator := range pkg.Metadata.Creator {
+		if creator = strings.TrimSpace(creator); creator != "" {
+			authors = append(authors, creator)
+		}
+	}
+	return DocumentMetadata{
+		Title:   firstNonEmpty(pkg.Metadata.Title),
+		Author:  strings.Join(authors, ", "),
+		Created: strings.TrimSpace(pkg.Metadata.Date),
+	}, nil
+}
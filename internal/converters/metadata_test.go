@@ -0,0 +1,59 @@
+package converters
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestReadMetadata(t *testing.T) {
+	for _, tt := range []struct {
+		file      string
+		converter Converter
+		want      DocumentMetadata
+	}{
+		{"test.docx", NewDocConverter(), DocumentMetadata{
+			Author:   "Adam Fourney",
+			Created:  "2024-03-15T05:45:00Z",
+			Modified: "2024-03-15T05:53:00Z",
+		}},
+		{"test.xlsx", NewExcelConverter(), DocumentMetadata{
+			Author:   "Adam Fourney",
+			Created:  "2015-06-05T18:17:20Z",
+			Modified: "2024-03-15T05:31:25Z",
+		}},
+		{"test.pptx", NewPptxConverter(), DocumentMetadata{
+			Title:    "AutoGen: Enabling Next-Gen LLM Applications via Multi-Agent Conversation",
+			Author:   "Adam Fourney",
+			Created:  "2024-03-15T05:57:54Z",
+			Modified: "2024-12-15T04:17:48Z",
+		}},
+	} {
+		t.Run(tt.file, func(t *testing.T) {
+			got, err := tt.converter.(MetadataReader).ReadMetadata(filepath.Join("..", "..", "test_files", tt.file))
+			if err != nil {
+				t.Fatalf("ReadMetadata() returned unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ReadMetadata() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadMetadata_EPUBAndPDF(t *testing.T) {
+	for _, tt := range []struct {
+		file      string
+		converter Converter
+	}{
+		{"test.epub", NewEpubConverter()},
+		{"test.pdf", NewPdfConverter()},
+	} {
+		if _, err := tt.converter.(MetadataReader).ReadMetadata(filepath.Join("..", "..", "test_files", tt.file)); err != nil {
+			t.Errorf("ReadMetadata(%s) returned unexpected error: %v", tt.file, err)
+		}
+	}
+
+	if _, err := NewDocConverter().(MetadataReader).ReadMetadata("missing.docx"); err == nil {
+		t.Error("ReadMetadata() of a missing file returned no error")
+	}
+}
@@ -0,0 +1,65 @@
+package converters
+
+import (
+	"cmp"
+	"os"
+)
+
+// defaultMmapThreshold is the size in bytes from which files are mapped
+// into memory when the MmapThreshold option is zero.
+const defaultMmapThreshold = 64 << 20
+
+// mappedFile is the content of a file read by readFile. Data must not be
+// used once the file is closed, nor written to.
+type mappedFile struct {
+	Data  []byte
+	unmap func() error
+}
+
+// Close unmaps the file when it was mapped.
+func (f *mappedFile) Close() error {
+	if f.unmap == nil {
+		return nil
+	}
+	unmap := f.unmap
+	f.Data, f.unmap = nil, nil
+	return unmap()
+}
+
+// readFile reads a file, mapping it into memory when it is at least
+// threshold bytes long, as set by the MmapThreshold option.
+func readFile(path string, threshold int64) (*mappedFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	if m := mapLarge(f, info.Size(), threshold); m != nil {
+		return m, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return &mappedFile{Data: data}, nil
+}
+
+// mapLarge maps a file of size bytes into memory when it is at least
+// threshold bytes long, 64 MiB when zero. It returns nil for smaller files,
+// when threshold is negative and when the file cannot be mapped.
+func mapLarge(f *os.File, size, threshold int64) *mappedFile {
+	threshold = cmp.Or(threshold, defaultMmapThreshold)
+	if threshold < 0 || size < threshold {
+		return nil
+	}
+	data, unmap, err := mapFile(f, size)
+	if err != nil {
+		return nil
+	}
+	return &mappedFile{Data: data, unmap: unmap}
+}
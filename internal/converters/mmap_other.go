@@ -0,0 +1,13 @@
+//go:build !unix
+
+package converters
+
+import (
+	"errors"
+	"os"
+)
+
+// mapFile fails on platforms without mmap, where files are read instead.
+func mapFile(*os.File, int64) ([]byte, func() error, error) {
+	return nil, nil, errors.ErrUnsupported
+}
@@ -0,0 +1,65 @@
+package converters
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadFile(t *testing.T) {
+	path := filepath.Join("..", "..", "test_files", "test.pdf")
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read test file: %v", err)
+	}
+
+	for _, threshold := range []int64{-1, 1} {
+		file, err := readFile(path, threshold)
+		if err != nil {
+			t.Fatalf("readFile() with threshold %d returned unexpected error: %v", threshold, err)
+		}
+		if string(file.Data) != string(want) {
+			t.Errorf("readFile() with threshold %d differs from the file", threshold)
+		}
+		if err := file.Close(); err != nil {
+			t.Errorf("Close() with threshold %d returned unexpected error: %v", threshold, err)
+		}
+	}
+}
+
+func TestConverters_Load_Mmap(t *testing.T) {
+	tests := []struct {
+		file       string
+		newConvert func() Converter
+	}{
+		{"test.pdf", NewPdfConverter},
+		{"test.pptx", NewPptxConverter},
+		{"test.docx", NewDocConverter},
+		{"test.xlsx", NewExcelConverter},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			path := filepath.Join("..", "..", "test_files", tt.file)
+			read, mapped := tt.newConvert(), tt.newConvert()
+			ConvertOptions{MmapThreshold: -1}.Configure(read)
+			ConvertOptions{MmapThreshold: 1}.Configure(mapped)
+			want, err := read.Load(path)
+			if err != nil {
+				t.Fatalf("Load() returned unexpected error: %v", err)
+			}
+			got, err := mapped.Load(path)
+			if err != nil {
+				t.Fatalf("Load() of a mapped file returned unexpected error: %v", err)
+			}
+			if got != want {
+				t.Error("Load() of a mapped file differs from Load() of a read file")
+			}
+		})
+	}
+
+	path := writeEncryptedFile(t, filepath.Join("..", "..", "test_files", "test.docx"), "secret")
+	if _, err := (&DocConverter{Options: ConvertOptions{Password: "secret", MmapThreshold: 1}}).Load(path); err != nil {
+		t.Errorf("Load() of a mapped encrypted package returned unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,21 @@
+//go:build unix
+
+package converters
+
+import (
+	"os"
+	"syscall"
+)
+
+// mapFile maps size bytes of a file into memory, read-only. The mapping
+// outlives the file and is removed by unmap.
+func mapFile(f *os.File, size int64) (data []byte, unmap func() error, err error) {
+	if int64(int(size)) != size {
+		return nil, nil, syscall.EFBIG
+	}
+	data, err = syscall.Mmap(int(f.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, func() error { return syscall.Munmap(data) }, nil
+}
@@ -1,5 +1,7 @@
 package converters
 
+import "github.com/flaviodelgrosso/marky/internal/utils"
+
 // Converter defines the interface for document converters.
 // It combines metadata about accepted formats with the conversion capability.
 type Converter interface {
@@ -12,6 +14,33 @@ type Converter interface {
 	// Load converts a document at the given path to markdown format.
 	// Returns the markdown content and an error if the operation fails.
 	Load(path string) (string, error)
+
+	// Capabilities returns what the converter can extract from documents.
+	Capabilities() Capabilities
+}
+
+// Capabilities describes what a converter can do, so that callers can
+// choose options and set expectations before converting a document.
+type Capabilities struct {
+	// Images reports whether the images of documents are written, subject
+	// to the image policy.
+	Images bool `json:"images"`
+
+	// Tables reports whether the tables of documents are written as
+	// markdown tables.
+	Tables bool `json:"tables"`
+
+	// Metadata reports whether document properties, such as the title and
+	// author, are written.
+	Metadata bool `json:"metadata"`
+
+	// Streaming reports whether documents are read incrementally rather
+	// than loaded into memory as a whole.
+	Streaming bool `json:"streaming"`
+
+	// Password reports whether encrypted documents can be opened with a
+	// password.
+	Password bool `json:"password"`
 }
 
 // BaseConverter provides a foundation for implementing converters.
@@ -38,3 +67,143 @@ func (b BaseConverter) AcceptedExtensions() []string {
 func (b BaseConverter) AcceptedMimeTypes() []string {
 	return b.acceptedMimeTypes
 }
+
+// Capabilities returns no capabilities. Converters embedding BaseConverter
+// override it with what they support.
+func (b BaseConverter) Capabilities() Capabilities {
+	return Capabilities{}
+}
+
+// ConvertOptions holds configuration for the conversion, shared by the
+// converters of paged formats. Each converter reads the options that apply
+// to its format.
+type ConvertOptions struct {
+	KeepDataURIs bool
+
+	// Slides selects the slides to convert, e.g. "1-10,15". Empty converts all slides.
+	Slides string
+
+	// Pages selects the PDF pages to convert, e.g. "1-5,12". Empty converts all pages.
+	Pages string
+
+	// PageMarkers writes a <!-- Page N --> comment before the text of each
+	// PDF page, so that citations can refer to page numbers.
+	PageMarkers bool
+
+	// Marker is the template of the markers written before each slide and
+	// PDF page, such as MarkerHeading or MarkerRule. Slides are marked with
+	// a <!-- Slide number: N --> comment when empty, and PDF pages with the
+	// PageMarkers comment. PDF pages are marked when Marker is set even
+	// without PageMarkers.
+	Marker string
+
+	// Provenance writes a <!-- source: page N --> comment before the text of
+	// each PDF page and a <!-- source: slide N --> comment before each slide,
+	// so that answers drawn from the markdown can cite the source document.
+	Provenance bool
+
+	// Chapters selects the EPUB content documents to convert in reading
+	// order, e.g. "1-3,7". Empty converts the whole book.
+	Chapters string
+
+	// Password opens encrypted PDF, DOCX and PPTX files. PDF files
+	// encrypted with an empty user password open without one.
+	Password string
+
+	// Partial keeps converting PDF files past the pages that cannot be
+	// read, leaving them out with a warning in the report instead of
+	// failing the whole conversion.
+	Partial bool
+
+	// Images controls how referenced images are written, ImagesLink by
+	// default.
+	Images ImagePolicy
+
+	// ImageDir is the directory ImagesDownload writes image files to, the
+	// current directory when empty. Links to the files are relative when it
+	// is.
+	ImageDir string
+
+	// InMemory disables every write to disk, for read-only filesystems
+	// such as those of serverless functions. Conversions needing a file
+	// fail with ErrDiskWrite instead: downloading images and running the
+	// mutool engine or OCR. Without it, converters still write only where
+	// asked to: ImagesDownload writes to ImageDir, and the mutool engine
+	// uses temporary directories removed after the conversion.
+	InMemory bool
+
+	// MmapThreshold is the size in bytes from which PDF files and office
+	// packages are mapped into memory rather than read, so that their pages
+	// are loaded as they are used and shared with the page cache instead of
+	// copied onto the heap. It is 64 MiB when zero, and files are always
+	// read when it is negative, as on platforms without mmap. Mapped files
+	// must not be truncated while they are converted.
+	MmapThreshold int64
+
+	// ArchiveLimits bounds the ZIP archives behind DOCX, PPTX, XLSX and
+	// EPUB files and ZIP archives, checked before they are read. The limits
+	// left zero are the defaults.
+	ArchiveLimits ArchiveLimits
+
+	// Tables sets the style, the HTML fallback and the cell width of the
+	// tables written by the converters, TableCompact tables without
+	// fallback or cut cells by default.
+	Tables utils.TableOptions
+
+	// Set lists the fields applied by Merge even when they are zero, such
+	// as FieldInMemory to turn InMemory off or FieldImages to go back to
+	// ImagesLink. Merge applies the other fields only when they are set.
+	Set OptionField
+}
+
+// OptionField names fields of ConvertOptions, combined with |.
+type OptionField uint
+
+// Fields of ConvertOptions.
+const (
+	FieldKeepDataURIs OptionField = 1 << iota
+	FieldSlides
+	FieldPages
+	FieldPageMarkers
+	FieldMarker
+	FieldProvenance
+	FieldChapters
+	FieldPassword
+	FieldPartial
+	FieldImages
+	FieldImageDir
+	FieldInMemory
+	FieldMmapThreshold
+	FieldArchiveLimits
+	FieldTables
+)
+
+// ImagePolicy controls how converters handle the images a document refers to.
+type ImagePolicy int
+
+const (
+	// ImagesLink keeps links to the images, made absolute for remote documents.
+	ImagesLink ImagePolicy = iota
+
+	// ImagesInline embeds the images as data URIs.
+	ImagesInline
+
+	// ImagesDownload writes the images to ImageDir and links to the files.
+	ImagesDownload
+
+	// ImagesDrop leaves the images out.
+	ImagesDrop
+)
+
+// Flavor selects the markdown dialect used for formatting that has no
+// CommonMark equivalent, such as underline or highlight.
+type Flavor = utils.Flavor
+
+const (
+	// FlavorGFM renders unsupported inline formatting as inline HTML tags.
+	FlavorGFM = utils.FlavorGFM
+
+	// FlavorExtended uses the extended inline syntax understood by Pandoc and
+	// common markdown-it plugins (==mark==, ^sup^, ~sub~).
+	FlavorExtended = utils.FlavorExtended
+)
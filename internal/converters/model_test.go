@@ -1,6 +1,9 @@
 package converters
 
 import (
+	"archive/zip"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 )
@@ -120,3 +123,97 @@ func TestConverterInterface(t *testing.T) {
 		t.Errorf("Load() = %v, want %v", result, expected)
 	}
 }
+
+func TestConverter_Capabilities(t *testing.T) {
+	if caps := NewBaseConverter(nil, nil).Capabilities(); caps != (Capabilities{}) {
+		t.Errorf("BaseConverter Capabilities() = %+v, want none", caps)
+	}
+
+	tests := []struct {
+		converter Converter
+		expected  Capabilities
+	}{
+		{NewCsvConverter(), Capabilities{Tables: true, Streaming: true}},
+		{NewExcelConverter(), Capabilities{Tables: true, Streaming: true, Password: true}},
+		{NewPdfConverter(), Capabilities{Metadata: true, Password: true}},
+		{NewHTMLConverter(), Capabilities{Images: true, Tables: true, Metadata: true}},
+	}
+
+	for _, tt := range tests {
+		if caps := tt.converter.Capabilities(); caps != tt.expected {
+			t.Errorf("%T Capabilities() = %+v, want %+v", tt.converter, caps, tt.expected)
+		}
+	}
+}
+
+// writeTestArchive creates a ZIP archive with the given name and file contents
+// in a temporary directory and returns its path.
+func writeTestArchive(t *testing.T, name string, files map[string]string) string {
+	t.Helper()
+
+	archive := filepath.Join(t.TempDir(), name)
+	f, err := os.Create(archive)
+	if err != nil {
+		t.Fatalf("Failed to create test archive: %v", err)
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	for fileName, content := range files {
+		w, err := zw.Create(fileName)
+		if err != nil {
+			t.Fatalf("Failed to create %s in test archive: %v", fileName, err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatalf("Failed to write %s in test archive: %v", fileName, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("Failed to finalize test archive: %v", err)
+	}
+
+	return archive
+}
+
+// writeCorruptFile copies a test file into a temporary directory with one of
+// its parts cut short, so that the XML of the part cannot be parsed, and
+// returns the path of the copy.
+func writeCorruptFile(t *testing.T, source, part string) string {
+	t.Helper()
+
+	r, err := zip.OpenReader(source)
+	if err != nil {
+		t.Fatalf("Failed to open test file: %v", err)
+	}
+	defer r.Close()
+
+	path := filepath.Join(t.TempDir(), filepath.Base(source))
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create corrupt test file: %v", err)
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	for _, file := range r.File {
+		data, err := readZipFile(file)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", file.Name, err)
+		}
+		if file.Name == part {
+			data = data[:len(data)/2]
+		}
+		w, err := zw.Create(file.Name)
+		if err != nil {
+			t.Fatalf("Failed to create %s in corrupt test file: %v", file.Name, err)
+		}
+		if _, err := w.Write(data); err != nil {
+			t.Fatalf("Failed to write %s in corrupt test file: %v", file.Name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("Failed to finalize corrupt test file: %v", err)
+	}
+
+	return path
+}
@@ -0,0 +1,135 @@
+package converters
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// IsObjectURI reports whether a path names an object in a cloud store:
+// s3://bucket/key, gs://bucket/object or az://account/container/blob.
+func IsObjectURI(path string) bool {
+	u, err := url.Parse(path)
+	if err != nil || u.Host == "" || strings.Trim(u.Path, "/") == "" {
+		return false
+	}
+	switch u.Scheme {
+	case "s3", "gs":
+		return true
+	case "az":
+		_, blob, ok := strings.Cut(strings.TrimPrefix(u.Path, "/"), "/")
+		return ok && blob != ""
+	}
+	return false
+}
+
+// ObjectName returns the name of an object, the last segment of its key,
+// whose extension selects the converter of the object.
+func ObjectName(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return ""
+	}
+	return path.Base(u.Path)
+}
+
+// ObjectOpener opens the objects of a cloud store for reading, given their
+// URIs. Openers are chosen by the scheme of the URIs, so that programs read
+// private objects with the SDK of their store and its credentials, without
+// marky depending on every SDK.
+type ObjectOpener interface {
+	OpenObject(ctx context.Context, uri string) (io.ReadCloser, error)
+}
+
+// ObjectOpenerFunc is a function implementing ObjectOpener.
+type ObjectOpenerFunc func(ctx context.Context, uri string) (io.ReadCloser, error)
+
+// OpenObject calls f.
+func (f ObjectOpenerFunc) OpenObject(ctx context.Context, uri string) (io.ReadCloser, error) {
+	return f(ctx, uri)
+}
+
+// PublicObjects opens the publicly readable objects of Amazon S3, Google
+// Cloud Storage and Azure Blob Storage anonymously, over HTTPS. Private
+// objects need an ObjectOpener with credentials.
+type PublicObjects struct {
+	// Client sends the requests. A client with a 5 minute timeout is used
+	// when nil.
+	Client *http.Client
+
+	// Endpoint, when set, is the base URL objects are read from in path
+	// style, as Endpoint/bucket/key, such as that of an S3 compatible store
+	// or of an emulator. Azure blobs are read as Endpoint/account/container/blob.
+	Endpoint string
+}
+
+// OpenObject opens an object, streaming its body from the store as it is
+// read. The request is cancelled once ctx is done.
+func (p PublicObjects) OpenObject(ctx context.Context, uri string) (io.ReadCloser, error) {
+	target, err := p.objectURL(uri)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.client().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch %s: %w", uri, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status %s for %s", resp.Status, uri)
+	}
+	return resp.Body, nil
+}
+
+// client returns the HTTP client of the opener.
+func (p PublicObjects) client() *http.Client {
+	if p.Client != nil {
+		return p.Client
+	}
+	return &http.Client{Timeout: 5 * time.Minute}
+}
+
+// objectURL returns the HTTPS URL of an object.
+func (p PublicObjects) objectURL(uri string) (string, error) {
+	if !IsObjectURI(uri) {
+		return "", fmt.Errorf("invalid object URI: %s", uri)
+	}
+	u, _ := url.Parse(uri)
+	key := escapeKey(strings.TrimPrefix(u.Path, "/"))
+	if p.Endpoint != "" {
+		return strings.TrimSuffix(p.Endpoint, "/") + "/" + u.Host + "/" + key, nil
+	}
+	switch u.Scheme {
+	case "s3":
+		return fmt.Sprintf("https://%s.s3.amazonaws.com/%s", u.Host, key), nil
+	case "gs":
+		return fmt.Sprintf("https://storage.googleapis.com/%s/%s", u.Host, key), nil
+	default:
+		return fmt.Sprintf("https://%s.blob.core.windows.net/%s", u.Host, key), nil
+	}
+}
+
+// escapeKey escapes the segments of an object key for a URL path, leaving
+// only unreserved characters as they are.
+func escapeKey(key string) string {
+	var b strings.Builder
+	for _, c := range []byte(key) {
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', c == '/':
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,101 @@
+package converters
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIsObjectURI(t *testing.T) {
+	cases := map[string]bool{
+		"s3://bucket/reports/q1.pdf":         true,
+		"gs://bucket/notes.docx":             true,
+		"az://account/container/slides.pptx": true,
+		"az://account/container":             false,
+		"s3://bucket":                        false,
+		"s3:///key":                          false,
+		"https://example.com/page":           false,
+		"report.pdf":                         false,
+	}
+	for uri, expected := range cases {
+		if got := IsObjectURI(uri); got != expected {
+			t.Errorf("IsObjectURI(%q) = %v, want %v", uri, got, expected)
+		}
+	}
+}
+
+func TestPublicObjects_OpenObject(t *testing.T) {
+	var authorization, requested string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization, requested = r.Header.Get("Authorization"), r.URL.EscapedPath()
+		if strings.HasSuffix(r.URL.Path, "missing.csv") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("a,b\n1,2\n"))
+	}))
+	defer server.Close()
+
+	objects := PublicObjects{Client: server.Client(), Endpoint: server.URL}
+	tests := []struct {
+		uri, path string
+	}{
+		{"s3://bucket/2024 reports/data.csv", "/bucket/2024%20reports/data.csv"},
+		{"gs://bucket/data.csv", "/bucket/data.csv"},
+		{"az://devstoreaccount1/container/data.csv", "/devstoreaccount1/container/data.csv"},
+	}
+	for _, tt := range tests {
+		body, err := objects.OpenObject(context.Background(), tt.uri)
+		if err != nil {
+			t.Fatalf("OpenObject(%q) returned unexpected error: %v", tt.uri, err)
+		}
+		data, err := io.ReadAll(body)
+		body.Close()
+		if err != nil || string(data) != "a,b\n1,2\n" {
+			t.Errorf("OpenObject(%q) read %q, %v", tt.uri, data, err)
+		}
+		if requested != tt.path || authorization != "" {
+			t.Errorf("OpenObject(%q) requested %s with %q, want %s anonymously", tt.uri, requested, authorization, tt.path)
+		}
+		if name := ObjectName(tt.uri); name != "data.csv" {
+			t.Errorf("ObjectName(%q) = %q, want data.csv", tt.uri, name)
+		}
+	}
+
+	if _, err := objects.OpenObject(context.Background(), "s3://bucket/missing.csv"); err == nil {
+		t.Error("OpenObject() should return error for a missing object")
+	}
+	if _, err := objects.OpenObject(context.Background(), "s3://bucket"); err == nil {
+		t.Error("OpenObject() should return error for an invalid URI")
+	}
+}
+
+func TestPublicObjects_Client(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	// The client of the caller bounds the requests
+	objects := PublicObjects{Client: &http.Client{Timeout: 10 * time.Millisecond}, Endpoint: server.URL}
+	if _, err := objects.OpenObject(context.Background(), "gs://bucket/data.csv"); err == nil {
+		t.Error("OpenObject() should return error once the client times out")
+	}
+}
+
+func TestPublicObjects_objectURL(t *testing.T) {
+	tests := map[string]string{
+		"s3://bucket/reports/q1.pdf":         "https://bucket.s3.amazonaws.com/reports/q1.pdf",
+		"gs://bucket/notes.docx":             "https://storage.googleapis.com/bucket/notes.docx",
+		"az://account/container/slides.pptx": "https://account.blob.core.windows.net/container/slides.pptx",
+	}
+	for uri, expected := range tests {
+		if got, err := (PublicObjects{}).objectURL(uri); err != nil || got != expected {
+			t.Errorf("objectURL(%q) = %q, %v, want %q", uri, got, err, expected)
+		}
+	}
+}
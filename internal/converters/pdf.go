@@ -2,14 +2,85 @@ package converters
 
 import (
 	"bytes"
+	"cmp"
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"unicode"
 
+	"github.com/flaviodelgrosso/marky/internal/utils"
 	"github.com/ledongthuc/pdf"
 )
 
-// PdfConverter handles loading and converting PDF files to text.
+// PdfConverter handles loading and converting PDF files to text. Text is
+// extracted from glyph positions and rebuilt into paragraphs, reading
+// multi-column pages column by column. Headings are inferred from font sizes
+// and weights, and taken from the document outline (bookmarks) when present.
+// The document metadata is written as YAML front matter, and the fields of
+// interactive forms as a table after the text.
 type PdfConverter struct {
 	BaseConverter
+
+	// Options configures the conversion: the page selection, page markers and
+	// password.
+	Options ConvertOptions
+
+	// SkipTOC leaves out the table of contents built from the outline, which
+	// is written before the text by default.
+	SkipTOC bool
+
+	// Comments includes text annotations, such as notes and highlights, as
+	// footnotes referenced after the text they annotate.
+	Comments bool
+
+	// SkipMetadata leaves out the front matter with the document title,
+	// author, subject, keywords and creation date.
+	SkipMetadata bool
+
+	// SkipFormFields leaves out the table of form field names and values.
+	SkipFormFields bool
+
+	// Engine, when set, extracts the text instead of the built-in reader,
+	// with the page selection, page markers and password of the options.
+	// The metadata, outline, links and form fields are only read by the
+	// built-in reader.
+	Engine PdfEngine
+
+	// OCR, when set, recognizes the text of pages without extractable text,
+	// such as scanned pages. It is called concurrently for different pages.
+	OCR OCREngine
+
+	// Workers bounds the number of pages extracted concurrently. Zero uses
+	// one worker per CPU.
+	Workers int
+
+	// Progress, when set, is called after each page extracted by the
+	// built-in reader.
+	Progress ProgressFunc
+}
+
+// OCREngine recognizes the text of a PDF page. Engines render the page to an
+// image and run OCR on it, for example with pdftoppm and tesseract. Engines
+// writing temporary files should fail with ErrDiskWrite when the InMemory
+// option is set.
+type OCREngine interface {
+	// RecognizePage returns the text of a page, given its 1-based number,
+	// opening encrypted files with the password of the options.
+	RecognizePage(path string, page int, options ConvertOptions) (OCRResult, error)
+}
+
+// OCRResult is the text recognized on a page.
+type OCRResult struct {
+	Text string
+
+	// Confidence is the mean confidence of the recognized text, from 0 to 1.
+	Confidence float64
 }
 
 // NewPdfConverter creates a new PDF converter with appropriate MIME types and extensions.
@@ -22,29 +93,536 @@ func NewPdfConverter() Converter {
 	}
 }
 
+// SetProgress sets the function called after each page.
+func (c *PdfConverter) SetProgress(progress ProgressFunc) {
+	c.Progress = progress
+}
+
+// Capabilities returns what the converter can extract from PDF files.
+func (c *PdfConverter) Capabilities() Capabilities {
+	return Capabilities{Metadata: true, Password: true}
+}
+
 // Load reads a PDF file and extracts its text content.
-func (*PdfConverter) Load(path string) (string, error) {
-	return readPdfFile(path)
+func (c *PdfConverter) Load(path string) (string, error) {
+	return c.LoadContext(context.Background(), path)
 }
 
-// readPdfFile reads and extracts text content from a PDF file.
-func readPdfFile(path string) (string, error) {
-	f, r, err := pdf.Open(path)
+// LoadContext converts a PDF file like Load, stopping before the next page
+// once ctx is done. Engines not implementing ContextEngine run until they
+// finish.
+func (c *PdfConverter) LoadContext(ctx context.Context, path string) (string, error) {
+	if c.Engine != nil {
+		return c.extract(ctx, path)
+	}
+	return c.readPdfFile(ctx, path, nil)
+}
+
+// LoadBytes converts a PDF document held in memory like Load. The document
+// is written to a temporary file for the Engine and the OCR engine, which
+// read files.
+func (c *PdfConverter) LoadBytes(ctx context.Context, data []byte) (string, error) {
+	if c.Engine != nil || c.OCR != nil {
+		if err := c.Options.checkDiskWrite(); err != nil {
+			return "", err
+		}
+		return loadTempFile(ctx, c, data)
+	}
+	r, err := newPdfReader(bytes.NewReader(data), int64(len(data)), memoryName, c.Options.Password)
 	if err != nil {
-		return "", fmt.Errorf("unable to open PDF file %s: %w", path, err)
+		return "", err
+	}
+	return c.readPdf(ctx, r, memoryName, nil)
+}
+
+// extract extracts the text of a PDF file with the Engine.
+func (c *PdfConverter) extract(ctx context.Context, path string) (string, error) {
+	if engine, ok := c.Engine.(ContextEngine); ok {
+		return engine.ExtractContext(ctx, path, c.Options)
+	}
+	return runContext(ctx, func() (string, error) {
+		return c.Engine.Extract(path, c.Options)
+	})
+}
+
+// LoadReport converts a PDF file like Load and reports its page count, with
+// a warning for each selected page without text, such as scanned pages when
+// OCR is not set. Pages are not checked for text when Engine is set.
+func (c *PdfConverter) LoadReport(ctx context.Context, path string) (string, *Report, error) {
+	report := &Report{}
+	if c.Engine == nil {
+		markdown, err := c.readPdfFile(ctx, path, report)
+		return markdown, report, err
+	}
+
+	markdown, err := c.extract(ctx, path)
+	if err != nil {
+		return "", nil, err
+	}
+	if f, r, err := openPdf(path, c.Options.Password, c.Options.MmapThreshold); err == nil {
+		report.Parts = r.NumPage()
+		f.Close()
+	}
+	return markdown, report, nil
+}
+
+// readPdfFile reads and extracts text content from the selected pages of a
+// PDF file, one paragraph per block separated by blank lines. The page
+// count and the pages without text are added to report when it is not nil.
+func (c *PdfConverter) readPdfFile(ctx context.Context, path string, report *Report) (string, error) {
+	f, r, err := openPdf(path, c.Options.Password, c.Options.MmapThreshold)
+	if err != nil {
+		return "", err
 	}
 	defer f.Close()
+	return c.readPdf(ctx, r, path, report)
+}
 
-	b, err := r.GetPlainText()
+// readPdf extracts the text content of the selected pages of an open PDF
+// document named path like readPdfFile.
+func (c *PdfConverter) readPdf(ctx context.Context, r *pdf.Reader, path string, report *Report) (string, error) {
+	selection, err := utils.ParseNumberRange(c.Options.Pages)
 	if err != nil {
-		return "", fmt.Errorf("unable to extract text from PDF file %s: %w", path, err)
+		return "", fmt.Errorf("invalid page selection: %w", err)
+	}
+
+	var pages []int
+	for i := 1; i <= r.NumPage(); i++ {
+		if selection.Contains(i) {
+			pages = append(pages, i)
+		}
 	}
 
-	var buf bytes.Buffer
-	_, err = buf.ReadFrom(b)
+	// Annotations are read in page order, so that footnotes are numbered in
+	// reading order.
+	var footnotes *[]string
+	if c.Comments {
+		footnotes = new([]string)
+	}
+	annotations := make([][]pdfAnnotation, len(pages))
+	for n, page := range pages {
+		annotations[n] = readAnnotations(r.Page(page), page, footnotes)
+	}
+
+	extracted, err := c.extractPages(ctx, r, path, pages, annotations, report)
 	if err != nil {
-		return "", fmt.Errorf("unable to read text content from PDF file %s: %w", path, err)
+		return "", err
+	}
+	var paragraphs []pdfParagraph
+	recognized := make(map[int]OCRResult)
+	for n, page := range extracted {
+		paragraphs = append(paragraphs, page.Paragraphs...)
+		if page.OCR != nil {
+			recognized[pages[n]] = *page.OCR
+		} else if len(page.Paragraphs) == 0 && !page.Skipped {
+			report.warn("page %d has no text", pages[n])
+		}
 	}
+	if report != nil {
+		report.Parts = r.NumPage()
+	}
+
+	inferHeadings(paragraphs)
+	var outline []pdfOutlineEntry
+	for _, entry := range readOutline(r) {
+		if entry.Page == 0 || selection.Contains(entry.Page) {
+			outline = append(outline, entry)
+		}
+	}
+	paragraphs, toc := placeOutline(paragraphs, outline)
 
+	var buf strings.Builder
+	if !c.SkipMetadata {
+		buf.WriteString(readMetadata(r).frontMatter())
+	}
+	if !c.SkipTOC && len(toc) > 0 {
+		if buf.Len() > 0 {
+			buf.WriteString("\n")
+		}
+		buf.WriteString(utils.HeadingList(toc))
+	}
+	next := 0
+	for _, page := range pages {
+		writePageMarker(&buf, c.Options, page)
+		if c.Options.Provenance {
+			if buf.Len() > 0 && !c.Options.marksPages() {
+				buf.WriteString("\n")
+			}
+			buf.WriteString(sourceComment(fmt.Sprintf("page %d", page)))
+		}
+		for ; next < len(paragraphs) && paragraphs[next].Page == page; next++ {
+			p := paragraphs[next]
+			if buf.Len() > 0 {
+				buf.WriteString("\n")
+			}
+			if p.Level > 0 {
+				buf.WriteString(strings.Repeat("#", p.Level) + " ")
+			}
+			buf.WriteString(p.Text)
+			buf.WriteString("\n")
+		}
+		if result, ok := recognized[page]; ok {
+			writeRecognizedText(&buf, result)
+		}
+	}
+	if !c.SkipFormFields {
+		writeFormFields(&buf, readFormFields(r), c.Options.Tables)
+	}
+	if footnotes != nil && len(*footnotes) > 0 {
+		fmt.Fprintf(&buf, "\n%s\n", strings.Join(*footnotes, "\n"))
+	}
 	return buf.String(), nil
 }
+
+// marksPages reports whether a marker is written before each PDF page.
+func (o ConvertOptions) marksPages() bool {
+	return o.PageMarkers || o.Marker != ""
+}
+
+// writePageMarker writes the marker of a PDF page, when pages are marked.
+func writePageMarker(buf *strings.Builder, options ConvertOptions, page int) {
+	if !options.marksPages() {
+		return
+	}
+	if buf.Len() > 0 {
+		buf.WriteString("\n")
+	}
+	marker := cmp.Or(options.Marker, defaultPageMarker)
+	buf.WriteString(expandMarker(marker, page, fmt.Sprintf("Page %d", page)) + "\n")
+}
+
+// writeRecognizedText writes the text recognized on a page, after a note
+// telling it comes from OCR with its confidence.
+func writeRecognizedText(buf *strings.Builder, result OCRResult) {
+	text := strings.TrimSpace(strings.ReplaceAll(result.Text, "\r\n", "\n"))
+	if text == "" {
+		return
+	}
+	if buf.Len() > 0 {
+		buf.WriteString("\n")
+	}
+	fmt.Fprintf(buf, "<!-- Text recognized by OCR, confidence %.0f%% -->\n\n", result.Confidence*100)
+	buf.WriteString(text)
+	buf.WriteString("\n")
+}
+
+// pdfPage is the text extracted from a page.
+type pdfPage struct {
+	Paragraphs []pdfParagraph
+
+	// OCR is the text recognized on pages without extractable text.
+	OCR *OCRResult
+
+	// Skipped is set on pages left out in partial mode because they could
+	// not be read.
+	Skipped bool
+}
+
+// extractPages extracts the text of the pages concurrently, with at most
+// Workers pages at a time, and returns the pages in order. No page is
+// started once ctx is done. In partial mode, the pages that cannot be read
+// are skipped with a warning added to report.
+func (c *PdfConverter) extractPages(ctx context.Context, r *pdf.Reader, path string, pages []int, annotations [][]pdfAnnotation, report *Report) ([]pdfPage, error) {
+	results := make([]pdfPage, len(pages))
+	errs := make([]error, len(pages))
+	workers := c.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	progress := newProgressCounter(c.Progress, "page", len(pages))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(workers, len(pages)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := range jobs {
+				results[n], errs[n] = c.extractPage(r, path, pages[n], annotations[n])
+				progress.step("")
+			}
+		}()
+	}
+	for n := range pages {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- n
+	}
+	close(jobs)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	for n, err := range errs {
+		if err == nil {
+			continue
+		}
+		if !c.Options.Partial {
+			return nil, err
+		}
+		results[n] = pdfPage{Skipped: true}
+		report.warn("page %d was skipped: %v", pages[n], err)
+	}
+	return results, nil
+}
+
+// extractPage extracts the paragraphs of a page, or recognizes its text
+// when it has none and an OCR engine is set.
+func (c *PdfConverter) extractPage(r *pdf.Reader, path string, number int, annotations []pdfAnnotation) (pdfPage, error) {
+	paragraphs, err := pageParagraphs(r.Page(number), number, annotations)
+	if err != nil {
+		return pdfPage{}, fmt.Errorf("unable to extract text from page %d of PDF file %s: %w", number, path, err)
+	}
+	page := pdfPage{Paragraphs: paragraphs}
+	if len(paragraphs) == 0 && c.OCR != nil {
+		result, err := c.OCR.RecognizePage(path, number, c.Options)
+		if err != nil {
+			return pdfPage{}, fmt.Errorf("unable to run OCR on page %d of PDF file %s: %w", number, path, err)
+		}
+		page.OCR = &result
+	}
+	return page, nil
+}
+
+// openPdf opens a PDF file, decrypting it with the password when it is
+// encrypted. Files of threshold bytes or more are mapped into memory, as
+// readFile does.
+func openPdf(path, password string, threshold int64) (io.Closer, *pdf.Reader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to open PDF file %s: %w", path, err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, fmt.Errorf("unable to open PDF file %s: %w", path, err)
+	}
+
+	// Large files are read from a mapping of the file rather than with a
+	// system call for every object.
+	var source io.ReaderAt = f
+	var closer io.Closer = f
+	if m := mapLarge(f, info.Size(), threshold); m != nil {
+		f.Close()
+		source, closer = bytes.NewReader(m.Data), m
+	}
+
+	r, err := newPdfReader(source, info.Size(), path, password)
+	if err != nil {
+		closer.Close()
+		return nil, nil, err
+	}
+	return closer, r, nil
+}
+
+// newPdfReader reads the PDF document of size bytes in source, named path in
+// errors, decrypting it with password when it is encrypted.
+func newPdfReader(source io.ReaderAt, size int64, path, password string) (*pdf.Reader, error) {
+	// The reader asks for passwords until one is empty; the empty user
+	// password is always tried first.
+	tried := false
+	r, err := pdf.NewReaderEncrypted(source, size, func() string {
+		if tried {
+			return ""
+		}
+		tried = true
+		return password
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, pdf.ErrInvalidPassword) && password == "":
+			return nil, fmt.Errorf("PDF file %s is encrypted, a password is required: %w: %w", path, ErrEncryptedDocument, err)
+		case errors.Is(err, pdf.ErrInvalidPassword):
+			return nil, fmt.Errorf("unable to open PDF file %s, wrong password: %w: %w", path, ErrEncryptedDocument, err)
+		}
+		return nil, fmt.Errorf("unable to open PDF file %s: %w: %w", path, ErrCorruptDocument, err)
+	}
+	return r, nil
+}
+
+// pdfOutlineEntry is a bookmark of the document outline and the place on a
+// page its destination points to.
+type pdfOutlineEntry struct {
+	Title string
+	Level int
+
+	// Page is the 1-based page number of the destination, zero when unknown.
+	Page int
+
+	// Top is the top of the destination view, in points from the page
+	// bottom, when HasTop is set.
+	Top    float64
+	HasTop bool
+}
+
+// maxOutlineEntries bounds the outline walk, as damaged files can link
+// outline items in a cycle.
+const maxOutlineEntries = 10000
+
+// readOutline returns the entries of the document outline in order, depth
+// first. Files without an outline have no entries.
+func readOutline(r *pdf.Reader) (entries []pdfOutlineEntry) {
+	defer func() {
+		if recover() != nil {
+			entries = nil
+		}
+	}()
+
+	root := r.Trailer().Key("Root")
+	pages := make([]pdf.Value, r.NumPage())
+	for i := range pages {
+		pages[i] = r.Page(i + 1).V
+	}
+
+	var walk func(parent pdf.Value, level int)
+	walk = func(parent pdf.Value, level int) {
+		for item := parent.Key("First"); item.Kind() == pdf.Dict && len(entries) < maxOutlineEntries; item = item.Key("Next") {
+			entry := pdfOutlineEntry{Title: strings.TrimSpace(item.Key("Title").Text()), Level: min(level, 6)}
+			if dest := outlineDestination(root, item); dest.Kind() == pdf.Array && dest.Len() > 0 {
+				entry.Page = destinationPage(pages, dest.Index(0))
+				entry.Top, entry.HasTop = destinationTop(dest)
+			}
+			if entry.Title != "" {
+				entries = append(entries, entry)
+			}
+			walk(item, level+1)
+		}
+	}
+	walk(root.Key("Outlines"), 1)
+	return entries
+}
+
+// outlineDestination returns the explicit destination array of an outline
+// item, resolving GoTo actions and named destinations.
+func outlineDestination(root, item pdf.Value) pdf.Value {
+	dest := item.Key("Dest")
+	if dest.IsNull() {
+		if action := item.Key("A"); action.Key("S").Name() == "GoTo" {
+			dest = action.Key("D")
+		}
+	}
+
+	switch dest.Kind() {
+	case pdf.Name:
+		dest = root.Key("Dests").Key(dest.Name())
+	case pdf.String:
+		dest = lookupNameTree(root.Key("Names").Key("Dests"), dest.RawString(), 0)
+	}
+	if dest.Kind() == pdf.Dict {
+		dest = dest.Key("D")
+	}
+	return dest
+}
+
+// lookupNameTree finds the value of a key in a PDF name tree.
+func lookupNameTree(node pdf.Value, key string, depth int) pdf.Value {
+	if node.Kind() != pdf.Dict || depth > 32 {
+		return pdf.Value{}
+	}
+	names := node.Key("Names")
+	for i := 0; i+1 < names.Len(); i += 2 {
+		if names.Index(i).RawString() == key {
+			return names.Index(i + 1)
+		}
+	}
+	kids := node.Key("Kids")
+	for i := range kids.Len() {
+		kid := kids.Index(i)
+		if limits := kid.Key("Limits"); limits.Len() == 2 {
+			if key < limits.Index(0).RawString() || key > limits.Index(1).RawString() {
+				continue
+			}
+		}
+		if value := lookupNameTree(kid, key, depth+1); !value.IsNull() {
+			return value
+		}
+	}
+	return pdf.Value{}
+}
+
+// destinationPage returns the 1-based number of the destination page, given
+// as a page object or, in remote destinations, as a 0-based page index.
+func destinationPage(pages []pdf.Value, page pdf.Value) int {
+	if page.Kind() == pdf.Integer {
+		if n := int(page.Int64()); n >= 0 && n < len(pages) {
+			return n + 1
+		}
+		return 0
+	}
+	for i, p := range pages {
+		if reflect.DeepEqual(p, page) {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// destinationTop returns the top coordinate of XYZ, FitH and FitBH views.
+func destinationTop(dest pdf.Value) (float64, bool) {
+	var top pdf.Value
+	switch dest.Index(1).Name() {
+	case "XYZ":
+		top = dest.Index(3)
+	case "FitH", "FitBH":
+		top = dest.Index(2)
+	}
+	if top.Kind() != pdf.Integer && top.Kind() != pdf.Real {
+		return 0, false
+	}
+	return top.Float64(), true
+}
+
+// placeOutline makes the paragraphs matching outline titles headings at the
+// outline level, and inserts a heading for outline entries whose title is
+// not found on their page, just below their destination. It returns the
+// paragraphs and the headings of the table of contents.
+func placeOutline(paragraphs []pdfParagraph, outline []pdfOutlineEntry) ([]pdfParagraph, []utils.Heading) {
+	var toc []utils.Heading
+	from := 0
+	for _, entry := range outline {
+		title := normalizeTitle(entry.Title)
+		match := -1
+		for i := from; i < len(paragraphs) && match < 0 && title != ""; i++ {
+			if (entry.Page == 0 || paragraphs[i].Page == entry.Page) && normalizeTitle(paragraphs[i].Text) == title {
+				match = i
+			}
+		}
+
+		if match < 0 {
+			if entry.Page == 0 {
+				continue
+			}
+			match = insertionPoint(paragraphs, entry)
+			paragraphs = append(paragraphs[:match], append([]pdfParagraph{{Text: entry.Title, Page: entry.Page}}, paragraphs[match:]...)...)
+		}
+		paragraphs[match].Level = entry.Level
+		toc = append(toc, utils.Heading{Level: entry.Level, Text: paragraphs[match].Text})
+		from = match + 1
+	}
+	return paragraphs, toc
+}
+
+// insertionPoint returns the index of the first paragraph on the entry page
+// below its destination, or after the last paragraph before that page.
+func insertionPoint(paragraphs []pdfParagraph, entry pdfOutlineEntry) int {
+	for i, p := range paragraphs {
+		if p.Page > entry.Page || (p.Page == entry.Page && (!entry.HasTop || p.Y <= entry.Top)) {
+			return i
+		}
+	}
+	return len(paragraphs)
+}
+
+// normalizeTitle reduces a title to its lowercase letters and digits, so
+// that "1. Introduction" in the text matches "1 Introduction" in the outline.
+func normalizeTitle(title string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(title) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
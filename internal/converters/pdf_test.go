@@ -1,9 +1,14 @@
 package converters
 
 import (
+	"context"
+	"crypto/md5"
+	"crypto/rc4"
+	"fmt"
 	"os"
 	"path/filepath"
 	"reflect"
+	"runtime"
 	"strings"
 	"testing"
 )
@@ -99,7 +104,7 @@ func TestPdfConverter_Load_InvalidPermissions(t *testing.T) {
 }
 
 func TestReadPdfFile_NonExistentFile(t *testing.T) {
-	_, err := readPdfFile("/nonexistent/file.pdf")
+	_, err := (&PdfConverter{}).readPdfFile(context.Background(), "/nonexistent/file.pdf", nil)
 
 	if err == nil {
 		t.Errorf("readPdfFile() should return error for non-existent file")
@@ -120,7 +125,7 @@ func TestReadPdfFile_InvalidFile(t *testing.T) {
 		t.Fatalf("Failed to create invalid file: %v", err)
 	}
 
-	_, err = readPdfFile(invalidFile)
+	_, err = (&PdfConverter{}).readPdfFile(context.Background(), invalidFile, nil)
 
 	if err == nil {
 		t.Errorf("readPdfFile() should return error for invalid PDF file")
@@ -249,3 +254,636 @@ func TestPdfConverter_LoadingScenarios(t *testing.T) {
 		})
 	}
 }
+
+// writeTestPdf creates a PDF file with one page per content stream in a
+// temporary directory and returns its path. Pages can use the fonts /F1
+// (Helvetica) and /F2 (Helvetica-Bold), both with 500-unit glyph widths.
+func writeTestPdf(t *testing.T, pages ...string) string {
+	t.Helper()
+	return writeTestPdfObjects(t, testPdf{}, pages...)
+}
+
+// testPdf holds additions to the PDF files written by writeTestPdfObjects.
+type testPdf struct {
+	// Catalog and Trailer are entries added to the catalog and trailer
+	// dictionaries.
+	Catalog, Trailer string
+
+	// Extra objects are numbered after the last page.
+	Extra []string
+
+	// Annots holds the annotation arrays of pages, by 0-based page index.
+	Annots map[int]string
+}
+
+// writeTestPdfObjects creates a PDF file like writeTestPdf, with the entries
+// and objects of doc. Page n (0-based) is object 5+2n.
+func writeTestPdfObjects(t *testing.T, doc testPdf, pages ...string) string {
+	t.Helper()
+
+	widths := strings.TrimSpace(strings.Repeat("500 ", 95))
+	font := func(name string) string {
+		return fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /FirstChar 32 /LastChar 126 /Widths [%s] >>", name, widths)
+	}
+
+	objects := []string{"<< /Type /Catalog /Pages 2 0 R " + doc.Catalog + ">>", "", font("Helvetica"), font("Helvetica-Bold")}
+	var kids []string
+	for i, content := range pages {
+		page := len(objects) + 1
+		kids = append(kids, fmt.Sprintf("%d 0 R", page))
+		annots := ""
+		if doc.Annots[i] != "" {
+			annots = "/Annots " + doc.Annots[i] + " "
+		}
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> %s/Contents %d 0 R >>", annots, page+1),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content)+1, content),
+		)
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages))
+	objects = append(objects, doc.Extra...)
+
+	var buf strings.Builder
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R %s>>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, doc.Trailer, xref)
+
+	path := filepath.Join(t.TempDir(), "test.pdf")
+	if err := os.WriteFile(path, []byte(buf.String()), 0o644); err != nil {
+		t.Fatalf("Failed to create test PDF file: %v", err)
+	}
+	return path
+}
+
+// pdfText returns the content stream operators drawing a line of text.
+func pdfText(font string, size, x, y float64, text string) string {
+	return fmt.Sprintf("BT /%s %g Tf %g %g Td (%s) Tj ET\n", font, size, x, y, text)
+}
+
+func TestPdfConverter_Load_Paragraphs(t *testing.T) {
+	content := pdfText("F1", 10, 72, 700, "The first paragraph is written across") +
+		pdfText("F1", 10, 72, 688, "two lines with a hyphen-") +
+		pdfText("F1", 10, 72, 676, "ated word.") +
+		pdfText("F1", 10, 72, 640, "A second paragraph after a gap.")
+
+	result, err := NewPdfConverter().Load(writeTestPdf(t, content))
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+
+	expected := "The first paragraph is written across two lines with a hyphenated word.\n\nA second paragraph after a gap.\n"
+	if result != expected {
+		t.Errorf("Load() = %q, want %q", result, expected)
+	}
+}
+
+func TestPdfConverter_Load_TwoColumns(t *testing.T) {
+	content := pdfText("F1", 10, 72, 720, "A title spanning both of the columns of this page layout")
+	left := []string{"Left column text begins here", "and keeps going on this line", "until the left column ends."}
+	right := []string{"Right column text starts now", "and carries on to its own end", "after three lines of text."}
+	for i := range left {
+		y := float64(690 - 12*i)
+		content += pdfText("F1", 10, 72, y, left[i]) + pdfText("F1", 10, 330, y, right[i])
+	}
+
+	result, err := NewPdfConverter().Load(writeTestPdf(t, content))
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+
+	expected := "A title spanning both of the columns of this page layout\n\n" +
+		"Left column text begins here and keeps going on this line until the left column ends.\n\n" +
+		"Right column text starts now and carries on to its own end after three lines of text.\n"
+	if result != expected {
+		t.Errorf("Load() = %q, want %q", result, expected)
+	}
+}
+
+func TestPdfConverter_Load_TableRowsReadAcross(t *testing.T) {
+	var content string
+	for i, row := range [][2]string{{"Item", "Price"}, {"Apple", "1.00"}, {"Pear", "2.50"}, {"Plum", "0.75"}} {
+		y := float64(700 - 14*i)
+		content += pdfText("F1", 10, 72, y, row[0]) + pdfText("F1", 10, 300, y, row[1])
+	}
+
+	result, err := NewPdfConverter().Load(writeTestPdf(t, content))
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+
+	expected := "Item Price Apple 1.00 Pear 2.50 Plum 0.75\n"
+	if result != expected {
+		t.Errorf("Load() = %q, want %q", result, expected)
+	}
+}
+
+func TestPdfConverter_Load_Headings(t *testing.T) {
+	content := pdfText("F2", 20, 72, 740, "Annual Report") +
+		pdfText("F1", 14, 72, 700, "Overview") +
+		pdfText("F1", 10, 72, 676, "Body text sits at the most common font size of the page.") +
+		pdfText("F2", 10, 72, 650, "Key Points") +
+		pdfText("F1", 10, 72, 630, "More body text follows the bold heading line.") +
+		pdfText("F1", 14, 72, 600, "12")
+
+	result, err := NewPdfConverter().Load(writeTestPdf(t, content))
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+
+	expected := "# Annual Report\n\n## Overview\n\nBody text sits at the most common font size of the page.\n\n" +
+		"### Key Points\n\nMore body text follows the bold heading line.\n\n12\n"
+	if result != expected {
+		t.Errorf("Load() = %q, want %q", result, expected)
+	}
+}
+
+func TestInferHeadings_SentencesAreNotHeadings(t *testing.T) {
+	paragraphs := []pdfParagraph{
+		{Text: "This larger paragraph ends like a sentence of body text.", Size: 14},
+		{Text: "Body text at the common size of the document, long enough to outweigh the rest.", Size: 10},
+		{Text: "Authors, editors and contributors,", Size: 12, Font: "Helvetica-Bold"},
+	}
+	inferHeadings(paragraphs)
+	for _, p := range paragraphs {
+		if p.Level != 0 {
+			t.Errorf("inferHeadings() made %q a level %d heading, want body text", p.Text, p.Level)
+		}
+	}
+}
+
+func TestPdfConverter_Load_Outline(t *testing.T) {
+	first := pdfText("F1", 10, 72, 720, "1. Introduction") +
+		pdfText("F1", 10, 72, 700, "The introduction text of the document.")
+	second := pdfText("F1", 10, 72, 720, "Text before the bookmarked place.") +
+		pdfText("F1", 10, 72, 480, "Text after the bookmarked place.")
+
+	// Objects 5 and 7 are the pages, the outline starts at object 9.
+	outline := []string{
+		"<< /Type /Outlines /First 10 0 R /Last 10 0 R /Count 2 >>",
+		"<< /Title (1 Introduction) /Parent 9 0 R /First 11 0 R /Last 11 0 R /Dest [5 0 R /XYZ 0 730 0] >>",
+		"<< /Title (Background) /Parent 10 0 R /A << /S /GoTo /D (background) >> >>",
+		"<< /Names [(background) [7 0 R /XYZ 0 500 0]] >>",
+	}
+	path := writeTestPdfObjects(t, testPdf{Catalog: "/Outlines 9 0 R /Names << /Dests 12 0 R >> ", Extra: outline}, first, second)
+
+	result, err := NewPdfConverter().Load(path)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+
+	expected := "- [1. Introduction](#1-introduction)\n  - [Background](#background)\n\n" +
+		"# 1. Introduction\n\nThe introduction text of the document.\n\n" +
+		"Text before the bookmarked place.\n\n## Background\n\nText after the bookmarked place.\n"
+	if result != expected {
+		t.Errorf("Load() = %q, want %q", result, expected)
+	}
+
+	result, err = (&PdfConverter{SkipTOC: true}).Load(path)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	if strings.Contains(result, "](#") || !strings.HasPrefix(result, "# 1. Introduction") {
+		t.Errorf("Load() with SkipTOC = %q, want the text without the table of contents", result)
+	}
+}
+
+func TestPdfConverter_Load_PagesAndMarkers(t *testing.T) {
+	path := writeTestPdf(t,
+		pdfText("F1", 10, 72, 700, "Text of the first page."),
+		pdfText("F1", 10, 72, 700, "Text of the second page."),
+		"",
+		pdfText("F1", 10, 72, 700, "Text of the fourth page."),
+	)
+
+	converter := &PdfConverter{Options: ConvertOptions{Pages: "2-", PageMarkers: true}}
+	result, err := converter.Load(path)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+
+	expected := "<!-- Page 2 -->\n\nText of the second page.\n\n<!-- Page 3 -->\n\n<!-- Page 4 -->\n\nText of the fourth page.\n"
+	if result != expected {
+		t.Errorf("Load() = %q, want %q", result, expected)
+	}
+
+	converter = &PdfConverter{Options: ConvertOptions{Pages: "4,1"}}
+	if result, err = converter.Load(path); err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	if expected := "Text of the first page.\n\nText of the fourth page.\n"; result != expected {
+		t.Errorf("Load() = %q, want %q", result, expected)
+	}
+
+	converter = &PdfConverter{Options: ConvertOptions{Pages: "3-1"}}
+	if _, err := converter.Load(path); err == nil || !strings.Contains(err.Error(), "invalid page selection") {
+		t.Errorf("Load() with an invalid page selection error = %v, want an invalid page selection error", err)
+	}
+}
+
+// fakeOCR returns the same text for every page it is asked to recognize.
+type fakeOCR struct {
+	pages     []int
+	passwords []string
+	err       error
+}
+
+func (o *fakeOCR) RecognizePage(path string, page int, options ConvertOptions) (OCRResult, error) {
+	o.pages = append(o.pages, page)
+	o.passwords = append(o.passwords, options.Password)
+	return OCRResult{Text: "Scanned text\r\nof the page.\n", Confidence: 0.874}, o.err
+}
+
+func TestPdfConverter_Load_OCR(t *testing.T) {
+	path := writeTestPdf(t,
+		pdfText("F1", 10, 72, 700, "Text of the first page."),
+		"",
+	)
+
+	ocr := &fakeOCR{}
+	converter := &PdfConverter{OCR: ocr, Options: ConvertOptions{PageMarkers: true}}
+	result, err := converter.Load(path)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+
+	expected := "<!-- Page 1 -->\n\nText of the first page.\n\n<!-- Page 2 -->\n\n" +
+		"<!-- Text recognized by OCR, confidence 87% -->\n\nScanned text\nof the page.\n"
+	if result != expected {
+		t.Errorf("Load() = %q, want %q", result, expected)
+	}
+	if !reflect.DeepEqual(ocr.pages, []int{2}) {
+		t.Errorf("OCR ran on pages %v, want only the page without text", ocr.pages)
+	}
+
+	converter = &PdfConverter{OCR: &fakeOCR{err: fmt.Errorf("engine unavailable")}}
+	if _, err := converter.Load(path); err == nil || !strings.Contains(err.Error(), "engine unavailable") {
+		t.Errorf("Load() with a failing OCR engine error = %v, want the engine error", err)
+	}
+}
+
+func TestPdfConverter_Load_OCREncrypted(t *testing.T) {
+	path := writeEncryptedTestPdf(t, "secret", "")
+
+	ocr := &fakeOCR{}
+	converter := &PdfConverter{OCR: ocr, Options: ConvertOptions{Password: "secret"}}
+	if _, err := converter.Load(path); err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(ocr.passwords, []string{"secret"}) {
+		t.Errorf("OCR got passwords %q, want the password of the options", ocr.passwords)
+	}
+}
+
+// pdfPasswordPad pads passwords of the standard security handler.
+var pdfPasswordPad = []byte("\x28\xbf\x4e\x5e\x4e\x75\x8a\x41\x64\x00\x4e\x56\xff\xfa\x01\x08\x2e\x2e\x00\xb6\xd0\x68\x3e\x80\x2f\x0c\xa9\xfe\x64\x53\x69\x7a")
+
+// writeEncryptedTestPdf creates a one-page PDF file encrypted with RC4
+// 128-bit keys (revision 3 of the standard security handler).
+func writeEncryptedTestPdf(t *testing.T, password, content string) string {
+	t.Helper()
+
+	id := "0123456789abcdef"
+	owner := strings.Repeat("o", 32)
+	const permissions = -4
+
+	h := md5.New()
+	h.Write(append([]byte(password), pdfPasswordPad[:32-len(password)]...))
+	h.Write([]byte(owner))
+	h.Write([]byte{0xFC, 0xFF, 0xFF, 0xFF})
+	h.Write([]byte(id))
+	key := h.Sum(nil)
+	for range 50 {
+		sum := md5.Sum(key)
+		key = sum[:]
+	}
+
+	h.Reset()
+	h.Write(pdfPasswordPad)
+	h.Write([]byte(id))
+	user := h.Sum(nil)
+	for i := range 20 {
+		xored := make([]byte, len(key))
+		for j := range key {
+			xored[j] = key[j] ^ byte(i)
+		}
+		c, _ := rc4.NewCipher(xored)
+		c.XORKeyStream(user, user)
+	}
+	user = append(user, make([]byte, 16)...)
+
+	objectKey := md5.Sum(append(key, 4, 0, 0, 0, 0))
+	c, _ := rc4.NewCipher(objectKey[:])
+	stream := []byte(content)
+	c.XORKeyStream(stream, stream)
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(stream), stream),
+		fmt.Sprintf("<< /Filter /Standard /V 2 /R 3 /Length 128 /P %d /O <%x> /U <%x> >>", permissions, owner, user),
+	}
+
+	var buf strings.Builder
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R /Encrypt 5 0 R /ID [<%x> <%x>] >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, id, id, xref)
+
+	path := filepath.Join(t.TempDir(), "encrypted.pdf")
+	if err := os.WriteFile(path, []byte(buf.String()), 0o644); err != nil {
+		t.Fatalf("Failed to create test PDF file: %v", err)
+	}
+	return path
+}
+
+func TestPdfConverter_Load_Password(t *testing.T) {
+	path := writeEncryptedTestPdf(t, "secret", "BT /Helvetica 10 Tf 72 700 Td (Confidential text.) Tj ET")
+
+	converter := &PdfConverter{Options: ConvertOptions{Password: "secret"}}
+	result, err := converter.Load(path)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	if expected := "Confidential text.\n"; result != expected {
+		t.Errorf("Load() = %q, want %q", result, expected)
+	}
+
+	tests := []struct {
+		password string
+		want     string
+	}{
+		{password: "", want: "a password is required"},
+		{password: "guess", want: "wrong password"},
+	}
+	for _, tt := range tests {
+		converter := &PdfConverter{Options: ConvertOptions{Password: tt.password}}
+		if _, err := converter.Load(path); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Load() with password %q error = %v, want it to mention %q", tt.password, err, tt.want)
+		}
+	}
+
+	unprotected := writeEncryptedTestPdf(t, "", "BT /Helvetica 10 Tf 72 700 Td (Public text.) Tj ET")
+	if result, err := NewPdfConverter().Load(unprotected); err != nil || result != "Public text.\n" {
+		t.Errorf("Load() of a file with an empty user password = %q, %v, want the text", result, err)
+	}
+}
+
+func TestPdfConverter_Load_Metadata(t *testing.T) {
+	content := pdfText("F1", 10, 72, 700, "Body text.")
+	info := "<< /Title (Draft title) /Author (Jane Doe) /Subject (Quarterly results) /Keywords (finance; 2024) /CreationDate (D:20240102150405+01'00') >>"
+
+	path := writeTestPdfObjects(t, testPdf{Trailer: "/Info 7 0 R ", Extra: []string{info}}, content)
+	result, err := NewPdfConverter().Load(path)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	expected := "---\ntitle: Draft title\nauthor: Jane Doe\nsubject: Quarterly results\nkeywords:\n  - finance\n  - \"2024\"\n" +
+		"created: 2024-01-02T15:04:05+01:00\n---\n\nBody text.\n"
+	if result != expected {
+		t.Errorf("Load() = %q, want %q", result, expected)
+	}
+
+	xmp := `<?xpacket begin="" id="W5M0MpCehiHzreSzNTczkc9d"?>
+<x:xmpmeta xmlns:x="adobe:ns:meta/"><rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+<rdf:Description rdf:about="" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:pdf="http://ns.adobe.com/pdf/1.3/" pdf:Keywords="annual, report">
+<dc:title><rdf:Alt><rdf:li xml:lang="x-default">Annual Report</rdf:li></rdf:Alt></dc:title>
+<dc:creator><rdf:Seq><rdf:li>Jane Doe</rdf:li><rdf:li>John Roe</rdf:li></rdf:Seq></dc:creator>
+</rdf:Description>
+<rdf:Description rdf:about="" xmlns:xmp="http://ns.adobe.com/xap/1.0/"><xmp:CreateDate>2024-03-01T09:00:00Z</xmp:CreateDate></rdf:Description>
+</rdf:RDF></x:xmpmeta>
+<?xpacket end="w"?>`
+	stream := fmt.Sprintf("<< /Type /Metadata /Subtype /XML /Length %d >>\nstream\n%s\nendstream", len(xmp), xmp)
+
+	path = writeTestPdfObjects(t, testPdf{Catalog: "/Metadata 8 0 R ", Trailer: "/Info 7 0 R ", Extra: []string{info, stream}}, content)
+	result, err = NewPdfConverter().Load(path)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	expected = "---\ntitle: Annual Report\nauthor: Jane Doe, John Roe\nsubject: Quarterly results\nkeywords:\n  - annual\n  - report\n" +
+		"created: 2024-03-01T09:00:00Z\n---\n\nBody text.\n"
+	if result != expected {
+		t.Errorf("Load() with XMP metadata = %q, want %q", result, expected)
+	}
+
+	result, err = (&PdfConverter{SkipMetadata: true}).Load(path)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	if result != "Body text.\n" {
+		t.Errorf("Load() with SkipMetadata = %q, want only the text", result)
+	}
+}
+
+func TestPdfDate(t *testing.T) {
+	cases := map[string]string{
+		"D:20240102150405+01'00'": "2024-01-02T15:04:05+01:00",
+		"D:20240102150405Z":       "2024-01-02T15:04:05Z",
+		"D:20240102150405-0530":   "2024-01-02T15:04:05-05:30",
+		"D:202401021504":          "2024-01-02T15:04",
+		"D:20240102":              "2024-01-02",
+		"D:2024":                  "2024",
+		"yesterday":               "yesterday",
+	}
+	for input, expected := range cases {
+		if got := pdfDate(input); got != expected {
+			t.Errorf("pdfDate(%q) = %q, want %q", input, got, expected)
+		}
+	}
+}
+
+func TestPdfConverter_Load_LinksAndComments(t *testing.T) {
+	// Glyphs are 5 points wide at size 10: "Read the docs" spans 72 to 137,
+	// with "docs" from 117.
+	content := pdfText("F1", 10, 72, 700, "Read the docs for details.") +
+		pdfText("F1", 10, 72, 650, "A second paragraph.")
+	annots := "[<< /Type /Annot /Subtype /Link /Rect [116 695 138 712] /A << /S /URI /URI (https://example.com/docs) >> >> " +
+		"<< /Type /Annot /Subtype /Highlight /Rect [71 695 93 712] /Contents (Check this) /T (Jane) >> " +
+		"<< /Type /Annot /Subtype /Text /Rect [560 640 580 660] /Contents (Margin\nnote) >> " +
+		"<< /Type /Annot /Subtype /Popup /Rect [560 600 700 660] /Contents (Margin note) >>]"
+	path := writeTestPdfObjects(t, testPdf{Annots: map[int]string{0: annots}}, content)
+
+	result, err := NewPdfConverter().Load(path)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	expected := "Read the [docs](https://example.com/docs) for details.\n\nA second paragraph.\n"
+	if result != expected {
+		t.Errorf("Load() = %q, want %q", result, expected)
+	}
+
+	result, err = (&PdfConverter{Comments: true}).Load(path)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	expected = "Read[^1] the [docs](https://example.com/docs) for details.\n\nA second paragraph.[^2]\n\n" +
+		"[^1]: Page 1 (Jane): Check this\n[^2]: Page 1: Margin note\n"
+	if result != expected {
+		t.Errorf("Load() with comments = %q, want %q", result, expected)
+	}
+}
+
+func TestPdfConverter_Load_FormFields(t *testing.T) {
+	content := pdfText("F1", 10, 72, 700, "Application form.")
+	fields := []string{
+		"<< /FT /Tx /T (name) /V (Jane Doe) >>",
+		"<< /T (address) /Kids [9 0 R 10 0 R] >>",
+		"<< /FT /Tx /T (city) /V <FEFF004D00FC006E006300680065006E> >>",
+		"<< /FT /Tx /T (zip) >>",
+		"<< /FT /Btn /T (subscribe) /V /Yes /Kids [<< /Subtype /Widget /AS /Yes >>] >>",
+		"<< /FT /Ch /T (topics) /V [(Go) (PDF)] >>",
+	}
+	doc := testPdf{Catalog: "/AcroForm << /Fields [7 0 R 8 0 R 11 0 R 12 0 R] >> ", Extra: fields}
+	path := writeTestPdfObjects(t, doc, content)
+
+	result, err := NewPdfConverter().Load(path)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	expected := "Application form.\n\n## Form fields\n\n| Field | Value |\n| --- | --- |\n| name | Jane Doe |\n" +
+		"| address.city | München |\n| address.zip |  |\n| subscribe | Yes |\n| topics | Go, PDF |\n"
+	if result != expected {
+		t.Errorf("Load() = %q, want %q", result, expected)
+	}
+
+	result, err = (&PdfConverter{SkipFormFields: true}).Load(path)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	if result != "Application form.\n" {
+		t.Errorf("Load() with SkipFormFields = %q, want only the text", result)
+	}
+}
+
+// writeFakeMutool creates a shell script standing in for mutool, which
+// records its arguments and writes the text of pages 2 and 10 to the output
+// pattern.
+func writeFakeMutool(t *testing.T) (command, argsFile string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake mutool is a shell script")
+	}
+
+	dir := t.TempDir()
+	argsFile = filepath.Join(dir, "args")
+	script := `#!/bin/sh
+echo "$@" > ` + argsFile + `
+while [ "$1" != "-o" ]; do shift; done
+pattern=$2
+printf 'Second page\r\ntext\n' > "$(printf "$pattern" 2)"
+printf '\n' > "$(printf "$pattern" 3)"
+printf 'Tenth page\n' > "$(printf "$pattern" 10)"
+`
+	command = filepath.Join(dir, "mutool")
+	if err := os.WriteFile(command, []byte(script), 0o755); err != nil {
+		t.Fatalf("Failed to create fake mutool: %v", err)
+	}
+	return command, argsFile
+}
+
+func TestPdfConverter_Load_Engine(t *testing.T) {
+	command, argsFile := writeFakeMutool(t)
+
+	converter := &PdfConverter{
+		Engine:  MutoolEngine{Command: command},
+		Options: ConvertOptions{Pages: "2-3,10-", PageMarkers: true, Password: "secret"},
+	}
+	result, err := converter.Load("document.pdf")
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	expected := "<!-- Page 2 -->\n\nSecond page\ntext\n\n<!-- Page 3 -->\n\n<!-- Page 10 -->\n\nTenth page\n"
+	if result != expected {
+		t.Errorf("Load() = %q, want %q", result, expected)
+	}
+
+	args, err := os.ReadFile(argsFile)
+	if err != nil {
+		t.Fatalf("Failed to read mutool arguments: %v", err)
+	}
+	if !strings.HasPrefix(string(args), "draw -q -F txt -o ") || !strings.HasSuffix(string(args), "-p secret document.pdf 2-3,10-N\n") {
+		t.Errorf("mutool arguments = %q", args)
+	}
+
+	converter = &PdfConverter{Engine: MutoolEngine{Command: filepath.Join(t.TempDir(), "missing")}}
+	if _, err := converter.Load("document.pdf"); err == nil {
+		t.Error("Load() with a missing mutool command should return error")
+	}
+}
+
+func TestPdfConverter_Load_Workers(t *testing.T) {
+	var pages []string
+	var expected strings.Builder
+	for i := 1; i <= 20; i++ {
+		pages = append(pages, pdfText("F1", 10, 72, 700, fmt.Sprintf("Text of page %d.", i)))
+		if i > 1 {
+			expected.WriteString("\n")
+		}
+		fmt.Fprintf(&expected, "<!-- Page %d -->\n\nText of page %d.\n", i, i)
+	}
+	path := writeTestPdf(t, pages...)
+
+	for _, workers := range []int{0, 1, 4, 50} {
+		converter := &PdfConverter{Workers: workers, Options: ConvertOptions{PageMarkers: true}}
+		result, err := converter.Load(path)
+		if err != nil {
+			t.Fatalf("Load() with %d workers returned unexpected error: %v", workers, err)
+		}
+		if result != expected.String() {
+			t.Errorf("Load() with %d workers = %q, want %q", workers, result, expected.String())
+		}
+	}
+}
+
+func TestPdfConverter_Load_Provenance(t *testing.T) {
+	path := writeTestPdf(t,
+		pdfText("F1", 10, 72, 700, "Text of the first page."),
+		pdfText("F1", 10, 72, 700, "Text of the second page."),
+	)
+
+	converter := &PdfConverter{Options: ConvertOptions{Provenance: true}}
+	result, err := converter.Load(path)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+
+	expected := "<!-- source: page 1 -->\n\nText of the first page.\n\n<!-- source: page 2 -->\n\nText of the second page.\n"
+	if result != expected {
+		t.Errorf("Load() = %q, want %q", result, expected)
+	}
+}
+
+func TestPdfConverter_Load_Marker(t *testing.T) {
+	path := writeTestPdf(t,
+		pdfText("F1", 10, 72, 700, "Text of the first page."),
+		pdfText("F1", 10, 72, 700, "Text of the second page."),
+	)
+
+	converter := &PdfConverter{Options: ConvertOptions{Marker: MarkerRule}}
+	result, err := converter.Load(path)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+
+	expected := "---\n\nText of the first page.\n\n---\n\nText of the second page.\n"
+	if result != expected {
+		t.Errorf("Load() = %q, want %q", result, expected)
+	}
+}
@@ -0,0 +1,99 @@
+package converters
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// pdfAnnotation is a link or comment annotation of a page, with the area it
+// covers as x0, y0, x1, y1 in points from the page bottom left.
+type pdfAnnotation struct {
+	Rect [4]float64
+
+	// URI is the target of link annotations.
+	URI string
+
+	// Note is the footnote reference of comment annotations, such as "[^1]".
+	Note string
+}
+
+// covers reports whether the middle of a glyph lies in the annotation area.
+func (a pdfAnnotation) covers(g pdf.Text) bool {
+	const tolerance = 1
+	x := g.X + glyphWidth(g)/2
+	y := g.Y + g.FontSize/4
+	return x >= a.Rect[0]-tolerance && x <= a.Rect[2]+tolerance && y >= a.Rect[1]-tolerance && y <= a.Rect[3]+tolerance
+}
+
+// linkAt returns the index of the link annotation covering a glyph, or -1.
+func linkAt(annotations []pdfAnnotation, g pdf.Text) int {
+	for i, a := range annotations {
+		if a.URI != "" && a.covers(g) {
+			return i
+		}
+	}
+	return -1
+}
+
+// readAnnotations returns the URI links of a page and, when footnotes are
+// collected, its comments with their footnote references. The comments are
+// appended to the footnotes, numbered across pages. Annotations that cannot
+// be read are left out.
+func readAnnotations(page pdf.Page, number int, footnotes *[]string) (annotations []pdfAnnotation) {
+	defer func() {
+		if recover() != nil {
+			annotations = nil
+		}
+	}()
+
+	annots := page.V.Key("Annots")
+	for i := range annots.Len() {
+		annot := annots.Index(i)
+		rect, ok := annotationRect(annot.Key("Rect"))
+		if !ok {
+			continue
+		}
+
+		switch subtype := annot.Key("Subtype").Name(); subtype {
+		case "Link":
+			if action := annot.Key("A"); action.Key("S").Name() == "URI" {
+				if uri := strings.TrimSpace(action.Key("URI").RawString()); uri != "" {
+					annotations = append(annotations, pdfAnnotation{Rect: rect, URI: uri})
+				}
+			}
+		case "Popup", "Widget":
+			// Popups repeat the comment of their parent and widgets are
+			// form fields.
+		default:
+			text := strings.Join(strings.Fields(annot.Key("Contents").Text()), " ")
+			if footnotes == nil || text == "" {
+				continue
+			}
+			label := fmt.Sprintf("[^%d]", len(*footnotes)+1)
+			note := fmt.Sprintf("Page %d: %s", number, text)
+			if author := strings.TrimSpace(annot.Key("T").Text()); author != "" {
+				note = fmt.Sprintf("Page %d (%s): %s", number, author, text)
+			}
+			*footnotes = append(*footnotes, fmt.Sprintf("%s: %s", label, note))
+			annotations = append(annotations, pdfAnnotation{Rect: rect, Note: label})
+		}
+	}
+	return annotations
+}
+
+// annotationRect reads an annotation rectangle, normalized so that the first
+// corner is the bottom left one.
+func annotationRect(v pdf.Value) ([4]float64, bool) {
+	var rect [4]float64
+	if v.Kind() != pdf.Array || v.Len() != 4 {
+		return rect, false
+	}
+	for i := range rect {
+		rect[i] = v.Index(i).Float64()
+	}
+	rect[0], rect[2] = min(rect[0], rect[2]), max(rect[0], rect[2])
+	rect[1], rect[3] = min(rect[1], rect[3]), max(rect[1], rect[3])
+	return rect, true
+}
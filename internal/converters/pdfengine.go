@@ -0,0 +1,139 @@
+package converters
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/flaviodelgrosso/marky/internal/utils"
+)
+
+// PdfEngine extracts the text of PDF files in place of the built-in reader,
+// for documents it cannot read, such as files with CJK fonts or compressed
+// cross-reference streams.
+type PdfEngine interface {
+	// Extract converts the pages of a PDF file selected by the options,
+	// honoring the password and page markers.
+	Extract(path string, options ConvertOptions) (string, error)
+}
+
+// ContextEngine is implemented by the PDF engines whose extraction stops
+// when a context is done.
+type ContextEngine interface {
+	PdfEngine
+	ExtractContext(ctx context.Context, path string, options ConvertOptions) (string, error)
+}
+
+// MutoolEngine extracts text with the mutool command of MuPDF.
+type MutoolEngine struct {
+	// Command is the path of the mutool executable, "mutool" when empty.
+	Command string
+}
+
+// Extract runs mutool draw to write the text of each selected page to a file
+// of its own, and joins the pages in order.
+func (e MutoolEngine) Extract(path string, options ConvertOptions) (string, error) {
+	return e.ExtractContext(context.Background(), path, options)
+}
+
+// ExtractContext extracts text like Extract, killing mutool once ctx is
+// done.
+func (e MutoolEngine) ExtractContext(ctx context.Context, path string, options ConvertOptions) (string, error) {
+	selection, err := utils.ParseNumberRange(options.Pages)
+	if err != nil {
+		return "", fmt.Errorf("invalid page selection: %w", err)
+	}
+
+	if err := options.checkDiskWrite(); err != nil {
+		return "", err
+	}
+	dir, err := os.MkdirTemp("", "marky-mutool-")
+	if err != nil {
+		return "", fmt.Errorf("unable to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	args := []string{"draw", "-q", "-F", "txt", "-o", filepath.Join(dir, "page-%d.txt")}
+	if options.Password != "" {
+		args = append(args, "-p", options.Password)
+	}
+	args = append(args, path)
+	if len(selection) > 0 {
+		args = append(args, mutoolPages(selection))
+	}
+
+	command := e.Command
+	if command == "" {
+		command = "mutool"
+	}
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, command, args...)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", fmt.Errorf("unable to extract text from PDF file %s with mutool: %w: %s", path, err, message)
+		}
+		return "", fmt.Errorf("unable to extract text from PDF file %s with mutool: %w", path, err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", fmt.Errorf("unable to read mutool output: %w", err)
+	}
+	var pages []int
+	for _, entry := range entries {
+		number := strings.TrimSuffix(strings.TrimPrefix(entry.Name(), "page-"), ".txt")
+		if n, err := strconv.Atoi(number); err == nil {
+			pages = append(pages, n)
+		}
+	}
+	slices.Sort(pages)
+
+	var buf strings.Builder
+	for _, page := range pages {
+		data, err := os.ReadFile(filepath.Join(dir, fmt.Sprintf("page-%d.txt", page)))
+		if err != nil {
+			return "", fmt.Errorf("unable to read mutool output: %w", err)
+		}
+		text := strings.TrimSpace(strings.ReplaceAll(string(data), "\r\n", "\n"))
+		writePageMarker(&buf, options, page)
+		if options.Provenance {
+			if buf.Len() > 0 && !options.marksPages() {
+				buf.WriteString("\n")
+			}
+			buf.WriteString(sourceComment(fmt.Sprintf("page %d", page)))
+		}
+		if text != "" {
+			if buf.Len() > 0 {
+				buf.WriteString("\n")
+			}
+			buf.WriteString(text)
+			buf.WriteString("\n")
+		}
+	}
+	return buf.String(), nil
+}
+
+// mutoolPages formats a page selection in the mutool page range syntax,
+// where N stands for the last page.
+func mutoolPages(selection utils.NumberRange) string {
+	parts := make([]string, 0, len(selection))
+	for _, span := range selection {
+		switch {
+		case span[1] == math.MaxInt:
+			parts = append(parts, fmt.Sprintf("%d-N", span[0]))
+		case span[0] == span[1]:
+			parts = append(parts, strconv.Itoa(span[0]))
+		default:
+			parts = append(parts, fmt.Sprintf("%d-%d", span[0], span[1]))
+		}
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,105 @@
+package converters
+
+import (
+	"strings"
+
+	"github.com/flaviodelgrosso/marky/internal/utils"
+	"github.com/ledongthuc/pdf"
+)
+
+// pdfFormField is a terminal field of an interactive form with its value.
+type pdfFormField struct {
+	Name  string
+	Value string
+}
+
+// maxFormFields bounds the field tree walk, as damaged files can link fields
+// in a cycle.
+const maxFormFields = 10000
+
+// readFormFields returns the terminal fields of the interactive form
+// (AcroForm) in document order, named by their fully qualified names. Files
+// without a form have no fields.
+func readFormFields(r *pdf.Reader) (fields []pdfFormField) {
+	defer func() {
+		if recover() != nil {
+			fields = nil
+		}
+	}()
+
+	var walk func(field pdf.Value, parent string, inherited pdf.Value, depth int)
+	walk = func(field pdf.Value, parent string, inherited pdf.Value, depth int) {
+		if field.Kind() != pdf.Dict || depth > 32 || len(fields) >= maxFormFields {
+			return
+		}
+		name := parent
+		if partial := strings.TrimSpace(field.Key("T").Text()); partial != "" {
+			name = strings.TrimPrefix(parent+"."+partial, ".")
+		}
+		value := field.Key("V")
+		if value.IsNull() {
+			value = inherited
+		}
+
+		// Kids without a partial name are the widgets of this field.
+		kids := field.Key("Kids")
+		var named []pdf.Value
+		for i := range kids.Len() {
+			if kid := kids.Index(i); !kid.Key("T").IsNull() {
+				named = append(named, kid)
+			}
+		}
+		if len(named) == 0 {
+			if name != "" {
+				fields = append(fields, pdfFormField{Name: name, Value: formValue(value)})
+			}
+			return
+		}
+		for _, kid := range named {
+			walk(kid, name, value, depth+1)
+		}
+	}
+
+	list := r.Trailer().Key("Root").Key("AcroForm").Key("Fields")
+	for i := range list.Len() {
+		walk(list.Index(i), "", pdf.Value{}, 0)
+	}
+	return fields
+}
+
+// formValue returns the text of a field value: the text of text fields, the
+// state of check boxes and radio buttons, and the selected options of
+// choice fields.
+func formValue(v pdf.Value) string {
+	switch v.Kind() {
+	case pdf.String:
+		return v.Text()
+	case pdf.Name:
+		return v.Name()
+	case pdf.Array:
+		var options []string
+		for i := range v.Len() {
+			options = append(options, formValue(v.Index(i)))
+		}
+		return strings.Join(options, ", ")
+	}
+	return ""
+}
+
+// writeFormFields writes the form fields as a table of names and values,
+// written with the options.
+func writeFormFields(buf *strings.Builder, fields []pdfFormField, tables utils.TableOptions) {
+	if len(fields) == 0 {
+		return
+	}
+	if buf.Len() > 0 {
+		buf.WriteString("\n")
+	}
+	buf.WriteString("## Form fields\n\n")
+	table := tables.NewTableWriter(buf)
+	table.WriteRow([]string{"Field", "Value"})
+	for _, field := range fields {
+		table.WriteRow([]string{field.Name, field.Value})
+	}
+	table.Flush()
+}
@@ -0,0 +1,477 @@
+package converters
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// pdfSegment is a run of glyphs on one baseline, not interrupted by a gap
+// wider than a few characters. Columns and table cells become separate
+// segments of the same baseline.
+type pdfSegment struct {
+	Text   string
+	X0, X1 float64
+	Y      float64
+	Size   float64
+	Font   string
+}
+
+// pdfParagraph is a block of consecutive lines sharing a font size, in
+// reading order.
+type pdfParagraph struct {
+	Text string
+	Size float64
+	Font string
+	Page int
+
+	// Y is the baseline of the first line, in points from the page bottom.
+	Y float64
+
+	// Level is the heading level of the paragraph, zero for body text.
+	Level int
+}
+
+// bold reports whether the paragraph font is a bold face.
+func (p *pdfParagraph) bold() bool {
+	font := strings.ToLower(p.Font)
+	if strings.HasPrefix(font, "cmbx") || strings.HasPrefix(font, "sfbx") {
+		// TeX bold extended faces, such as CMBX10.
+		return true
+	}
+	return strings.Contains(font, "bold") || strings.Contains(font, "black") || strings.Contains(font, "heavy")
+}
+
+// pageParagraphs extracts the text of a page as paragraphs in reading order.
+// Multi-column layouts are read column by column, with text spanning the
+// columns, such as titles, breaking the columns into bands. Link annotations
+// become markdown links around the text they cover, and comment annotations
+// footnote references after it.
+func pageParagraphs(page pdf.Page, number int, annotations []pdfAnnotation) (paragraphs []pdfParagraph, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New(fmt.Sprint(r))
+		}
+	}()
+
+	if page.V.IsNull() {
+		return nil, nil
+	}
+	segments, unplaced := pageSegments(page.Content().Text, annotations)
+	for _, block := range orderSegments(segments) {
+		for _, p := range blockParagraphs(block) {
+			p.Page = number
+			paragraphs = append(paragraphs, p)
+		}
+	}
+	placeNotes(paragraphs, unplaced)
+	return paragraphs, nil
+}
+
+// glyphWidth returns the advance of a glyph, estimated from its font size
+// when the font declares no widths.
+func glyphWidth(g pdf.Text) float64 {
+	if g.W > 0 {
+		return g.W
+	}
+	return g.FontSize / 2
+}
+
+// isTextGlyph reports whether a glyph draws text. Line feeds mark text
+// positioning operators rather than spaces, so the gap to the next glyph
+// decides instead, and glyphs without a Unicode mapping decode to control
+// characters.
+func isTextGlyph(g pdf.Text) bool {
+	if g.S == "" || g.S == "\n" || g.S == "\r" {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(g.S)
+	return !unicode.IsControl(r)
+}
+
+// pageSegments groups the glyphs of a page, in drawing order, into
+// segments. A space is inserted where glyphs are apart by more than a
+// fraction of the font size, as many PDFs position words without spaces.
+// Link text is wrapped in markdown links and the footnote references of
+// comments follow the last glyph they cover; it returns the comments
+// covering no glyph.
+func pageSegments(glyphs []pdf.Text, annotations []pdfAnnotation) ([]pdfSegment, []pdfAnnotation) {
+	var segments []pdfSegment
+	var text strings.Builder
+	var cur *pdfSegment
+	var prev pdf.Text
+	link := -1
+
+	// Footnote references go after the last glyph covered by the comment.
+	notesAfter := make(map[int][]string)
+	var unplaced []pdfAnnotation
+	for _, a := range annotations {
+		if a.Note == "" {
+			continue
+		}
+		last := -1
+		for i, g := range glyphs {
+			if isTextGlyph(g) && strings.TrimSpace(g.S) != "" && a.covers(g) {
+				last = i
+			}
+		}
+		if last < 0 {
+			unplaced = append(unplaced, a)
+			continue
+		}
+		notesAfter[last] = append(notesAfter[last], a.Note)
+	}
+
+	closeLink := func() {
+		if link < 0 {
+			return
+		}
+		trailing := strings.HasSuffix(text.String(), " ")
+		body := strings.TrimRight(text.String(), " ")
+		text.Reset()
+		text.WriteString(body + "](" + annotations[link].URI + ")")
+		if trailing {
+			text.WriteByte(' ')
+		}
+		link = -1
+	}
+	flush := func() {
+		closeLink()
+		if cur != nil {
+			cur.Text = strings.TrimSpace(text.String())
+			if cur.Text != "" {
+				segments = append(segments, *cur)
+			}
+		}
+		cur = nil
+		text.Reset()
+	}
+
+	for i, g := range glyphs {
+		if !isTextGlyph(g) {
+			continue
+		}
+		space := false
+		if cur != nil {
+			// Superscripts and subscripts stay on the line of the text
+			// around them.
+			size := math.Max(math.Max(g.FontSize, cur.Size), 1)
+			gap := g.X - (prev.X + glyphWidth(prev))
+			switch {
+			case math.Abs(g.Y-cur.Y) > size/2, gap > size*1.5, gap < -size:
+				flush()
+			case gap > size/5 && !strings.HasSuffix(text.String(), " ") && g.S != " ":
+				space = true
+			}
+		}
+		if cur == nil {
+			if strings.TrimSpace(g.S) == "" {
+				continue
+			}
+			cur = &pdfSegment{X0: g.X, X1: g.X, Y: g.Y, Size: g.FontSize, Font: g.Font}
+		}
+
+		target := -1
+		if strings.TrimSpace(g.S) != "" {
+			target = linkAt(annotations, g)
+		} else if link >= 0 && annotations[link].covers(g) {
+			target = link
+		}
+		if target != link {
+			closeLink()
+		}
+		if space {
+			text.WriteByte(' ')
+		}
+		if target >= 0 && target != link {
+			text.WriteByte('[')
+			link = target
+		}
+
+		if g.S != " " || !strings.HasSuffix(text.String(), " ") {
+			text.WriteString(g.S)
+		}
+		for _, note := range notesAfter[i] {
+			text.WriteString(note)
+		}
+		cur.X1 = math.Max(cur.X1, g.X+glyphWidth(g))
+		if strings.TrimSpace(g.S) != "" && g.FontSize > cur.Size {
+			cur.Size, cur.Font, cur.Y = g.FontSize, g.Font, g.Y
+		}
+		prev = g
+	}
+	flush()
+	return segments, unplaced
+}
+
+// placeNotes appends the footnote references of comments covering no text,
+// such as sticky notes in the margin, to the paragraph closest to them.
+func placeNotes(paragraphs []pdfParagraph, notes []pdfAnnotation) {
+	for _, note := range notes {
+		closest := -1
+		for i, p := range paragraphs {
+			if closest < 0 || math.Abs(p.Y-note.Rect[3]) < math.Abs(paragraphs[closest].Y-note.Rect[3]) {
+				closest = i
+			}
+		}
+		if closest >= 0 {
+			paragraphs[closest].Text += note.Note
+		}
+	}
+}
+
+// sortSegments sorts segments top to bottom, then left to right on the
+// same baseline.
+func sortSegments(segments []pdfSegment) {
+	sort.SliceStable(segments, func(i, j int) bool {
+		a, b := segments[i], segments[j]
+		if math.Abs(a.Y-b.Y) > math.Min(a.Size, b.Size)/3 {
+			return a.Y > b.Y
+		}
+		return a.X0 < b.X0
+	})
+}
+
+// orderSegments splits the segments of a page into blocks read one after
+// the other: a single block for single-column text, or a block per column
+// for each band between the text spanning the columns.
+func orderSegments(segments []pdfSegment) [][]pdfSegment {
+	sortSegments(segments)
+	if len(segments) == 0 {
+		return nil
+	}
+	lo, hi, ok := findGutter(segments)
+	if !ok {
+		return [][]pdfSegment{segments}
+	}
+
+	var blocks [][]pdfSegment
+	var left, right, spanning []pdfSegment
+	flush := func() {
+		if len(spanning) > 0 {
+			blocks = append(blocks, spanning)
+		}
+		if len(left) > 0 {
+			blocks = append(blocks, orderSegments(left)...)
+		}
+		if len(right) > 0 {
+			blocks = append(blocks, orderSegments(right)...)
+		}
+		left, right, spanning = nil, nil, nil
+	}
+	for _, s := range segments {
+		switch {
+		case s.X1 <= lo:
+			left = append(left, s)
+		case s.X0 >= hi:
+			right = append(right, s)
+		default:
+			if len(left) > 0 || len(right) > 0 {
+				flush()
+			}
+			spanning = append(spanning, s)
+		}
+	}
+	flush()
+	return blocks
+}
+
+// findGutter looks for a vertical strip no column-width segment crosses,
+// separating two columns of running text. Segments wider than most of the
+// text area may cross it, as they span the columns. Columns of short cells,
+// as in tables, are not split, so that table rows are read across.
+func findGutter(segments []pdfSegment) (lo, hi float64, ok bool) {
+	if len(segments) < 6 {
+		return 0, 0, false
+	}
+	minX, maxX := math.Inf(1), math.Inf(-1)
+	sizes := make([]float64, 0, len(segments))
+	for _, s := range segments {
+		minX, maxX = math.Min(minX, s.X0), math.Max(maxX, s.X1)
+		sizes = append(sizes, s.Size)
+	}
+	slices.Sort(sizes)
+	size := sizes[len(sizes)/2]
+	width := maxX - minX
+
+	var narrow []pdfSegment
+	for _, s := range segments {
+		if s.X1-s.X0 < width*0.6 {
+			narrow = append(narrow, s)
+		}
+	}
+	slices.SortFunc(narrow, func(a, b pdfSegment) int {
+		if a.X0 < b.X0 {
+			return -1
+		}
+		if a.X0 > b.X0 {
+			return 1
+		}
+		return 0
+	})
+
+	// Sweep the narrow segments left to right for the widest uncovered strip
+	// in the middle of the text area.
+	best := 0.0
+	reach := math.Inf(-1)
+	for _, s := range narrow {
+		if reach > math.Inf(-1) && s.X0-reach > best && reach > minX+width*0.2 && s.X0 < maxX-width*0.2 {
+			best, lo, hi = s.X0-reach, reach, s.X0
+		}
+		reach = math.Max(reach, s.X1)
+	}
+	if best < size*1.5 {
+		return 0, 0, false
+	}
+
+	var leftCount, rightCount, leftRunes, rightRunes int
+	for _, s := range narrow {
+		if s.X1 <= lo {
+			leftCount++
+			leftRunes += utf8.RuneCountInString(s.Text)
+		} else {
+			rightCount++
+			rightRunes += utf8.RuneCountInString(s.Text)
+		}
+	}
+	// Both columns hold a fair share of the lines, and lines long enough
+	// for running text.
+	least := max(3, len(narrow)/5)
+	if leftCount < least || rightCount < least || leftRunes/leftCount < 15 || rightRunes/rightCount < 15 {
+		return 0, 0, false
+	}
+	return lo, hi, true
+}
+
+// blockParagraphs joins the segments of a block into lines and the lines
+// into paragraphs. A paragraph ends at a vertical gap wider than the line
+// spacing or where the font size changes.
+func blockParagraphs(block []pdfSegment) []pdfParagraph {
+	var lines []pdfSegment
+	for _, s := range block {
+		if n := len(lines); n > 0 && math.Abs(lines[n-1].Y-s.Y) <= math.Min(lines[n-1].Size, s.Size)/3 {
+			lines[n-1].Text += " " + s.Text
+			lines[n-1].X1 = s.X1
+			continue
+		}
+		lines = append(lines, s)
+	}
+
+	var paragraphs []pdfParagraph
+	var text string
+	var top float64
+	var prev pdfSegment
+	flush := func() {
+		if text != "" {
+			paragraphs = append(paragraphs, pdfParagraph{Text: text, Size: prev.Size, Font: prev.Font, Y: top})
+		}
+		text = ""
+	}
+	for i, line := range lines {
+		if i > 0 {
+			gap := prev.Y - line.Y
+			if gap <= 0 || gap > math.Max(prev.Size, line.Size)*1.6 || math.Abs(prev.Size-line.Size) > 0.5 {
+				flush()
+			}
+		}
+		if text == "" {
+			top = line.Y
+		}
+		text = joinLine(text, line.Text)
+		prev = line
+	}
+	flush()
+	return paragraphs
+}
+
+// joinLine appends the next line to the paragraph text, separated by a space
+// or joined to a word hyphenated at the line end.
+func joinLine(text, next string) string {
+	if text == "" {
+		return next
+	}
+	if before, ok := strings.CutSuffix(text, "-"); ok {
+		last, _ := utf8.DecodeLastRuneInString(before)
+		first, _ := utf8.DecodeRuneInString(next)
+		if unicode.IsLetter(last) && unicode.IsLower(first) {
+			return before + next
+		}
+	}
+	return text + " " + next
+}
+
+// inferHeadings sets heading levels from font sizes and weights. The size
+// covering the most text is the body size; the distinct larger sizes of short
+// paragraphs become H1 to H3 from the largest down, and short bold paragraphs
+// around the body size become the level below the smallest larger size, H3 at
+// most.
+func inferHeadings(paragraphs []pdfParagraph) {
+	weights := make(map[float64]int)
+	for _, p := range paragraphs {
+		weights[roundSize(p.Size)] += utf8.RuneCountInString(p.Text)
+	}
+	body, most := 0.0, 0
+	for size, weight := range weights {
+		if weight > most || (weight == most && size < body) {
+			body, most = size, weight
+		}
+	}
+
+	var sizes []float64
+	for _, p := range paragraphs {
+		if size := roundSize(p.Size); size >= body*1.15 && isHeadingText(p.Text) && !slices.Contains(sizes, size) {
+			sizes = append(sizes, size)
+		}
+	}
+	slices.Sort(sizes)
+	slices.Reverse(sizes)
+	if len(sizes) > 3 {
+		sizes = sizes[:3]
+	}
+
+	for i := range paragraphs {
+		p := &paragraphs[i]
+		if !isHeadingText(p.Text) {
+			continue
+		}
+		size := roundSize(p.Size)
+		switch {
+		case size >= body*1.15:
+			level := len(sizes)
+			for l, s := range sizes {
+				if size >= s {
+					level = l + 1
+					break
+				}
+			}
+			p.Level = level
+		case size >= body*0.85 && p.bold():
+			p.Level = min(len(sizes)+1, 3)
+		}
+	}
+}
+
+// roundSize rounds a font size to half a point, so sizes scaled by slightly
+// different text matrices cluster together.
+func roundSize(size float64) float64 {
+	return math.Round(size*2) / 2
+}
+
+// isHeadingText reports whether a paragraph reads like a heading: short, with
+// letters, and not ending like a sentence or a list of names.
+func isHeadingText(text string) bool {
+	if utf8.RuneCountInString(text) > 120 || strings.HasSuffix(text, ",") || strings.HasSuffix(text, ":") {
+		return false
+	}
+	if strings.HasSuffix(text, ".") && len(strings.Fields(text)) > 3 {
+		return false
+	}
+	return strings.IndexFunc(text, unicode.IsLetter) >= 0
+}
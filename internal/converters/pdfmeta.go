@@ -0,0 +1,206 @@
+package converters
+
+import (
+	"cmp"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/flaviodelgrosso/marky/internal/utils"
+	"github.com/ledongthuc/pdf"
+)
+
+// pdfMetadata is the descriptive metadata of a PDF document.
+type pdfMetadata struct {
+	Title    string
+	Author   string
+	Subject  string
+	Keywords []string
+	Created  string
+	Modified string
+}
+
+// frontMatter returns the metadata as a YAML front matter block, empty when
+// the document has no metadata.
+func (m pdfMetadata) frontMatter() string {
+	return utils.FrontMatter([]utils.FrontMatterField{
+		{Key: "title", Value: m.Title},
+		{Key: "author", Value: m.Author},
+		{Key: "subject", Value: m.Subject},
+		{Key: "keywords", List: m.Keywords},
+		{Key: "created", Value: m.Created},
+	})
+}
+
+// readMetadata reads the document metadata from the XMP metadata stream of
+// the catalog and the document information dictionary. XMP values take
+// precedence, as they supersede the information dictionary since PDF 2.0.
+func readMetadata(r *pdf.Reader) (meta pdfMetadata) {
+	defer func() {
+		if recover() != nil {
+			meta = pdfMetadata{}
+		}
+	}()
+
+	info := r.Trailer().Key("Info")
+	meta = pdfMetadata{
+		Title:    strings.TrimSpace(info.Key("Title").Text()),
+		Author:   strings.TrimSpace(info.Key("Author").Text()),
+		Subject:  strings.TrimSpace(info.Key("Subject").Text()),
+		Keywords: splitKeywords(info.Key("Keywords").Text()),
+		Created:  pdfDate(info.Key("CreationDate").Text()),
+		Modified: pdfDate(info.Key("ModDate").Text()),
+	}
+
+	if stream := r.Trailer().Key("Root").Key("Metadata"); stream.Kind() == pdf.Stream {
+		if xmp, err := readXmp(stream.Reader()); err == nil {
+			meta = xmp.merge(meta)
+		}
+	}
+	return meta
+}
+
+// splitKeywords splits a keywords string separated by commas or semicolons.
+func splitKeywords(keywords string) []string {
+	var list []string
+	for _, keyword := range strings.FieldsFunc(keywords, func(r rune) bool { return r == ',' || r == ';' }) {
+		if keyword = strings.TrimSpace(keyword); keyword != "" {
+			list = append(list, keyword)
+		}
+	}
+	return list
+}
+
+// pdfDate converts a PDF date string, D:YYYYMMDDHHmmSSOHH'mm', to ISO 8601,
+// keeping the precision of the original. Unparsable dates are kept as is.
+func pdfDate(date string) string {
+	date = strings.TrimPrefix(strings.TrimSpace(date), "D:")
+	digits := len(date) - len(strings.TrimLeft(date, "0123456789"))
+	if digits < 4 || digits%2 != 0 || digits > 14 {
+		return date
+	}
+
+	d := date[:digits]
+	iso := d[:4]
+	for i, sep := range []string{"-", "-", "T", ":", ":"} {
+		if len(d) < 6+2*i {
+			break
+		}
+		iso += sep + d[4+2*i:6+2*i]
+	}
+	if len(d) == 10 {
+		iso += ":00"
+	}
+	if len(d) < 10 {
+		return iso
+	}
+
+	switch zone := strings.ReplaceAll(date[digits:], "'", ""); {
+	case zone == "Z":
+		iso += "Z"
+	case len(zone) == 5 && (zone[0] == '+' || zone[0] == '-'):
+		iso += zone[:3] + ":" + zone[3:]
+	case len(zone) == 3 && (zone[0] == '+' || zone[0] == '-'):
+		iso += zone + ":00"
+	}
+	return iso
+}
+
+// xmpDescription holds the Dublin Core, PDF and XMP basic properties of an
+// rdf:Description element. Simple properties may also be given as
+// attributes.
+type xmpDescription struct {
+	Title       []string   `xml:"title>Alt>li"`
+	Creator     []string   `xml:"creator>Seq>li"`
+	Description []string   `xml:"description>Alt>li"`
+	Subject     []string   `xml:"subject>Bag>li"`
+	Keywords    string     `xml:"Keywords"`
+	CreateDate  string     `xml:"CreateDate"`
+	ModifyDate  string     `xml:"ModifyDate"`
+	Attrs       []xml.Attr `xml:",any,attr"`
+}
+
+// readXmp reads the rdf:Description elements of an XMP packet into one
+// description.
+func readXmp(r io.Reader) (xmpDescription, error) {
+	var merged xmpDescription
+	decoder := xml.NewDecoder(r)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return merged, nil
+		}
+		if err != nil {
+			return merged, fmt.Errorf("unable to parse XMP metadata: %w", err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "Description" {
+			continue
+		}
+
+		var d xmpDescription
+		if err := decoder.DecodeElement(&d, &start); err != nil {
+			return merged, fmt.Errorf("unable to parse XMP metadata: %w", err)
+		}
+		for _, attr := range d.Attrs {
+			switch attr.Name.Local {
+			case "Keywords":
+				d.Keywords = attr.Value
+			case "CreateDate":
+				d.CreateDate = attr.Value
+			case "ModifyDate":
+				d.ModifyDate = attr.Value
+			}
+		}
+		merged.Title = append(merged.Title, d.Title...)
+		merged.Creator = append(merged.Creator, d.Creator...)
+		merged.Description = append(merged.Description, d.Description...)
+		merged.Subject = append(merged.Subject, d.Subject...)
+		merged.Keywords = cmp.Or(merged.Keywords, d.Keywords)
+		merged.CreateDate = cmp.Or(merged.CreateDate, d.CreateDate)
+		merged.ModifyDate = cmp.Or(merged.ModifyDate, d.ModifyDate)
+	}
+}
+
+// merge returns the metadata with the XMP properties replacing the values
+// they define.
+func (d xmpDescription) merge(meta pdfMetadata) pdfMetadata {
+	if title := firstNonEmpty(d.Title); title != "" {
+		meta.Title = title
+	}
+	var creators []string
+	for _, creator := range d.Creator {
+		if creator = strings.TrimSpace(creator); creator != "" {
+			creators = append(creators, creator)
+		}
+	}
+	if len(creators) > 0 {
+		meta.Author = strings.Join(creators, ", ")
+	}
+	if description := firstNonEmpty(d.Description); description != "" {
+		meta.Subject = description
+	}
+	if keywords := splitKeywords(d.Keywords); len(keywords) > 0 {
+		meta.Keywords = keywords
+	} else if keywords := splitKeywords(strings.Join(d.Subject, ",")); len(keywords) > 0 {
+		meta.Keywords = keywords
+	}
+	if created := strings.TrimSpace(d.CreateDate); created != "" {
+		meta.Created = created
+	}
+	if modified := strings.TrimSpace(d.ModifyDate); modified != "" {
+		meta.Modified = modified
+	}
+	return meta
+}
+
+// firstNonEmpty returns the first value that is not blank, trimmed.
+func firstNonEmpty(values []string) string {
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,140 @@
+package converters
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PivotTableDefinition represents a pivot table part of an XLSX file.
+type PivotTableDefinition struct {
+	Name     string `xml:"name,attr"`
+	Location struct {
+		Ref string `xml:"ref,attr"`
+	} `xml:"location"`
+	RowFields  []PivotField     `xml:"rowFields>field"`
+	ColFields  []PivotField     `xml:"colFields>field"`
+	PageFields []PivotPageField `xml:"pageFields>pageField"`
+	DataFields []PivotDataField `xml:"dataFields>dataField"`
+}
+
+// PivotField refers to a cache field by index. The index -2 stands for the
+// values pseudo-field that lays out multiple data fields.
+type PivotField struct {
+	X int `xml:"x,attr"`
+}
+
+type PivotPageField struct {
+	Fld int `xml:"fld,attr"`
+}
+
+type PivotDataField struct {
+	Name     string `xml:"name,attr"`
+	Fld      int    `xml:"fld,attr"`
+	Subtotal string `xml:"subtotal,attr"`
+}
+
+// PivotCacheDefinition represents the cache definition a pivot table reads
+// its source range and field names from.
+type PivotCacheDefinition struct {
+	RecordCount int `xml:"recordCount,attr"`
+	Source      struct {
+		Ref   string `xml:"ref,attr"`
+		Sheet string `xml:"sheet,attr"`
+		Name  string `xml:"name,attr"`
+	} `xml:"cacheSource>worksheetSource"`
+	Fields []struct {
+		Name string `xml:"name,attr"`
+	} `xml:"cacheFields>cacheField"`
+}
+
+// fieldName returns the name of a cache field, or an empty string when the
+// index is out of range.
+func (c *PivotCacheDefinition) fieldName(index int) string {
+	if index < 0 || index >= len(c.Fields) {
+		return ""
+	}
+	return c.Fields[index].Name
+}
+
+// source returns the range or named range the pivot table summarizes.
+func (c *PivotCacheDefinition) source() string {
+	if c.Source.Name != "" {
+		return c.Source.Name
+	}
+	if c.Source.Sheet == "" {
+		return c.Source.Ref
+	}
+
+	sheet := c.Source.Sheet
+	if strings.ContainsAny(sheet, " '!") {
+		sheet = "'" + strings.ReplaceAll(sheet, "'", "''") + "'"
+	}
+	return sheet + "!" + c.Source.Ref
+}
+
+// summarizePivotTable describes a pivot table by its location, source range
+// and the fields laid out on its rows, columns, filters and values.
+func summarizePivotTable(pivot *PivotTableDefinition, cache *PivotCacheDefinition) string {
+	var summary strings.Builder
+
+	summary.WriteString("### Pivot table")
+	if pivot.Name != "" {
+		summary.WriteString(": " + pivot.Name)
+	}
+	summary.WriteString("\n\n")
+
+	if pivot.Location.Ref != "" {
+		fmt.Fprintf(&summary, "- Location: %s\n", pivot.Location.Ref)
+	}
+	if source := cache.source(); source != "" {
+		fmt.Fprintf(&summary, "- Source: %s", source)
+		if cache.RecordCount > 0 {
+			fmt.Fprintf(&summary, " (%d records)", cache.RecordCount)
+		}
+		summary.WriteString("\n")
+	}
+
+	fieldNames := func(fields []PivotField) []string {
+		var names []string
+		for _, f := range fields {
+			if name := cache.fieldName(f.X); name != "" {
+				names = append(names, name)
+			}
+		}
+		return names
+	}
+	var filters []string
+	for _, f := range pivot.PageFields {
+		if name := cache.fieldName(f.Fld); name != "" {
+			filters = append(filters, name)
+		}
+	}
+	var values []string
+	for _, f := range pivot.DataFields {
+		subtotal := f.Subtotal
+		if subtotal == "" {
+			subtotal = "sum"
+		}
+		value := fmt.Sprintf("%s of %s", subtotal, cache.fieldName(f.Fld))
+		if f.Name != "" {
+			value = fmt.Sprintf("%s (%s)", f.Name, value)
+		}
+		values = append(values, value)
+	}
+
+	for _, layout := range []struct {
+		label  string
+		fields []string
+	}{
+		{"Rows", fieldNames(pivot.RowFields)},
+		{"Columns", fieldNames(pivot.ColFields)},
+		{"Filters", filters},
+		{"Values", values},
+	} {
+		if len(layout.fields) > 0 {
+			fmt.Fprintf(&summary, "- %s: %s\n", layout.label, strings.Join(layout.fields, ", "))
+		}
+	}
+
+	return summary.String()
+}
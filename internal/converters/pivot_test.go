@@ -0,0 +1,31 @@
+package converters
+
+import "testing"
+
+func TestSummarizePivotTable(t *testing.T) {
+	pivot := &PivotTableDefinition{
+		Name:       "Sales",
+		RowFields:  []PivotField{{X: 0}},
+		ColFields:  []PivotField{{X: -2}, {X: 1}},
+		PageFields: []PivotPageField{{Fld: 2}},
+		DataFields: []PivotDataField{{Fld: 3}, {Name: "Orders", Fld: 3, Subtotal: "count"}},
+	}
+	pivot.Location.Ref = "A3:D20"
+
+	cache := &PivotCacheDefinition{RecordCount: 120}
+	cache.Source.Sheet = "Raw Data"
+	cache.Source.Ref = "A1:D121"
+	for _, name := range []string{"Region", "Quarter", "Year", "Amount"} {
+		cache.Fields = append(cache.Fields, struct {
+			Name string `xml:"name,attr"`
+		}{name})
+	}
+
+	result := summarizePivotTable(pivot, cache)
+	expected := "### Pivot table: Sales\n\n- Location: A3:D20\n- Source: 'Raw Data'!A1:D121 (120 records)\n" +
+		"- Rows: Region\n- Columns: Quarter\n- Filters: Year\n- Values: sum of Amount, Orders (count of Amount)\n"
+
+	if result != expected {
+		t.Errorf("summarizePivotTable() = %q, want %q", result, expected)
+	}
+}
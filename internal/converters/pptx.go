@@ -3,79 +3,192 @@ package converters
 import (
 	"archive/zip"
 	"bytes"
+	"cmp"
+	"context"
 	"encoding/base64"
 	"encoding/xml"
 	"errors"
 	"fmt"
-	"html"
 	"io"
-	"os"
+	"path"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/flaviodelgrosso/marky/internal/utils"
 )
 
 // PptxConverter handles loading and converting PPTX files to markdown.
+// Slideshow (PPSX), macro-enabled (PPTM, PPSM) and template (POTX, POTM)
+// variants share the same package layout and are converted the same way.
 type PptxConverter struct {
 	BaseConverter
+
+	// Options configures the conversion.
+	Options ConvertOptions
+
+	// Progress, when set, is called after each slide.
+	Progress ProgressFunc
 }
 
 // NewPptxConverter creates a new PPTX converter with appropriate MIME types and extensions.
 func NewPptxConverter() Converter {
 	return &PptxConverter{
 		BaseConverter: NewBaseConverter(
-			[]string{".pptx"},
+			[]string{".pptx", ".ppsx", ".pptm", ".ppsm", ".potx", ".potm"},
 			[]string{
 				"application/vnd.openxmlformats-officedocument.presentationml.presentation",
 				"application/vnd.openxmlformats-officedocument.presentationml",
+				"application/vnd.openxmlformats-officedocument.presentationml.slideshow",
+				"application/vnd.openxmlformats-officedocument.presentationml.template",
+				"application/vnd.ms-powerpoint.presentation.macroEnabled.12",
+				"application/vnd.ms-powerpoint.slideshow.macroEnabled.12",
+				"application/vnd.ms-powerpoint.template.macroEnabled.12",
 			},
 		),
+		Options: ConvertOptions{KeepDataURIs: true},
 	}
 }
 
+// SetProgress sets the function called after each slide.
+func (p *PptxConverter) SetProgress(progress ProgressFunc) {
+	p.Progress = progress
+}
+
+// Capabilities returns what the converter can extract from PPTX files.
+func (p *PptxConverter) Capabilities() Capabilities {
+	return Capabilities{Images: true, Tables: true, Password: true}
+}
+
 // Load reads a PPTX file and converts it to markdown format.
-func (*PptxConverter) Load(path string) (string, error) {
-	data, err := os.ReadFile(path)
+func (p *PptxConverter) Load(path string) (string, error) {
+	return p.LoadContext(context.Background(), path)
+}
+
+// LoadContext converts a PPTX file like Load, stopping before the next
+// slide once ctx is done.
+func (p *PptxConverter) LoadContext(ctx context.Context, path string) (string, error) {
+	file, err := readFile(path, p.Options.MmapThreshold)
 	if err != nil {
 		return "", fmt.Errorf("failed to read PPTX file: %w", err)
 	}
+	defer file.Close()
 
-	result, err := convertToMarkdown(data, ConvertOptions{KeepDataURIs: true})
+	result, err := convertToMarkdown(ctx, file.Data, p.Options, p.Progress)
 	if err != nil {
-		return "", fmt.Errorf("failed to convert PPTX to markdown: %w", err)
+		return "", fmt.Errorf("failed to convert PPTX to markdown: %w", corrupt(err))
 	}
 	return result.Markdown, nil
 }
 
+// LoadTo converts a PPTX file like LoadContext, writing the markdown to w
+// one slide at a time.
+func (p *PptxConverter) LoadTo(ctx context.Context, w io.Writer, path string) error {
+	file, err := readFile(path, p.Options.MmapThreshold)
+	if err != nil {
+		return fmt.Errorf("failed to read PPTX file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := writePresentation(ctx, w, file.Data, p.Options, p.Progress); err != nil {
+		return fmt.Errorf("failed to convert PPTX to markdown: %w", corrupt(err))
+	}
+	return nil
+}
+
+// LoadBytes converts a presentation held in memory like Load.
+func (p *PptxConverter) LoadBytes(ctx context.Context, data []byte) (string, error) {
+	result, err := convertToMarkdown(ctx, data, p.Options, p.Progress)
+	if err != nil {
+		return "", fmt.Errorf("failed to convert PPTX to markdown: %w", corrupt(err))
+	}
+	return result.Markdown, nil
+}
+
+// LoadReport converts a PPTX file like Load and reports its slide count,
+// with a warning for each slide and chart that could not be read.
+func (p *PptxConverter) LoadReport(ctx context.Context, path string) (string, *Report, error) {
+	file, err := readFile(path, p.Options.MmapThreshold)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read PPTX file: %w", err)
+	}
+	defer file.Close()
+
+	result, err := convertToMarkdown(ctx, file.Data, p.Options, p.Progress)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to convert PPTX to markdown: %w", corrupt(err))
+	}
+	return result.Markdown, &result.Report, nil
+}
+
 // DocumentConverterResult represents the conversion result
 type DocumentConverterResult struct {
 	Markdown string
+	Report   Report
 }
 
-// ConvertOptions holds configuration for the conversion
-type ConvertOptions struct {
-	KeepDataURIs bool
+// Convert converts PPTX content to Markdown
+func convertToMarkdown(ctx context.Context, data []byte, options ConvertOptions, progress ProgressFunc) (*DocumentConverterResult, error) {
+	var markdown strings.Builder
+	report, err := writePresentation(ctx, &markdown, data, options, progress)
+	if err != nil {
+		return nil, err
+	}
+	return &DocumentConverterResult{Markdown: markdown.String(), Report: *report}, nil
 }
 
-// Convert converts PPTX content to Markdown
-func convertToMarkdown(data []byte, options ConvertOptions) (*DocumentConverterResult, error) {
+// writePresentation writes the markdown of PPTX content to w one slide at a
+// time, without leading or trailing white space, and reports on it. Each
+// slide written is reported to progress, which may be nil.
+func writePresentation(ctx context.Context, w io.Writer, data []byte, options ConvertOptions, progress ProgressFunc) (*Report, error) {
+	selection, err := utils.ParseNumberRange(options.Slides)
+	if err != nil {
+		return nil, fmt.Errorf("invalid slide selection: %w", err)
+	}
+
+	if data, err = decryptPackage(data, options.Password); err != nil {
+		return nil, err
+	}
 	reader := bytes.NewReader(data)
-	zipReader, err := zip.NewReader(reader, int64(len(data)))
+	zipReader, err := newArchiveReader(reader, int64(len(data)), options.ArchiveLimits)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open PPTX file: %w", err)
+		return nil, fmt.Errorf("failed to open PPTX file: %w", corrupt(err))
 	}
 
 	presentation, err := parsePresentationXML(zipReader)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse presentation: %w", err)
+		return nil, fmt.Errorf("failed to parse presentation: %w", corrupt(err))
 	}
 
-	slides := parseSlides(zipReader, presentation)
+	report := &Report{Parts: len(presentation.SlideIDs)}
+	slides := parseSlides(zipReader, presentation, selection, report)
+	loadSlideMedia(zipReader, slides)
 
-	markdown := convertSlidesToMarkdown(slides, zipReader, options)
+	counter := newProgressCounter(progress, "slide", len(slides))
+	if err := writeSlides(ctx, &trimWriter{w: w}, slides, zipReader, options, counter); err != nil {
+		return nil, err
+	}
+	for _, slide := range slides {
+		warnUnreadCharts(report, slide)
+	}
+	return report, nil
+}
 
-	return &DocumentConverterResult{
-		Markdown: strings.TrimSpace(markdown),
-	}, nil
+// warnUnreadCharts warns of the charts of a slide left out of the markdown
+// because their parts could not be read by loadSlideCharts.
+func warnUnreadCharts(report *Report, slide *Slide) {
+	tree := slide.CommonSlideData.ShapeTree
+	frames := slices.Clone(tree.Tables)
+	for _, group := range tree.Groups {
+		frames = append(frames, group.Tables...)
+	}
+	for _, frame := range frames {
+		if chart := frame.Graphic.GraphicData.Chart; chart != nil && chart.Data == nil {
+			report.warn("slide %d has a chart that could not be read", slide.Number)
+		}
+	}
 }
 
 // Presentation represents the structure of the PPTX presentation
@@ -85,12 +198,17 @@ type Presentation struct {
 
 type SlideID struct {
 	ID  string `xml:"id,attr"`
-	RID string `xml:"r:id,attr"`
+	RID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
 }
 
 type Slide struct {
 	CommonSlideData CommonSlideData `xml:"cSld"`
 	Notes           *Notes          `xml:"notes,omitempty"`
+
+	// Number is the 1-based position of the slide in the presentation.
+	Number int `xml:"-"`
+
+	rels map[string]Relationship
 }
 
 type CommonSlideData struct {
@@ -105,23 +223,55 @@ type ShapeTree struct {
 }
 
 type Shape struct {
-	TextBody *TextBody `xml:"txBody"`
-	NvSpPr   NvSpPr    `xml:"nvSpPr"`
+	TextBody *TextBody       `xml:"txBody"`
+	NvSpPr   NvSpPr          `xml:"nvSpPr"`
+	SpPr     ShapeProperties `xml:"spPr"`
 }
 
 type Pic struct {
-	NvPicPr  NvPicPr  `xml:"nvPicPr"`
-	BlipFill BlipFill `xml:"blipFill"`
+	NvPicPr  NvPicPr         `xml:"nvPicPr"`
+	BlipFill BlipFill        `xml:"blipFill"`
+	SpPr     ShapeProperties `xml:"spPr"`
+
+	// Media describes the audio or video the picture stands for, resolved
+	// from the slide relationships after parsing.
+	Media *MediaInfo `xml:"-"`
+}
+
+// MediaInfo describes an audio or video clip placed on a slide.
+type MediaInfo struct {
+	Kind        string
+	Name        string
+	ContentType string
+	Duration    time.Duration
 }
 
 type Table struct {
-	Graphic Graphic `xml:"graphic"`
+	Graphic Graphic   `xml:"graphic"`
+	Xfrm    Transform `xml:"xfrm"`
 }
 
 type Group struct {
-	Shapes []Shape `xml:"sp"`
-	Pics   []Pic   `xml:"pic"`
-	Tables []Table `xml:"graphicFrame"`
+	GrpSpPr ShapeProperties `xml:"grpSpPr"`
+	Shapes  []Shape         `xml:"sp"`
+	Pics    []Pic           `xml:"pic"`
+	Tables  []Table         `xml:"graphicFrame"`
+}
+
+// ShapeProperties holds the visual properties of a shape, including its position.
+type ShapeProperties struct {
+	Xfrm Transform `xml:"xfrm"`
+}
+
+// Transform holds the 2D transform of a shape.
+type Transform struct {
+	Off Offset `xml:"off"`
+}
+
+// Offset is the position of a shape's top-left corner in EMUs.
+type Offset struct {
+	X int64 `xml:"x,attr"`
+	Y int64 `xml:"y,attr"`
 }
 
 type TextBody struct {
@@ -129,19 +279,114 @@ type TextBody struct {
 }
 
 type Paragraph struct {
-	Runs []Run `xml:"r"`
+	PPr  *ParagraphProperties `xml:"pPr"`
+	Runs []Run                `xml:"r"`
+}
+
+// ParagraphProperties holds the list level and bullet settings of a paragraph.
+type ParagraphProperties struct {
+	Lvl       int        `xml:"lvl,attr"`
+	BuNone    *struct{}  `xml:"buNone"`
+	BuChar    *struct{}  `xml:"buChar"`
+	BuAutoNum *BuAutoNum `xml:"buAutoNum"`
+}
+
+// BuAutoNum describes an automatically numbered bullet.
+type BuAutoNum struct {
+	Type    string `xml:"type,attr"`
+	StartAt int    `xml:"startAt,attr"`
 }
 
 type Run struct {
-	Text string `xml:"t"`
+	Text string         `xml:"t"`
+	RPr  *RunProperties `xml:"rPr"`
+
+	// Link is the external URL of the run's hyperlink, resolved from the
+	// part relationships after parsing.
+	Link string `xml:"-"`
+}
+
+type RunProperties struct {
+	HlinkClick *HyperlinkRef `xml:"hlinkClick"`
+}
+
+// HyperlinkRef references a hyperlink target through the part relationships.
+type HyperlinkRef struct {
+	RID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+}
+
+// UnmarshalXML decodes a paragraph, keeping line breaks (a:br) as newline
+// runs so that they stay in order with the surrounding text.
+func (p *Paragraph) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "pPr":
+				var pPr ParagraphProperties
+				if err := d.DecodeElement(&pPr, &t); err != nil {
+					return err
+				}
+				p.PPr = &pPr
+			case "r", "fld":
+				var run Run
+				if err := d.DecodeElement(&run, &t); err != nil {
+					return err
+				}
+				p.Runs = append(p.Runs, run)
+			case "br":
+				p.Runs = append(p.Runs, Run{Text: "\n"})
+				if err := d.Skip(); err != nil {
+					return err
+				}
+			default:
+				if err := d.Skip(); err != nil {
+					return err
+				}
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
 }
 
 type NvSpPr struct {
 	CNvPr CNvPr `xml:"cNvPr"`
+	NvPr  NvPr  `xml:"nvPr"`
+}
+
+type NvPr struct {
+	Ph *Placeholder `xml:"ph"`
+}
+
+// Placeholder identifies a shape that inherits its role from the slide layout.
+type Placeholder struct {
+	Type string `xml:"type,attr"`
+	Idx  string `xml:"idx,attr"`
 }
 
 type NvPicPr struct {
-	CNvPr CNvPr `xml:"cNvPr"`
+	CNvPr CNvPr      `xml:"cNvPr"`
+	NvPr  NvPicMedia `xml:"nvPr"`
+}
+
+// NvPicMedia holds the media references of a picture that stands for a clip.
+// Embedded clips are referenced by the p14:media extension, linked clips only
+// by the audioFile/videoFile link.
+type NvPicMedia struct {
+	AudioFile *MediaRef `xml:"audioFile"`
+	VideoFile *MediaRef `xml:"videoFile"`
+	Embedded  *MediaRef `xml:"extLst>ext>media"`
+}
+
+type MediaRef struct {
+	Link  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships link,attr"`
+	Embed string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships embed,attr"`
 }
 
 type CNvPr struct {
@@ -163,6 +408,14 @@ type Graphic struct {
 
 type GraphicData struct {
 	Table TableData `xml:"tbl"`
+	Chart *ChartRef `xml:"chart"`
+}
+
+// ChartRef references a chart part through the slide relationships.
+// The referenced chart is loaded into Data while parsing the slide.
+type ChartRef struct {
+	RID  string      `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	Data *ChartSpace `xml:"-"`
 }
 
 type TableData struct {
@@ -181,6 +434,17 @@ type Notes struct {
 	Text string `xml:",innerxml"`
 }
 
+// SlideLayout represents a slide layout or slide master part, whose
+// placeholders define the default position of slide placeholders.
+type SlideLayout struct {
+	CommonSlideData CommonSlideData `xml:"cSld"`
+}
+
+// NotesSlide represents a notesSlide part.
+type NotesSlide struct {
+	CommonSlideData CommonSlideData `xml:"cSld"`
+}
+
 func parsePresentationXML(zipReader *zip.Reader) (*Presentation, error) {
 	for _, file := range zipReader.File {
 		if file.Name == "ppt/presentation.xml" {
@@ -207,161 +471,527 @@ func parsePresentationXML(zipReader *zip.Reader) (*Presentation, error) {
 	return nil, errors.New("presentation.xml not found")
 }
 
-func parseSlides(zipReader *zip.Reader, presentation *Presentation) []*Slide {
-	var slides []*Slide
+const (
+	relTypeSlide       = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/slide"
+	relTypeNotesSlide  = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/notesSlide"
+	relTypeSlideLayout = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/slideLayout"
+	relTypeSlideMaster = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/slideMaster"
+)
 
-	for i := range presentation.SlideIDs {
-		slideFile := fmt.Sprintf("ppt/slides/slide%d.xml", i+1)
+// parseSlides loads the slides in presentation order, resolving each slide ID
+// through the presentation relationships to its actual slide part. Slides
+// whose part is missing or cannot be parsed are left out with a warning.
+func parseSlides(zipReader *zip.Reader, presentation *Presentation, selection utils.NumberRange, report *Report) []*Slide {
+	rels := partRelationships(zipReader, "ppt/presentation.xml")
 
-		for _, file := range zipReader.File {
-			if file.Name == slideFile {
-				rc, err := file.Open()
-				if err != nil {
-					continue
-				}
+	var slides []*Slide
+	for i, slideID := range presentation.SlideIDs {
+		if !selection.Contains(i + 1) {
+			continue
+		}
 
-				data, err := io.ReadAll(rc)
-				rc.Close()
-				if err != nil {
-					continue
-				}
+		rel, ok := rels[slideID.RID]
+		if !ok || rel.Type != relTypeSlide {
+			report.warn("slide %d left out: no slide part", i+1)
+			continue
+		}
 
-				var slide Slide
-				err = xml.Unmarshal(data, &slide)
-				if err != nil {
-					continue
-				}
+		file, err := findFileInZip(zipReader, rel.Target)
+		if err != nil {
+			report.warn("slide %d left out: %v", i+1, err)
+			continue
+		}
 
-				// Try to parse notes
-				notesFile := fmt.Sprintf("ppt/notesSlides/notesSlide%d.xml", i+1)
-				parseSlideNotes(zipReader, notesFile, &slide)
+		slide := Slide{Number: i + 1}
+		if err := parseXMLFile(file, &slide); err != nil {
+			report.warn("slide %d left out: %v", i+1, err)
+			continue
+		}
 
-				slides = append(slides, &slide)
+		slideRels := partRelationships(zipReader, rel.Target)
+		slide.rels = slideRels
+		resolveHyperlinks(slideRels, slideTextBodies(&slide.CommonSlideData.ShapeTree))
+		loadSlideCharts(zipReader, slideRels, &slide)
+		inheritPlaceholderPositions(loadLayoutChain(zipReader, slideRels), &slide)
+
+		// Try to parse notes
+		for _, slideRel := range slideRels {
+			if slideRel.Type == relTypeNotesSlide {
+				parseSlideNotes(zipReader, slideRel.Target, &slide)
 				break
 			}
 		}
+
+		slides = append(slides, &slide)
 	}
 
 	return slides
 }
 
-func parseSlideNotes(zipReader *zip.Reader, notesFile string, slide *Slide) {
-	for _, file := range zipReader.File {
-		if file.Name == notesFile {
-			rc, err := file.Open()
-			if err != nil {
-				return
+// loadSlideCharts loads the chart parts referenced by the slide's graphic frames.
+// Charts that cannot be resolved or parsed are left empty and skipped on output.
+func loadSlideCharts(zipReader *zip.Reader, rels map[string]Relationship, slide *Slide) {
+	tree := &slide.CommonSlideData.ShapeTree
+	frames := make([]*Table, 0, len(tree.Tables))
+	for i := range tree.Tables {
+		frames = append(frames, &tree.Tables[i])
+	}
+	for i := range tree.Groups {
+		for j := range tree.Groups[i].Tables {
+			frames = append(frames, &tree.Groups[i].Tables[j])
+		}
+	}
+
+	for _, frame := range frames {
+		ref := frame.Graphic.GraphicData.Chart
+		if ref == nil {
+			continue
+		}
+		rel, ok := rels[ref.RID]
+		if !ok {
+			continue
+		}
+		file, err := findFileInZip(zipReader, rel.Target)
+		if err != nil {
+			continue
+		}
+		var chart ChartSpace
+		if err := parseXMLFile(file, &chart); err == nil {
+			ref.Data = &chart
+		}
+	}
+}
+
+// loadLayoutChain loads the slide layout of a slide and the slide master of
+// that layout, in inheritance order. Parts that cannot be loaded are omitted.
+func loadLayoutChain(zipReader *zip.Reader, slideRels map[string]Relationship) []*SlideLayout {
+	var chain []*SlideLayout
+
+	rels := slideRels
+	for _, relType := range []string{relTypeSlideLayout, relTypeSlideMaster} {
+		var target string
+		for _, rel := range rels {
+			if rel.Type == relType {
+				target = rel.Target
+				break
 			}
-			defer rc.Close()
+		}
+		if target == "" {
+			break
+		}
 
-			data, err := io.ReadAll(rc)
-			if err != nil {
-				return
+		file, err := findFileInZip(zipReader, target)
+		if err != nil {
+			break
+		}
+		var layout SlideLayout
+		if err := parseXMLFile(file, &layout); err != nil {
+			break
+		}
+		chain = append(chain, &layout)
+		rels = partRelationships(zipReader, target)
+	}
+
+	return chain
+}
+
+// inheritPlaceholderPositions copies the position of layout or master
+// placeholders onto slide placeholders that do not declare their own.
+func inheritPlaceholderPositions(chain []*SlideLayout, slide *Slide) {
+	tree := &slide.CommonSlideData.ShapeTree
+	for i := range tree.Shapes {
+		shape := &tree.Shapes[i]
+		ph := shape.NvSpPr.NvPr.Ph
+		if ph == nil || shape.SpPr.Xfrm != (Transform{}) {
+			continue
+		}
+		for j, layout := range chain {
+			if xfrm, ok := layout.placeholderTransform(ph, j > 0); ok {
+				shape.SpPr.Xfrm = xfrm
+				break
 			}
+		}
+	}
+}
 
-			// Simple text extraction from notes
-			text := string(data)
-			re := regexp.MustCompile(`<a:t>([^<]*)</a:t>`)
-			matches := re.FindAllStringSubmatch(text, -1)
+// placeholderTransform finds the position of the placeholder matching ph.
+// Layout placeholders match by index when set, masters only by type.
+func (l *SlideLayout) placeholderTransform(ph *Placeholder, isMaster bool) (Transform, bool) {
+	for _, shape := range l.CommonSlideData.ShapeTree.Shapes {
+		lph := shape.NvSpPr.NvPr.Ph
+		if lph == nil || shape.SpPr.Xfrm == (Transform{}) {
+			continue
+		}
 
-			var notesText strings.Builder
-			for _, match := range matches {
-				if len(match) > 1 {
-					notesText.WriteString(match[1])
-					notesText.WriteString(" ")
-				}
+		var match bool
+		if !isMaster && ph.Idx != "" && ph.Idx != "0" {
+			match = lph.Idx == ph.Idx
+		} else {
+			match = placeholderType(lph) == placeholderType(ph)
+		}
+		if match {
+			return shape.SpPr.Xfrm, true
+		}
+	}
+	return Transform{}, false
+}
+
+// placeholderType normalizes a placeholder type so that slide placeholders can
+// be matched against their master: centered titles are titles and untyped
+// object placeholders and subtitles are body placeholders.
+func placeholderType(ph *Placeholder) string {
+	switch ph.Type {
+	case "ctrTitle":
+		return "title"
+	case "", "obj", "subTitle":
+		return "body"
+	default:
+		return ph.Type
+	}
+}
+
+// ContentTypes represents the [Content_Types].xml part of an OOXML package.
+type ContentTypes struct {
+	Defaults []struct {
+		Extension   string `xml:"Extension,attr"`
+		ContentType string `xml:"ContentType,attr"`
+	} `xml:"Default"`
+	Overrides []struct {
+		PartName    string `xml:"PartName,attr"`
+		ContentType string `xml:"ContentType,attr"`
+	} `xml:"Override"`
+}
+
+// contentType returns the declared content type of a package part.
+func (c *ContentTypes) contentType(part string) string {
+	for _, o := range c.Overrides {
+		if strings.TrimPrefix(o.PartName, "/") == part {
+			return o.ContentType
+		}
+	}
+	ext := strings.TrimPrefix(path.Ext(part), ".")
+	for _, d := range c.Defaults {
+		if strings.EqualFold(d.Extension, ext) {
+			return d.ContentType
+		}
+	}
+	return ""
+}
+
+// loadSlideMedia resolves the audio and video clips of the slides' pictures.
+// The duration is read from embedded MP4-family clips when possible.
+func loadSlideMedia(zipReader *zip.Reader, slides []*Slide) {
+	var contentTypes ContentTypes
+	if file, err := findFileInZip(zipReader, "[Content_Types].xml"); err == nil {
+		_ = parseXMLFile(file, &contentTypes)
+	}
+
+	for _, slide := range slides {
+		tree := &slide.CommonSlideData.ShapeTree
+		pics := make([]*Pic, 0, len(tree.Pics))
+		for i := range tree.Pics {
+			pics = append(pics, &tree.Pics[i])
+		}
+		for i := range tree.Groups {
+			for j := range tree.Groups[i].Pics {
+				pics = append(pics, &tree.Groups[i].Pics[j])
 			}
+		}
+
+		for _, pic := range pics {
+			pic.Media = resolveMedia(zipReader, slide.rels, &contentTypes, &pic.NvPicPr.NvPr)
+		}
+	}
+}
+
+func resolveMedia(zipReader *zip.Reader, rels map[string]Relationship, contentTypes *ContentTypes, nvPr *NvPicMedia) *MediaInfo {
+	var media MediaInfo
+	var ref *MediaRef
+	switch {
+	case nvPr.VideoFile != nil:
+		media.Kind, ref = "Video", nvPr.VideoFile
+	case nvPr.AudioFile != nil:
+		media.Kind, ref = "Audio", nvPr.AudioFile
+	default:
+		return nil
+	}
+
+	rel, ok := rels[ref.Link]
+	if nvPr.Embedded != nil {
+		if embedded, found := rels[nvPr.Embedded.Embed]; found {
+			rel, ok = embedded, true
+		}
+	}
+	if !ok {
+		media.Name = "unresolved media"
+		return &media
+	}
 
-			if notesText.Len() > 0 {
-				slide.Notes = &Notes{Text: strings.TrimSpace(notesText.String())}
+	if rel.TargetMode == "External" {
+		media.Name = rel.Target
+		return &media
+	}
+
+	media.Name = path.Base(rel.Target)
+	media.ContentType = contentTypes.contentType(rel.Target)
+	if file, err := findFileInZip(zipReader, rel.Target); err == nil {
+		if rc, err := file.Open(); err == nil {
+			if d, err := mp4Duration(rc); err == nil {
+				media.Duration = d
 			}
-			break
+			rc.Close()
 		}
 	}
+	return &media
 }
 
-func convertSlidesToMarkdown(slides []*Slide, zipReader *zip.Reader, options ConvertOptions) string {
-	var markdown strings.Builder
+// partRelationships reads the relationships of a package part, keyed by ID,
+// with internal targets resolved to archive paths. Missing or malformed
+// relationship parts yield an empty map.
+func partRelationships(zipReader *zip.Reader, part string) map[string]Relationship {
+	result := make(map[string]Relationship)
 
-	for i, slide := range slides {
-		slideNum := i + 1
-		markdown.WriteString(fmt.Sprintf("\n\n<!-- Slide number: %d -->\n", slideNum))
+	relsFile, err := findFileInZip(zipReader, path.Join(path.Dir(part), "_rels", path.Base(part)+".rels"))
+	if err != nil {
+		return result
+	}
 
-		// Process shapes, pictures, and tables
-		processShapes(slide.CommonSlideData.ShapeTree.Shapes, &markdown, true)
-		processPics(slide.CommonSlideData.ShapeTree.Pics, &markdown, zipReader, options)
-		processTables(slide.CommonSlideData.ShapeTree.Tables, &markdown)
-		processGroups(slide.CommonSlideData.ShapeTree.Groups, &markdown, zipReader, options)
+	var rels Relationships
+	if err := parseXMLFile(relsFile, &rels); err != nil {
+		return result
+	}
+
+	for _, rel := range rels.Relationship {
+		if rel.TargetMode != "External" {
+			rel.Target = resolvePartTarget(part, rel.Target)
+		}
+		result[rel.ID] = rel
+	}
+	return result
+}
+
+// resolvePartTarget resolves a relationship target relative to its source part.
+func resolvePartTarget(part, target string) string {
+	if after, ok := strings.CutPrefix(target, "/"); ok {
+		return after
+	}
+	return path.Join(path.Dir(part), target)
+}
+
+// parseSlideNotes reads the speaker notes from the body placeholder of a
+// notes slide, skipping the slide image, slide number and header/footer.
+func parseSlideNotes(zipReader *zip.Reader, notesFile string, slide *Slide) {
+	file, err := findFileInZip(zipReader, notesFile)
+	if err != nil {
+		return
+	}
+
+	var notes NotesSlide
+	if err := parseXMLFile(file, &notes); err != nil {
+		return
+	}
+	resolveHyperlinks(partRelationships(zipReader, notesFile), slideTextBodies(&notes.CommonSlideData.ShapeTree))
+
+	var parts []string
+	for _, shape := range notes.CommonSlideData.ShapeTree.Shapes {
+		ph := shape.NvSpPr.NvPr.Ph
+		if ph == nil || ph.Type != "body" || shape.TextBody == nil {
+			continue
+		}
+		if text := formatTextBody(shape.TextBody); text != "" {
+			parts = append(parts, text)
+		}
+	}
+
+	if len(parts) > 0 {
+		slide.Notes = &Notes{Text: strings.Join(parts, "\n")}
+	}
+}
+
+// writeSlides writes the markdown of the slides to w one slide at a time,
+// stopping before the next slide once ctx is done.
+func writeSlides(ctx context.Context, w io.Writer, slides []*Slide, zipReader *zip.Reader, options ConvertOptions, progress *progressCounter) error {
+	markdown := getBuffer()
+	defer putBuffer(markdown)
+	for _, slide := range slides {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		markdown.Reset()
+		marker := cmp.Or(options.Marker, defaultSlideMarker)
+		markdown.WriteString("\n\n" + expandMarker(marker, slide.Number, fmt.Sprintf("Slide %d", slide.Number)) + "\n")
+		if options.Provenance {
+			markdown.WriteString(sourceComment(fmt.Sprintf("slide %d", slide.Number)))
+		}
+
+		// Process shapes, pictures, tables and groups in reading order
+		tree := slide.CommonSlideData.ShapeTree
+		elements := orderSlideElements(tree.Shapes, tree.Pics, tree.Tables, tree.Groups)
+		processElements(elements, markdown, zipReader, options, findTitleShape(elements))
 
 		// Add notes if present
 		if slide.Notes != nil && slide.Notes.Text != "" {
 			markdown.WriteString("\n\n### Notes:\n")
 			markdown.WriteString(slide.Notes.Text)
 		}
+
+		if _, err := w.Write(markdown.Bytes()); err != nil {
+			return err
+		}
+		progress.step("")
 	}
 
-	return markdown.String()
+	return nil
+}
+
+// slideElement is a single shape tree element together with its position.
+// Exactly one of the element pointers is set.
+type slideElement struct {
+	off   Offset
+	shape *Shape
+	pic   *Pic
+	table *Table
+	group *Group
+}
+
+// orderSlideElements merges the shape tree elements and sorts them
+// top-to-bottom, then left-to-right, so text is emitted in reading order.
+func orderSlideElements(shapes []Shape, pics []Pic, tables []Table, groups []Group) []slideElement {
+	elements := make([]slideElement, 0, len(shapes)+len(pics)+len(tables)+len(groups))
+	for i := range shapes {
+		elements = append(elements, slideElement{off: shapes[i].SpPr.Xfrm.Off, shape: &shapes[i]})
+	}
+	for i := range pics {
+		elements = append(elements, slideElement{off: pics[i].SpPr.Xfrm.Off, pic: &pics[i]})
+	}
+	for i := range tables {
+		elements = append(elements, slideElement{off: tables[i].Xfrm.Off, table: &tables[i]})
+	}
+	for i := range groups {
+		elements = append(elements, slideElement{off: groups[i].GrpSpPr.Xfrm.Off, group: &groups[i]})
+	}
+
+	slices.SortStableFunc(elements, func(a, b slideElement) int {
+		if c := cmp.Compare(a.off.Y, b.off.Y); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.off.X, b.off.X)
+	})
+
+	return elements
+}
+
+// findTitleShape returns the slide's title placeholder. Slides without one
+// fall back to the first top-level shape with text.
+func findTitleShape(elements []slideElement) *Shape {
+	var fallback *Shape
+	for _, el := range elements {
+		if el.shape == nil || el.shape.TextBody == nil || extractTextFromTextBody(el.shape.TextBody) == "" {
+			continue
+		}
+		if ph := el.shape.NvSpPr.NvPr.Ph; ph != nil && placeholderType(ph) == "title" {
+			return el.shape
+		}
+		if fallback == nil {
+			fallback = el.shape
+		}
+	}
+	return fallback
 }
 
-func processShapes(shapes []Shape, markdown *strings.Builder, isTitle bool) {
-	// Sort shapes by position (simplified - just by order for now)
-	for _, shape := range shapes {
-		if shape.TextBody != nil {
-			text := extractTextFromTextBody(shape.TextBody)
-			if text != "" {
-				if isTitle && len(shapes) > 0 {
-					markdown.WriteString("# ")
-					markdown.WriteString(strings.TrimSpace(text))
-					markdown.WriteString("\n")
-					isTitle = false // Only first shape with text is title
-				} else {
-					markdown.WriteString(text)
-					markdown.WriteString("\n")
+func processElements(elements []slideElement, markdown *bytes.Buffer, zipReader *zip.Reader, options ConvertOptions, title *Shape) {
+	for _, el := range elements {
+		switch {
+		case el.shape != nil:
+			processShape(el.shape, markdown, el.shape == title)
+		case el.pic != nil:
+			if el.pic.Media != nil {
+				processMedia(el.pic.Media, markdown)
+				continue
+			}
+			processPic(el.pic, markdown, zipReader, options)
+		case el.table != nil:
+			if chart := el.table.Graphic.GraphicData.Chart; chart != nil {
+				if chart.Data != nil {
+					markdown.WriteString(convertChartToMarkdown(chart.Data, options.Tables))
 				}
+				continue
 			}
+			markdown.WriteString(convertTableToMarkdown(el.table.Graphic.GraphicData.Table))
+		case el.group != nil:
+			g := el.group
+			processElements(orderSlideElements(g.Shapes, g.Pics, g.Tables, nil), markdown, zipReader, options, nil)
 		}
 	}
 }
 
-func processPics(pics []Pic, markdown *strings.Builder, zipReader *zip.Reader, options ConvertOptions) {
-	for _, pic := range pics {
-		altText := pic.NvPicPr.CNvPr.Descr
-		if altText == "" {
-			altText = pic.NvPicPr.CNvPr.Name
+// processShape writes the text of a shape, as a heading if it is the title.
+// Date, footer, header and slide number placeholders are skipped.
+func processShape(shape *Shape, markdown *bytes.Buffer, isTitle bool) {
+	if shape.TextBody == nil {
+		return
+	}
+	if ph := shape.NvSpPr.NvPr.Ph; ph != nil {
+		switch ph.Type {
+		case "dt", "ftr", "hdr", "sldNum":
+			return
 		}
+	}
 
-		// Clean alt text
-		altText = regexp.MustCompile(`[\r\n\[\]]`).ReplaceAllString(altText, " ")
-		altText = regexp.MustCompile(`\s+`).ReplaceAllString(altText, " ")
-		altText = strings.TrimSpace(altText)
+	text := extractTextFromTextBody(shape.TextBody)
+	if text == "" {
+		return
+	}
 
-		if options.KeepDataURIs && pic.BlipFill.Blip.Embed != "" {
-			// Try to get the actual image data
-			imageData := getImageData(zipReader)
-			if imageData != nil {
-				b64String := base64.StdEncoding.EncodeToString(imageData)
-				fmt.Fprintf(markdown, "\n![%s](data:image/png;base64,%s)\n", altText, b64String)
-			} else {
-				fmt.Fprintf(markdown, "\n![%s](%s.jpg)\n", altText, sanitizeFilename(altText))
-			}
-		} else {
-			filename := sanitizeFilename(altText) + ".jpg"
-			fmt.Fprintf(markdown, "\n![%s](%s)\n", altText, filename)
-		}
+	if isTitle {
+		markdown.WriteString("# ")
+		markdown.WriteString(strings.TrimSpace(text))
+	} else {
+		markdown.WriteString(formatTextBody(shape.TextBody))
 	}
+	markdown.WriteString("\n")
 }
 
-func processTables(tables []Table, markdown *strings.Builder) {
-	for _, table := range tables {
-		markdown.WriteString(convertTableToMarkdown(table.Graphic.GraphicData.Table))
+// processMedia writes a reference line for an audio or video clip.
+func processMedia(media *MediaInfo, markdown *bytes.Buffer) {
+	details := []string{}
+	if media.ContentType != "" {
+		details = append(details, media.ContentType)
 	}
+	if media.Duration > 0 {
+		details = append(details, formatDuration(media.Duration))
+	}
+
+	fmt.Fprintf(markdown, "\n[%s: %s", media.Kind, media.Name)
+	if len(details) > 0 {
+		fmt.Fprintf(markdown, " (%s)", strings.Join(details, ", "))
+	}
+	markdown.WriteString("]\n")
 }
 
-func processGroups(groups []Group, markdown *strings.Builder, zipReader *zip.Reader, options ConvertOptions) {
-	for _, group := range groups {
-		processShapes(group.Shapes, markdown, false)
-		processPics(group.Pics, markdown, zipReader, options)
-		processTables(group.Tables, markdown)
+func processPic(pic *Pic, markdown *bytes.Buffer, zipReader *zip.Reader, options ConvertOptions) {
+	altText := pic.NvPicPr.CNvPr.Descr
+	if altText == "" {
+		altText = pic.NvPicPr.CNvPr.Name
+	}
+
+	// Clean alt text
+	altText = regexp.MustCompile(`[\r\n\[\]]`).ReplaceAllString(altText, " ")
+	altText = regexp.MustCompile(`\s+`).ReplaceAllString(altText, " ")
+	altText = strings.TrimSpace(altText)
+
+	if options.KeepDataURIs && pic.BlipFill.Blip.Embed != "" {
+		// Try to get the actual image data
+		imageData := getImageData(zipReader)
+		if imageData != nil {
+			b64String := base64.StdEncoding.EncodeToString(imageData)
+			fmt.Fprintf(markdown, "\n![%s](data:image/png;base64,%s)\n", altText, b64String)
+		} else {
+			fmt.Fprintf(markdown, "\n![%s](%s.jpg)\n", altText, sanitizeFilename(altText))
+		}
+	} else {
+		filename := sanitizeFilename(altText) + ".jpg"
+		fmt.Fprintf(markdown, "\n![%s](%s)\n", altText, filename)
 	}
 }
 
@@ -369,15 +999,131 @@ func extractTextFromTextBody(textBody *TextBody) string {
 	var text strings.Builder
 
 	for _, paragraph := range textBody.Paragraphs {
-		for _, run := range paragraph.Runs {
-			text.WriteString(run.Text)
-		}
+		text.WriteString(paragraphText(&paragraph))
 		text.WriteString("\n")
 	}
 
 	return strings.TrimSpace(text.String())
 }
 
+// paragraphText returns the text of a paragraph, with consecutive runs
+// sharing a hyperlink rendered as a single markdown link.
+func paragraphText(paragraph *Paragraph) string {
+	var text strings.Builder
+
+	runs := paragraph.Runs
+	for i := 0; i < len(runs); i++ {
+		if runs[i].Link == "" {
+			text.WriteString(utils.EscapeMarkdown(runs[i].Text, utils.FlavorGFM))
+			continue
+		}
+
+		var label strings.Builder
+		j := i
+		for ; j < len(runs) && runs[j].Link == runs[i].Link; j++ {
+			label.WriteString(runs[j].Text)
+		}
+		fmt.Fprintf(&text, "[%s](%s)", utils.EscapeMarkdown(label.String(), utils.FlavorGFM), utils.EscapeURL(runs[i].Link))
+		i = j - 1
+	}
+
+	return text.String()
+}
+
+// resolveHyperlinks sets the link of every run with an external hyperlink.
+// Links to other slides or unknown relationships are left as plain text.
+func resolveHyperlinks(rels map[string]Relationship, textBodies []*TextBody) {
+	for _, textBody := range textBodies {
+		for i := range textBody.Paragraphs {
+			runs := textBody.Paragraphs[i].Runs
+			for j := range runs {
+				if runs[j].RPr == nil || runs[j].RPr.HlinkClick == nil {
+					continue
+				}
+				if rel, ok := rels[runs[j].RPr.HlinkClick.RID]; ok && rel.TargetMode == "External" {
+					runs[j].Link = rel.Target
+				}
+			}
+		}
+	}
+}
+
+// slideTextBodies returns the text bodies of all shapes and table cells on a slide.
+func slideTextBodies(tree *ShapeTree) []*TextBody {
+	var textBodies []*TextBody
+
+	addShapes := func(shapes []Shape, tables []Table) {
+		for i := range shapes {
+			if shapes[i].TextBody != nil {
+				textBodies = append(textBodies, shapes[i].TextBody)
+			}
+		}
+		for i := range tables {
+			for _, row := range tables[i].Graphic.GraphicData.Table.Rows {
+				for k := range row.Cells {
+					textBodies = append(textBodies, &row.Cells[k].TextBody)
+				}
+			}
+		}
+	}
+
+	addShapes(tree.Shapes, tree.Tables)
+	for i := range tree.Groups {
+		addShapes(tree.Groups[i].Shapes, tree.Groups[i].Tables)
+	}
+	return textBodies
+}
+
+// formatTextBody renders the paragraphs of a text body, turning bulleted and
+// auto-numbered paragraphs into nested markdown lists.
+func formatTextBody(textBody *TextBody) string {
+	var lines []string
+	var widths []int   // marker width per list level, used to indent children
+	var counters []int // next number per list level
+
+	for _, paragraph := range textBody.Paragraphs {
+		line := strings.TrimSpace(paragraphText(&paragraph))
+
+		pPr := paragraph.PPr
+		if pPr == nil || pPr.BuNone != nil || (pPr.BuChar == nil && pPr.BuAutoNum == nil) {
+			widths, counters = nil, nil
+			lines = append(lines, line)
+			continue
+		}
+		if line == "" {
+			continue
+		}
+
+		lvl := max(pPr.Lvl, 0)
+		for len(widths) <= lvl {
+			widths = append(widths, 0)
+			counters = append(counters, 0)
+		}
+		widths, counters = widths[:lvl+1], counters[:lvl+1]
+
+		marker := "- "
+		if pPr.BuAutoNum != nil {
+			if counters[lvl] == 0 {
+				counters[lvl] = max(pPr.BuAutoNum.StartAt, 1)
+			}
+			marker = strconv.Itoa(counters[lvl]) + ". "
+			counters[lvl]++
+		} else {
+			counters[lvl] = 0
+		}
+
+		indent := 0
+		for _, w := range widths[:lvl] {
+			indent += w
+		}
+		widths[lvl] = len(marker)
+
+		lines = append(lines, strings.Repeat(" ", indent)+marker+line)
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
 func convertTableToMarkdown(table TableData) string {
 	if len(table.Rows) == 0 {
 		return ""
@@ -390,7 +1136,7 @@ func convertTableToMarkdown(table TableData) string {
 		markdown.WriteString("|")
 		for _, cell := range table.Rows[0].Cells {
 			cellText := extractTextFromTextBody(&cell.TextBody)
-			cellText = html.EscapeString(cellText)
+			cellText = utils.EscapeCell(cellText)
 			markdown.WriteString(" ")
 			markdown.WriteString(cellText)
 			markdown.WriteString(" |")
@@ -410,7 +1156,7 @@ func convertTableToMarkdown(table TableData) string {
 		markdown.WriteString("|")
 		for _, cell := range table.Rows[i].Cells {
 			cellText := extractTextFromTextBody(&cell.TextBody)
-			cellText = html.EscapeString(cellText)
+			cellText = utils.EscapeCell(cellText)
 			markdown.WriteString(" ")
 			markdown.WriteString(cellText)
 			markdown.WriteString(" |")
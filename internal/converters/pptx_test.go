@@ -1,6 +1,10 @@
 package converters
 
 import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -11,10 +15,15 @@ import (
 func TestNewPptxConverter(t *testing.T) {
 	converter := NewPptxConverter()
 
-	expectedExtensions := []string{".pptx"}
+	expectedExtensions := []string{".pptx", ".ppsx", ".pptm", ".ppsm", ".potx", ".potm"}
 	expectedMimeTypes := []string{
 		"application/vnd.openxmlformats-officedocument.presentationml.presentation",
 		"application/vnd.openxmlformats-officedocument.presentationml",
+		"application/vnd.openxmlformats-officedocument.presentationml.slideshow",
+		"application/vnd.openxmlformats-officedocument.presentationml.template",
+		"application/vnd.ms-powerpoint.presentation.macroEnabled.12",
+		"application/vnd.ms-powerpoint.slideshow.macroEnabled.12",
+		"application/vnd.ms-powerpoint.template.macroEnabled.12",
 	}
 
 	if !reflect.DeepEqual(converter.AcceptedExtensions(), expectedExtensions) {
@@ -106,15 +115,15 @@ func TestPptxConverter_Interface(t *testing.T) {
 
 	// Test AcceptedExtensions
 	extensions := converter.AcceptedExtensions()
-	expectedExtensions := []string{".pptx"}
+	expectedExtensions := []string{".pptx", ".ppsx", ".pptm", ".ppsm", ".potx", ".potm"}
 	if !reflect.DeepEqual(extensions, expectedExtensions) {
 		t.Errorf("AcceptedExtensions() = %v, want %v", extensions, expectedExtensions)
 	}
 
 	// Test AcceptedMimeTypes
 	mimeTypes := converter.AcceptedMimeTypes()
-	if len(mimeTypes) != 2 {
-		t.Errorf("AcceptedMimeTypes() should return 2 MIME types, got %d", len(mimeTypes))
+	if len(mimeTypes) != 7 {
+		t.Errorf("AcceptedMimeTypes() should return 7 MIME types, got %d", len(mimeTypes))
 	}
 }
 
@@ -244,3 +253,311 @@ func TestConvertOptions(t *testing.T) {
 		t.Errorf("ConvertOptions.KeepDataURIs = %v, want false", options2.KeepDataURIs)
 	}
 }
+
+func TestOrderSlideElements_ReadingOrder(t *testing.T) {
+	textShape := func(text string, x, y int64) Shape {
+		return Shape{
+			TextBody: &TextBody{Paragraphs: []Paragraph{{Runs: []Run{{Text: text}}}}},
+			SpPr:     ShapeProperties{Xfrm: Transform{Off: Offset{X: x, Y: y}}},
+		}
+	}
+
+	shapes := []Shape{
+		textShape("Bottom", 0, 3000),
+		textShape("Right", 2000, 1000),
+		textShape("Title", 0, 0),
+		textShape("Left", 0, 1000),
+	}
+
+	var markdown bytes.Buffer
+	elements := orderSlideElements(shapes, nil, nil, nil)
+	processElements(elements, &markdown, nil, ConvertOptions{}, findTitleShape(elements))
+
+	expected := "# Title\nLeft\nRight\nBottom\n"
+	if markdown.String() != expected {
+		t.Errorf("processElements() = %q, want %q", markdown.String(), expected)
+	}
+}
+
+func TestFormatTextBody_Lists(t *testing.T) {
+	paragraphXML := `<p:txBody xmlns:a="a" xmlns:p="p">
+		<a:p><a:r><a:t>Intro</a:t></a:r></a:p>
+		<a:p><a:pPr><a:buChar char="•"/></a:pPr><a:r><a:t>First</a:t></a:r></a:p>
+		<a:p><a:pPr lvl="1"><a:buAutoNum type="arabicPeriod"/></a:pPr><a:r><a:t>Step one</a:t></a:r></a:p>
+		<a:p><a:pPr lvl="1"><a:buAutoNum type="arabicPeriod"/></a:pPr><a:r><a:t>Step two</a:t></a:r></a:p>
+		<a:p><a:pPr><a:buChar char="•"/></a:pPr><a:r><a:t>Second</a:t></a:r></a:p>
+		<a:p><a:pPr><a:buNone/></a:pPr><a:r><a:t>Outro</a:t></a:r></a:p>
+		<a:p><a:pPr><a:buAutoNum type="arabicPeriod" startAt="3"/></a:pPr><a:r><a:t>Third</a:t></a:r></a:p>
+	</p:txBody>`
+
+	var textBody TextBody
+	if err := xml.Unmarshal([]byte(paragraphXML), &textBody); err != nil {
+		t.Fatalf("Failed to parse text body XML: %v", err)
+	}
+
+	result := formatTextBody(&textBody)
+	expected := "Intro\n- First\n  1. Step one\n  2. Step two\n- Second\nOutro\n3. Third"
+	if result != expected {
+		t.Errorf("formatTextBody() = %q, want %q", result, expected)
+	}
+}
+
+// testSlideXML returns a slide part containing a single text shape.
+func testSlideXML(text string) string {
+	return `<p:sld xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main" ` +
+		`xmlns:p="http://schemas.openxmlformats.org/presentationml/2006/main"><p:cSld><p:spTree>` +
+		`<p:sp><p:txBody><a:p><a:r><a:t>` + text + `</a:t></a:r></a:p></p:txBody></p:sp>` +
+		`</p:spTree></p:cSld></p:sld>`
+}
+
+func TestPptxConverter_Load_SlideOrderFromRelationships(t *testing.T) {
+	files := map[string]string{
+		"ppt/presentation.xml": `<p:presentation xmlns:p="http://schemas.openxmlformats.org/presentationml/2006/main" ` +
+			`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><p:sldIdLst>` +
+			`<p:sldId id="256" r:id="rId2"/><p:sldId id="257" r:id="rId1"/></p:sldIdLst></p:presentation>`,
+		"ppt/_rels/presentation.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/slide" Target="slides/slide1.xml"/>` +
+			`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/slide" Target="/ppt/slides/slide7.xml"/>` +
+			`</Relationships>`,
+		"ppt/slides/slide1.xml": testSlideXML("Second"),
+		"ppt/slides/slide7.xml": testSlideXML("First"),
+	}
+	pptxFile := writeTestArchive(t, "test.pptx", files)
+
+	result, err := NewPptxConverter().Load(pptxFile)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+
+	expected := "<!-- Slide number: 1 -->\n# First\n\n\n<!-- Slide number: 2 -->\n# Second"
+	if result != expected {
+		t.Errorf("Load() = %q, want %q", result, expected)
+	}
+}
+
+// testPresentationFiles returns the parts of a presentation whose slides are
+// stored as slide1.xml..slideN.xml in presentation order.
+func testPresentationFiles(slides ...string) map[string]string {
+	var ids, rels strings.Builder
+	files := make(map[string]string)
+	for i, slide := range slides {
+		fmt.Fprintf(&ids, `<p:sldId id="%d" r:id="rId%d"/>`, 256+i, i+1)
+		fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/slide" Target="slides/slide%d.xml"/>`, i+1, i+1)
+		files[fmt.Sprintf("ppt/slides/slide%d.xml", i+1)] = slide
+	}
+
+	files["ppt/presentation.xml"] = `<p:presentation xmlns:p="http://schemas.openxmlformats.org/presentationml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><p:sldIdLst>` +
+		ids.String() + `</p:sldIdLst></p:presentation>`
+	files["ppt/_rels/presentation.xml.rels"] = `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		rels.String() + `</Relationships>`
+	return files
+}
+
+func TestPptxConverter_Load_SpeakerNotes(t *testing.T) {
+	files := testPresentationFiles(testSlideXML("Title"))
+	files["ppt/slides/_rels/slide1.xml.rels"] = `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/notesSlide" Target="../notesSlides/notesSlide9.xml"/>` +
+		`</Relationships>`
+	files["ppt/notesSlides/notesSlide9.xml"] = `<p:notes xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main" ` +
+		`xmlns:p="http://schemas.openxmlformats.org/presentationml/2006/main"><p:cSld><p:spTree>` +
+		`<p:sp><p:nvSpPr><p:nvPr><p:ph type="sldImg"/></p:nvPr></p:nvSpPr></p:sp>` +
+		`<p:sp><p:nvSpPr><p:nvPr><p:ph type="body" idx="1"/></p:nvPr></p:nvSpPr><p:txBody>` +
+		`<a:p><a:r><a:t>First line</a:t></a:r><a:br/><a:r><a:t>after break</a:t></a:r></a:p>` +
+		`<a:p><a:r><a:t>Second paragraph</a:t></a:r></a:p></p:txBody></p:sp>` +
+		`<p:sp><p:nvSpPr><p:nvPr><p:ph type="sldNum" idx="5"/></p:nvPr></p:nvSpPr><p:txBody>` +
+		`<a:p><a:fld type="slidenum"><a:t>1</a:t></a:fld></a:p></p:txBody></p:sp>` +
+		`</p:spTree></p:cSld></p:notes>`
+	pptxFile := writeTestArchive(t, "test.pptx", files)
+
+	result, err := NewPptxConverter().Load(pptxFile)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+
+	expected := "<!-- Slide number: 1 -->\n# Title\n\n\n### Notes:\nFirst line\nafter break\nSecond paragraph"
+	if result != expected {
+		t.Errorf("Load() = %q, want %q", result, expected)
+	}
+}
+
+func TestPptxConverter_Load_TitlePlaceholderFromLayout(t *testing.T) {
+	const ns = `xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main" ` +
+		`xmlns:p="http://schemas.openxmlformats.org/presentationml/2006/main"`
+	placeholder := func(ph, text, xfrm string) string {
+		return `<p:sp><p:nvSpPr><p:nvPr>` + ph + `</p:nvPr></p:nvSpPr><p:spPr>` + xfrm + `</p:spPr>` +
+			`<p:txBody><a:p><a:r><a:t>` + text + `</a:t></a:r></a:p></p:txBody></p:sp>`
+	}
+	offset := func(y string) string { return `<a:xfrm><a:off x="0" y="` + y + `"/></a:xfrm>` }
+
+	slide := `<p:sld ` + ns + `><p:cSld><p:spTree>` +
+		placeholder(`<p:ph type="sldNum" idx="12"/>`, "3", "") +
+		placeholder(``, "Caption", offset("100")) +
+		placeholder(`<p:ph idx="1"/>`, "Body", "") +
+		placeholder(`<p:ph type="title"/>`, "Real Title", "") +
+		`</p:spTree></p:cSld></p:sld>`
+
+	files := testPresentationFiles(slide)
+	files["ppt/slides/_rels/slide1.xml.rels"] = `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/slideLayout" Target="../slideLayouts/slideLayout1.xml"/>` +
+		`</Relationships>`
+	files["ppt/slideLayouts/slideLayout1.xml"] = `<p:sldLayout ` + ns + `><p:cSld><p:spTree>` +
+		placeholder(`<p:ph type="title"/>`, "", offset("500")) +
+		`</p:spTree></p:cSld></p:sldLayout>`
+	files["ppt/slideLayouts/_rels/slideLayout1.xml.rels"] = `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/slideMaster" Target="../slideMasters/slideMaster1.xml"/>` +
+		`</Relationships>`
+	files["ppt/slideMasters/slideMaster1.xml"] = `<p:sldMaster ` + ns + `><p:cSld><p:spTree>` +
+		placeholder(`<p:ph type="body" idx="1"/>`, "", offset("1000")) +
+		`</p:spTree></p:cSld></p:sldMaster>`
+	pptxFile := writeTestArchive(t, "test.pptx", files)
+
+	result, err := NewPptxConverter().Load(pptxFile)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+
+	expected := "<!-- Slide number: 1 -->\nCaption\n# Real Title\nBody"
+	if result != expected {
+		t.Errorf("Load() = %q, want %q", result, expected)
+	}
+}
+
+func TestPptxConverter_Load_Hyperlinks(t *testing.T) {
+	link := func(text, rid string) string {
+		return `<a:r><a:rPr><a:hlinkClick r:id="` + rid + `"/></a:rPr><a:t>` + text + `</a:t></a:r>`
+	}
+	slide := `<p:sld xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main" ` +
+		`xmlns:p="http://schemas.openxmlformats.org/presentationml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><p:cSld><p:spTree>` +
+		`<p:sp><p:txBody><a:p><a:r><a:t>Title</a:t></a:r></a:p></p:txBody></p:sp>` +
+		`<p:sp><p:txBody><a:p><a:r><a:t>See </a:t></a:r>` + link("the ", "rId2") + link("docs", "rId2") +
+		`<a:r><a:t> or </a:t></a:r>` + link("slide 3", "rId3") + `</a:p></p:txBody></p:sp>` +
+		`</p:spTree></p:cSld></p:sld>`
+
+	files := testPresentationFiles(slide)
+	files["ppt/slides/_rels/slide1.xml.rels"] = `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/hyperlink" Target="https://example.com/docs" TargetMode="External"/>` +
+		`<Relationship Id="rId3" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/slide" Target="slide3.xml"/>` +
+		`</Relationships>`
+	pptxFile := writeTestArchive(t, "test.pptx", files)
+
+	result, err := NewPptxConverter().Load(pptxFile)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+
+	expected := "<!-- Slide number: 1 -->\n# Title\nSee [the docs](https://example.com/docs) or slide 3"
+	if result != expected {
+		t.Errorf("Load() = %q, want %q", result, expected)
+	}
+}
+
+func TestPptxConverter_Load_SlideSelection(t *testing.T) {
+	pptxFile := writeTestArchive(t, "test.pptx", testPresentationFiles(
+		testSlideXML("One"), testSlideXML("Two"), testSlideXML("Three"), testSlideXML("Four"),
+	))
+
+	converter := &PptxConverter{Options: ConvertOptions{Slides: "1,3-"}}
+	result, err := converter.Load(pptxFile)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+
+	expected := "<!-- Slide number: 1 -->\n# One\n\n\n<!-- Slide number: 3 -->\n# Three\n\n\n<!-- Slide number: 4 -->\n# Four"
+	if result != expected {
+		t.Errorf("Load() = %q, want %q", result, expected)
+	}
+
+	converter.Options.Slides = "2-1"
+	if _, err := converter.Load(pptxFile); err == nil || !strings.Contains(err.Error(), "invalid slide selection") {
+		t.Errorf("Load() with invalid selection should return slide selection error, got %v", err)
+	}
+}
+
+func TestPptxConverter_Load_MediaInventory(t *testing.T) {
+	files := testPresentationFiles(`<p:sld xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main" ` +
+		`xmlns:p="http://schemas.openxmlformats.org/presentationml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships" ` +
+		`xmlns:p14="http://schemas.microsoft.com/office/powerpoint/2010/main"><p:cSld><p:spTree>` +
+		`<p:sp><p:txBody><a:p><a:r><a:t>Title</a:t></a:r></a:p></p:txBody></p:sp>` +
+		`<p:pic><p:nvPicPr><p:cNvPr id="4" name="clip"/><p:nvPr><a:videoFile r:link="rId2"/>` +
+		`<p:extLst><p:ext uri="{DAA4B4D4-6D71-4841-9C94-3DE7FCFB9230}"><p14:media r:embed="rId3"/></p:ext></p:extLst>` +
+		`</p:nvPr></p:nvPicPr><p:spPr><a:xfrm><a:off x="0" y="100"/></a:xfrm></p:spPr></p:pic>` +
+		`<p:pic><p:nvPicPr><p:cNvPr id="5" name="song"/><p:nvPr><a:audioFile r:link="rId4"/></p:nvPr></p:nvPicPr>` +
+		`<p:spPr><a:xfrm><a:off x="0" y="200"/></a:xfrm></p:spPr></p:pic>` +
+		`</p:spTree></p:cSld></p:sld>`)
+	files["ppt/slides/_rels/slide1.xml.rels"] = `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/video" Target="../media/media1.mp4"/>` +
+		`<Relationship Id="rId3" Type="http://schemas.microsoft.com/office/2007/relationships/media" Target="../media/media1.mp4"/>` +
+		`<Relationship Id="rId4" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/audio" Target="https://example.com/song.mp3" TargetMode="External"/>` +
+		`</Relationships>`
+	files["[Content_Types].xml"] = `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="mp4" ContentType="video/mp4"/></Types>`
+	files["ppt/media/media1.mp4"] = string(testMP4(600, 7200))
+	pptxFile := writeTestArchive(t, "test.pptx", files)
+
+	result, err := NewPptxConverter().Load(pptxFile)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+
+	expected := "<!-- Slide number: 1 -->\n# Title\n\n[Video: media1.mp4 (video/mp4, 0:12)]\n\n[Audio: https://example.com/song.mp3]"
+	if result != expected {
+		t.Errorf("Load() = %q, want %q", result, expected)
+	}
+}
+
+func TestPptxConverter_Load_Provenance(t *testing.T) {
+	pptxFile := writeTestArchive(t, "test.pptx", testPresentationFiles(testSlideXML("First"), testSlideXML("Second")))
+
+	converter := &PptxConverter{Options: ConvertOptions{Provenance: true}}
+	result, err := converter.Load(pptxFile)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+
+	expected := "<!-- Slide number: 1 -->\n<!-- source: slide 1 -->\n# First\n\n\n<!-- Slide number: 2 -->\n<!-- source: slide 2 -->\n# Second"
+	if result != expected {
+		t.Errorf("Load() = %q, want %q", result, expected)
+	}
+}
+
+func TestPptxConverter_Load_Marker(t *testing.T) {
+	pptxFile := writeTestArchive(t, "test.pptx", testPresentationFiles(testSlideXML("First"), testSlideXML("Second")))
+
+	tests := []struct {
+		marker   string
+		expected string
+	}{
+		{MarkerHeading, "## Slide 1\n# First\n\n\n## Slide 2\n# Second"},
+		{MarkerRule, "---\n# First\n\n\n---\n# Second"},
+		{"<!-- deck slide {n} -->", "<!-- deck slide 1 -->\n# First\n\n\n<!-- deck slide 2 -->\n# Second"},
+	}
+
+	for _, tt := range tests {
+		converter := &PptxConverter{Options: ConvertOptions{Marker: tt.marker}}
+		result, err := converter.Load(pptxFile)
+		if err != nil {
+			t.Fatalf("Load() returned unexpected error: %v", err)
+		}
+		if result != tt.expected {
+			t.Errorf("Load() with marker %q = %q, want %q", tt.marker, result, tt.expected)
+		}
+	}
+}
+
+func TestPptxConverter_LoadReport_CorruptSlide(t *testing.T) {
+	path := writeCorruptFile(t, filepath.Join("..", "..", "test_files", "test.pptx"), "ppt/slides/slide2.xml")
+
+	markdown, report, err := NewPptxConverter().(*PptxConverter).LoadReport(context.Background(), path)
+	if err != nil {
+		t.Fatalf("LoadReport() returned unexpected error: %v", err)
+	}
+	if strings.Contains(markdown, "<!-- Slide number: 2 -->") || !strings.Contains(markdown, "<!-- Slide number: 3 -->") {
+		t.Errorf("LoadReport() = %q, want slide 2 left out", markdown)
+	}
+	if len(report.Warnings) != 1 || !strings.HasPrefix(report.Warnings[0], "slide 2 left out: ") {
+		t.Errorf("LoadReport() warnings = %q, want one for slide 2", report.Warnings)
+	}
+}
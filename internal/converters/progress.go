@@ -0,0 +1,58 @@
+package converters
+
+import "sync"
+
+// ProgressEvent reports that one more part of a document was converted.
+type ProgressEvent struct {
+	// Unit names the parts of the document: "page", "slide", "sheet" or
+	// "chapter".
+	Unit string
+
+	// Done is the number of parts converted so far, out of Total selected
+	// parts.
+	Done  int
+	Total int
+
+	// Name is the name of the part when it has one, such as the name of a
+	// sheet or the path of a chapter in the book.
+	Name string
+}
+
+// ProgressFunc receives the progress of a conversion. Calls for a document
+// are never concurrent, even when its pages are converted concurrently.
+type ProgressFunc func(ProgressEvent)
+
+// ProgressConverter is implemented by converters reporting their progress
+// through long documents, once per page, slide, sheet or chapter, to the
+// function given to SetProgress.
+type ProgressConverter interface {
+	Converter
+	SetProgress(progress ProgressFunc)
+}
+
+// progressCounter counts the converted parts of a document and reports each
+// of them to a ProgressFunc. It is safe for concurrent use.
+type progressCounter struct {
+	mu       sync.Mutex
+	progress ProgressFunc
+	unit     string
+	done     int
+	total    int
+}
+
+// newProgressCounter returns a counter of total parts named unit, reporting
+// to progress, which may be nil.
+func newProgressCounter(progress ProgressFunc, unit string, total int) *progressCounter {
+	return &progressCounter{progress: progress, unit: unit, total: total}
+}
+
+// step reports that the part called name was converted.
+func (p *progressCounter) step(name string) {
+	if p.progress == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done++
+	p.progress(ProgressEvent{Unit: p.unit, Done: p.done, Total: p.total, Name: name})
+}
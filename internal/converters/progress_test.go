@@ -0,0 +1,52 @@
+package converters
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestSetProgress(t *testing.T) {
+	for _, tt := range []struct {
+		file      string
+		converter Converter
+		unit      string
+	}{
+		{"test.pdf", NewPdfConverter(), "page"},
+		{"test.pptx", NewPptxConverter(), "slide"},
+		{"test.xlsx", NewExcelConverter(), "sheet"},
+		{"test.epub", NewEpubConverter(), "chapter"},
+	} {
+		t.Run(tt.file, func(t *testing.T) {
+			var events []ProgressEvent
+			tt.converter.(ProgressConverter).SetProgress(func(event ProgressEvent) {
+				events = append(events, event)
+			})
+			if _, err := tt.converter.Load(filepath.Join("..", "..", "test_files", tt.file)); err != nil {
+				t.Fatalf("Load() returned unexpected error: %v", err)
+			}
+
+			if len(events) == 0 {
+				t.Fatal("no progress was reported")
+			}
+			for i, event := range events {
+				if event.Unit != tt.unit || event.Done != i+1 || event.Total != len(events) {
+					t.Errorf("event %d = %+v, want %s %d of %d", i, event, tt.unit, i+1, len(events))
+				}
+			}
+		})
+	}
+}
+
+func TestSetProgress_Sheets(t *testing.T) {
+	c := NewExcelConverter().(*ExcelConverter)
+	var names []string
+	c.SetProgress(func(event ProgressEvent) {
+		names = append(names, event.Name)
+	})
+	if _, err := c.Load(filepath.Join("..", "..", "test_files", "test.xlsx")); err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	if len(names) != 2 || names[0] != "Sheet1" || names[1] != "09060124-b5e7-4717-9d07-3c046eb" {
+		t.Errorf("progress names = %q, want the sheet names", names)
+	}
+}
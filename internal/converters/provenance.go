@@ -0,0 +1,26 @@
+package converters
+
+import "strings"
+
+// sourceComment returns the provenance comment written before the markdown
+// converted from a location of the source document, such as "page 3",
+// "slide 2", "sheet Sales!A1:D20" or "paragraph 12".
+func sourceComment(location string) string {
+	// "--" would end the comment early
+	return "<!-- source: " + strings.ReplaceAll(location, "--", "- -") + " -->\n"
+}
+
+// sheetLocation returns the location of a sheet in A1 notation, quoting
+// the sheet name when it holds characters other than letters, digits and
+// underscores.
+func sheetLocation(name, dimension string) string {
+	if strings.ContainsFunc(name, func(r rune) bool {
+		return !(r == '_' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r > 127)
+	}) {
+		name = "'" + strings.ReplaceAll(name, "'", "''") + "'"
+	}
+	if dimension == "" {
+		return "sheet " + name
+	}
+	return "sheet " + name + "!" + dimension
+}
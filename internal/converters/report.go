@@ -0,0 +1,32 @@
+package converters
+
+import (
+	"context"
+	"fmt"
+)
+
+// Report describes a converted document beyond its markdown.
+type Report struct {
+	// Parts counts the pages of PDF files, the slides of presentations or
+	// the sheets of workbooks, selected or not.
+	Parts int
+
+	// Warnings describe the content left out of the markdown, such as pages
+	// without text, unreadable charts or rows beyond a limit.
+	Warnings []string
+}
+
+// Reporter is implemented by the converters that report on the documents
+// they convert along with their markdown. Conversions stop when ctx is
+// done, as with ContextConverter.
+type Reporter interface {
+	LoadReport(ctx context.Context, path string) (string, *Report, error)
+}
+
+// warn adds a warning to the report, which may be nil when the caller of
+// the converter did not ask for one.
+func (r *Report) warn(format string, args ...any) {
+	if r != nil {
+		r.Warnings = append(r.Warnings, fmt.Sprintf(format, args...))
+	}
+}
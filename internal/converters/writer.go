@@ -0,0 +1,81 @@
+package converters
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"unicode"
+)
+
+// WriterConverter is implemented by the converters that write markdown as
+// they convert a document, such as those of DOCX and PPTX files, so that
+// large documents are not held in memory as a whole.
+type WriterConverter interface {
+	Converter
+	LoadTo(ctx context.Context, w io.Writer, path string) error
+}
+
+// LoadTo converts a document with a converter, writing the markdown to w.
+// The documents of converters that do not implement WriterConverter are
+// converted as a whole before being written.
+func LoadTo(ctx context.Context, c Converter, w io.Writer, path string) error {
+	if c, ok := c.(WriterConverter); ok {
+		return c.LoadTo(ctx, w, path)
+	}
+	markdown, err := LoadContext(ctx, c, path)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, markdown)
+	return err
+}
+
+// errWriter remembers the first error of the writes to w, for writers used
+// with fmt.Fprint, whose errors go unchecked. Writes after an error are
+// dropped.
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (e *errWriter) Write(p []byte) (int, error) {
+	if e.err != nil {
+		return 0, e.err
+	}
+	n, err := e.w.Write(p)
+	e.err = err
+	return n, err
+}
+
+// trimWriter writes to w without leading and trailing white space, like
+// strings.TrimSpace, holding back the white space that ends each write
+// until more text follows it. Each write must end on a rune boundary.
+type trimWriter struct {
+	w       io.Writer
+	started bool
+	space   []byte
+}
+
+func (t *trimWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	if !t.started {
+		p = bytes.TrimLeftFunc(p, unicode.IsSpace)
+	}
+	text := bytes.TrimRightFunc(p, unicode.IsSpace)
+	if len(text) == 0 {
+		if t.started {
+			t.space = append(t.space, p...)
+		}
+		return n, nil
+	}
+
+	if _, err := t.w.Write(t.space); err != nil {
+		return 0, err
+	}
+	if _, err := t.w.Write(text); err != nil {
+		return 0, err
+	}
+	t.started = true
+	t.space = append(t.space[:0], p[len(text):]...)
+	return n, nil
+}
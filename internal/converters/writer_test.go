@@ -0,0 +1,71 @@
+package converters
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadTo(t *testing.T) {
+	tests := []struct {
+		file      string
+		converter Converter
+	}{
+		{"test.docx", NewDocConverter()},
+		{"test.docx", &DocConverter{TOC: TOCStrip}},
+		{"test.pptx", NewPptxConverter()},
+		{"test.csv", NewCsvConverter()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			path := filepath.Join("..", "..", "test_files", tt.file)
+			want, err := tt.converter.Load(path)
+			if err != nil {
+				t.Fatalf("Load() returned unexpected error: %v", err)
+			}
+			var got strings.Builder
+			if err := LoadTo(context.Background(), tt.converter, &got, path); err != nil {
+				t.Fatalf("LoadTo() returned unexpected error: %v", err)
+			}
+			if got.String() != want {
+				t.Errorf("LoadTo() differs from Load():\ngot  %q\nwant %q", got.String(), want)
+			}
+		})
+	}
+}
+
+// failingWriter fails every write.
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestLoadTo_WriteError(t *testing.T) {
+	for _, file := range []string{"test.docx", "test.pptx"} {
+		path := filepath.Join("..", "..", "test_files", file)
+		var c Converter = NewPptxConverter()
+		if file == "test.docx" {
+			c = &DocConverter{TOC: TOCStrip}
+		}
+		if err := LoadTo(context.Background(), c, failingWriter{}, path); err == nil || !strings.Contains(err.Error(), "disk full") {
+			t.Errorf("LoadTo() of %s error = %v, want the write error", file, err)
+		}
+	}
+}
+
+func TestTrimWriter(t *testing.T) {
+	var buf strings.Builder
+	w := &trimWriter{w: &buf}
+	for _, chunk := range []string{"\n\n", "\n\n# Slide 1\n", "\n", "text  \n\n", "more\n\n"} {
+		if _, err := w.Write([]byte(chunk)); err != nil {
+			t.Fatalf("Write() returned unexpected error: %v", err)
+		}
+	}
+
+	want := "# Slide 1\n\ntext  \n\nmore"
+	if buf.String() != want {
+		t.Errorf("trimWriter wrote %q, want %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,642 @@
+package converters
+
+import (
+	"archive/zip"
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// xlsbWorkbookPart is the workbook part of a binary (BIFF12) Excel workbook.
+const xlsbWorkbookPart = "xl/workbook.bin"
+
+// BIFF12 record types read from XLSB parts, as listed in [MS-XLSB].
+const (
+	brtRowHdr         = 0
+	brtCellBlank      = 1
+	brtCellRk         = 2
+	brtCellError      = 3
+	brtCellBool       = 4
+	brtCellReal       = 5
+	brtCellSt         = 6
+	brtCellIsst       = 7
+	brtFmlaString     = 8
+	brtFmlaNum        = 9
+	brtFmlaBool       = 10
+	brtFmlaError      = 11
+	brtSSTItem        = 19
+	brtFmt            = 44
+	brtXF             = 47
+	brtColInfo        = 60
+	brtEndSheetData   = 146
+	brtWbProp         = 153
+	brtBundleSh       = 156
+	brtHLink          = 494
+	brtBeginCellXFs   = 617
+	brtEndCellXFs     = 618
+	xlsbHiddenRowFlag = 0x10
+)
+
+// xlsbErrors maps BIFF12 error codes to the error values Excel displays.
+var xlsbErrors = map[byte]string{
+	0x00: "#NULL!",
+	0x07: "#DIV/0!",
+	0x0F: "#VALUE!",
+	0x17: "#REF!",
+	0x1D: "#NAME?",
+	0x24: "#NUM!",
+	0x2A: "#N/A",
+	0x2B: "#GETTING_DATA",
+}
+
+// writeXlsbFile streams the selected sheets of an XLSB workbook to w. Cell
+// values are read natively from the binary parts, so formula text and
+// comments are not available; formulas are written as their cached values.
+func (e *ExcelConverter) writeXlsbFile(ctx context.Context, zipReader *zip.Reader, w *strings.Builder, report *Report) error {
+	workbook, err := readXlsbWorkbook(zipReader)
+	if err != nil {
+		return err
+	}
+	if len(workbook.sheets) == 0 {
+		return errors.New("no sheets found")
+	}
+
+	names := make([]string, len(workbook.sheets))
+	for i, sheet := range workbook.sheets {
+		names[i] = sheet.name
+	}
+	names, err = selectSheets(names, e.Sheets)
+	if err != nil {
+		return err
+	}
+
+	strs, err := readXlsbSharedStrings(zipReader)
+	if err != nil {
+		return fmt.Errorf("unable to read shared strings: %w", err)
+	}
+	styles, err := readXlsbStyles(zipReader)
+	if err != nil {
+		return fmt.Errorf("unable to read styles: %w", err)
+	}
+
+	rels := partRelationships(zipReader, xlsbWorkbookPart)
+	if report != nil {
+		report.Parts = len(workbook.sheets)
+	}
+	footnotes := 0
+	progress := newProgressCounter(e.Progress, "sheet", len(names))
+	for i, info := range workbook.sheets {
+		if !slices.Contains(names, info.name) {
+			continue
+		}
+		if info.hidden && e.SkipHiddenSheets {
+			report.warn("hidden sheet %s left out", info.name)
+			progress.step(info.name)
+			continue
+		}
+		part := rels[info.relID].Target
+		sheet := excelSheet{
+			Name:          info.name,
+			Number:        i + 1,
+			Marks:         make(cellMarks),
+			Summaries:     sheetSummaries(zipReader, part, e.Tables),
+			HiddenColumns: make(map[int]bool),
+			FootnoteCount: &footnotes,
+			Report:        report,
+		}
+		if err := scanXlsbWorksheet(zipReader, part, &sheet); err != nil {
+			return fmt.Errorf("unable to scan sheet %s: %w", info.name, err)
+		}
+
+		rows := &xlsbRows{
+			strings:  strs,
+			styles:   styles,
+			date1904: workbook.date1904,
+			values:   e.Values,
+		}
+		if file, err := findFileInZip(zipReader, part); err == nil {
+			rc, err := file.Open()
+			if err != nil {
+				return fmt.Errorf("unable to open sheet %s: %w", info.name, err)
+			}
+			rows.closer = rc
+			rows.records = newXlsbRecordReader(rc)
+		}
+		if err := e.writeRows(ctx, rows, nil, &sheet, nil, nil, w); err != nil {
+			return fmt.Errorf("unable to read rows from sheet %s: %w", info.name, err)
+		}
+		progress.step(info.name)
+	}
+	return nil
+}
+
+// xlsbWorkbook holds the sheet list and date system of an XLSB workbook.
+type xlsbWorkbook struct {
+	sheets   []xlsbSheetInfo
+	date1904 bool
+}
+
+type xlsbSheetInfo struct {
+	name   string
+	relID  string
+	hidden bool
+}
+
+// readXlsbWorkbook reads the sheets of xl/workbook.bin in workbook order.
+func readXlsbWorkbook(zipReader *zip.Reader) (*xlsbWorkbook, error) {
+	workbook := &xlsbWorkbook{}
+	err := readXlsbPart(zipReader, xlsbWorkbookPart, func(kind int, data []byte) error {
+		switch kind {
+		case brtWbProp:
+			if len(data) >= 4 {
+				workbook.date1904 = binary.LittleEndian.Uint32(data)&1 != 0
+			}
+		case brtBundleSh:
+			if len(data) < 8 {
+				return errors.New("truncated sheet record")
+			}
+			r := xlsbData(data[8:])
+			relID, _ := r.string()
+			name, ok := r.string()
+			if !ok {
+				return errors.New("truncated sheet record")
+			}
+			workbook.sheets = append(workbook.sheets, xlsbSheetInfo{
+				name:   name,
+				relID:  relID,
+				hidden: binary.LittleEndian.Uint32(data) != 0,
+			})
+		}
+		return nil
+	})
+	return workbook, err
+}
+
+// readXlsbSharedStrings reads the shared string table, which is optional.
+func readXlsbSharedStrings(zipReader *zip.Reader) ([]string, error) {
+	var strs []string
+	err := readXlsbPart(zipReader, "xl/sharedStrings.bin", func(kind int, data []byte) error {
+		if kind != brtSSTItem || len(data) < 1 {
+			return nil
+		}
+		r := xlsbData(data[1:])
+		text, _ := r.string()
+		strs = append(strs, text)
+		return nil
+	})
+	return strs, err
+}
+
+// xlsbStyles reports which cell formats of an XLSB workbook format dates.
+type xlsbStyles struct {
+	dates []bool
+}
+
+// readXlsbStyles reads the number format of every cell format in
+// xl/styles.bin, which is optional.
+func readXlsbStyles(zipReader *zip.Reader) (*xlsbStyles, error) {
+	styles := &xlsbStyles{}
+	codes := make(map[int]string)
+	var formats []int
+	inCellXFs := false
+
+	err := readXlsbPart(zipReader, "xl/styles.bin", func(kind int, data []byte) error {
+		switch kind {
+		case brtFmt:
+			if len(data) < 2 {
+				return nil
+			}
+			r := xlsbData(data[2:])
+			code, _ := r.string()
+			codes[int(binary.LittleEndian.Uint16(data))] = code
+		case brtBeginCellXFs:
+			inCellXFs = true
+		case brtEndCellXFs:
+			inCellXFs = false
+		case brtXF:
+			if inCellXFs && len(data) >= 4 {
+				formats = append(formats, int(binary.LittleEndian.Uint16(data[2:])))
+			}
+		}
+		return nil
+	})
+
+	styles.dates = make([]bool, len(formats))
+	for i, id := range formats {
+		if code, ok := codes[id]; ok {
+			styles.dates[i] = isDateFormatCode(code)
+		} else {
+			styles.dates[i] = isBuiltInDateFormat(id)
+		}
+	}
+	return styles, err
+}
+
+// isDate reports whether the cell format at index formats dates or times.
+func (s *xlsbStyles) isDate(index int) bool {
+	return index >= 0 && index < len(s.dates) && s.dates[index]
+}
+
+// scanXlsbWorksheet marks the hyperlinked cells and records the hidden
+// columns of an XLSB worksheet part, like scanWorksheet does for XLSX.
+func scanXlsbWorksheet(zipReader *zip.Reader, part string, sheet *excelSheet) error {
+	var rels map[string]Relationship
+	return readXlsbPart(zipReader, part, func(kind int, data []byte) error {
+		switch kind {
+		case brtColInfo:
+			if len(data) < 18 || data[16]&1 == 0 {
+				return nil
+			}
+			first := int(binary.LittleEndian.Uint32(data))
+			last := int(binary.LittleEndian.Uint32(data[4:]))
+			for c := first; c <= last && c < excelize.MaxColumns; c++ {
+				sheet.HiddenColumns[c+1] = true
+			}
+		case brtHLink:
+			if len(data) < 16 {
+				return nil
+			}
+			ref, err := xlsbRangeRef(data)
+			if err != nil {
+				return err
+			}
+			r := xlsbData(data[16:])
+			relID, _ := r.string()
+			location, _ := r.string()
+
+			target := location
+			if relID != "" {
+				if rels == nil {
+					rels = partRelationships(zipReader, part)
+				}
+				target = rels[relID].Target
+			}
+			if target = hyperlinkTarget(target); target == "" {
+				return nil
+			}
+			return sheet.Marks.mark(ref, func(m *cellMark) { m.Link = target })
+		}
+		return nil
+	})
+}
+
+// xlsbRangeRef converts the zero-based RfX range at the start of data into
+// an A1 reference.
+func xlsbRangeRef(data []byte) (string, error) {
+	firstRow := int(binary.LittleEndian.Uint32(data))
+	lastRow := int(binary.LittleEndian.Uint32(data[4:]))
+	firstCol := int(binary.LittleEndian.Uint32(data[8:]))
+	lastCol := int(binary.LittleEndian.Uint32(data[12:]))
+
+	first, err := excelize.CoordinatesToCellName(firstCol+1, firstRow+1)
+	if err != nil {
+		return "", err
+	}
+	last, err := excelize.CoordinatesToCellName(lastCol+1, lastRow+1)
+	if err != nil {
+		return "", err
+	}
+	return first + ":" + last, nil
+}
+
+// readXlsbPart calls fn with every record of a package part. Missing parts
+// have no records.
+func readXlsbPart(zipReader *zip.Reader, part string, fn func(kind int, data []byte) error) error {
+	file, err := findFileInZip(zipReader, part)
+	if err != nil {
+		return nil
+	}
+	rc, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	records := newXlsbRecordReader(rc)
+	for {
+		kind, data, err := records.next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(kind, data); err != nil {
+			return err
+		}
+	}
+}
+
+// xlsbRecordReader reads BIFF12 records, each a variable-length type and size
+// followed by its data. The data buffer is reused between records.
+type xlsbRecordReader struct {
+	r   *bufio.Reader
+	buf []byte
+}
+
+func newXlsbRecordReader(r io.Reader) *xlsbRecordReader {
+	return &xlsbRecordReader{r: bufio.NewReader(r)}
+}
+
+// next returns the type and data of the next record, or io.EOF at the end
+// of the part.
+func (x *xlsbRecordReader) next() (int, []byte, error) {
+	kind, err := x.varint(2)
+	if err != nil {
+		return 0, nil, err
+	}
+	size, err := x.varint(4)
+	if err != nil {
+		return 0, nil, truncated(err)
+	}
+
+	if cap(x.buf) < size {
+		x.buf = make([]byte, size)
+	}
+	data := x.buf[:size]
+	if _, err := io.ReadFull(x.r, data); err != nil {
+		return 0, nil, truncated(err)
+	}
+	return kind, data, nil
+}
+
+// varint reads a little-endian number stored in up to n bytes of seven bits,
+// the high bit of each byte marking that another byte follows.
+func (x *xlsbRecordReader) varint(n int) (int, error) {
+	value := 0
+	for i := range n {
+		b, err := x.r.ReadByte()
+		if err != nil {
+			if i > 0 {
+				return 0, truncated(err)
+			}
+			return 0, err
+		}
+		value |= int(b&0x7F) << (7 * i)
+		if b&0x80 == 0 {
+			break
+		}
+	}
+	return value, nil
+}
+
+func truncated(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// xlsbData reads the wide strings of a record.
+type xlsbData []byte
+
+// string reads an XLWideString: a character count followed by UTF-16LE text.
+// The count of a null XLNullableWideString reads as an empty string.
+func (d *xlsbData) string() (string, bool) {
+	if len(*d) < 4 {
+		return "", false
+	}
+	count := binary.LittleEndian.Uint32(*d)
+	*d = (*d)[4:]
+	if count == math.MaxUint32 {
+		return "", true
+	}
+	if uint64(len(*d)) < uint64(count)*2 {
+		return "", false
+	}
+
+	units := make([]uint16, count)
+	for i := range units {
+		units[i] = binary.LittleEndian.Uint16((*d)[i*2:])
+	}
+	*d = (*d)[count*2:]
+	return string(utf16.Decode(units)), true
+}
+
+// xlsbRows streams the rows of an XLSB worksheet part. Rows without a row
+// record are reported as empty, so row numbers match the sheet.
+type xlsbRows struct {
+	records  *xlsbRecordReader
+	closer   io.Closer
+	strings  []string
+	styles   *xlsbStyles
+	date1904 bool
+	values   ValueMode
+
+	number  int
+	current *xlsbRow
+	ahead   *xlsbRow
+	header  *xlsbRow
+	done    bool
+	err     error
+}
+
+// xlsbRow holds the cells of a row, indexed by zero-based column.
+type xlsbRow struct {
+	index  int
+	hidden bool
+	cells  map[int]string
+	last   int
+}
+
+// Next advances to the next row number and reports whether the sheet has
+// rows left.
+func (x *xlsbRows) Next() bool {
+	if x.err != nil || x.records == nil {
+		return false
+	}
+	if x.ahead == nil && !x.done {
+		x.ahead, x.err = x.readRow()
+		if x.err != nil {
+			return false
+		}
+	}
+	if x.ahead == nil {
+		return false
+	}
+
+	x.number++
+	x.current = nil
+	if x.ahead.index+1 <= x.number {
+		x.current, x.ahead = x.ahead, nil
+	}
+	return true
+}
+
+// Columns returns the cell values of the current row, without trailing
+// empty cells.
+func (x *xlsbRows) Columns(...excelize.Options) ([]string, error) {
+	if x.err != nil {
+		return nil, x.err
+	}
+	if x.current == nil || len(x.current.cells) == 0 {
+		return nil, nil
+	}
+
+	row := make([]string, x.current.last+1)
+	for c, value := range x.current.cells {
+		row[c] = value
+	}
+	for len(row) > 0 && row[len(row)-1] == "" {
+		row = row[:len(row)-1]
+	}
+	return row, nil
+}
+
+// GetRowOpts returns the visibility of the current row.
+func (x *xlsbRows) GetRowOpts() excelize.RowOpts {
+	return excelize.RowOpts{Hidden: x.current != nil && x.current.hidden}
+}
+
+// Close closes the worksheet part.
+func (x *xlsbRows) Close() error {
+	if x.closer == nil {
+		return x.err
+	}
+	err := x.closer.Close()
+	x.closer = nil
+	if x.err != nil {
+		return x.err
+	}
+	return err
+}
+
+// readRow reads the next row record and its cells, keeping the header of
+// the row after it. It returns nil at the end of the sheet data.
+func (x *xlsbRows) readRow() (*xlsbRow, error) {
+	row := x.header
+	x.header = nil
+	for {
+		kind, data, err := x.records.next()
+		if errors.Is(err, io.EOF) {
+			x.done = true
+			return row, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case kind == brtRowHdr:
+			if len(data) < 12 {
+				return nil, errors.New("truncated row record")
+			}
+			index := int(binary.LittleEndian.Uint32(data))
+			if index >= excelize.TotalRows {
+				return nil, fmt.Errorf("%w: row %d out of range", ErrCorruptDocument, index+1)
+			}
+			next := &xlsbRow{
+				index:  index,
+				hidden: data[11]&xlsbHiddenRowFlag != 0,
+				cells:  make(map[int]string),
+			}
+			if row != nil {
+				x.header = next
+				return row, nil
+			}
+			row = next
+		case kind == brtEndSheetData:
+			x.done = true
+			return row, nil
+		case kind >= brtCellBlank && kind <= brtFmlaError && row != nil:
+			if len(data) < 8 {
+				return nil, errors.New("truncated cell record")
+			}
+			col := int(binary.LittleEndian.Uint32(data))
+			if col >= excelize.MaxColumns {
+				return nil, fmt.Errorf("%w: column %d out of range", ErrCorruptDocument, col+1)
+			}
+			style := int(binary.LittleEndian.Uint32(data[4:]) & 0xFFFFFF)
+			value, err := x.cellValue(kind, style, data[8:])
+			if err != nil {
+				return nil, err
+			}
+			if value != "" {
+				row.cells[col] = value
+				row.last = max(row.last, col)
+			}
+		}
+	}
+}
+
+// cellValue returns the text of a cell record. Numbers with a date format
+// are written as ISO 8601 unless raw values are requested.
+func (x *xlsbRows) cellValue(kind, style int, data []byte) (string, error) {
+	switch kind {
+	case brtCellRk:
+		if len(data) < 4 {
+			return "", errors.New("truncated cell record")
+		}
+		return x.formatNumber(style, rkNumber(binary.LittleEndian.Uint32(data))), nil
+	case brtCellReal, brtFmlaNum:
+		if len(data) < 8 {
+			return "", errors.New("truncated cell record")
+		}
+		return x.formatNumber(style, math.Float64frombits(binary.LittleEndian.Uint64(data))), nil
+	case brtCellBool, brtFmlaBool:
+		if len(data) < 1 {
+			return "", errors.New("truncated cell record")
+		}
+		if x.values == ValuesRaw {
+			return strconv.Itoa(int(data[0])), nil
+		}
+		if data[0] != 0 {
+			return "TRUE", nil
+		}
+		return "FALSE", nil
+	case brtCellError, brtFmlaError:
+		if len(data) < 1 {
+			return "", errors.New("truncated cell record")
+		}
+		return xlsbErrors[data[0]], nil
+	case brtCellSt, brtFmlaString:
+		r := xlsbData(data)
+		text, ok := r.string()
+		if !ok {
+			return "", errors.New("truncated cell record")
+		}
+		return text, nil
+	case brtCellIsst:
+		if len(data) < 4 {
+			return "", errors.New("truncated cell record")
+		}
+		index := int(binary.LittleEndian.Uint32(data))
+		if index >= len(x.strings) {
+			return "", fmt.Errorf("shared string %d out of range", index)
+		}
+		return x.strings[index], nil
+	}
+	return "", nil
+}
+
+func (x *xlsbRows) formatNumber(style int, value float64) string {
+	if x.values != ValuesRaw && x.styles.isDate(style) {
+		return isoDate(value, x.date1904)
+	}
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// rkNumber decodes an RkNumber: a 30-bit integer or the high bits of a
+// float64, optionally scaled by 100.
+func rkNumber(rk uint32) float64 {
+	var value float64
+	if rk&0x2 != 0 {
+		value = float64(int32(rk) >> 2)
+	} else {
+		value = math.Float64frombits(uint64(rk&0xFFFFFFFC) << 32)
+	}
+	if rk&0x1 != 0 {
+		value /= 100
+	}
+	return value
+}
@@ -0,0 +1,159 @@
+package converters
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+// xlsbRecord encodes a BIFF12 record with its variable-length type and size.
+func xlsbRecord(kind int, fields ...any) []byte {
+	var data bytes.Buffer
+	for _, field := range fields {
+		switch v := field.(type) {
+		case string:
+			units := utf16.Encode([]rune(v))
+			binary.Write(&data, binary.LittleEndian, uint32(len(units)))
+			binary.Write(&data, binary.LittleEndian, units)
+		default:
+			binary.Write(&data, binary.LittleEndian, v)
+		}
+	}
+
+	var record bytes.Buffer
+	for _, n := range []int{kind, data.Len()} {
+		for n >= 0x80 {
+			record.WriteByte(byte(n&0x7F) | 0x80)
+			n >>= 7
+		}
+		record.WriteByte(byte(n))
+	}
+	record.Write(data.Bytes())
+	return record.Bytes()
+}
+
+func xlsbRowHeader(index uint32, hidden bool) []byte {
+	var flags uint8
+	if hidden {
+		flags = xlsbHiddenRowFlag
+	}
+	return xlsbRecord(brtRowHdr, index, uint32(0), uint16(300), uint8(0), flags, uint8(0))
+}
+
+func xlsbPart(records ...[]byte) string {
+	return string(bytes.Join(records, nil))
+}
+
+func TestExcelConverter_Load_Xlsb(t *testing.T) {
+	archive := writeTestArchive(t, "report.xlsb", map[string]string{
+		"xl/workbook.bin": xlsbPart(
+			xlsbRecord(brtWbProp, uint32(0)),
+			xlsbRecord(brtBundleSh, uint32(0), uint32(1), "rId1", "Sales"),
+			xlsbRecord(brtBundleSh, uint32(1), uint32(2), "rId2", "Scratch"),
+		),
+		"xl/_rels/workbook.bin.rels": `<?xml version="1.0" encoding="UTF-8"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.microsoft.com/office/2006/relationships/xlBinaryIndex" Target="worksheets/sheet1.bin"/>
+<Relationship Id="rId2" Type="http://schemas.microsoft.com/office/2006/relationships/xlBinaryIndex" Target="worksheets/sheet2.bin"/>
+</Relationships>`,
+		"xl/sharedStrings.bin": xlsbPart(
+			xlsbRecord(brtSSTItem, uint8(0), "Item"),
+			xlsbRecord(brtSSTItem, uint8(0), "Shipped"),
+		),
+		"xl/styles.bin": xlsbPart(
+			xlsbRecord(brtBeginCellXFs, uint32(2)),
+			xlsbRecord(brtXF, uint16(0), uint16(0), uint32(0), uint32(0)),
+			xlsbRecord(brtXF, uint16(0), uint16(14), uint32(0), uint32(0)),
+			xlsbRecord(brtEndCellXFs),
+		),
+		"xl/worksheets/sheet1.bin": xlsbPart(
+			xlsbRecord(brtColInfo, uint32(2), uint32(2), uint32(0), uint32(0), uint16(1)),
+			xlsbRowHeader(0, false),
+			xlsbRecord(brtCellIsst, uint32(0), uint32(0), uint32(0)),
+			xlsbRecord(brtCellIsst, uint32(1), uint32(0), uint32(1)),
+			xlsbRecord(brtCellSt, uint32(2), uint32(0), "Cost"),
+			xlsbRowHeader(1, false),
+			xlsbRecord(brtCellSt, uint32(0), uint32(0), "Widget"),
+			xlsbRecord(brtCellReal, uint32(1), uint32(1), float64(45292)),
+			xlsbRecord(brtCellRk, uint32(2), uint32(0), uint32(350<<2|0x3)),
+			xlsbRowHeader(2, true),
+			xlsbRecord(brtCellSt, uint32(0), uint32(0), "Draft"),
+			xlsbRowHeader(4, false),
+			xlsbRecord(brtFmlaNum, uint32(0), uint32(0), math.Pi, uint16(0)),
+			xlsbRecord(brtFmlaBool, uint32(1), uint32(0), uint8(1), uint16(0)),
+			xlsbRecord(brtCellError, uint32(2), uint32(0), uint8(0x07)),
+			xlsbRecord(brtEndSheetData),
+			xlsbRecord(brtHLink, uint32(1), uint32(1), uint32(0), uint32(0), uint32(math.MaxUint32), "Scratch!A1", "", ""),
+		),
+		"xl/worksheets/sheet2.bin": xlsbPart(
+			xlsbRowHeader(0, false),
+			xlsbRecord(brtCellSt, uint32(0), uint32(0), "Notes"),
+			xlsbRecord(brtEndSheetData),
+		),
+	})
+
+	result, err := NewExcelConverter().Load(archive)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	expected := "## Sales\n\n| Item | Shipped | Cost |\n| --- | --- | --- |\n" +
+		"| [Widget](#scratch) | 2024-01-01 | 3.5 |\n| Draft |  |  |\n|  |  |  |\n" +
+		"| 3.141592653589793 | TRUE | #DIV/0! |\n\n## Scratch\n\n| Notes |\n| --- |\n"
+	if result != expected {
+		t.Errorf("Load() = %q, want %q", result, expected)
+	}
+
+	converter := &ExcelConverter{Values: ValuesRaw, SkipHiddenSheets: true, SkipHiddenRows: true, SkipHiddenColumns: true}
+	result, err = converter.Load(archive)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	expected = "## Sales\n\n| Item | Shipped |\n| --- | --- |\n| [Widget](#scratch) | 45292 |\n|  |  |\n| 3.141592653589793 | 1 |\n"
+	if result != expected {
+		t.Errorf("Load() with raw values and hidden content skipped = %q, want %q", result, expected)
+	}
+}
+
+func TestRkNumber(t *testing.T) {
+	tests := []struct {
+		rk   uint32
+		want float64
+	}{
+		{rk: 42<<2 | 0x2, want: 42},
+		{rk: 0xFFFFFFEE, want: -5},
+		{rk: 1234<<2 | 0x3, want: 12.34},
+		{rk: uint32(math.Float64bits(1.5) >> 32), want: 1.5},
+	}
+	for _, tt := range tests {
+		if got := rkNumber(tt.rk); got != tt.want {
+			t.Errorf("rkNumber(%#x) = %v, want %v", tt.rk, got, tt.want)
+		}
+	}
+}
+
+func TestXlsbRows_OutOfRange(t *testing.T) {
+	tests := map[string]string{
+		"column": xlsbPart(xlsbRowHeader(0, false), xlsbRecord(brtCellSt, uint32(math.MaxUint32), uint32(0), "Widget")),
+		"row":    xlsbPart(xlsbRowHeader(math.MaxUint32, false), xlsbRecord(brtCellSt, uint32(0), uint32(0), "Widget")),
+	}
+	for name, part := range tests {
+		rows := &xlsbRows{records: newXlsbRecordReader(strings.NewReader(part))}
+		if rows.Next() {
+			t.Errorf("Next() with a %s out of range = true, want false", name)
+		}
+		if _, err := rows.Columns(); !errors.Is(err, ErrCorruptDocument) {
+			t.Errorf("Columns() with a %s out of range error = %v, want ErrCorruptDocument", name, err)
+		}
+	}
+}
+
+func TestXlsbRecordReader_Truncated(t *testing.T) {
+	records := newXlsbRecordReader(strings.NewReader(string(xlsbRecord(brtCellSt, "Widget")[:5])))
+	if _, _, err := records.next(); err == nil {
+		t.Error("next() on a truncated record should return an error")
+	}
+}
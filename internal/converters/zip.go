@@ -0,0 +1,108 @@
+package converters
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/flaviodelgrosso/marky/internal/utils"
+)
+
+// ZipConverter handles loading ZIP archives, converting the files they hold
+// with the function given to SetConverter under a "Files" section.
+type ZipConverter struct {
+	BaseConverter
+
+	// Limits bounds the conversion of the files of archives.
+	Limits ContainerLimits
+
+	// InMemory lists the files without converting them, since they are
+	// written to temporary files to be converted. It is set by the
+	// InMemory field of ConvertOptions.
+	InMemory bool
+
+	// ArchiveLimits bounds the archives, as set by the ArchiveLimits field
+	// of ConvertOptions.
+	ArchiveLimits ArchiveLimits
+
+	convert ConvertFunc
+}
+
+// NewZipConverter creates a new ZIP converter with appropriate MIME types and extensions.
+func NewZipConverter() Converter {
+	return &ZipConverter{
+		BaseConverter: NewBaseConverter(
+			[]string{".zip"},
+			[]string{"application/zip", "application/x-zip-compressed"},
+		),
+	}
+}
+
+// SetConverter sets the function converting the files of archives.
+func (c *ZipConverter) SetConverter(convert ConvertFunc) {
+	c.convert = convert
+}
+
+// Load converts the files of a ZIP archive to markdown.
+func (c *ZipConverter) Load(path string) (string, error) {
+	return c.LoadContext(context.Background(), path)
+}
+
+// LoadContext converts the files of a ZIP archive to markdown, stopping
+// once ctx is done.
+func (c *ZipConverter) LoadContext(ctx context.Context, path string) (string, error) {
+	r, err := openArchive(path, c.ArchiveLimits)
+	if err != nil {
+		return "", fmt.Errorf("failed to open ZIP archive: %w", corrupt(err))
+	}
+	defer r.Close()
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return c.loadArchive(ctx, &r.Reader, name)
+}
+
+// LoadBytes converts a ZIP archive held in memory like Load, titled
+// "Archive".
+func (c *ZipConverter) LoadBytes(ctx context.Context, data []byte) (string, error) {
+	r, err := newArchiveReader(bytes.NewReader(data), int64(len(data)), c.ArchiveLimits)
+	if err != nil {
+		return "", fmt.Errorf("failed to open ZIP archive: %w", corrupt(err))
+	}
+	return c.loadArchive(ctx, r, "Archive")
+}
+
+// loadArchive converts the files of an archive under a title.
+func (c *ZipConverter) loadArchive(ctx context.Context, r *zip.Reader, title string) (string, error) {
+	var files []attachment
+	for _, f := range r.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		files = append(files, attachment{
+			Name: f.Name,
+			Size: int64(f.UncompressedSize64),
+			read: func() ([]byte, error) { return readZipFile(f) },
+		})
+	}
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "# %s\n", utils.EscapeMarkdown(title, utils.FlavorGFM))
+	if err := writeAttachments(ctx, &buf, "Files", files, c.Limits, c.InMemory, c.convert); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// readZipFile reads a file of an archive, no further than its declared
+// size.
+func readZipFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(io.LimitReader(rc, int64(f.UncompressedSize64)))
+}
@@ -0,0 +1,73 @@
+package converters
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestZipConverter_Load(t *testing.T) {
+	archive := writeTestArchive(t, "bundle.zip", map[string]string{
+		"data.csv":  "name,age\nAda,36\n",
+		"notes.txt": "not converted",
+	})
+
+	c := NewZipConverter().(*ZipConverter)
+	c.SetConverter(func(ctx context.Context, path string) (string, error) {
+		if filepath.Ext(path) == ".csv" {
+			return NewCsvConverter().Load(path)
+		}
+		return "", ErrDiskWrite
+	})
+	markdown, err := c.Load(archive)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+
+	for _, want := range []string{"# bundle\n", "## Files\n", "### data.csv\n", "| Ada | 36 |", "### notes.txt\n", "_Not converted: "} {
+		if !strings.Contains(markdown, want) {
+			t.Errorf("Load() = %q, want it to contain %q", markdown, want)
+		}
+	}
+}
+
+func TestZipConverter_Load_Limits(t *testing.T) {
+	archive := writeTestArchive(t, "bundle.zip", map[string]string{
+		"a.csv": "x\n1\n",
+		"b.csv": "x\n" + strings.Repeat("1\n", 100),
+		"c.csv": "x\n3\n",
+	})
+	convert := func(ctx context.Context, path string) (string, error) {
+		return NewCsvConverter().Load(path)
+	}
+
+	c := &ZipConverter{Limits: ContainerLimits{MaxSize: 50}}
+	c.SetConverter(convert)
+	markdown, err := c.Load(archive)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	for _, want := range []string{"| 1 |", "| 3 |", "### b.csv\n\n_Not converted: larger than 50 bytes._"} {
+		if !strings.Contains(markdown, want) {
+			t.Errorf("Load() = %q, want it to contain %q", markdown, want)
+		}
+	}
+
+	c.Limits.MaxFiles = 2
+	if markdown, err = c.Load(archive); err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	if !strings.Contains(markdown, "_… 1 more file_") {
+		t.Errorf("Load() = %q, want the third file counted", markdown)
+	}
+
+	nested := context.WithValue(context.Background(), depthKey{}, 3)
+	markdown, err = c.LoadContext(nested, archive)
+	if err != nil {
+		t.Fatalf("LoadContext() returned unexpected error: %v", err)
+	}
+	if !strings.Contains(markdown, "_Not converted: nested too deeply._") {
+		t.Errorf("LoadContext() = %q, want files nested too deeply", markdown)
+	}
+}
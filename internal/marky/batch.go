@@ -0,0 +1,141 @@
+package marky
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/flaviodelgrosso/marky/internal/worker"
+)
+
+// BatchOptions configures the conversion of the files of a directory by
+// ConvertDir.
+type BatchOptions struct {
+	// Workers bounds the number of files converted concurrently. Zero uses
+	// one worker per CPU.
+	Workers int
+
+	// Extensions restricts the conversion to the files with one of these
+	// extensions, such as ".pdf". Every file a converter accepts is
+	// converted when empty.
+	Extensions []string
+
+	// Hidden also converts the files and directories whose names start with
+	// a dot, which are skipped by default.
+	Hidden bool
+
+	// MaxDistance is the number of bits the simhash signatures of two
+	// documents may differ by for them to be near duplicates, 3 when zero.
+	// It is negative to flag exact duplicates only.
+	MaxDistance int
+}
+
+// BatchResult is the outcome of the conversion of a file by ConvertDir.
+type BatchResult struct {
+	// Path is the path of the file, joined to the directory.
+	Path string `json:"path"`
+
+	// Result is the converted document, nil when the conversion failed.
+	Result *ConversionResult `json:"result,omitempty"`
+
+	// Err is the error of the conversion.
+	Err error `json:"-"`
+
+	// Hash and Simhash fingerprint the converted markdown. DuplicateOf is
+	// the path of a file before it in walking order with the same content,
+	// and NearDuplicateOf the path of one with similar content.
+	Hash            string `json:"hash,omitempty"`
+	Simhash         string `json:"simhash,omitempty"`
+	DuplicateOf     string `json:"duplicate_of,omitempty"`
+	NearDuplicateOf string `json:"near_duplicate_of,omitempty"`
+}
+
+// ConvertDir converts the files of a directory tree concurrently, with at
+// most Workers files at a time, and returns the result of each file in
+// walking order, as ConvertWithResult would. Files no converter accepts are
+// left out of the results, and the errors of the other files are recorded
+// in theirs. The converted files are fingerprinted in walking order, so that
+// duplicates point to the first file with their content. It returns an error when the directory cannot be walked, or the
+// error of ctx once it is done, after which no file is started.
+func (m *Marky) ConvertDir(ctx context.Context, dir string, opts BatchOptions) ([]BatchResult, error) {
+	var paths []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != dir && !opts.Hidden && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Type().IsRegular() && opts.accepts(path) {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]BatchResult, len(paths))
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(workers, len(paths)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := range jobs {
+				result, err := m.convertWithResult(ctx, paths[n])
+				results[n] = BatchResult{Path: paths[n], Result: result, Err: err}
+			}
+		}()
+	}
+	for n := range paths {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- n
+	}
+	close(jobs)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	results = slices.DeleteFunc(results, func(r BatchResult) bool {
+		return errors.Is(r.Err, ErrNoConverter)
+	})
+	fingerprints := &worker.Fingerprints{MaxDistance: opts.MaxDistance}
+	for n, r := range results {
+		if r.Err != nil {
+			continue
+		}
+		status := worker.Status{Job: worker.Job{ID: r.Path}}
+		fingerprints.Check(&status, r.Result.Markdown)
+		results[n].Hash, results[n].Simhash = status.Hash, status.Simhash
+		results[n].DuplicateOf, results[n].NearDuplicateOf = status.DuplicateOf, status.NearDuplicateOf
+	}
+	return results, nil
+}
+
+// accepts reports whether a file has one of the extensions of the options.
+func (opts BatchOptions) accepts(path string) bool {
+	if len(opts.Extensions) == 0 {
+		return true
+	}
+	extension := strings.ToLower(filepath.Ext(path))
+	return slices.ContainsFunc(opts.Extensions, func(e string) bool {
+		return strings.EqualFold(strings.TrimPrefix(e, "."), strings.TrimPrefix(extension, "."))
+	})
+}
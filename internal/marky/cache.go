@@ -0,0 +1,55 @@
+package marky
+
+import "container/list"
+
+// maxDetections bounds the number of detections cached by a Marky, so that
+// long-running services converting uploads under unique paths do not grow
+// the cache for the life of the process.
+const maxDetections = 1024
+
+// detectionCache holds the detections of the most recently converted paths,
+// dropping the least recently used one once it holds maxDetections. It is
+// not safe for concurrent use.
+type detectionCache struct {
+	entries map[string]*list.Element
+	order   list.List
+}
+
+// detectionEntry is a detection cached for a path.
+type detectionEntry struct {
+	path      string
+	detection detection
+}
+
+// get returns the detection cached for path.
+func (c *detectionCache) get(path string) (detection, bool) {
+	element, ok := c.entries[path]
+	if !ok {
+		return detection{}, false
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*detectionEntry).detection, true
+}
+
+// put caches the detection of path.
+func (c *detectionCache) put(path string, d detection) {
+	if element, ok := c.entries[path]; ok {
+		element.Value.(*detectionEntry).detection = d
+		c.order.MoveToFront(element)
+		return
+	}
+	if c.entries == nil {
+		c.entries = make(map[string]*list.Element)
+	}
+	c.entries[path] = c.order.PushFront(&detectionEntry{path: path, detection: d})
+	if c.order.Len() > maxDetections {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*detectionEntry).path)
+	}
+}
+
+// len returns the number of cached detections.
+func (c *detectionCache) len() int {
+	return c.order.Len()
+}
@@ -0,0 +1,22 @@
+package marky
+
+import "bytes"
+
+// Signature identifies a file format by the magic bytes at an offset of its
+// header, for formats the built-in detection does not know.
+type Signature struct {
+	// MimeType is the MIME type of the format, which the converter of the
+	// format accepts.
+	MimeType string
+
+	// Offset is the position of the magic bytes. Signatures must lie within
+	// the first 3072 bytes of the file.
+	Offset int
+	Magic  []byte
+}
+
+// matches reports whether a file header starts with the signature bytes at
+// the signature offset.
+func (s Signature) matches(header []byte) bool {
+	return len(s.Magic) > 0 && s.Offset >= 0 && s.Offset <= len(header) && bytes.HasPrefix(header[s.Offset:], s.Magic)
+}
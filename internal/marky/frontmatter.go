@@ -0,0 +1,62 @@
+package marky
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/flaviodelgrosso/marky/internal/converters"
+	"github.com/flaviodelgrosso/marky/internal/mimetypes"
+	"github.com/flaviodelgrosso/marky/internal/utils"
+)
+
+// SetFrontMatter sets whether converted documents start with a YAML front
+// matter block of their metadata.
+func (m *Marky) SetFrontMatter(enabled bool) {
+	m.FrontMatter = enabled
+}
+
+// addFrontMatter adds the metadata of a document, its source and its format
+// to the front matter of its markdown. Fields written by the converter are
+// kept as they are; the others are added after them.
+func addFrontMatter(markdown string, meta converters.DocumentMetadata, source, format string) string {
+	fields, body := utils.SplitFrontMatter(markdown)
+	for _, field := range []utils.FrontMatterField{
+		{Key: "title", Value: meta.Title},
+		{Key: "author", Value: meta.Author},
+		{Key: "created", Value: meta.Created},
+		{Key: "modified", Value: meta.Modified},
+		{Key: "source", Value: source},
+		{Key: "format", Value: format},
+	} {
+		exists := slices.ContainsFunc(fields, func(f utils.FrontMatterField) bool { return f.Key == field.Key })
+		if !exists && strings.TrimSpace(field.Value) != "" {
+			fields = append(fields, field)
+		}
+	}
+
+	frontMatter := utils.FrontMatter(fields)
+	if frontMatter == "" {
+		return body
+	}
+	return frontMatter + "\n" + body
+}
+
+// sourceName returns the source field of the front matter of a document:
+// the name of a file, or a URL as it is.
+func sourceName(path string) string {
+	if path == "" || converters.IsURL(path) || converters.IsObjectURI(path) {
+		return path
+	}
+	return filepath.Base(path)
+}
+
+// formatName returns the format field of the front matter of a document,
+// such as docx, from the extension of its MIME type or else of its path.
+func formatName(mimeType, path string) string {
+	extension := mimetypes.Extension(mimeType)
+	if extension == "" {
+		extension = filepath.Ext(path)
+	}
+	return strings.ToLower(strings.TrimPrefix(extension, "."))
+}
@@ -0,0 +1,48 @@
+package marky
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/flaviodelgrosso/marky/internal/converters"
+)
+
+func TestMarky_Convert_FrontMatter(t *testing.T) {
+	m := &Marky{}
+	m.RegisterConverter(converters.NewDocConverter())
+	m.SetFrontMatter(true)
+
+	got, err := m.Convert(filepath.Join("..", "..", "test_files", "test.docx"))
+	if err != nil {
+		t.Fatalf("Convert() returned unexpected error: %v", err)
+	}
+	want := "---\nauthor: Adam Fourney\ncreated: 2024-03-15T05:45:00Z\nmodified: 2024-03-15T05:53:00Z\nsource: test.docx\nformat: docx\n---\n\n"
+	if !strings.HasPrefix(got, want) {
+		t.Errorf("Convert() front matter = %q, want %q", got[:min(len(got), len(want))], want)
+	}
+
+	var streamed strings.Builder
+	if err := m.ConvertTo(&streamed, filepath.Join("..", "..", "test_files", "test.docx")); err != nil || streamed.String() != got {
+		t.Errorf("ConvertTo() = %q, %v, want %q", streamed.String(), err, got)
+	}
+}
+
+func TestMarky_Convert_FrontMatter_KeepsConverterFields(t *testing.T) {
+	m := &Marky{}
+	m.RegisterConverter(newFakeConverter("---\ntitle: Notes\n---\n\n# Notes\n", []string{".txt"}, nil))
+	m.SetFrontMatter(true)
+
+	got, err := m.Convert(writeTestFile(t, "notes.txt", "text"))
+	if err != nil {
+		t.Fatalf("Convert() returned unexpected error: %v", err)
+	}
+	if want := "---\ntitle: Notes\nsource: notes.txt\nformat: txt\n---\n\n# Notes\n"; got != want {
+		t.Errorf("Convert() = %q, want %q", got, want)
+	}
+
+	m.SetFrontMatter(false)
+	if got, _ := m.Convert(writeTestFile(t, "notes.txt", "text")); strings.Contains(got, "source:") {
+		t.Errorf("Convert() = %q, want no front matter fields added", got)
+	}
+}
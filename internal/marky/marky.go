@@ -1,50 +1,707 @@
 package marky
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"os"
+	"path/filepath"
 	"slices"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/flaviodelgrosso/marky/internal/converters"
-	"github.com/gabriel-vasile/mimetype"
+	"github.com/flaviodelgrosso/marky/internal/mimetypes"
+	"github.com/flaviodelgrosso/marky/internal/utils"
 )
 
 // Marky manages document converters and provides conversion functionality.
 type Marky struct {
+	// Converters lists the registered converters in detection order. It is
+	// kept by the registration methods, such as Register and Remove.
 	Converters []converters.Converter
+
+	// registry holds the registered converters in registration order.
+	registry []Registration
+
+	// Signatures identify formats before the built-in detection, in the
+	// order they were registered.
+	Signatures []Signature
+
+	// Extensions maps lowercase file extensions, such as ".abc", to the
+	// converter of the files with that extension, regardless of their
+	// content.
+	Extensions map[string]converters.Converter
+
+	// HeadingOffset moves the headings of the converted documents down by
+	// that many levels, and MaxHeadingDepth caps their level, so documents
+	// can be embedded under the headings of an existing page. Headings are
+	// kept as they are when both are zero.
+	HeadingOffset   int
+	MaxHeadingDepth int
+
+	// OutputFormat selects how the converted documents are rendered,
+	// FormatMarkdown by default.
+	OutputFormat OutputFormat
+
+	// Summarizer, when set, writes a summary placed at the top of each
+	// converted document.
+	Summarizer Summarizer
+
+	// PostProcessors transform the markdown of the converted documents in
+	// order, before the summary is added.
+	PostProcessors []PostProcessor
+
+	// FrontMatter, when set, starts the converted documents with a YAML
+	// front matter block of their title, author, creation and modification
+	// dates, source and format. The metadata is read by the converters
+	// implementing converters.MetadataReader.
+	FrontMatter bool
+
+	// MaxFileSize is the size in bytes above which files are not converted,
+	// failing with ErrFileTooLarge. Sizes are not checked when zero.
+	MaxFileSize int64
+
+	// DetectionHook is called with the path of each file before its MIME
+	// type is detected.
+	DetectionHook DetectionHook
+
+	// Objects maps the schemes of object URIs, "s3", "gs" and "az", to the
+	// openers reading their objects, such as openers using the SDKs of the
+	// stores. Objects of schemes without an opener are read anonymously
+	// with converters.PublicObjects.
+	Objects map[string]converters.ObjectOpener
+
+	// Logger receives the diagnostics of the converters implementing
+	// converters.LoggingConverter, such as files that could not be closed.
+	// It is set with SetLogger; converters use slog.Default when nil.
+	Logger *slog.Logger
+
+	// Progress receives the progress of the converters implementing
+	// converters.ProgressConverter through the pages, slides, sheets and
+	// chapters of long documents. It is set with SetProgress.
+	Progress converters.ProgressFunc
+
+	// Shared holds the settings of the instance given to every converter
+	// with converters.ConvertOptions.Configure, such as InMemory, which
+	// keeps the converters from writing to disk, and MmapThreshold. It is
+	// set with Share, and applies to the converters registered or
+	// configured later.
+	Shared converters.ConvertOptions
+
+	// detections caches the converters found from file contents by path,
+	// for the most recently converted paths.
+	mu         sync.Mutex
+	detections *detectionCache
 }
 
+// OutputFormat selects how Convert renders the converted documents.
+type OutputFormat int
+
+const (
+	// FormatMarkdown returns the markdown written by the converters.
+	FormatMarkdown OutputFormat = iota
+
+	// FormatText returns the plain text of the document, without markdown
+	// syntax or front matter, for search indexing.
+	FormatText
+
+	// FormatJSON returns the front matter and the blocks of the document,
+	// with their types and line positions in the markdown, as JSON.
+	FormatJSON
+
+	// FormatHTML returns a standalone HTML page of the document, to preview
+	// the conversion in a browser.
+	FormatHTML
+)
+
+// ErrFileTooLarge is returned for files larger than MaxFileSize.
+var ErrFileTooLarge = errors.New("file too large")
+
+// ErrNoConverter is returned for documents no registered converter accepts.
+var ErrNoConverter = errors.New("no converter found")
+
+// DetectionHook lets callers take over the detection of the MIME type of a
+// file. It returns the MIME type of the file when the caller knows it, or an
+// empty string to let detection run. An error vetoes the conversion of the
+// file and is returned by Convert.
+type DetectionHook func(path string) (string, error)
+
+// detection is the result of detecting the converter of a file, valid while
+// the file keeps its modification time and size. The converter is nil when
+// none accepts the MIME type of the file.
+type detection struct {
+	modTime   time.Time
+	size      int64
+	converter converters.Converter
+	mimeType  string
+}
+
+// IMarky is the conversion interface of Marky. Instances are configured
+// through the methods of Marky.
 type IMarky interface {
 	Convert(path string) (string, error)
+	ConvertContext(ctx context.Context, path string) (string, error)
+	ConvertBytes(data []byte, mimeType string) (string, error)
+	ConvertTo(w io.Writer, path string) error
+	ConvertWithResult(path string) (*ConversionResult, error)
+	ConvertDir(ctx context.Context, dir string, opts BatchOptions) ([]BatchResult, error)
+	Score(path, reference string) (Fidelity, error)
+}
+
+// SetHeadingLevels sets the offset and maximum depth of the headings of the
+// converted documents.
+func (m *Marky) SetHeadingLevels(offset, maxDepth int) {
+	m.HeadingOffset = offset
+	m.MaxHeadingDepth = maxDepth
+}
+
+// SetSummarizer sets the summarizer of the converted documents, or removes it
+// when summarizer is nil.
+func (m *Marky) SetSummarizer(summarizer Summarizer) {
+	m.Summarizer = summarizer
+}
+
+// SetOutputFormat sets how the converted documents are rendered.
+func (m *Marky) SetOutputFormat(format OutputFormat) {
+	m.OutputFormat = format
+}
+
+// Configure applies the options of each format to its converters, among the
+// registered converters and those of registered extensions. It returns an
+// error for options of a format no converter converts.
+func (m *Marky) Configure(options ...converters.FormatOptions) error {
+	for _, option := range options {
+		configured := false
+		for _, c := range m.Converters {
+			configured = option.Configure(c) || configured
+		}
+		for _, c := range m.Extensions {
+			configured = option.Configure(c) || configured
+		}
+		if !configured {
+			return fmt.Errorf("no converter for %T", option)
+		}
+	}
+	// Format options replace the ConvertOptions of their converters
+	for _, converter := range m.Converters {
+		m.passShared(converter)
+	}
+	for _, converter := range m.Extensions {
+		m.passShared(converter)
+	}
+	return nil
+}
+
+// Formats returns the registered converters, whose extensions, MIME types
+// and capabilities describe the formats Convert handles.
+func (m *Marky) Formats() []converters.Converter {
+	return slices.Clone(m.Converters)
 }
 
-// RegisterConverter adds a new document converter to the available converters.
+// RegisterConverter adds a new document converter to the available
+// converters, without a name and with the default priority.
 func (m *Marky) RegisterConverter(converter converters.Converter) {
-	m.Converters = append(m.Converters, converter)
+	m.registry = append(m.registry, Registration{Converter: converter})
+	m.sortConverters()
+}
+
+// RegisterSignature adds a magic-byte signature to the detection, so that
+// files of formats unknown to the built-in detection reach the converter
+// accepting the signature MIME type.
+func (m *Marky) RegisterSignature(signature Signature) {
+	m.Signatures = append(m.Signatures, signature)
+	m.resetDetections()
+}
+
+// SetDetectionHook sets the hook called before the MIME type of each file is
+// detected, or removes it when hook is nil.
+func (m *Marky) SetDetectionHook(hook DetectionHook) {
+	m.DetectionHook = hook
+}
+
+// SetLogger sets the logger of the diagnostics of the registered converters
+// and of those registered later, so that applications can route them to
+// their own structured logging.
+func (m *Marky) SetLogger(logger *slog.Logger) {
+	m.Logger = logger
+	for _, converter := range m.Converters {
+		m.passLogger(converter)
+	}
+	for _, converter := range m.Extensions {
+		m.passLogger(converter)
+	}
+}
+
+// passLogger gives the Logger to a converter implementing
+// converters.LoggingConverter.
+func (m *Marky) passLogger(converter converters.Converter) {
+	if c, ok := converter.(converters.LoggingConverter); ok {
+		c.SetLogger(m.Logger)
+	}
+}
+
+// SetProgress sets the function receiving the progress of the registered
+// converters and of those registered later, so that frontends can render
+// progress bars for long documents.
+func (m *Marky) SetProgress(progress converters.ProgressFunc) {
+	m.Progress = progress
+	for _, converter := range m.Converters {
+		m.passProgress(converter)
+	}
+	for _, converter := range m.Extensions {
+		m.passProgress(converter)
+	}
+}
+
+// passProgress gives the Progress to a converter implementing
+// converters.ProgressConverter.
+func (m *Marky) passProgress(converter converters.Converter) {
+	if c, ok := converter.(converters.ProgressConverter); ok {
+		c.SetProgress(m.Progress)
+	}
+}
+
+// Share adds the fields set in options to the Shared settings, and gives
+// them to the registered converters and to those registered or configured
+// later, so that settings such as InMemory, for read-only filesystems, hold
+// for the whole instance.
+func (m *Marky) Share(options converters.ConvertOptions) {
+	m.Shared = m.Shared.Merge(options)
+	for _, converter := range m.Converters {
+		m.passShared(converter)
+	}
+	for _, converter := range m.Extensions {
+		m.passShared(converter)
+	}
+}
+
+// passShared gives the Shared settings to a converter.
+func (m *Marky) passShared(converter converters.Converter) {
+	if m.Shared != (converters.ConvertOptions{}) {
+		m.Shared.Configure(converter)
+	}
+}
+
+// RegisterExtension routes the files with an extension to a converter,
+// which does not need to be registered with RegisterConverter.
+func (m *Marky) RegisterExtension(extension string, converter converters.Converter) {
+	if m.Extensions == nil {
+		m.Extensions = make(map[string]converters.Converter)
+	}
+	extension = strings.ToLower(extension)
+	if !strings.HasPrefix(extension, ".") {
+		extension = "." + extension
+	}
+	m.Extensions[extension] = converter
+	if m.Logger != nil {
+		m.passLogger(converter)
+	}
+	if m.Progress != nil {
+		m.passProgress(converter)
+	}
+	m.passShared(converter)
 }
 
 // Convert processes a document file and converts it to markdown format.
-// Returns the markdown content and an error if the conversion fails.
+// http and https URLs are converted as web pages by the HTML converter.
+// Objects of cloud stores, such as s3://bucket/key, are read into memory and
+// converted like the documents of ConvertBytes, routed by the extension of
+// their name.
+// Files go to the converter of the MIME type given by the detection hook,
+// then of their extension, then of their detected MIME type.
+// With FrontMatter, the metadata of the document is added to its front
+// matter. The PostProcessors then transform the markdown, the summary of
+// the Summarizer is added, then headings are shifted by HeadingOffset and
+// capped at MaxHeadingDepth, and the document is rendered in the
+// OutputFormat.
+// Returns the rendered content and an error if the conversion fails.
 func (m *Marky) Convert(path string) (string, error) {
+	return m.ConvertContext(context.Background(), path)
+}
+
+// ConvertContext converts a document like Convert, stopping once ctx is
+// done with its error. Converters implementing
+// converters.ContextConverter stop where they are, such as before the next
+// page of a PDF file; the conversions of other converters are left to
+// finish in the background.
+func (m *Marky) ConvertContext(ctx context.Context, path string) (string, error) {
+	result, err := m.load(ctx, path)
+	if err != nil {
+		return "", err
+	}
+	return m.render(result.Markdown)
+}
+
+// ConvertBytes converts a document held in memory, such as an object of a
+// cloud store or the payload of a message, with the converter of its MIME
+// type, which is detected from the content when empty. Converters
+// implementing converters.BytesConverter convert it without touching the
+// filesystem; it is written to a temporary file for the others. The
+// markdown is rendered like that of Convert.
+func (m *Marky) ConvertBytes(data []byte, mimeType string) (string, error) {
+	if m.MaxFileSize > 0 && int64(len(data)) > m.MaxFileSize {
+		return "", fmt.Errorf("%w: document is %d bytes, more than %d", ErrFileTooLarge, len(data), m.MaxFileSize)
+	}
+
+	var converter converters.Converter
+	if mimeType == "" {
+		var err error
+		if converter, mimeType, err = m.findBytes("", data); err != nil {
+			return "", err
+		}
+	} else {
+		if mediaType, _, err := mime.ParseMediaType(mimeType); err == nil {
+			mimeType = mediaType
+		}
+		if converter = m.converterFor(mimeType); converter == nil {
+			return "", fmt.Errorf("%w for MIME type: %s", ErrNoConverter, mimeType)
+		}
+	}
+
+	markdown, err := m.loadBytes(context.Background(), converter, data)
+	if err != nil {
+		return "", err
+	}
+	if m.FrontMatter {
+		markdown = addFrontMatter(markdown, converters.DocumentMetadata{}, "", formatName(mimeType, ""))
+	}
+	return m.render(markdown)
+}
+
+// ConvertTo converts a document like Convert, writing the result to w.
+// Converters implementing converters.WriterConverter, such as those of DOCX
+// and PPTX files, write the markdown to w as they convert the document, so
+// that large documents are not held in memory as a whole. Documents are
+// converted as a whole first when their markdown is rendered, by a
+// PostProcessors, a Summarizer, heading levels, FrontMatter or an
+// OutputFormat other than FormatMarkdown.
+func (m *Marky) ConvertTo(w io.Writer, path string) error {
+	return m.convertTo(context.Background(), w, path)
+}
+
+// convertTo converts a document like ConvertTo, stopping once ctx is done.
+func (m *Marky) convertTo(ctx context.Context, w io.Writer, path string) error {
+	if m.renders() || m.FrontMatter || converters.IsObjectURI(path) {
+		markdown, err := m.ConvertContext(ctx, path)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(w, markdown)
+		return err
+	}
+
+	converter, _, err := m.find(path)
+	if err != nil {
+		return err
+	}
+	return converters.LoadTo(ctx, converter, w, path)
+}
+
+// renders reports whether render changes converted markdown.
+func (m *Marky) renders() bool {
+	shifted := m.HeadingOffset != 0 || (m.MaxHeadingDepth > 0 && m.MaxHeadingDepth < 6)
+	return len(m.PostProcessors) > 0 || m.Summarizer != nil || shifted || m.OutputFormat != FormatMarkdown
+}
+
+// render runs the PostProcessors on converted markdown, adds the summary of
+// the Summarizer, shifts its headings and renders it in the OutputFormat.
+func (m *Marky) render(markdown string) (string, error) {
+	markdown, err := postProcess(markdown, m.PostProcessors)
+	if err != nil {
+		return "", fmt.Errorf("failed to post-process document: %w", err)
+	}
+	if m.Summarizer != nil {
+		if markdown, err = addSummary(markdown, m.Summarizer); err != nil {
+			return "", fmt.Errorf("failed to summarize document: %w", err)
+		}
+	}
+	markdown = utils.ShiftHeadings(markdown, m.HeadingOffset, m.MaxHeadingDepth)
+
+	switch m.OutputFormat {
+	case FormatText:
+		return utils.RenderText(markdown), nil
+	case FormatJSON:
+		return utils.RenderJSON(markdown)
+	case FormatHTML:
+		return utils.RenderHTML(markdown), nil
+	}
+	return markdown, nil
+}
+
+// convert converts a document with the converter found for it.
+func (m *Marky) convert(path string) (string, error) {
+	result, err := m.load(context.Background(), path)
+	if err != nil {
+		return "", err
+	}
+	return result.Markdown, nil
+}
+
+// load converts a document with the converter found for it, and tells the
+// converter and the MIME type it was chosen for. Converters implementing
+// converters.Reporter also report on the document.
+func (m *Marky) load(ctx context.Context, path string) (*ConversionResult, error) {
+	return m.loadAs(ctx, path, path)
+}
+
+// loadAs converts a document like load, naming it source in its front
+// matter, such as the URI of the object a temporary file was fetched from.
+func (m *Marky) loadAs(ctx context.Context, path, source string) (*ConversionResult, error) {
+	if converters.IsObjectURI(path) {
+		return m.loadObject(ctx, path, source)
+	}
+
+	converter, mimeType, err := m.find(path)
+	if err != nil {
+		return nil, err
+	}
+	result := &ConversionResult{MimeType: mimeType, Converter: converterName(converter)}
+	if reporter, ok := converter.(converters.Reporter); ok {
+		markdown, report, err := reporter.LoadReport(ctx, path)
+		if err != nil {
+			return nil, err
+		}
+		result.Markdown, result.Parts, result.Warnings = markdown, report.Parts, report.Warnings
+	} else if result.Markdown, err = converters.LoadContext(ctx, converter, path); err != nil {
+		return nil, err
+	}
+
+	if m.FrontMatter {
+		var meta converters.DocumentMetadata
+		if reader, ok := converter.(converters.MetadataReader); ok {
+			if meta, err = reader.ReadMetadata(path); err != nil {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("metadata could not be read: %v", err))
+			}
+		}
+		result.Markdown = addFrontMatter(result.Markdown, meta, sourceName(source), formatName(mimeType, source))
+	}
+	return result, nil
+}
+
+// openObject opens an object with the opener of its scheme.
+func (m *Marky) openObject(ctx context.Context, uri string) (io.ReadCloser, error) {
+	scheme, _, _ := strings.Cut(uri, "://")
+	if opener, ok := m.Objects[scheme]; ok {
+		return opener.OpenObject(ctx, uri)
+	}
+	return converters.PublicObjects{}.OpenObject(ctx, uri)
+}
+
+// loadObject converts an object of a cloud store like loadAs, reading it
+// into memory rather than to a temporary file. The metadata of the front
+// matter, read from files by the converters, is left out.
+func (m *Marky) loadObject(ctx context.Context, uri, source string) (*ConversionResult, error) {
+	body, err := m.openObject(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	var r io.Reader = body
+	if m.MaxFileSize > 0 {
+		r = io.LimitReader(body, m.MaxFileSize+1)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch %s: %w", uri, err)
+	}
+	if m.MaxFileSize > 0 && int64(len(data)) > m.MaxFileSize {
+		return nil, fmt.Errorf("%w: %s is more than %d bytes", ErrFileTooLarge, uri, m.MaxFileSize)
+	}
+
+	converter, mimeType, err := m.findBytes(converters.ObjectName(uri), data)
+	if err != nil {
+		return nil, err
+	}
+	result := &ConversionResult{MimeType: mimeType, Converter: converterName(converter)}
+	if result.Markdown, err = m.loadBytes(ctx, converter, data); err != nil {
+		return nil, err
+	}
+	if m.FrontMatter {
+		result.Markdown = addFrontMatter(result.Markdown, converters.DocumentMetadata{}, sourceName(source), formatName(mimeType, source))
+	}
+	return result, nil
+}
+
+// loadBytes converts a document held in memory with a converter, failing
+// with converters.ErrDiskWrite when the Shared settings are InMemory for
+// converters that would write it to a temporary file.
+func (m *Marky) loadBytes(ctx context.Context, converter converters.Converter, data []byte) (string, error) {
+	if _, ok := converter.(converters.BytesConverter); !ok && m.Shared.InMemory {
+		return "", fmt.Errorf("%w: %s converts files only", converters.ErrDiskWrite, converterName(converter))
+	}
+	return converters.LoadBytes(ctx, converter, data)
+}
+
+// findBytes returns the converter of a document held in memory and the MIME
+// type it was chosen for, like find does for files, from the extension of
+// its name, when it has one, then from its content.
+func (m *Marky) findBytes(name string, data []byte) (converters.Converter, string, error) {
+	extension := strings.ToLower(filepath.Ext(name))
+	if converter, ok := m.Extensions[extension]; ok {
+		return converter, extensionType(extension, converter), nil
+	}
+	if extension == "" {
+		// Without an extension, the content alone is inspected
+		name = ""
+	}
+
+	converter, mimeType := m.detectHeader(name, data)
+	if converter == nil && extension != "" && converters.IsEncryptedData(data) {
+		for _, converter := range m.Converters {
+			if slices.Contains(converter.AcceptedExtensions(), extension) {
+				return converter, extensionType(extension, converter), nil
+			}
+		}
+	}
+	if converter == nil {
+		return nil, "", fmt.Errorf("%w for MIME type: %s", ErrNoConverter, mimeType)
+	}
+	return converter, mimeType, nil
+}
+
+// find returns the converter of a document and the MIME type it was chosen
+// for.
+func (m *Marky) find(path string) (converters.Converter, string, error) {
+	if converters.IsURL(path) {
+		if converter := m.converterFor("text/html"); converter != nil {
+			return converter, "text/html", nil
+		}
+		return nil, "", fmt.Errorf("%w for URL: %s", ErrNoConverter, path)
+	}
+
+	if m.MaxFileSize > 0 {
+		if info, err := os.Stat(path); err == nil && info.Size() > m.MaxFileSize {
+			return nil, "", fmt.Errorf("%w: %s is %d bytes, more than %d", ErrFileTooLarge, path, info.Size(), m.MaxFileSize)
+		}
+	}
+
+	if m.DetectionHook != nil {
+		mimeType, err := m.DetectionHook(path)
+		if err != nil {
+			return nil, "", err
+		}
+		if mimeType != "" {
+			if converter := m.converterFor(mimeType); converter != nil {
+				return converter, mimeType, nil
+			}
+			return nil, "", fmt.Errorf("%w for MIME type: %s", ErrNoConverter, mimeType)
+		}
+	}
+
+	extension := strings.ToLower(filepath.Ext(path))
+	if converter, ok := m.Extensions[extension]; ok {
+		return converter, extensionType(extension, converter), nil
+	}
+
+	result, err := m.detect(path)
+	if err != nil {
+		return nil, "", err
+	}
+	if result.converter == nil && converters.IsEncryptedPackage(path) {
+		// The content of encrypted DOCX, XLSX and PPTX files is hidden, so
+		// they go to the converter of their extension.
+		for _, converter := range m.Converters {
+			if slices.Contains(converter.AcceptedExtensions(), extension) {
+				return converter, extensionType(extension, converter), nil
+			}
+		}
+		return nil, "", fmt.Errorf("%w, and its format is unknown: %s", converters.ErrEncryptedDocument, path)
+	}
+	if result.converter == nil {
+		return nil, "", fmt.Errorf("%w for MIME type: %s", ErrNoConverter, result.mimeType)
+	}
+	return result.converter, result.mimeType, nil
+}
+
+// extensionType returns the MIME type of an extension, or else the first
+// type the converter routed the extension accepts.
+func extensionType(extension string, converter converters.Converter) string {
+	mimeType := mimetypes.TypeByExtension(extension)
+	if accepted := converter.AcceptedMimeTypes(); mimeType == "" && len(accepted) > 0 {
+		mimeType = accepted[0]
+	}
+	return mimeType
+}
+
+// detect finds the converter of a file from its content. The result is
+// reused for repeated conversions of the same path, such as in batch runs,
+// until the file changes or the path drops out of the cache.
+func (m *Marky) detect(path string) (detection, error) {
 	// Detect MIME type from file content - this is mandatory
-	mtype, err := mimetype.DetectFile(path)
+	info, err := os.Stat(path)
+	if err != nil {
+		return detection{}, fmt.Errorf("failed to detect MIME type: %w", err)
+	}
+	m.mu.Lock()
+	var cached detection
+	var ok bool
+	if m.detections != nil {
+		cached, ok = m.detections.get(path)
+	}
+	m.mu.Unlock()
+	if ok && cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+		return cached, nil
+	}
+
+	header, err := mimetypes.ReadHeader(path)
 	if err != nil {
-		return "", fmt.Errorf("failed to detect MIME type: %w", err)
+		return detection{}, fmt.Errorf("failed to detect MIME type: %w", err)
+	}
+	result := detection{modTime: info.ModTime(), size: info.Size()}
+	result.converter, result.mimeType = m.detectHeader(path, header)
+
+	m.mu.Lock()
+	if m.detections == nil {
+		m.detections = &detectionCache{}
+	}
+	m.detections.put(path, result)
+	m.mu.Unlock()
+	return result, nil
+}
+
+// detectHeader finds the converter of a file from its leading bytes, trying
+// the registered signatures before the built-in detection. It returns the
+// detected MIME type along with the converter, which is nil when none accepts
+// it.
+func (m *Marky) detectHeader(path string, header []byte) (converters.Converter, string) {
+	for _, signature := range m.Signatures {
+		if !signature.matches(header) {
+			continue
+		}
+		if converter := m.converterFor(signature.MimeType); converter != nil {
+			return converter, signature.MimeType
+		}
 	}
 
 	// Find a converter that can handle this MIME type
+	mtype := mimetypes.Detect(path, header)
 	for _, converter := range m.Converters {
-		if accepts(mtype, converter.AcceptedExtensions(), converter.AcceptedMimeTypes()) {
-			return converter.Load(path)
+		if mimetypes.Accepts(mtype, converter.AcceptedExtensions(), converter.AcceptedMimeTypes()) {
+			return converter, mtype.String()
 		}
 	}
-
-	return "", fmt.Errorf("no converter found for MIME type: %s", mtype.String())
+	return nil, mtype.String()
 }
 
-func accepts(mtype *mimetype.MIME, extensions, mtypes []string) bool {
-	if slices.Contains(extensions, mtype.Extension()) {
-		return true
+// converterFor returns the first converter accepting a MIME type, or nil.
+func (m *Marky) converterFor(mimeType string) converters.Converter {
+	for _, converter := range m.Converters {
+		if mimetypes.AcceptsType(mimeType, converter.AcceptedMimeTypes()) {
+			return converter
+		}
 	}
+	return nil
+}
 
-	return slices.ContainsFunc(mtypes, mtype.Is)
+// resetDetections drops the cached detections, which may no longer hold
+// after converters or signatures are registered.
+func (m *Marky) resetDetections() {
+	m.mu.Lock()
+	m.detections = nil
+	m.mu.Unlock()
 }
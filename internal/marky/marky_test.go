@@ -0,0 +1,784 @@
+package marky
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flaviodelgrosso/marky/internal/converters"
+	"github.com/flaviodelgrosso/marky/internal/utils"
+	"github.com/xuri/excelize/v2"
+)
+
+// fakeConverter returns its name as the markdown of any file.
+type fakeConverter struct {
+	converters.BaseConverter
+	name string
+}
+
+func (c *fakeConverter) Load(string) (string, error) {
+	return c.name, nil
+}
+
+func newFakeConverter(name string, extensions, mimeTypes []string) *fakeConverter {
+	return &fakeConverter{BaseConverter: converters.NewBaseConverter(extensions, mimeTypes), name: name}
+}
+
+func writeTestFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	return path
+}
+
+func TestMarky_Convert_Signature(t *testing.T) {
+	m := &Marky{}
+	m.RegisterConverter(newFakeConverter("pdf", []string{".pdf"}, []string{"application/pdf"}))
+	m.RegisterConverter(newFakeConverter("acme", nil, []string{"application/x-acme"}))
+	m.RegisterSignature(Signature{MimeType: "application/x-acme", Offset: 4, Magic: []byte("ACME")})
+
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"signature", "\x00\x01\x02\x03ACME data", "acme"},
+		{"built-in detection", "%PDF-1.4\n", "pdf"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := m.Convert(writeTestFile(t, "file.bin", tt.content))
+			if err != nil {
+				t.Fatalf("Convert() returned unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Convert() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+
+	if _, err := m.Convert(writeTestFile(t, "file.bin", "ACME at the wrong offset")); err == nil {
+		t.Error("Convert() should return error for an unknown format")
+	}
+}
+
+func TestMarky_Convert_Extension(t *testing.T) {
+	m := &Marky{}
+	m.RegisterConverter(newFakeConverter("pdf", []string{".pdf"}, []string{"application/pdf"}))
+	m.RegisterExtension("ACME", newFakeConverter("acme", nil, nil))
+
+	result, err := m.Convert(writeTestFile(t, "report.acme", "%PDF-1.4\n"))
+	if err != nil {
+		t.Fatalf("Convert() returned unexpected error: %v", err)
+	}
+	if result != "acme" {
+		t.Errorf("Convert() = %q, want the converter of the extension", result)
+	}
+}
+
+func TestMarky_Convert_DetectionHook(t *testing.T) {
+	errVetoed := errors.New("vetoed")
+	m := &Marky{}
+	m.RegisterConverter(newFakeConverter("pdf", []string{".pdf"}, []string{"application/pdf"}))
+	m.RegisterConverter(newFakeConverter("acme", nil, []string{"application/x-acme"}))
+	m.SetDetectionHook(func(path string) (string, error) {
+		switch filepath.Base(path) {
+		case "known.bin":
+			return "application/x-acme", nil
+		case "unknown.bin":
+			return "application/x-unknown", nil
+		case "vetoed.pdf":
+			return "", errVetoed
+		}
+		return "", nil
+	})
+
+	tests := []struct {
+		name     string
+		file     string
+		expected string
+		err      error
+	}{
+		{"known MIME type", "known.bin", "acme", nil},
+		{"detection", "detected.bin", "pdf", nil},
+		{"vetoed", "vetoed.pdf", "", errVetoed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := m.Convert(writeTestFile(t, tt.file, "%PDF-1.4\n"))
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Convert() error = %v, want %v", err, tt.err)
+			}
+			if result != tt.expected {
+				t.Errorf("Convert() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+
+	if _, err := m.Convert(writeTestFile(t, "unknown.bin", "%PDF-1.4\n")); err == nil {
+		t.Error("Convert() should return error for a MIME type no converter accepts")
+	}
+}
+
+func TestMarky_Convert_CachesDetection(t *testing.T) {
+	m := &Marky{}
+	m.RegisterConverter(newFakeConverter("pdf", []string{".pdf"}, []string{"application/pdf"}))
+	path := writeTestFile(t, "file.bin", "%PDF-1.4\n")
+
+	if _, err := m.Convert(path); err != nil {
+		t.Fatalf("Convert() returned unexpected error: %v", err)
+	}
+	cached, ok := m.detections.get(path)
+	if !ok {
+		t.Fatal("Convert() should cache the detection of the file")
+	}
+
+	// A cached detection is reused while the file is unchanged
+	m.detections.put(path, detection{
+		modTime:   cached.modTime,
+		size:      cached.size,
+		converter: newFakeConverter("cached", nil, nil),
+	})
+	if result, _ := m.Convert(path); result != "cached" {
+		t.Errorf("Convert() = %q, want the cached converter", result)
+	}
+
+	// Changed files are detected again
+	if err := os.WriteFile(path, []byte("ACME data of another size"), 0o644); err != nil {
+		t.Fatalf("Failed to update test file: %v", err)
+	}
+	if _, err := m.Convert(path); err == nil {
+		t.Error("Convert() should detect a changed file again")
+	}
+
+	// Registering converters drops the cache
+	m.RegisterConverter(newFakeConverter("acme", nil, []string{"application/x-acme"}))
+	if m.detections != nil {
+		t.Error("RegisterConverter() should drop the cached detections")
+	}
+}
+
+func TestDetectionCache_Bounded(t *testing.T) {
+	var c detectionCache
+	for i := range maxDetections + 10 {
+		c.put(fmt.Sprintf("upload-%d", i), detection{size: int64(i)})
+		if i == maxDetections-1 {
+			// Recently used paths are kept
+			c.get("upload-0")
+		}
+	}
+	if c.len() != maxDetections {
+		t.Errorf("cache holds %d detections, want %d", c.len(), maxDetections)
+	}
+	if _, ok := c.get("upload-0"); !ok {
+		t.Error("a recently used detection was dropped")
+	}
+	if _, ok := c.get("upload-1"); ok {
+		t.Error("the least recently used detection was kept")
+	}
+	if d, ok := c.get(fmt.Sprintf("upload-%d", maxDetections+9)); !ok || d.size != maxDetections+9 {
+		t.Error("the latest detection was not cached")
+	}
+}
+
+func TestSignature_Matches(t *testing.T) {
+	header := []byte("abcdef")
+	tests := []struct {
+		signature Signature
+		expected  bool
+	}{
+		{Signature{Magic: []byte("abc")}, true},
+		{Signature{Offset: 3, Magic: []byte("def")}, true},
+		{Signature{Offset: 4, Magic: []byte("def")}, false},
+		{Signature{Offset: 10, Magic: []byte("x")}, false},
+		{Signature{Offset: -1, Magic: []byte("a")}, false},
+		{Signature{}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.signature.matches(header); got != tt.expected {
+			t.Errorf("%+v.matches() = %v, want %v", tt.signature, got, tt.expected)
+		}
+	}
+}
+
+func TestMarky_Convert_HeadingLevels(t *testing.T) {
+	m := &Marky{}
+	m.RegisterConverter(newFakeConverter("# Title\n\n### Sub\n", []string{".txt"}, nil))
+	m.SetHeadingLevels(1, 3)
+
+	got, err := m.Convert(writeTestFile(t, "doc.txt", "text"))
+	if err != nil {
+		t.Fatalf("Convert() returned unexpected error: %v", err)
+	}
+	if want := "## Title\n\n### Sub\n"; got != want {
+		t.Errorf("Convert() = %q, want %q", got, want)
+	}
+}
+
+func TestMarky_Convert_OutputFormat(t *testing.T) {
+	m := &Marky{}
+	m.RegisterConverter(newFakeConverter("# Title\n\nSome **bold** text.\n", []string{".txt"}, nil))
+	path := writeTestFile(t, "doc.txt", "text")
+
+	m.SetOutputFormat(FormatText)
+	if got, err := m.Convert(path); err != nil || got != "Title\n\nSome bold text.\n" {
+		t.Errorf("Convert() as text = %q, %v", got, err)
+	}
+
+	m.SetOutputFormat(FormatJSON)
+	got, err := m.Convert(path)
+	if err != nil || !strings.Contains(got, `"type": "heading"`) || !strings.Contains(got, `"text": "Some bold text."`) {
+		t.Errorf("Convert() as JSON = %q, %v", got, err)
+	}
+}
+
+func TestMarky_Configure(t *testing.T) {
+	m := &Marky{}
+	m.RegisterConverter(converters.NewCsvConverter())
+	m.RegisterConverter(converters.NewPptxConverter())
+	path := writeTestFile(t, "data.csv", "name,age\nAda,36\n")
+
+	if err := m.Configure(converters.CSVOptions{Header: converters.HeaderNone}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+	got, err := m.Convert(path)
+	if err != nil || !strings.Contains(got, "Col1") || !strings.Contains(got, "| name") {
+		t.Errorf("Convert() with HeaderNone = %q, %v", got, err)
+	}
+
+	pptx := m.Converters[1].(*converters.PptxConverter)
+	m.Configure(converters.PPTXOptions{ConvertOptions: converters.ConvertOptions{Slides: "1-2"}})
+	if pptx.Options.Slides != "1-2" {
+		t.Errorf("Configure() Slides = %q, want 1-2", pptx.Options.Slides)
+	}
+
+	m.Configure(converters.ConvertOptions{Images: converters.ImagesDrop})
+	if pptx.Options.Images != converters.ImagesDrop || pptx.Options.Slides != "1-2" {
+		t.Errorf("Configure() shared options = %+v, want ImagesDrop merged into the PPTX options", pptx.Options)
+	}
+
+	if err := m.Configure(converters.PDFOptions{}); err == nil {
+		t.Error("Configure() without a PDF converter: expected an error")
+	}
+}
+
+func TestMarky_Convert_MaxFileSize(t *testing.T) {
+	m := &Marky{MaxFileSize: 4}
+	m.RegisterConverter(newFakeConverter("text", []string{".txt"}, nil))
+
+	if _, err := m.Convert(writeTestFile(t, "small.txt", "tiny")); err != nil {
+		t.Errorf("Convert() of a file within the limit returned error: %v", err)
+	}
+	if _, err := m.Convert(writeTestFile(t, "large.txt", "too large")); !errors.Is(err, ErrFileTooLarge) {
+		t.Errorf("Convert() of a file over the limit error = %v, want ErrFileTooLarge", err)
+	}
+}
+
+// reportingConverter reports one part and a warning for any file.
+type reportingConverter struct {
+	*fakeConverter
+}
+
+func (c reportingConverter) LoadReport(_ context.Context, path string) (string, *converters.Report, error) {
+	markdown, _ := c.Load(path)
+	return markdown, &converters.Report{Parts: 1, Warnings: []string{"page 1 has no text"}}, nil
+}
+
+func TestMarky_ConvertWithResult(t *testing.T) {
+	m := &Marky{}
+	m.RegisterExtension(".abc", newFakeConverter("---\ntitle: Report\n---\n\n# Summary\n", nil, []string{"application/x-abc"}))
+	m.RegisterExtension(".rep", reportingConverter{newFakeConverter("# Scan\n", nil, nil)})
+	m.SetHeadingLevels(1, 0)
+
+	result, err := m.ConvertWithResult(writeTestFile(t, "doc.abc", "text"))
+	if err != nil {
+		t.Fatalf("ConvertWithResult() returned unexpected error: %v", err)
+	}
+	want := ConversionResult{
+		Markdown:  "---\ntitle: Report\n---\n\n## Summary\n",
+		MimeType:  "application/x-abc",
+		Converter: "fakeConverter",
+		Title:     "Report",
+
+		Words:          1,
+		Characters:     7,
+		ReadingMinutes: 1,
+	}
+	if !reflect.DeepEqual(*result, want) {
+		t.Errorf("ConvertWithResult() = %+v, want %+v", *result, want)
+	}
+
+	result, err = m.ConvertWithResult(writeTestFile(t, "doc.rep", "text"))
+	if err != nil {
+		t.Fatalf("ConvertWithResult() returned unexpected error: %v", err)
+	}
+	if result.Title != "Scan" || result.Converter != "reportingConverter" || result.Parts != 1 || len(result.Warnings) != 1 {
+		t.Errorf("ConvertWithResult() of a reporting converter = %+v", *result)
+	}
+}
+
+// blockingConverter waits for release before converting any file.
+type blockingConverter struct {
+	*fakeConverter
+	release chan struct{}
+}
+
+func (c blockingConverter) Load(path string) (string, error) {
+	<-c.release
+	return c.fakeConverter.Load(path)
+}
+
+func TestMarky_ConvertContext(t *testing.T) {
+	converter := blockingConverter{newFakeConverter("text", []string{".txt"}, nil), make(chan struct{})}
+	defer close(converter.release)
+	m := &Marky{}
+	m.RegisterConverter(converter)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := m.ConvertContext(ctx, writeTestFile(t, "doc.txt", "text")); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("ConvertContext() error = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestTextStats(t *testing.T) {
+	words, characters := textStats("---\ntitle: Notes\n---\n\n# Café\n\nSome **bold** text.\n")
+	if words != 4 || characters != 21 {
+		t.Errorf("textStats() = %d words, %d characters, want 4 words, 21 characters", words, characters)
+	}
+}
+
+func TestMarky_Registry(t *testing.T) {
+	m := &Marky{}
+	first := newFakeConverter("first", []string{".txt"}, nil)
+	second := newFakeConverter("second", []string{".txt"}, nil)
+	if err := m.Register("first", first, 0); err != nil {
+		t.Fatalf("Register() returned unexpected error: %v", err)
+	}
+	if err := m.Register("second", second, 0); err != nil {
+		t.Fatalf("Register() returned unexpected error: %v", err)
+	}
+	if err := m.Register("first", second, 0); err == nil {
+		t.Error("Register() of a registered name should return an error")
+	}
+	path := writeTestFile(t, "doc.txt", "text")
+
+	if got, _ := m.Convert(path); got != "first" {
+		t.Errorf("Convert() = %q, want the first registered converter", got)
+	}
+	if err := m.SetPriority("second", 1); err != nil {
+		t.Fatalf("SetPriority() returned unexpected error: %v", err)
+	}
+	if got, _ := m.Convert(path); got != "second" {
+		t.Errorf("Convert() after SetPriority() = %q, want second", got)
+	}
+
+	replacement := newFakeConverter("replacement", []string{".txt"}, nil)
+	if err := m.Replace("second", replacement); err != nil {
+		t.Fatalf("Replace() returned unexpected error: %v", err)
+	}
+	if got, ok := m.Get("second"); !ok || got != replacement {
+		t.Errorf("Get() = %v, %v, want the replacement", got, ok)
+	}
+	if got, _ := m.Convert(path); got != "replacement" {
+		t.Errorf("Convert() after Replace() = %q, want replacement", got)
+	}
+
+	if err := m.Remove("second"); err != nil {
+		t.Fatalf("Remove() returned unexpected error: %v", err)
+	}
+	if got, _ := m.Convert(path); got != "first" {
+		t.Errorf("Convert() after Remove() = %q, want first", got)
+	}
+	if err := m.Remove("second"); !errors.Is(err, ErrUnknownConverter) {
+		t.Errorf("Remove() of a removed name error = %v, want ErrUnknownConverter", err)
+	}
+}
+
+func TestMarky_Convert_Container(t *testing.T) {
+	m := &Marky{}
+	m.Register("csv", converters.NewCsvConverter(), 0)
+	m.Register("zip", converters.NewZipConverter(), 0)
+
+	var inner bytes.Buffer
+	zw := zip.NewWriter(&inner)
+	w, _ := zw.Create("data.csv")
+	w.Write([]byte("name,age\nAda,36\n"))
+	zw.Close()
+	var outer bytes.Buffer
+	zw = zip.NewWriter(&outer)
+	w, _ = zw.Create("inner.zip")
+	w.Write(inner.Bytes())
+	zw.Close()
+	path := writeTestFile(t, "outer.zip", outer.String())
+
+	got, err := m.Convert(path)
+	if err != nil {
+		t.Fatalf("Convert() returned unexpected error: %v", err)
+	}
+	want := "# outer\n\n## Files\n\n### inner.zip\n\n#### inner\n\n##### Files\n\n###### data.csv\n\n| name | age |\n| --- | --- |\n| Ada | 36 |\n"
+	if got != want {
+		t.Errorf("Convert() = %q, want %q", got, want)
+	}
+}
+
+func TestMarky_Convert_JSON(t *testing.T) {
+	m := &Marky{}
+	m.Register("ipynb", converters.NewIpynbConverter(), 0)
+	m.Register("json", converters.NewJSONConverter(), 0)
+	dir := t.TempDir()
+
+	for _, tt := range []struct {
+		name, content, want string
+	}{
+		{"records.json", `[{"id": 1}, {"id": 2}]`, "| id |\n| --- |\n| 1 |\n| 2 |\n"},
+		{"records.jsonl", "{\"id\": 1}\n{\"id\": 2}\n", "| id |\n| --- |\n| 1 |\n| 2 |\n"},
+		{"notebook.json", `{"nbformat": 4, "nbformat_minor": 5, "metadata": {}, "cells": [{"cell_type": "markdown", "metadata": {}, "source": ["# Notes"]}]}`, "# Notes"},
+	} {
+		path := filepath.Join(dir, tt.name)
+		if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+		got, err := m.Convert(path)
+		if err != nil {
+			t.Errorf("Convert(%s) returned unexpected error: %v", tt.name, err)
+			continue
+		}
+		if !strings.HasPrefix(got, tt.want) {
+			t.Errorf("Convert(%s) = %q, want it to start with %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMarky_ConvertDir(t *testing.T) {
+	m := &Marky{}
+	m.RegisterConverter(newFakeConverter("text", []string{".txt"}, []string{"text/plain"}))
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"a.txt":          "text",
+		"sub/b.txt":      "text",
+		"sub/c.bin":      "\x00\x01\x02\x03",
+		".hidden/d.txt":  "text",
+		"sub/.e.txt":     "text",
+		"sub/deep/f.TXT": "text",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("Failed to create test directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	results, err := m.ConvertDir(context.Background(), dir, BatchOptions{Workers: 2})
+	if err != nil {
+		t.Fatalf("ConvertDir() returned unexpected error: %v", err)
+	}
+	var paths []string
+	for _, r := range results {
+		rel, _ := filepath.Rel(dir, r.Path)
+		paths = append(paths, filepath.ToSlash(rel))
+		if r.Err != nil || r.Result == nil || r.Result.Markdown != "text" {
+			t.Errorf("ConvertDir() result of %s = %+v, %v, want the converted text", rel, r.Result, r.Err)
+		}
+	}
+	if want := []string{"a.txt", "sub/b.txt", "sub/deep/f.TXT"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("ConvertDir() converted %v, want %v", paths, want)
+	}
+	if len(results) == 3 {
+		if results[0].Hash == "" || results[0].Simhash == "" || results[0].DuplicateOf != "" {
+			t.Errorf("ConvertDir() fingerprinted the first file as %+v, want a hash and no duplicate", results[0])
+		}
+		for _, r := range results[1:] {
+			if r.Hash != results[0].Hash || r.DuplicateOf != results[0].Path {
+				t.Errorf("ConvertDir() flagged %s as a duplicate of %q, want %q", r.Path, r.DuplicateOf, results[0].Path)
+			}
+		}
+	}
+
+	results, err = m.ConvertDir(context.Background(), dir, BatchOptions{Extensions: []string{"txt"}, Hidden: true})
+	if err != nil {
+		t.Fatalf("ConvertDir() returned unexpected error: %v", err)
+	}
+	if len(results) != 5 {
+		t.Errorf("ConvertDir() with hidden files converted %d files, want 5", len(results))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := m.ConvertDir(ctx, dir, BatchOptions{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("ConvertDir() error = %v, want context.Canceled", err)
+	}
+	if _, err := m.ConvertDir(context.Background(), filepath.Join(dir, "missing"), BatchOptions{}); err == nil {
+		t.Error("ConvertDir() of a missing directory should return an error")
+	}
+}
+
+func TestMarky_Convert_EncryptedPackage(t *testing.T) {
+	encrypted, err := excelize.Encrypt([]byte("PK\x03\x04"), &excelize.Options{Password: "secret"})
+	if err != nil {
+		t.Fatalf("Failed to encrypt test file: %v", err)
+	}
+	m := &Marky{}
+	m.RegisterConverter(newFakeConverter("docx", []string{".docx"}, []string{"application/vnd.openxmlformats-officedocument.wordprocessingml.document"}))
+
+	if got, err := m.Convert(writeTestFile(t, "secret.docx", string(encrypted))); err != nil || got != "docx" {
+		t.Errorf("Convert() = %q, %v, want the converter of the extension", got, err)
+	}
+	if _, err := m.Convert(writeTestFile(t, "secret.bin", string(encrypted))); !errors.Is(err, converters.ErrEncryptedDocument) {
+		t.Errorf("Convert() error = %v, want ErrEncryptedDocument", err)
+	}
+}
+
+func TestMarky_ConvertBytes(t *testing.T) {
+	m := &Marky{}
+	m.Register("csv", converters.NewCsvConverter(), 0)
+	m.Register("docx", converters.NewDocConverter(), 0)
+	data := []byte("name,age\nAda,36\n")
+	want := "| name | age |\n| --- | --- |\n| Ada | 36 |\n"
+
+	if got, err := m.ConvertBytes(data, "text/csv; charset=utf-8"); err != nil || got != want {
+		t.Errorf("ConvertBytes() = %q, %v, want %q", got, err, want)
+	}
+	if _, err := m.ConvertBytes(data, "application/pdf"); !errors.Is(err, ErrNoConverter) {
+		t.Errorf("ConvertBytes() error = %v, want ErrNoConverter", err)
+	}
+
+	docx, err := os.ReadFile(filepath.Join("..", "..", "test_files", "test.docx"))
+	if err != nil {
+		t.Fatalf("Failed to read test file: %v", err)
+	}
+	got, err := m.ConvertBytes(docx, "")
+	if err != nil {
+		t.Fatalf("ConvertBytes() returned unexpected error: %v", err)
+	}
+	if !strings.Contains(got, "# Abstract") {
+		t.Errorf("ConvertBytes() of a detected DOCX document = %q", got)
+	}
+
+	m.MaxFileSize = 4
+	if _, err := m.ConvertBytes(data, "text/csv"); !errors.Is(err, ErrFileTooLarge) {
+		t.Errorf("ConvertBytes() error = %v, want ErrFileTooLarge", err)
+	}
+}
+
+func TestMarky_Convert_Object(t *testing.T) {
+	docx, err := os.ReadFile(filepath.Join("..", "..", "test_files", "test.docx"))
+	if err != nil {
+		t.Fatalf("Failed to read test file: %v", err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, ".csv") {
+			w.Write([]byte("name,age\nAda,36\n"))
+			return
+		}
+		w.Write(docx)
+	}))
+	defer server.Close()
+	objects := converters.PublicObjects{Client: server.Client(), Endpoint: server.URL}
+
+	// Objects are converted in memory, without temporary files
+	m := &Marky{FrontMatter: true, Objects: map[string]converters.ObjectOpener{"s3": objects}}
+	m.Register("csv", converters.NewCsvConverter(), 0)
+	m.Register("docx", converters.NewDocConverter(), 0)
+	m.Share(converters.ConvertOptions{InMemory: true})
+	got, err := m.Convert("s3://bucket/people.csv")
+	if err != nil || !strings.Contains(got, "source: s3://bucket/people.csv") || !strings.Contains(got, "| Ada | 36 |") {
+		t.Errorf("Convert() = %q, %v", got, err)
+	}
+	// Without an extension, the object is detected from its content
+	if got, err := m.Convert("s3://bucket/report"); err != nil || !strings.Contains(got, "# Abstract") {
+		t.Errorf("Convert() of an object without extension = %q, %v", got, err)
+	}
+
+	m.MaxFileSize = 4
+	if _, err := m.Convert("s3://bucket/people.csv"); !errors.Is(err, ErrFileTooLarge) {
+		t.Errorf("Convert() error = %v, want ErrFileTooLarge", err)
+	}
+	m.MaxFileSize = 0
+
+	// The opener of a scheme reads its objects
+	var opened string
+	m.Objects["gs"] = converters.ObjectOpenerFunc(func(_ context.Context, uri string) (io.ReadCloser, error) {
+		opened = uri
+		return io.NopCloser(strings.NewReader("name,age\nGrace,45\n")), nil
+	})
+	if got, err := m.Convert("gs://bucket/people.csv"); err != nil || opened != "gs://bucket/people.csv" || !strings.Contains(got, "| Grace | 45 |") {
+		t.Errorf("Convert() with an opener = %q, %v", got, err)
+	}
+}
+
+func TestMarky_ConvertTo(t *testing.T) {
+	m := &Marky{}
+	m.Register("docx", converters.NewDocConverter(), 0)
+	m.Register("pptx", converters.NewPptxConverter(), 0)
+
+	for _, file := range []string{"test.docx", "test.pptx"} {
+		path := filepath.Join("..", "..", "test_files", file)
+		for _, format := range []OutputFormat{FormatMarkdown, FormatText} {
+			m.OutputFormat = format
+			want, err := m.Convert(path)
+			if err != nil {
+				t.Fatalf("Convert() returned unexpected error: %v", err)
+			}
+			var got strings.Builder
+			if err := m.ConvertTo(&got, path); err != nil {
+				t.Fatalf("ConvertTo() returned unexpected error: %v", err)
+			}
+			if got.String() != want {
+				t.Errorf("ConvertTo() of %s in format %d differs from Convert()", file, format)
+			}
+		}
+	}
+
+	if err := m.ConvertTo(io.Discard, filepath.Join("..", "..", "test_files", "test.csv")); !errors.Is(err, ErrNoConverter) {
+		t.Errorf("ConvertTo() error = %v, want ErrNoConverter", err)
+	}
+}
+
+// loggingConverter records the logger it is given.
+type loggingConverter struct {
+	*fakeConverter
+	logger *slog.Logger
+}
+
+func (c *loggingConverter) SetLogger(logger *slog.Logger) {
+	c.logger = logger
+}
+
+func TestMarky_SetLogger(t *testing.T) {
+	registered := &loggingConverter{fakeConverter: newFakeConverter("a", []string{".a"}, nil)}
+	m := &Marky{}
+	m.RegisterConverter(registered)
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	m.SetLogger(logger)
+	if registered.logger != logger {
+		t.Error("SetLogger() did not pass the logger to a registered converter")
+	}
+
+	later := &loggingConverter{fakeConverter: newFakeConverter("b", []string{".b"}, nil)}
+	m.RegisterConverter(later)
+	routed := &loggingConverter{fakeConverter: newFakeConverter("c", nil, nil)}
+	m.RegisterExtension(".c", routed)
+	if later.logger != logger || routed.logger != logger {
+		t.Error("converters registered after SetLogger() did not get the logger")
+	}
+
+	m.SetLogger(nil)
+	if registered.logger != nil || routed.logger != nil {
+		t.Error("SetLogger(nil) did not remove the logger of the converters")
+	}
+}
+
+func TestMarky_Share(t *testing.T) {
+	m := &Marky{}
+	m.Register("pdf", converters.NewPdfConverter(), 0)
+	m.Share(converters.ConvertOptions{InMemory: true})
+	m.Share(converters.ConvertOptions{MmapThreshold: -1})
+	// Format options replace the ConvertOptions of the converter
+	if err := m.Configure(converters.PDFOptions{ConvertOptions: converters.ConvertOptions{Pages: "1"}}); err != nil {
+		t.Fatalf("Configure() returned unexpected error: %v", err)
+	}
+	m.Register("excel", converters.NewExcelConverter(), 0)
+	m.Register("csv", converters.NewCsvConverter(), 0)
+	m.Share(converters.ConvertOptions{Tables: utils.TableOptions{Style: utils.TablePretty}})
+	if err := m.Configure(converters.CSVOptions{MaxRows: 10}); err != nil {
+		t.Fatalf("Configure() returned unexpected error: %v", err)
+	}
+
+	pdf, _ := m.Get("pdf")
+	excel, _ := m.Get("excel")
+	csv, _ := m.Get("csv")
+	if options := pdf.(*converters.PdfConverter).Options; !options.InMemory || options.MmapThreshold != -1 || options.Pages != "1" {
+		t.Errorf("PDF options = %+v, want the shared settings kept", options)
+	}
+	if e := excel.(*converters.ExcelConverter); !e.InMemory || e.MmapThreshold != -1 {
+		t.Error("a converter registered after Share() did not get the shared settings")
+	}
+	if c := csv.(*converters.CsvConverter); c.Tables.Style != utils.TablePretty || c.MaxRows != 10 {
+		t.Errorf("CSV converter = %+v, want the shared table style kept", c)
+	}
+
+	// Converters of files only would write the document to a temporary file
+	m.Register("fake", newFakeConverter("fake", []string{".fake"}, []string{"application/x-fake"}), 0)
+	if _, err := m.ConvertBytes([]byte("fake"), "application/x-fake"); !errors.Is(err, converters.ErrDiskWrite) {
+		t.Errorf("ConvertBytes() error = %v, want ErrDiskWrite", err)
+	}
+}
+
+func TestMarky_Share_Set(t *testing.T) {
+	m := &Marky{}
+	m.Register("pdf", converters.NewPdfConverter(), 0)
+	m.Register("excel", converters.NewExcelConverter(), 0)
+	m.Share(converters.ConvertOptions{InMemory: true, Provenance: true, Images: converters.ImagesDrop})
+	// Zero fields are only applied when listed in Set
+	m.Share(converters.ConvertOptions{Provenance: false})
+	m.Share(converters.ConvertOptions{Set: converters.FieldInMemory | converters.FieldImages})
+
+	pdf, _ := m.Get("pdf")
+	excel, _ := m.Get("excel")
+	options := pdf.(*converters.PdfConverter).Options
+	if options.InMemory || !options.Provenance || options.Images != converters.ImagesLink {
+		t.Errorf("PDF options = %+v, want InMemory off, Provenance kept and ImagesLink", options)
+	}
+	if excel.(*converters.ExcelConverter).InMemory {
+		t.Error("Share() with FieldInMemory did not turn InMemory off for the Excel converter")
+	}
+}
+
+// progressConverter records the progress function it is given.
+type progressConverter struct {
+	*fakeConverter
+	progress converters.ProgressFunc
+}
+
+func (c *progressConverter) SetProgress(progress converters.ProgressFunc) {
+	c.progress = progress
+}
+
+func TestMarky_SetProgress(t *testing.T) {
+	registered := &progressConverter{fakeConverter: newFakeConverter("a", []string{".a"}, nil)}
+	m := &Marky{}
+	m.RegisterConverter(registered)
+
+	var events []converters.ProgressEvent
+	m.SetProgress(func(event converters.ProgressEvent) {
+		events = append(events, event)
+	})
+	later := &progressConverter{fakeConverter: newFakeConverter("b", []string{".b"}, nil)}
+	m.RegisterConverter(later)
+	routed := &progressConverter{fakeConverter: newFakeConverter("c", nil, nil)}
+	m.RegisterExtension(".c", routed)
+
+	for _, c := range []*progressConverter{registered, later, routed} {
+		if c.progress == nil {
+			t.Fatalf("converter %s did not get the progress function", c.name)
+		}
+		c.progress(converters.ProgressEvent{Unit: "page", Done: 1, Total: 1})
+	}
+	if len(events) != 3 {
+		t.Errorf("got %d progress events, want 3", len(events))
+	}
+
+	m.SetProgress(nil)
+	if registered.progress != nil || routed.progress != nil {
+		t.Error("SetProgress(nil) did not remove the progress function of the converters")
+	}
+}
@@ -0,0 +1,86 @@
+package marky
+
+import (
+	"regexp"
+	"strings"
+)
+
+// PostProcessor transforms the markdown of converted documents, such as to
+// normalize headings, strip HTML comments or collapse blank lines. The
+// post-processors added with AddPostProcessor run in order on the output of
+// every converter.
+type PostProcessor interface {
+	Process(markdown string) (string, error)
+}
+
+// PostProcessorFunc adapts a function to the PostProcessor interface.
+type PostProcessorFunc func(markdown string) (string, error)
+
+// Process calls f.
+func (f PostProcessorFunc) Process(markdown string) (string, error) {
+	return f(markdown)
+}
+
+var (
+	commentLinePattern = regexp.MustCompile(`(?m)^[ \t]*<!--(?s:.*?)-->[ \t]*(?:\n|\z)`)
+	commentPattern     = regexp.MustCompile(`(?s)<!--.*?-->`)
+	blankLinesPattern  = regexp.MustCompile(`\n(?:[ \t]*\n){2,}`)
+)
+
+// StripHTMLComments removes the HTML comments of documents outside code
+// blocks, such as the slide and page markers, along with the lines they
+// stood alone on.
+var StripHTMLComments = PostProcessorFunc(func(markdown string) (string, error) {
+	return outsideFences(markdown, func(text string) string {
+		return commentPattern.ReplaceAllString(commentLinePattern.ReplaceAllString(text, ""), "")
+	}), nil
+})
+
+// CollapseBlankLines replaces runs of blank lines outside code blocks with
+// a single blank line.
+var CollapseBlankLines = PostProcessorFunc(func(markdown string) (string, error) {
+	return outsideFences(markdown, func(text string) string {
+		return blankLinesPattern.ReplaceAllString(text, "\n\n")
+	}), nil
+})
+
+// AddPostProcessor adds a post-processor run on the markdown of converted
+// documents after those added before it.
+func (m *Marky) AddPostProcessor(processor PostProcessor) {
+	m.PostProcessors = append(m.PostProcessors, processor)
+}
+
+// postProcess runs the post-processors on markdown in order.
+func postProcess(markdown string, processors []PostProcessor) (string, error) {
+	for _, p := range processors {
+		var err error
+		if markdown, err = p.Process(markdown); err != nil {
+			return "", err
+		}
+	}
+	return markdown, nil
+}
+
+// outsideFences applies fn to the parts of markdown outside fenced code
+// blocks, keeping the blocks as they are.
+func outsideFences(markdown string, fn func(string) string) string {
+	var out, part strings.Builder
+	inFence := false
+	for _, line := range strings.SplitAfter(markdown, "\n") {
+		fence := strings.HasPrefix(strings.TrimLeft(line, " "), "```")
+		switch {
+		case inFence:
+			out.WriteString(line)
+			inFence = !fence
+		case fence:
+			out.WriteString(fn(part.String()))
+			part.Reset()
+			out.WriteString(line)
+			inFence = true
+		default:
+			part.WriteString(line)
+		}
+	}
+	out.WriteString(fn(part.String()))
+	return out.String()
+}
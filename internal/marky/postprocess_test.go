@@ -0,0 +1,62 @@
+package marky
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestStripHTMLComments(t *testing.T) {
+	input := "<!-- Slide number: 1 -->\n# Title\n\nText <!-- note --> here.\n<!--\nmulti\nline\n-->\n\n```html\n<!-- kept -->\n```\n"
+
+	got, err := StripHTMLComments.Process(input)
+	if err != nil {
+		t.Fatalf("Process() returned unexpected error: %v", err)
+	}
+	if want := "# Title\n\nText  here.\n\n```html\n<!-- kept -->\n```\n"; got != want {
+		t.Errorf("Process() = %q, want %q", got, want)
+	}
+}
+
+func TestCollapseBlankLines(t *testing.T) {
+	input := "# Title\n\n\n\nText\n  \n\t\n\nMore\n\n```\na\n\n\n\nb\n```\n"
+
+	got, err := CollapseBlankLines.Process(input)
+	if err != nil {
+		t.Fatalf("Process() returned unexpected error: %v", err)
+	}
+	if want := "# Title\n\nText\n\nMore\n\n```\na\n\n\n\nb\n```\n"; got != want {
+		t.Errorf("Process() = %q, want %q", got, want)
+	}
+}
+
+func TestMarky_Convert_PostProcessors(t *testing.T) {
+	m := &Marky{}
+	m.RegisterConverter(newFakeConverter("<!-- Page 1 -->\n\n\n\n# Report\n", []string{".txt"}, nil))
+	m.SetHeadingLevels(1, 0)
+	m.AddPostProcessor(StripHTMLComments)
+	m.AddPostProcessor(CollapseBlankLines)
+	m.AddPostProcessor(PostProcessorFunc(func(markdown string) (string, error) {
+		return strings.TrimSpace(markdown) + "\n", nil
+	}))
+
+	got, err := m.Convert(writeTestFile(t, "doc.txt", "text"))
+	if err != nil {
+		t.Fatalf("Convert() returned unexpected error: %v", err)
+	}
+	if want := "## Report\n"; got != want {
+		t.Errorf("Convert() = %q, want %q", got, want)
+	}
+
+	var streamed strings.Builder
+	if err := m.ConvertTo(&streamed, writeTestFile(t, "doc.txt", "text")); err != nil || streamed.String() != got {
+		t.Errorf("ConvertTo() = %q, %v, want %q", streamed.String(), err, got)
+	}
+
+	m.AddPostProcessor(PostProcessorFunc(func(string) (string, error) {
+		return "", errors.New("bad heading")
+	}))
+	if _, err := m.Convert(writeTestFile(t, "doc.txt", "text")); err == nil || !strings.Contains(err.Error(), "bad heading") {
+		t.Errorf("Convert() error = %v, want the post-processor error", err)
+	}
+}
@@ -0,0 +1,136 @@
+package marky
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/flaviodelgrosso/marky/internal/converters"
+)
+
+// ErrUnknownConverter is returned for names no converter is registered
+// under.
+var ErrUnknownConverter = errors.New("unknown converter")
+
+// Registration is a converter registered with a Marky. Converters added by
+// RegisterConverter have no name.
+type Registration struct {
+	Name      string
+	Converter converters.Converter
+
+	// Priority orders the converters for detection: files go to the first
+	// converter accepting them, from the highest priority to the lowest, in
+	// registration order among equal priorities. The default is 0.
+	Priority int
+}
+
+// Register adds a converter under a name, such as "pdf", with a priority.
+// It returns an error when a converter is already registered under the
+// name, which Replace swaps out instead.
+func (m *Marky) Register(name string, converter converters.Converter, priority int) error {
+	if _, ok := m.Get(name); ok {
+		return fmt.Errorf("converter %q is already registered", name)
+	}
+	m.registry = append(m.registry, Registration{Name: name, Converter: converter, Priority: priority})
+	m.sortConverters()
+	return nil
+}
+
+// Get returns the converter registered under a name.
+func (m *Marky) Get(name string) (converters.Converter, bool) {
+	if i := m.lookup(name); i >= 0 {
+		return m.registry[i].Converter, true
+	}
+	return nil, false
+}
+
+// Replace swaps the converter registered under a name for another one,
+// which keeps its priority.
+func (m *Marky) Replace(name string, converter converters.Converter) error {
+	i := m.lookup(name)
+	if i < 0 {
+		return fmt.Errorf("%w: %s", ErrUnknownConverter, name)
+	}
+	m.registry[i].Converter = converter
+	m.sortConverters()
+	return nil
+}
+
+// Remove removes the converter registered under a name.
+func (m *Marky) Remove(name string) error {
+	i := m.lookup(name)
+	if i < 0 {
+		return fmt.Errorf("%w: %s", ErrUnknownConverter, name)
+	}
+	m.registry = slices.Delete(m.registry, i, i+1)
+	m.sortConverters()
+	return nil
+}
+
+// SetPriority changes the priority of the converter registered under a
+// name, so that it is tried before or after the others.
+func (m *Marky) SetPriority(name string, priority int) error {
+	i := m.lookup(name)
+	if i < 0 {
+		return fmt.Errorf("%w: %s", ErrUnknownConverter, name)
+	}
+	m.registry[i].Priority = priority
+	m.sortConverters()
+	return nil
+}
+
+// Registrations returns the registered converters with their names and
+// priorities, in detection order.
+func (m *Marky) Registrations() []Registration {
+	registrations := slices.Clone(m.registry)
+	slices.SortStableFunc(registrations, func(a, b Registration) int {
+		return cmp.Compare(b.Priority, a.Priority)
+	})
+	return registrations
+}
+
+// lookup returns the index of the registration of a name, or -1.
+func (m *Marky) lookup(name string) int {
+	if name == "" {
+		return -1
+	}
+	return slices.IndexFunc(m.registry, func(r Registration) bool {
+		return r.Name == name
+	})
+}
+
+// sortConverters lists the registered converters in Converters in detection
+// order, and drops the cached detections, which may no longer hold. The
+// converters of emails and archives convert the files they hold with the
+// Marky, the converters logging diagnostics or reporting progress get its
+// Logger and Progress, and every converter gets the Shared settings.
+func (m *Marky) sortConverters() {
+	registrations := m.Registrations()
+	m.Converters = make([]converters.Converter, len(registrations))
+	for i, r := range registrations {
+		m.Converters[i] = r.Converter
+		if container, ok := r.Converter.(converters.ContainerConverter); ok {
+			container.SetConverter(m.loadMarkdown)
+		}
+		if m.Logger != nil {
+			m.passLogger(r.Converter)
+		}
+		if m.Progress != nil {
+			m.passProgress(r.Converter)
+		}
+		m.passShared(r.Converter)
+	}
+	m.resetDetections()
+}
+
+// loadMarkdown converts a file held by an email or archive, leaving the
+// rendering to the conversion of the container.
+func (m *Marky) loadMarkdown(ctx context.Context, path string) (string, error) {
+	result, err := m.load(ctx, path)
+	if err != nil {
+		return "", err
+	}
+	return result.Markdown, nil
+}
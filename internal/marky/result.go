@@ -0,0 +1,105 @@
+package marky
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/flaviodelgrosso/marky/internal/converters"
+	"github.com/flaviodelgrosso/marky/internal/utils"
+)
+
+// ConversionResult is a converted document along with what is known about
+// its conversion.
+type ConversionResult struct {
+	// Markdown is the document rendered in the OutputFormat, as returned by
+	// Convert.
+	Markdown string `json:"markdown,omitempty"`
+
+	// MimeType is the MIME type the converter was chosen for: the detected
+	// type, the type given by the detection hook, or the type of the
+	// extension of the file.
+	MimeType string `json:"mime_type"`
+
+	// Converter is the type name of the converter, such as PdfConverter.
+	Converter string `json:"converter"`
+
+	// Title is the title of the front matter of the document, or the text
+	// of its first heading.
+	Title string `json:"title,omitempty"`
+
+	// Parts counts the pages of PDF files, the slides of presentations or
+	// the sheets of workbooks. It is zero for other formats.
+	Parts int `json:"parts,omitempty"`
+
+	// Warnings describe the content the converter left out, such as pages
+	// without text or rows beyond a limit.
+	Warnings []string `json:"warnings,omitempty"`
+
+	// Words and Characters count the words and the characters, spaces
+	// included, of the plain text of the document, and ReadingMinutes is
+	// the time needed to read it at ReadingSpeed, rounded up.
+	Words          int `json:"words"`
+	Characters     int `json:"characters"`
+	ReadingMinutes int `json:"reading_minutes"`
+}
+
+// ReadingSpeed is the number of words read per minute to estimate reading
+// times.
+const ReadingSpeed = 200
+
+// ConvertWithResult converts a document like Convert, and returns it along
+// with its MIME type, its converter, its title, its text statistics, and the
+// page, slide or sheet count and warnings of the converters reporting them.
+// Statistics are computed from the converted markdown, before any summary.
+func (m *Marky) ConvertWithResult(path string) (*ConversionResult, error) {
+	return m.convertWithResult(context.Background(), path)
+}
+
+// convertWithResult converts a document like ConvertWithResult, stopping
+// once ctx is done.
+func (m *Marky) convertWithResult(ctx context.Context, path string) (*ConversionResult, error) {
+	result, err := m.load(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	result.Title = documentTitle(result.Markdown)
+	result.Words, result.Characters = textStats(result.Markdown)
+	result.ReadingMinutes = (result.Words + ReadingSpeed - 1) / ReadingSpeed
+	if result.Markdown, err = m.render(result.Markdown); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// documentTitle returns the title field of the front matter of markdown, or
+// the text of its first heading.
+func documentTitle(markdown string) string {
+	fields, body := utils.SplitFrontMatter(markdown)
+	for _, field := range fields {
+		if field.Key == "title" && field.Value != "" {
+			return field.Value
+		}
+	}
+	if headings := utils.ExtractHeadings(body); len(headings) > 0 {
+		return headings[0].Text
+	}
+	return ""
+}
+
+// textStats counts the words and the characters of the plain text of
+// markdown, without its front matter and syntax.
+func textStats(markdown string) (words, characters int) {
+	text := strings.TrimSpace(utils.RenderText(markdown))
+	return len(strings.Fields(text)), utf8.RuneCountInString(text)
+}
+
+// converterName returns the type name of a converter.
+func converterName(converter converters.Converter) string {
+	t := reflect.TypeOf(converter)
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Name()
+}
@@ -0,0 +1,121 @@
+package marky
+
+import (
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/flaviodelgrosso/marky/internal/utils"
+)
+
+// Fidelity measures how much of the structure and text of a reference
+// markdown document a conversion kept. Scores range from 0 to 1, 1 when the
+// conversion matches the reference.
+type Fidelity struct {
+	Headings          int `json:"headings"`
+	ReferenceHeadings int `json:"reference_headings"`
+
+	// TableCells counts the cells of tables, header cells included.
+	TableCells          int `json:"table_cells"`
+	ReferenceTableCells int `json:"reference_table_cells"`
+
+	Words          int `json:"words"`
+	ReferenceWords int `json:"reference_words"`
+
+	// HeadingScore and TableScore compare the counts of headings and table
+	// cells, as the smaller count over the larger one.
+	HeadingScore float64 `json:"heading_score"`
+	TableScore   float64 `json:"table_score"`
+
+	// WordRetention is the share of the words of the reference found in
+	// the conversion, counting each occurrence of a word once.
+	WordRetention float64 `json:"word_retention"`
+
+	// Score is the mean of HeadingScore, TableScore and WordRetention.
+	Score float64 `json:"score"`
+}
+
+// Score converts a document and measures its fidelity against the markdown
+// of a reference file, written by hand or by another engine. The markdown
+// of the converter is scored, before any summary, heading shift or
+// rendering.
+func (m *Marky) Score(path, reference string) (Fidelity, error) {
+	expected, err := os.ReadFile(reference)
+	if err != nil {
+		return Fidelity{}, err
+	}
+	markdown, err := m.convert(path)
+	if err != nil {
+		return Fidelity{}, err
+	}
+	return ScoreMarkdown(markdown, string(expected)), nil
+}
+
+// ScoreMarkdown measures the fidelity of converted markdown against
+// reference markdown. Front matter is left out of both.
+func ScoreMarkdown(markdown, reference string) Fidelity {
+	got, want := documentStats(markdown), documentStats(reference)
+
+	f := Fidelity{
+		Headings:            got.headings,
+		ReferenceHeadings:   want.headings,
+		TableCells:          got.cells,
+		ReferenceTableCells: want.cells,
+		Words:               len(got.words),
+		ReferenceWords:      len(want.words),
+		HeadingScore:        countScore(got.headings, want.headings),
+		TableScore:          countScore(got.cells, want.cells),
+		WordRetention:       1,
+	}
+
+	if len(want.words) > 0 {
+		counts := make(map[string]int)
+		for _, word := range got.words {
+			counts[word]++
+		}
+		kept := 0
+		for _, word := range want.words {
+			if counts[word] > 0 {
+				counts[word]--
+				kept++
+			}
+		}
+		f.WordRetention = float64(kept) / float64(len(want.words))
+	}
+
+	f.Score = (f.HeadingScore + f.TableScore + f.WordRetention) / 3
+	return f
+}
+
+type markdownStats struct {
+	headings, cells int
+	words           []string
+}
+
+// documentStats counts the headings and table cells of a markdown document
+// and lists the lowercase words of its text.
+func documentStats(markdown string) markdownStats {
+	var stats markdownStats
+	for _, block := range utils.ParseBlocks(markdown) {
+		switch block.Type {
+		case utils.BlockHeading:
+			stats.headings++
+		case utils.BlockTable:
+			for _, row := range block.Rows {
+				stats.cells += len(row)
+			}
+		}
+		stats.words = append(stats.words, strings.FieldsFunc(strings.ToLower(block.Text), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})...)
+	}
+	return stats
+}
+
+// countScore compares two counts, as the smaller over the larger.
+func countScore(got, want int) float64 {
+	if got == want {
+		return 1
+	}
+	return float64(min(got, want)) / float64(max(got, want))
+}
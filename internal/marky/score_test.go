@@ -0,0 +1,78 @@
+package marky
+
+import (
+	"math"
+	"testing"
+)
+
+func TestScoreMarkdown(t *testing.T) {
+	reference := "# Report\n\n## Sales\n\n| Region | Total |\n|---|---|\n| North | 10 |\n\nSales grew in the north.\n"
+
+	tests := []struct {
+		name     string
+		markdown string
+		expected Fidelity
+	}{
+		{
+			name:     "identical",
+			markdown: reference,
+			expected: Fidelity{Headings: 2, TableCells: 4, Words: 11, HeadingScore: 1, TableScore: 1, WordRetention: 1, Score: 1},
+		},
+		{
+			name:     "front matter is ignored",
+			markdown: "---\ntitle: Report\n---\n\n" + reference,
+			expected: Fidelity{Headings: 2, TableCells: 4, Words: 11, HeadingScore: 1, TableScore: 1, WordRetention: 1, Score: 1},
+		},
+		{
+			name:     "lost table and heading",
+			markdown: "# Report\n\nSales Region Total North 10\n",
+			expected: Fidelity{Headings: 1, TableCells: 0, Words: 6, HeadingScore: 0.5, TableScore: 0, WordRetention: 6.0 / 11, Score: (0.5 + 6.0/11) / 3},
+		},
+		{
+			name:     "empty",
+			markdown: "",
+			expected: Fidelity{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ScoreMarkdown(tt.markdown, reference)
+			tt.expected.ReferenceHeadings, tt.expected.ReferenceTableCells, tt.expected.ReferenceWords = 2, 4, 11
+			if got.Headings != tt.expected.Headings || got.TableCells != tt.expected.TableCells || got.Words != tt.expected.Words ||
+				got.ReferenceHeadings != 2 || got.ReferenceTableCells != 4 || got.ReferenceWords != 11 {
+				t.Errorf("ScoreMarkdown() counts = %+v, want %+v", got, tt.expected)
+			}
+			for _, score := range [][2]float64{
+				{got.HeadingScore, tt.expected.HeadingScore},
+				{got.TableScore, tt.expected.TableScore},
+				{got.WordRetention, tt.expected.WordRetention},
+				{got.Score, tt.expected.Score},
+			} {
+				if math.Abs(score[0]-score[1]) > 1e-9 {
+					t.Errorf("ScoreMarkdown() = %+v, want %+v", got, tt.expected)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestMarky_Score(t *testing.T) {
+	m := &Marky{}
+	m.RegisterConverter(newFakeConverter("# Title\n\nSome text.\n", []string{".txt"}, nil))
+	path := writeTestFile(t, "doc.txt", "text")
+	reference := writeTestFile(t, "doc.md", "# Title\n\nSome other text.\n")
+
+	got, err := m.Score(path, reference)
+	if err != nil {
+		t.Fatalf("Score() error = %v", err)
+	}
+	if got.HeadingScore != 1 || got.WordRetention != 0.75 {
+		t.Errorf("Score() = %+v, want heading score 1 and word retention 0.75", got)
+	}
+
+	if _, err := m.Score(path, reference+".missing"); err == nil {
+		t.Error("Score() with a missing reference: expected an error")
+	}
+}
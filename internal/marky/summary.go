@@ -0,0 +1,146 @@
+package marky
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/flaviodelgrosso/marky/internal/utils"
+)
+
+// Summarizer writes a summary of a converted document, placed in a Summary
+// section at the top of the markdown. Implementations may call a language
+// model or pick sentences of the document, as ExtractiveSummarizer does. An
+// empty summary leaves the document as it is.
+type Summarizer interface {
+	Summarize(markdown string) (string, error)
+}
+
+// SummarizerFunc adapts a function to the Summarizer interface.
+type SummarizerFunc func(markdown string) (string, error)
+
+// Summarize calls f.
+func (f SummarizerFunc) Summarize(markdown string) (string, error) {
+	return f(markdown)
+}
+
+// addSummary inserts the summary of a document after its front matter.
+func addSummary(markdown string, s Summarizer) (string, error) {
+	summary, err := s.Summarize(markdown)
+	if err != nil {
+		return "", err
+	}
+	summary = strings.TrimSpace(summary)
+	if summary == "" {
+		return markdown, nil
+	}
+
+	fields, body := utils.SplitFrontMatter(markdown)
+	section := "## Summary\n\n" + summary + "\n\n"
+	if fields == nil {
+		return section + body, nil
+	}
+	return utils.FrontMatter(fields) + "\n" + section + body, nil
+}
+
+// ExtractiveSummarizer summarizes documents locally with the sentences of
+// their paragraphs whose words are most frequent in the document, in the
+// order they appear.
+type ExtractiveSummarizer struct {
+	// Sentences is the number of sentences of the summary, 3 when zero.
+	Sentences int
+}
+
+// summaryStopWords are common English words left out of word frequencies.
+var summaryStopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "by": true, "for": true, "from": true, "has": true, "have": true,
+	"in": true, "is": true, "it": true, "its": true, "of": true, "on": true,
+	"or": true, "that": true, "the": true, "this": true, "to": true, "was": true,
+	"were": true, "which": true, "with": true,
+}
+
+// Summarize returns the highest scoring sentences of the paragraphs of a
+// document.
+func (s ExtractiveSummarizer) Summarize(markdown string) (string, error) {
+	sentences := proseSentences(markdown)
+
+	freq := make(map[string]int)
+	for _, sentence := range sentences {
+		for _, word := range summaryWords(sentence) {
+			freq[word]++
+		}
+	}
+
+	type scored struct {
+		index int
+		score float64
+	}
+	ranked := make([]scored, len(sentences))
+	for i, sentence := range sentences {
+		words := summaryWords(sentence)
+		total := 0
+		for _, word := range words {
+			total += freq[word]
+		}
+		ranked[i] = scored{i, float64(total) / float64(max(len(words), 1))}
+	}
+	slices.SortStableFunc(ranked, func(a, b scored) int { return cmp.Compare(b.score, a.score) })
+
+	n := min(cmp.Or(s.Sentences, 3), len(ranked))
+	picked := ranked[:n]
+	slices.SortFunc(picked, func(a, b scored) int { return a.index - b.index })
+
+	summary := make([]string, n)
+	for i, p := range picked {
+		summary[i] = sentences[p.index]
+	}
+	return strings.Join(summary, " "), nil
+}
+
+// proseSentences returns the sentences of the paragraphs of a document,
+// leaving out headings, lists, tables, quotes, HTML and code.
+func proseSentences(markdown string) []string {
+	var sentences []string
+	fence := ""
+	_, body := utils.SplitFrontMatter(markdown)
+	for line := range strings.Lines(body) {
+		line = strings.TrimSpace(line)
+		if marker := line[:min(3, len(line))]; marker == "```" || marker == "~~~" {
+			if fence == "" {
+				fence = marker
+			} else if marker == fence {
+				fence = ""
+			}
+			continue
+		}
+		if fence != "" || line == "" || strings.ContainsRune("#|-*+>!<[", rune(line[0])) || unicode.IsDigit(rune(line[0])) {
+			continue
+		}
+
+		start := 0
+		for i := 0; i < len(line); i++ {
+			if strings.IndexByte(".!?", line[i]) >= 0 && (i+1 == len(line) || line[i+1] == ' ') {
+				if sentence := strings.TrimSpace(line[start : i+1]); len(summaryWords(sentence)) >= 3 {
+					sentences = append(sentences, sentence)
+				}
+				start = i + 1
+			}
+		}
+	}
+	return sentences
+}
+
+// summaryWords returns the lowercase words of a sentence, without stop words.
+func summaryWords(sentence string) []string {
+	var words []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(sentence), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if !summaryStopWords[word] {
+			words = append(words, word)
+		}
+	}
+	return words
+}
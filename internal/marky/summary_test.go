@@ -0,0 +1,48 @@
+package marky
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestExtractiveSummarizer_Summarize(t *testing.T) {
+	input := "---\ntitle: Solar\n---\n\n# Solar power\n\n" +
+		"Solar panels turn sunlight into electricity. The weather was nice on Tuesday.\n\n" +
+		"```\nsolar panels solar panels solar panels.\n```\n\n" +
+		"- Solar panels solar panels in a list.\n\n" +
+		"Panels of solar cells produce electricity from sunlight. Lunch was served at noon today.\n"
+
+	summary, err := ExtractiveSummarizer{Sentences: 2}.Summarize(input)
+	if err != nil {
+		t.Fatalf("Summarize() returned unexpected error: %v", err)
+	}
+	expected := "Solar panels turn sunlight into electricity. Panels of solar cells produce electricity from sunlight."
+	if summary != expected {
+		t.Errorf("Summarize() = %q, want %q", summary, expected)
+	}
+}
+
+func TestMarky_Convert_Summarizer(t *testing.T) {
+	m := &Marky{}
+	m.RegisterConverter(newFakeConverter("---\ntitle: Report\n---\n\n# Report\n", []string{".txt"}, nil))
+	m.SetHeadingLevels(1, 0)
+	m.SetSummarizer(SummarizerFunc(func(markdown string) (string, error) {
+		return "A short report.\n", nil
+	}))
+
+	got, err := m.Convert(writeTestFile(t, "doc.txt", "text"))
+	if err != nil {
+		t.Fatalf("Convert() returned unexpected error: %v", err)
+	}
+	if want := "---\ntitle: Report\n---\n\n### Summary\n\nA short report.\n\n## Report\n"; got != want {
+		t.Errorf("Convert() = %q, want %q", got, want)
+	}
+
+	m.SetSummarizer(SummarizerFunc(func(string) (string, error) {
+		return "", errors.New("model unavailable")
+	}))
+	if _, err := m.Convert(writeTestFile(t, "doc.txt", "text")); err == nil || !strings.Contains(err.Error(), "model unavailable") {
+		t.Errorf("Convert() error = %v, want the summarizer error", err)
+	}
+}
@@ -0,0 +1,336 @@
+// Package mimetypes detects the MIME types of documents and maps them to
+// file extensions. It is the one model of formats shared by the registry of
+// converters, which routes files by extension and detected type, and by the
+// converters, which name embedded images and attachments.
+package mimetypes
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/gabriel-vasile/mimetype"
+)
+
+// SniffLimit bounds the number of bytes read from a file to detect its MIME
+// type. It matches the default read limit of the mimetype package, which
+// reads whole files when its global limit is lifted.
+const SniffLimit = 3072
+
+// Type is a MIME type detected by Detect.
+type Type struct {
+	// mime is the detected node of the mimetype package, nil for the types
+	// refined by Detect that the package does not know.
+	mime      *mimetype.MIME
+	name      string
+	extension string
+}
+
+// String returns the MIME type, with its parameters such as the charset.
+func (t Type) String() string {
+	if t.mime != nil {
+		return t.mime.String()
+	}
+	return t.name
+}
+
+// Extension returns the usual file extension of the type, such as .pdf.
+func (t Type) Extension() string {
+	if t.mime != nil {
+		return t.mime.Extension()
+	}
+	return t.extension
+}
+
+// Is reports whether the type is expected or an alias of it. Parameters are
+// ignored.
+func (t Type) Is(expected string) bool {
+	if t.mime != nil {
+		return t.mime.Is(expected)
+	}
+	expected, _, _ = strings.Cut(expected, ";")
+	return strings.TrimSpace(expected) == t.name
+}
+
+// refinedType is a type told apart by Detect from a generic type found by
+// the mimetype package.
+type refinedType struct {
+	parent    string
+	name      string
+	extension string
+	match     func(raw []byte, limit uint32) bool
+}
+
+// refinedTypes tell Jupyter notebooks and JSON Lines files apart from other
+// JSON and text by their content, so that the notebook converter does not
+// claim every JSON file. They are matched by Detect rather than added to the
+// detection tree of the mimetype package, which is shared by every program
+// importing it.
+var refinedTypes = []refinedType{
+	{"application/json", "application/x-ipynb+json", ".ipynb", isNotebook},
+	{"text/plain", "application/x-ndjson", ".ndjson", isJSONLines},
+}
+
+// refine returns the refined type of a header of a generic type, or the
+// type itself when none matches.
+func refine(header []byte, mtype *mimetype.MIME) Type {
+	raw := header[:min(len(header), SniffLimit)]
+	for _, refined := range refinedTypes {
+		if !mtype.Is(refined.parent) || !refined.match(raw, SniffLimit) {
+			continue
+		}
+		if known := mimetype.Lookup(refined.name); known != nil {
+			return Type{mime: known}
+		}
+		return Type{name: refined.name, extension: refined.extension}
+	}
+	return Type{mime: mtype}
+}
+
+// ReadHeader reads the leading bytes of a file used to detect its type,
+// without reading the rest of the file.
+func ReadHeader(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	header := make([]byte, SniffLimit)
+	n, err := io.ReadFull(f, header)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, err
+	}
+	return header[:n], nil
+}
+
+// DetectFile detects the MIME type of a file from its leading bytes,
+// without reading the rest of the file.
+func DetectFile(path string) (Type, error) {
+	header, err := ReadHeader(path)
+	if err != nil {
+		return Type{}, err
+	}
+	return Detect(path, header), nil
+}
+
+// Detect detects the MIME type of a file from its leading bytes. Files
+// without an extension, such as downloaded blobs, are inspected further when
+// the leading bytes only reveal a generic type. The path of documents held
+// in memory is empty, and their header is the whole document.
+func Detect(path string, header []byte) Type {
+	mtype := mimetype.Detect(header)
+	if filepath.Ext(path) != "" {
+		return refine(header, mtype)
+	}
+	if inspected := mimetype.Lookup(inspectContent(path, header, mtype)); inspected != nil {
+		return Type{mime: inspected}
+	}
+	return refine(header, mtype)
+}
+
+// inspectContent returns the MIME type of a file of a generic type found by
+// looking at the member names of ZIP archives, for office documents and
+// books, and at the whole header of other files, for PDF documents that do
+// not start with their signature and HTML pages starting with text. It
+// returns an empty string when nothing more specific is found.
+func inspectContent(path string, header []byte, mtype *mimetype.MIME) string {
+	switch {
+	case mtype.Is("application/zip"):
+		return zipMimeType(path, header)
+	case mtype.Is("application/octet-stream"), mtype.Is("text/plain"):
+		// PDF readers accept the signature within the first 1024 bytes
+		if bytes.Contains(header[:min(len(header), 1024)], []byte("%PDF-")) {
+			return "application/pdf"
+		}
+		if looksLikeHTML(header) {
+			return "text/html"
+		}
+	}
+	return ""
+}
+
+// zipMimeType returns the MIME type of an office document or EPUB book from
+// the names of its members, or an empty string for other archives. The
+// archive is read from data when path is empty.
+func zipMimeType(path string, data []byte) string {
+	var files []*zip.File
+	if path == "" {
+		reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return ""
+		}
+		files = reader.File
+	} else {
+		reader, err := zip.OpenReader(path)
+		if err != nil {
+			return ""
+		}
+		defer reader.Close()
+		files = reader.File
+	}
+
+	for _, file := range files {
+		switch {
+		case file.Name == "META-INF/container.xml":
+			return "application/epub+zip"
+		case strings.HasPrefix(file.Name, "word/"):
+			return "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+		case strings.HasPrefix(file.Name, "xl/"):
+			return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+		case strings.HasPrefix(file.Name, "ppt/"):
+			return "application/vnd.openxmlformats-officedocument.presentationml.presentation"
+		}
+	}
+	return ""
+}
+
+// looksLikeHTML reports whether a header holds the document tags of an HTML page
+// anywhere in its header.
+func looksLikeHTML(header []byte) bool {
+	text := bytes.ToLower(header)
+	for _, tag := range []string{"<!doctype html", "<html", "<head", "<body"} {
+		if bytes.Contains(text, []byte(tag)) {
+			return true
+		}
+	}
+	return false
+}
+
+// isNotebook reports whether a JSON document is a Jupyter notebook: an object
+// with a numeric nbformat key, or with the cells or worksheets list of the
+// current and legacy formats. The keys are looked up in order, so truncated
+// documents match when one of them comes first.
+func isNotebook(raw []byte, _ uint32) bool {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return false
+	}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return false
+		}
+		value, err := dec.Token()
+		if err != nil {
+			return false
+		}
+		switch key {
+		case "nbformat":
+			if _, ok := value.(float64); ok {
+				return true
+			}
+		case "cells", "worksheets":
+			if value == json.Delim('[') {
+				return true
+			}
+		}
+		// Skip the rest of nested values
+		for depth := nesting(value); depth > 0; depth += nesting(value) {
+			if value, err = dec.Token(); err != nil {
+				return false
+			}
+		}
+	}
+	return false
+}
+
+// nesting returns how a JSON token changes the nesting depth.
+func nesting(tok json.Token) int {
+	switch tok {
+	case json.Delim('{'), json.Delim('['):
+		return 1
+	case json.Delim('}'), json.Delim(']'):
+		return -1
+	}
+	return 0
+}
+
+// isJSONLines reports whether text holds JSON values one per line, at least
+// two of them objects or arrays. The last line is left out when the input
+// was cut at the read limit, so that long files match as well.
+func isJSONLines(raw []byte, limit uint32) bool {
+	lines := bytes.Split(raw, []byte("\n"))
+	if limit > 0 && len(raw) >= int(limit) {
+		lines = lines[:len(lines)-1]
+	}
+	values := 0
+	for _, line := range lines {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		if !json.Valid(line) {
+			return false
+		}
+		if line[0] == '{' || line[0] == '[' {
+			values++
+		}
+	}
+	return values > 1
+}
+
+// Accepts reports whether a converter of the given extensions and MIME
+// types accepts a detected MIME type: the extension of the type is one of
+// the extensions, or the type or one of its aliases is one of the types.
+func Accepts(mtype Type, extensions, mimeTypes []string) bool {
+	if slices.Contains(extensions, mtype.Extension()) {
+		return true
+	}
+	return slices.ContainsFunc(mimeTypes, mtype.Is)
+}
+
+// AcceptsType reports whether a MIME type given by name, such as by a
+// detection hook or a signature, is one of mimeTypes or an alias of one.
+// Parameters, such as the charset, are ignored.
+func AcceptsType(mimeType string, mimeTypes []string) bool {
+	mediaType, _, _ := strings.Cut(mimeType, ";")
+	mediaType = strings.TrimSpace(mediaType)
+	if slices.Contains(mimeTypes, mimeType) || slices.Contains(mimeTypes, mediaType) {
+		return true
+	}
+	if mtype := mimetype.Lookup(mediaType); mtype != nil {
+		return slices.ContainsFunc(mimeTypes, mtype.Is)
+	}
+	return false
+}
+
+// TypeByExtension returns the MIME type of a file extension, such as .docx,
+// or an empty string when it is unknown.
+func TypeByExtension(extension string) string {
+	return mime.TypeByExtension(extension)
+}
+
+// Extension returns the usual file extension of a MIME type, such as .jpg
+// for image/jpeg, or an empty string when it is unknown. Parameters are
+// ignored.
+func Extension(mimeType string) string {
+	mediaType, _, _ := strings.Cut(mimeType, ";")
+	mediaType = strings.TrimSpace(mediaType)
+	for _, refined := range refinedTypes {
+		if refined.name == mediaType {
+			return refined.extension
+		}
+	}
+	if mtype := mimetype.Lookup(mediaType); mtype != nil && mtype.Extension() != "" {
+		return mtype.Extension()
+	}
+	if extensions, _ := mime.ExtensionsByType(mediaType); len(extensions) > 0 {
+		return extensions[0]
+	}
+	return ""
+}
+
+// DetectData returns the MIME type of data held in memory, without
+// parameters, such as for the images embedded in documents.
+func DetectData(data []byte) string {
+	mediaType, _, _ := strings.Cut(mimetype.Detect(data).String(), ";")
+	return mediaType
+}
@@ -0,0 +1,219 @@
+package mimetypes
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gabriel-vasile/mimetype"
+)
+
+func TestDetectMimeType(t *testing.T) {
+	dir := t.TempDir()
+	pdf := filepath.Join(dir, "doc.pdf")
+	if err := os.WriteFile(pdf, []byte("%PDF-1.4\n"), 0o644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	mtype, err := DetectFile(pdf)
+	if err != nil {
+		t.Fatalf("DetectFile() returned unexpected error: %v", err)
+	}
+	if !mtype.Is("application/pdf") {
+		t.Errorf("DetectFile() = %s, want application/pdf", mtype)
+	}
+
+	if _, err := DetectFile(filepath.Join(dir, "missing.pdf")); err == nil {
+		t.Error("DetectFile() should return error for a missing file")
+	}
+	if _, err := DetectFile(dir); err == nil {
+		t.Error("DetectFile() should return error for a directory")
+	}
+}
+
+func TestDetectMimeType_ReadsOnlyHeader(t *testing.T) {
+	// Without a limit, the mimetype package reads whole inputs.
+	mimetype.SetLimit(0)
+	t.Cleanup(func() { mimetype.SetLimit(SniffLimit) })
+
+	// A large sparse file would take gigabytes to read in full.
+	path := filepath.Join(t.TempDir(), "large.bin")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if _, err := f.WriteString("%PDF-1.7\n"); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	if err := f.Truncate(4 << 30); err != nil {
+		t.Skipf("sparse files are not supported: %v", err)
+	}
+	f.Close()
+
+	mtype, err := DetectFile(path)
+	if err != nil {
+		t.Fatalf("DetectFile() returned unexpected error: %v", err)
+	}
+	if !mtype.Is("application/pdf") {
+		t.Errorf("DetectFile() = %s, want application/pdf", mtype)
+	}
+}
+
+func TestDetectMimeType_JSON(t *testing.T) {
+	longLines := strings.Repeat(`{"text": "a line of a long JSON Lines file"}`+"\n", 100)
+
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"notebook", `{"cells": [], "metadata": {}, "nbformat": 4, "nbformat_minor": 5}`, "application/x-ipynb+json"},
+		{"notebook nbformat first", `{"metadata": {}, "nbformat": 4, "cells": []}`, "application/x-ipynb+json"},
+		{"legacy notebook", `{"metadata": {}, "nbformat": 3, "worksheets": []}`, "application/x-ipynb+json"},
+		{"truncated notebook", `{"cells": [` + strings.Repeat(`{"cell_type": "code", "source": "x = 1"},`, 200) + `{}]}`, "application/x-ipynb+json"},
+		{"cells key", `{"name": "marky", "cells": 3}`, "application/json"},
+		{"plain object", `{"name": "marky", "nested": {"cells": []}}`, "application/json"},
+		{"array", `[{"cells": []}]`, "application/json"},
+		{"json lines", "{\"a\": 1}\n{\"a\": 2}\n", "application/x-ndjson"},
+		{"long json lines", longLines, "application/x-ndjson"},
+	}
+
+	dir := t.TempDir()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, strings.ReplaceAll(tt.name, " ", "-"))
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+
+			mtype, err := DetectFile(path)
+			if err != nil {
+				t.Fatalf("DetectFile() returned unexpected error: %v", err)
+			}
+			if !mtype.Is(tt.expected) {
+				t.Errorf("DetectFile() = %s, want %s", mtype, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDetectMimeType_LeavesMimetypeUnchanged(t *testing.T) {
+	notebook := []byte(`{"cells": [], "metadata": {}, "nbformat": 4}`)
+	if mtype := mimetype.Detect(notebook); !mtype.Is("application/json") {
+		t.Errorf("mimetype.Detect() = %s, want application/json for other importers", mtype)
+	}
+	if mtype := Detect("", notebook); !mtype.Is("application/x-ipynb+json") || mtype.Extension() != ".ipynb" {
+		t.Errorf("Detect() = %s %s, want application/x-ipynb+json .ipynb", mtype, mtype.Extension())
+	}
+}
+
+// zipWithMembers returns a ZIP archive with the named members, led by a large
+// member that pushes them past the bytes used to detect the MIME type.
+func zipWithMembers(t *testing.T, names ...string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, name := range append([]string{"padding.bin"}, names...) {
+		f, err := w.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store})
+		if err != nil {
+			t.Fatalf("Failed to create ZIP member: %v", err)
+		}
+		if name == "padding.bin" {
+			f.Write(bytes.Repeat([]byte{0}, 2*SniffLimit))
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Failed to write ZIP archive: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestDetectMimeType_Extensionless(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  []byte
+		expected string
+	}{
+		{"docx", zipWithMembers(t, "[Content_Types].xml", "word/document.xml"), "application/vnd.openxmlformats-officedocument.wordprocessingml.document"},
+		{"xlsx", zipWithMembers(t, "[Content_Types].xml", "xl/workbook.xml"), "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"},
+		{"pptx", zipWithMembers(t, "[Content_Types].xml", "ppt/presentation.xml"), "application/vnd.openxmlformats-officedocument.presentationml.presentation"},
+		{"epub", zipWithMembers(t, "META-INF/container.xml", "OEBPS/content.opf"), "application/epub+zip"},
+		{"zip", zipWithMembers(t, "notes.txt"), "application/zip"},
+		{"pdf", []byte("\x00\x01\x02 junk\n%PDF-1.7\n"), "application/pdf"},
+		{"html", []byte("Saved page\n<!DOCTYPE html><html><body><p>Hi</p></body></html>"), "text/html"},
+		{"text", []byte("Plain text mentioning html and body"), "text/plain"},
+	}
+
+	dir := t.TempDir()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.name)
+			if err := os.WriteFile(path, tt.content, 0o644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+
+			mtype, err := DetectFile(path)
+			if err != nil {
+				t.Fatalf("DetectFile() returned unexpected error: %v", err)
+			}
+			if !mtype.Is(tt.expected) {
+				t.Errorf("DetectFile() = %s, want %s", mtype, tt.expected)
+			}
+		})
+	}
+
+	// Files with an extension are left to their leading bytes
+	path := filepath.Join(dir, "document.bin")
+	if err := os.WriteFile(path, tests[0].content, 0o644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	mtype, err := DetectFile(path)
+	if err != nil {
+		t.Fatalf("DetectFile() returned unexpected error: %v", err)
+	}
+	if !mtype.Is("application/zip") {
+		t.Errorf("DetectFile() = %s, want application/zip", mtype)
+	}
+}
+
+func TestExtension(t *testing.T) {
+	for mimeType, want := range map[string]string{
+		"image/jpeg":               ".jpg",
+		"image/png":                ".png",
+		"application/pdf; q=1":     ".pdf",
+		"text/csv; charset=utf-8":  ".csv",
+		"application/x-ipynb+json": ".ipynb",
+		"application/x-unknown":    "",
+	} {
+		if got := Extension(mimeType); got != want {
+			t.Errorf("Extension(%q) = %q, want %q", mimeType, got, want)
+		}
+	}
+}
+
+func TestAcceptsType(t *testing.T) {
+	accepted := []string{"text/csv", "application/zip"}
+	for mimeType, want := range map[string]bool{
+		"text/csv":                     true,
+		"text/csv; charset=utf-8":      true,
+		"application/x-zip-compressed": true,
+		"application/json":             false,
+	} {
+		if got := AcceptsType(mimeType, accepted); got != want {
+			t.Errorf("AcceptsType(%q) = %v, want %v", mimeType, got, want)
+		}
+	}
+}
+
+func TestDetectData(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	if got := DetectData(png); got != "image/png" {
+		t.Errorf("DetectData() = %q, want image/png", got)
+	}
+	if got := DetectData([]byte("plain text")); got != "text/plain" {
+		t.Errorf("DetectData() = %q, want text/plain", got)
+	}
+}
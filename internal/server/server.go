@@ -0,0 +1,572 @@
+// Package server converts documents uploaded over HTTP as jobs run in the
+// background by a pool of workers, so that large documents are not
+// converted within the request. Clients poll the status of a job or give a
+// callback URL the status is posted to once the job is finished.
+package server
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flaviodelgrosso/marky/internal/worker"
+)
+
+// State is the stage a job is at.
+type State string
+
+const (
+	StateQueued    State = "queued"
+	StateRunning   State = "running"
+	StateSucceeded State = "succeeded"
+	StateFailed    State = "failed"
+)
+
+// JobStatus is the status of a job returned by the API and posted to its
+// callback URL.
+type JobStatus struct {
+	ID    string `json:"id"`
+	State State  `json:"state"`
+
+	// Name is the file name of the uploaded document.
+	Name string `json:"name,omitempty"`
+
+	// Error is the error of the last attempt of a failed job.
+	Error string `json:"error,omitempty"`
+
+	// Attempts is the number of failed conversions of the job so far.
+	Attempts int `json:"attempts,omitempty"`
+
+	// Result is the path of the markdown of a succeeded job.
+	Result string `json:"result,omitempty"`
+
+	Created  time.Time  `json:"created"`
+	Finished *time.Time `json:"finished,omitempty"`
+}
+
+// job is a job held by the server.
+type job struct {
+	status   JobStatus
+	input    string
+	output   string
+	callback string
+}
+
+// Server is an http.Handler serving the job API:
+//
+//	POST   /jobs              uploads a document and returns its job, 202
+//	GET    /jobs/{id}         returns the status of a job
+//	GET    /jobs/{id}/result  returns the markdown of a succeeded job
+//	DELETE /jobs/{id}         removes a finished job and its markdown
+//
+// Documents are uploaded as the request body, with their file name in the
+// name query parameter, or as the file field of a multipart form. Either
+// way they are streamed to Dir rather than held in memory. The callback
+// query parameter is a URL, on one of the CallbackHosts, the status of the
+// job is posted to once it is finished. Jobs are converted once Start is
+// called, and finished jobs expire after JobTTL. Requests are authenticated
+// with Token or Middleware; the API is open to anyone reaching it without.
+type Server struct {
+	// Converter converts the uploaded documents.
+	Converter worker.Converter
+
+	// Dir is the directory uploads and their markdown are written to.
+	Dir string
+
+	// Workers is the number of jobs converted at once, the number of CPUs
+	// when zero.
+	Workers int
+
+	// MaxAttempts is the number of times a job is tried before it fails,
+	// 3 when zero.
+	MaxAttempts int
+
+	// MaxUploadSize is the size in bytes above which uploads are refused.
+	// Sizes are not limited when zero.
+	MaxUploadSize int64
+
+	// Client posts the statuses to the callback URLs,
+	// http.DefaultClient when nil. Redirects are only followed to the
+	// CallbackHosts.
+	Client *http.Client
+
+	// CallbackHosts lists the host names callback URLs may point to, so
+	// that clients cannot make the server post to internal services.
+	// Callbacks are refused when it is empty.
+	CallbackHosts []string
+
+	// JobTTL is how long finished jobs and their markdown are kept, one
+	// hour when zero. Negative durations keep them until they are deleted.
+	JobTTL time.Duration
+
+	// Logger receives the errors of callbacks, slog.Default when nil.
+	Logger *slog.Logger
+
+	// Token, when set, is the bearer token requests must carry in their
+	// Authorization header. Other requests are refused with 401.
+	Token string
+
+	// Middleware, when set, wraps the handler of the API, such as to
+	// authenticate requests in other ways than with Token, which is
+	// checked first.
+	Middleware func(http.Handler) http.Handler
+
+	mu      sync.Mutex
+	jobs    map[string]*job
+	pending chan string
+	once    sync.Once
+	handler http.Handler
+
+	// done is closed once the context of Start is done, so that the jobs
+	// waiting to be queued are dropped.
+	done     chan struct{}
+	stopOnce sync.Once
+
+	// workers tracks the workers and the expiry of jobs, and callbacks the
+	// callbacks being posted.
+	workers   sync.WaitGroup
+	callbacks sync.WaitGroup
+}
+
+// defaultJobTTL is how long finished jobs are kept when JobTTL is zero.
+const defaultJobTTL = time.Hour
+
+// init sets up the job table and the routes of the server once.
+func (s *Server) init() {
+	s.once.Do(func() {
+		s.jobs = make(map[string]*job)
+		s.pending = make(chan string, 1024)
+		s.done = make(chan struct{})
+		mux := http.NewServeMux()
+		mux.HandleFunc("POST /jobs", s.handleCreate)
+		mux.HandleFunc("GET /jobs/{id}", s.handleStatus)
+		mux.HandleFunc("GET /jobs/{id}/result", s.handleResult)
+		mux.HandleFunc("DELETE /jobs/{id}", s.handleDelete)
+		s.handler = mux
+		if s.Middleware != nil {
+			s.handler = s.Middleware(mux)
+		}
+	})
+}
+
+// ServeHTTP serves the job API to the requests carrying the Token.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.init()
+	if s.Token != "" && !s.authorized(r) {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="marky"`)
+		writeError(w, http.StatusUnauthorized, "missing or invalid bearer token")
+		return
+	}
+	s.handler.ServeHTTP(w, r)
+}
+
+// authorized reports whether a request carries the Token as its bearer
+// token.
+func (s *Server) authorized(r *http.Request) bool {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(strings.TrimSpace(token)), []byte(s.Token)) == 1
+}
+
+// Start runs the workers converting the jobs until ctx is done. The
+// conversions of converters implementing worker.ContextConverter stop when
+// ctx is done.
+func (s *Server) Start(ctx context.Context) {
+	s.init()
+	workers := s.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	for range workers {
+		w := &worker.Worker{Queue: s, Converter: s.Converter, MaxAttempts: s.MaxAttempts}
+		s.workers.Add(1)
+		go func() {
+			defer s.workers.Done()
+			w.Run(ctx)
+		}()
+	}
+
+	s.workers.Add(1)
+	go func() {
+		defer s.workers.Done()
+		s.expireJobs(ctx)
+	}()
+	go func() {
+		<-ctx.Done()
+		s.stopOnce.Do(func() { close(s.done) })
+	}()
+}
+
+// Wait waits for the workers to stop once the context of Start is done,
+// and for the callbacks being posted to finish. Dir can be removed once it
+// returns.
+func (s *Server) Wait() {
+	s.workers.Wait()
+	s.callbacks.Wait()
+}
+
+// enqueue queues a job for the workers, unless the server is stopped
+// first.
+func (s *Server) enqueue(id string) {
+	select {
+	case s.pending <- id:
+	case <-s.done:
+	}
+}
+
+// expireJobs removes the finished jobs older than JobTTL every minute, or
+// more often for shorter durations, until ctx is done.
+func (s *Server) expireJobs(ctx context.Context) {
+	ttl := cmp.Or(s.JobTTL, defaultJobTTL)
+	if ttl < 0 {
+		return
+	}
+	ticker := time.NewTicker(min(ttl, time.Minute))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.expire(now.Add(-ttl))
+		}
+	}
+}
+
+// expire removes the jobs finished before a time, with their markdown.
+func (s *Server) expire(before time.Time) {
+	var expired []*job
+	s.mu.Lock()
+	for id, j := range s.jobs {
+		if j.status.Finished != nil && j.status.Finished.Before(before) {
+			delete(s.jobs, id)
+			expired = append(expired, j)
+		}
+	}
+	s.mu.Unlock()
+	for _, j := range expired {
+		os.Remove(j.output)
+	}
+}
+
+// Next claims the next queued job, waiting for one until ctx is done. It
+// implements worker.Queue.
+func (s *Server) Next(ctx context.Context) (worker.Job, error) {
+	s.init()
+	for {
+		select {
+		case <-ctx.Done():
+			return worker.Job{}, ctx.Err()
+		case id := <-s.pending:
+			s.mu.Lock()
+			j, ok := s.jobs[id]
+			if ok {
+				j.status.State = StateRunning
+			}
+			s.mu.Unlock()
+			if !ok {
+				// The job was deleted while queued
+				continue
+			}
+			return worker.Job{ID: id, Input: j.input, Output: j.output, Attempts: j.status.Attempts}, nil
+		}
+	}
+}
+
+// Retry queues a job that failed again. It implements worker.Queue.
+func (s *Server) Retry(wj worker.Job) error {
+	s.mu.Lock()
+	if j, ok := s.jobs[wj.ID]; ok {
+		j.status.State, j.status.Attempts = StateQueued, wj.Attempts
+	}
+	s.mu.Unlock()
+	go s.enqueue(wj.ID)
+	return nil
+}
+
+// Finish records the outcome of a job, removes its upload and posts its
+// status to its callback URL. It implements worker.Queue.
+func (s *Server) Finish(ws worker.Status) error {
+	os.Remove(ws.Input)
+
+	s.mu.Lock()
+	j, ok := s.jobs[ws.ID]
+	if !ok {
+		s.mu.Unlock()
+		os.Remove(ws.Output)
+		return nil
+	}
+	finished := ws.Finished
+	j.status.Finished, j.status.Attempts = &finished, ws.Attempts
+	if ws.Error != "" {
+		j.status.State, j.status.Error = StateFailed, ws.Error
+	} else {
+		j.status.State, j.status.Result = StateSucceeded, "/jobs/"+ws.ID+"/result"
+	}
+	status, callback := j.status, j.callback
+	s.mu.Unlock()
+
+	if callback != "" {
+		s.callbacks.Add(1)
+		go func() {
+			defer s.callbacks.Done()
+			if err := s.postStatus(callback, status); err != nil {
+				s.logger().Warn("failed to post job status", "job", status.ID, "callback", callback, "error", err)
+			}
+		}()
+	}
+	return nil
+}
+
+// postStatus posts the status of a job as JSON to its callback URL.
+func (s *Server) postStatus(callback string, status JobStatus) error {
+	data, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, callback, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := *cmp.Or(s.Client, http.DefaultClient)
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		if !s.allowedCallback(req.URL) {
+			return fmt.Errorf("callback redirected to %s, which is not a callback host", req.URL.Host)
+		}
+		return nil
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("callback returned %s", resp.Status)
+	}
+	return nil
+}
+
+// allowedCallback reports whether statuses may be posted to a URL: an
+// http(s) URL on one of the CallbackHosts.
+func (s *Server) allowedCallback(u *url.URL) bool {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return false
+	}
+	return slices.ContainsFunc(s.CallbackHosts, func(host string) bool {
+		return strings.EqualFold(host, u.Hostname())
+	})
+}
+
+// logger returns the Logger, or slog.Default when it is nil.
+func (s *Server) logger() *slog.Logger {
+	if s.Logger == nil {
+		return slog.Default()
+	}
+	return s.Logger
+}
+
+// handleCreate streams an uploaded document to Dir and queues its job.
+func (s *Server) handleCreate(w http.ResponseWriter, r *http.Request) {
+	callback := r.URL.Query().Get("callback")
+	if callback != "" {
+		u, err := url.Parse(callback)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			writeError(w, http.StatusBadRequest, "the callback must be an http or https URL")
+			return
+		}
+		if !s.allowedCallback(u) {
+			writeError(w, http.StatusBadRequest, "the callback host is not allowed")
+			return
+		}
+	}
+	if s.MaxUploadSize > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, s.MaxUploadSize)
+	}
+
+	body, name, err := upload(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	id, err := newID()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	input := filepath.Join(s.Dir, id+strings.ToLower(filepath.Ext(name)))
+	if err := saveUpload(input, body); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("uploads are limited to %d bytes", tooLarge.Limit))
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	j := &job{
+		status:   JobStatus{ID: id, State: StateQueued, Name: name, Created: time.Now()},
+		input:    input,
+		output:   filepath.Join(s.Dir, id+".md"),
+		callback: callback,
+	}
+	s.mu.Lock()
+	s.jobs[id] = j
+	status := j.status
+	s.mu.Unlock()
+	go s.enqueue(id)
+
+	w.Header().Set("Location", "/jobs/"+id)
+	writeJSON(w, http.StatusAccepted, status)
+}
+
+// upload returns the document of a request and its file name: the file
+// field of a multipart form, or else the request body named by the name
+// query parameter.
+func upload(r *http.Request) (io.Reader, string, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		return r.Body, filepath.Base(r.URL.Query().Get("name")), nil
+	}
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, "", err
+	}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return nil, "", errors.New("the form has no file field")
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		if part.FormName() == "file" {
+			return part, filepath.Base(part.FileName()), nil
+		}
+	}
+}
+
+// saveUpload streams an upload to a file, which is removed when the upload
+// cannot be read.
+func saveUpload(path string, body io.Reader) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, body); err != nil {
+		f.Close()
+		os.Remove(path)
+		return err
+	}
+	return f.Close()
+}
+
+// handleStatus returns the status of a job.
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	status, ok := s.status(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "job not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+// handleResult returns the markdown of a succeeded job.
+func (s *Server) handleResult(w http.ResponseWriter, r *http.Request) {
+	status, ok := s.status(r.PathValue("id"))
+	switch {
+	case !ok:
+		writeError(w, http.StatusNotFound, "job not found")
+		return
+	case status.State == StateFailed:
+		writeError(w, http.StatusUnprocessableEntity, status.Error)
+		return
+	case status.State != StateSucceeded:
+		writeError(w, http.StatusConflict, "job is "+string(status.State))
+		return
+	}
+	w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	http.ServeFile(w, r, filepath.Join(s.Dir, status.ID+".md"))
+}
+
+// handleDelete removes a job, along with its markdown once it is
+// finished.
+func (s *Server) handleDelete(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	s.mu.Lock()
+	j, ok := s.jobs[id]
+	if ok && j.status.State == StateRunning {
+		s.mu.Unlock()
+		writeError(w, http.StatusConflict, "job is running")
+		return
+	}
+	delete(s.jobs, id)
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "job not found")
+		return
+	}
+	os.Remove(j.input)
+	os.Remove(j.output)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// status returns the status of a job.
+func (s *Server) status(id string) (JobStatus, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, ok := s.jobs[id]
+	if !ok {
+		return JobStatus{}, false
+	}
+	return j.status, true
+}
+
+// newID returns a random job ID.
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// writeJSON writes v as the JSON body of a response.
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an error as the JSON body of a response.
+func writeError(w http.ResponseWriter, code int, message string) {
+	writeJSON(w, code, map[string]string{"error": message})
+}
@@ -0,0 +1,299 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// upperConverter converts files by upper-casing them, and fails for files
+// starting with "fail".
+type upperConverter struct{}
+
+func (upperConverter) Convert(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	if strings.HasPrefix(string(data), "fail") {
+		return "", errors.New("conversion failed")
+	}
+	return strings.ToUpper(string(data)), nil
+}
+
+func newTestServer(t *testing.T) (*Server, *httptest.Server) {
+	t.Helper()
+	s := &Server{Converter: upperConverter{}, Dir: t.TempDir(), Workers: 2, MaxAttempts: 1}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	s.Start(ctx)
+	ts := httptest.NewServer(s)
+	t.Cleanup(ts.Close)
+	return s, ts
+}
+
+func createJob(t *testing.T, ts *httptest.Server, query, body string) JobStatus {
+	t.Helper()
+	resp, err := http.Post(ts.URL+"/jobs"+query, "application/octet-stream", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("POST /jobs failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("POST /jobs returned %s, want 202 Accepted", resp.Status)
+	}
+	var status JobStatus
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		t.Fatalf("Failed to decode job: %v", err)
+	}
+	return status
+}
+
+func waitJob(t *testing.T, ts *httptest.Server, id string) JobStatus {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		resp, err := http.Get(ts.URL + "/jobs/" + id)
+		if err != nil {
+			t.Fatalf("GET /jobs/%s failed: %v", id, err)
+		}
+		var status JobStatus
+		err = json.NewDecoder(resp.Body).Decode(&status)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatalf("Failed to decode job: %v", err)
+		}
+		if status.State == StateSucceeded || status.State == StateFailed {
+			return status
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("job %s did not finish", id)
+	return JobStatus{}
+}
+
+func TestServer_Jobs(t *testing.T) {
+	s, ts := newTestServer(t)
+
+	job := createJob(t, ts, "?name=notes.txt", "hello")
+	if job.ID == "" || job.State != StateQueued || job.Name != "notes.txt" {
+		t.Errorf("POST /jobs = %+v, want a queued job named notes.txt", job)
+	}
+	status := waitJob(t, ts, job.ID)
+	if status.State != StateSucceeded || status.Result != "/jobs/"+job.ID+"/result" {
+		t.Fatalf("job status = %+v, want succeeded with a result", status)
+	}
+
+	resp, err := http.Get(ts.URL + status.Result)
+	if err != nil {
+		t.Fatalf("GET result failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "HELLO" {
+		t.Errorf("GET result = %s %q, want 200 \"HELLO\"", resp.Status, body)
+	}
+	if _, err := os.Stat(filepath.Join(s.Dir, job.ID+".txt")); !os.IsNotExist(err) {
+		t.Error("the upload of a finished job was not removed")
+	}
+
+	req, _ := http.NewRequest(http.MethodDelete, ts.URL+"/jobs/"+job.ID, nil)
+	if resp, err := http.DefaultClient.Do(req); err != nil || resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("DELETE /jobs/%s = %v, %v, want 204", job.ID, resp, err)
+	}
+	if resp, _ := http.Get(ts.URL + "/jobs/" + job.ID); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("GET a deleted job returned %s, want 404", resp.Status)
+	}
+}
+
+func TestServer_Jobs_Failed(t *testing.T) {
+	_, ts := newTestServer(t)
+
+	job := createJob(t, ts, "", "fail please")
+	status := waitJob(t, ts, job.ID)
+	if status.State != StateFailed || status.Error != "conversion failed" {
+		t.Fatalf("job status = %+v, want failed", status)
+	}
+	if resp, _ := http.Get(ts.URL + "/jobs/" + job.ID + "/result"); resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("GET the result of a failed job returned %s, want 422", resp.Status)
+	}
+}
+
+func TestServer_Jobs_Callback(t *testing.T) {
+	posted := make(chan JobStatus, 1)
+	callback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var status JobStatus
+		json.NewDecoder(r.Body).Decode(&status)
+		posted <- status
+	}))
+	defer callback.Close()
+
+	s, ts := newTestServer(t)
+	s.CallbackHosts = []string{"127.0.0.1"}
+	job := createJob(t, ts, "?callback="+callback.URL, "hello")
+	select {
+	case status := <-posted:
+		if status.ID != job.ID || status.State != StateSucceeded {
+			t.Errorf("posted status = %+v, want job %s succeeded", status, job.ID)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the status was not posted to the callback URL")
+	}
+
+	for _, callback := range []string{"file:///etc/passwd", "http://169.254.169.254/latest/meta-data/"} {
+		resp, err := http.Post(ts.URL+"/jobs?callback="+callback, "text/plain", strings.NewReader("x"))
+		if err != nil || resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("POST /jobs with callback %s = %v, %v, want 400", callback, resp, err)
+		}
+	}
+}
+
+func TestServer_Expire(t *testing.T) {
+	s, ts := newTestServer(t)
+	job := createJob(t, ts, "", "hello")
+	waitJob(t, ts, job.ID)
+
+	s.expire(time.Now().Add(-time.Minute))
+	if _, ok := s.status(job.ID); !ok {
+		t.Fatal("a job finished after the expiry time was removed")
+	}
+	s.expire(time.Now().Add(time.Minute))
+	if _, ok := s.status(job.ID); ok {
+		t.Error("an expired job was kept")
+	}
+	if _, err := os.Stat(filepath.Join(s.Dir, job.ID+".md")); !os.IsNotExist(err) {
+		t.Error("the markdown of an expired job was kept")
+	}
+}
+
+// blockingConverter converts files once its context is done.
+type blockingConverter struct {
+	started chan struct{}
+	stopped bool
+}
+
+func (c *blockingConverter) Convert(path string) (string, error) {
+	return "", errors.New("Convert() called instead of ConvertContext()")
+}
+
+func (c *blockingConverter) ConvertContext(ctx context.Context, path string) (string, error) {
+	close(c.started)
+	<-ctx.Done()
+	c.stopped = true
+	return "", ctx.Err()
+}
+
+func TestServer_Wait(t *testing.T) {
+	converter := &blockingConverter{started: make(chan struct{})}
+	s := &Server{Converter: converter, Dir: t.TempDir(), Workers: 1}
+	ctx, cancel := context.WithCancel(context.Background())
+	s.Start(ctx)
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	createJob(t, ts, "", "hello")
+	<-converter.started
+	cancel()
+	s.Wait()
+	if !converter.stopped {
+		t.Error("Wait() returned before the conversion stopped")
+	}
+}
+
+func TestServer_Jobs_Multipart(t *testing.T) {
+	_, ts := newTestServer(t)
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	form.WriteField("note", "ignored")
+	part, _ := form.CreateFormFile("file", "report.txt")
+	part.Write([]byte("from a form"))
+	form.Close()
+
+	resp, err := http.Post(ts.URL+"/jobs", form.FormDataContentType(), &body)
+	if err != nil {
+		t.Fatalf("POST /jobs failed: %v", err)
+	}
+	var job JobStatus
+	json.NewDecoder(resp.Body).Decode(&job)
+	resp.Body.Close()
+	if job.Name != "report.txt" {
+		t.Errorf("job name = %q, want report.txt", job.Name)
+	}
+	if status := waitJob(t, ts, job.ID); status.State != StateSucceeded {
+		t.Errorf("job status = %+v, want succeeded", status)
+	}
+}
+
+func TestServer_Jobs_MaxUploadSize(t *testing.T) {
+	s, ts := newTestServer(t)
+	s.MaxUploadSize = 4
+
+	resp, err := http.Post(ts.URL+"/jobs", "text/plain", strings.NewReader("too large"))
+	if err != nil || resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("POST /jobs = %v, %v, want 413", resp, err)
+	}
+	if entries, _ := os.ReadDir(s.Dir); len(entries) != 0 {
+		t.Errorf("the refused upload left %d files", len(entries))
+	}
+}
+
+func TestServer_Token(t *testing.T) {
+	s, ts := newTestServer(t)
+	s.Token = "secret"
+
+	for _, authorization := range []string{"", "Bearer wrong", "Basic secret"} {
+		req, _ := http.NewRequest(http.MethodPost, ts.URL+"/jobs", strings.NewReader("hello"))
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil || resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("POST /jobs with %q = %v, %v, want 401", authorization, resp, err)
+		}
+	}
+	if entries, _ := os.ReadDir(s.Dir); len(entries) != 0 {
+		t.Errorf("the refused uploads left %d files", len(entries))
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, ts.URL+"/jobs?name=notes.txt", strings.NewReader("hello"))
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil || resp.StatusCode != http.StatusAccepted {
+		t.Errorf("POST /jobs with the token = %v, %v, want 202", resp, err)
+	}
+}
+
+func TestServer_Middleware(t *testing.T) {
+	s := &Server{Converter: upperConverter{}, Dir: t.TempDir(), MaxAttempts: 1}
+	s.Middleware = func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("X-Api-Key") != "key" {
+				http.Error(w, "forbidden", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	if resp, err := http.Get(ts.URL + "/jobs/unknown"); err != nil || resp.StatusCode != http.StatusForbidden {
+		t.Errorf("GET /jobs/unknown without the key = %v, %v, want 403", resp, err)
+	}
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/jobs/unknown", nil)
+	req.Header.Set("X-Api-Key", "key")
+	if resp, err := http.DefaultClient.Do(req); err != nil || resp.StatusCode != http.StatusNotFound {
+		t.Errorf("GET /jobs/unknown with the key = %v, %v, want 404", resp, err)
+	}
+}
@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ValueKind is the type of a table value, inferred from its text.
+type ValueKind int
+
+const (
+	KindText ValueKind = iota
+	KindNumber
+	KindDate
+)
+
+// dateLayouts are the date and time layouts recognized in table values.
+var dateLayouts = []string{
+	time.DateOnly,
+	time.DateTime,
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006/01/02",
+	"01/02/2006",
+	"1/2/2006",
+	"02.01.2006",
+}
+
+var (
+	// plainNumber matches unsigned decimal numbers, with an optional exponent.
+	plainNumber = regexp.MustCompile(`^(\d+\.?\d*|\.\d+)([eE][+-]?\d+)?$`)
+
+	// thousandsNumber matches unsigned numbers with comma thousands separators.
+	thousandsNumber = regexp.MustCompile(`^\d{1,3}(,\d{3})+(\.\d+)?$`)
+)
+
+// KindOf returns whether a value reads as a number, a date or text. Numbers
+// may carry a sign, a currency symbol, a percent sign or the parentheses of
+// accounting negatives, as in financial exports.
+func KindOf(value string) ValueKind {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return KindText
+	}
+	if isNumber(value) {
+		return KindNumber
+	}
+	for _, layout := range dateLayouts {
+		if _, err := time.Parse(layout, value); err == nil {
+			return KindDate
+		}
+	}
+	return KindText
+}
+
+func isNumber(value string) bool {
+	if strings.HasPrefix(value, "(") && strings.HasSuffix(value, ")") {
+		value = value[1 : len(value)-1]
+	}
+	value = strings.TrimSuffix(value, "%")
+	value = strings.TrimLeft(value, "+-")
+	for _, symbol := range []string{"$", "€", "£", "¥"} {
+		value = strings.TrimPrefix(value, symbol)
+	}
+	value = strings.TrimSpace(value)
+	return plainNumber.MatchString(value) || thousandsNumber.MatchString(value)
+}
+
+// ColumnKind returns the kind shared by the non-empty values of a column,
+// KindText when they differ or the column is empty.
+func ColumnKind(rows [][]string, col int) ValueKind {
+	kind, seen := KindText, false
+	for _, row := range rows {
+		if col >= len(row) || strings.TrimSpace(row[col]) == "" {
+			continue
+		}
+		value := KindOf(row[col])
+		if !seen {
+			kind, seen = value, true
+		}
+		if value != kind {
+			return KindText
+		}
+	}
+	return kind
+}
+
+// InferAlignment returns the alignment of the columns of a table from its
+// data rows: right for columns of numbers, centered for columns of dates and
+// the default for the others.
+func InferAlignment(rows [][]string, columns int) []Alignment {
+	align := make([]Alignment, columns)
+	for col := range align {
+		switch ColumnKind(rows, col) {
+		case KindNumber:
+			align[col] = AlignRight
+		case KindDate:
+			align[col] = AlignCenter
+		}
+	}
+	return align
+}
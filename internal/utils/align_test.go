@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestKindOf(t *testing.T) {
+	tests := map[string]ValueKind{
+		"42":         KindNumber,
+		"-3.25":      KindNumber,
+		"1e6":        KindNumber,
+		"1,234,567":  KindNumber,
+		"$1,200.00":  KindNumber,
+		"-€5":        KindNumber,
+		"(1,200.50)": KindNumber,
+		"12.5%":      KindNumber,
+		"12,34":      KindText,
+		"NaN":        KindText,
+		"Inf":        KindText,
+		"$":          KindText,
+		"()":         KindText,
+		"2024-01-31": KindDate,
+		"31.01.2024": KindDate,
+		"1/2/2024":   KindDate,
+		"Widget":     KindText,
+		"":           KindText,
+	}
+	for value, want := range tests {
+		if got := KindOf(value); got != want {
+			t.Errorf("KindOf(%q) = %d, want %d", value, got, want)
+		}
+	}
+}
+
+func TestInferAlignment(t *testing.T) {
+	rows := [][]string{
+		{"2024-01-01", "Rent", "$1,200.00", ""},
+		{"2024-01-15", "Coffee", "(3.50)", "x"},
+		{"", "42", "", "1"},
+	}
+	expected := []Alignment{AlignCenter, AlignDefault, AlignRight, AlignDefault, AlignDefault}
+	if got := InferAlignment(rows, 5); !reflect.DeepEqual(got, expected) {
+		t.Errorf("InferAlignment() = %v, want %v", got, expected)
+	}
+}
+
+func TestTableWriter_InferAlign(t *testing.T) {
+	var buf strings.Builder
+	table := NewTableWriter(&buf)
+	table.InferAlign = true
+	table.Align = []Alignment{AlignLeft}
+	table.WriteRow([]string{"Amount", "Balance", "Item"})
+	for range alignSampleRows - 2 {
+		table.WriteRow([]string{"1", "2", "x"})
+	}
+	if buf.Len() > 0 {
+		t.Fatalf("TableWriter wrote %q before the sample was complete", buf.String())
+	}
+	table.WriteRow([]string{"1", "2", "x"})
+
+	// Rows after the sample are streamed
+	table.WriteRow([]string{"3", "text", "y"})
+	lines := strings.Split(buf.String(), "\n")
+	if lines[1] != "| :--- | ---: | --- |" {
+		t.Errorf("TableWriter separator = %q, want the inferred alignment", lines[1])
+	}
+	if len(lines) != alignSampleRows+3 || lines[len(lines)-2] != "| 3 | text | y |" {
+		t.Errorf("TableWriter wrote %q, want the rows written so far", buf.String())
+	}
+
+	// Short tables are written on Flush
+	buf.Reset()
+	table = NewTableWriter(&buf)
+	table.InferAlign = true
+	table.WriteRow([]string{"Date", "Total"})
+	table.WriteRow([]string{"2024-01-01", "10"})
+	table.Flush()
+	expected := "| Date | Total |\n| :---: | ---: |\n| 2024-01-01 | 10 |\n"
+	if buf.String() != expected {
+		t.Errorf("TableWriter wrote %q, want %q", buf.String(), expected)
+	}
+}
@@ -0,0 +1,273 @@
+package utils
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// BlockType is the kind of a block of a markdown document.
+type BlockType string
+
+const (
+	BlockHeading   BlockType = "heading"
+	BlockParagraph BlockType = "paragraph"
+	BlockList      BlockType = "list"
+	BlockTable     BlockType = "table"
+	BlockCode      BlockType = "code"
+	BlockQuote     BlockType = "quote"
+	BlockHTML      BlockType = "html"
+	BlockRule      BlockType = "rule"
+)
+
+// Block is a top-level block of a markdown document, with its position as
+// 1-based line numbers of the document.
+type Block struct {
+	Type BlockType `json:"type"`
+
+	// Level is the level of headings.
+	Level int `json:"level,omitempty"`
+
+	// Language is the info string of code blocks.
+	Language string `json:"language,omitempty"`
+
+	// Text is the plain text of the block, without markdown syntax. The
+	// items of lists and the rows of tables are on lines of their own, and
+	// table cells are separated by tabs.
+	Text string `json:"text"`
+
+	// Items holds the plain text of the items of lists, and Rows the cells
+	// of tables, header row first.
+	Items []string   `json:"items,omitempty"`
+	Rows  [][]string `json:"rows,omitempty"`
+
+	// Markdown is the source of the block.
+	Markdown string `json:"-"`
+
+	StartLine int `json:"start_line"`
+	EndLine   int `json:"end_line"`
+}
+
+var (
+	listItem      = regexp.MustCompile(`^(\s*)([-*+]|\d{1,9}[.)])\s+`)
+	thematicBreak = regexp.MustCompile(`^ {0,3}(-( *-){2,}|\*( *\*){2,}|_( *_){2,}) *$`)
+	tableDivider  = regexp.MustCompile(`^\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?$`)
+)
+
+// ParseBlocks splits a markdown document, such as the output of the
+// converters, into its top-level blocks. A front matter block is skipped.
+// Nested blocks, such as code inside list items, belong to their parent
+// block.
+func ParseBlocks(markdown string) []Block {
+	lines := strings.Split(strings.TrimSuffix(markdown, "\n"), "\n")
+	start := 0
+	if fields, body := SplitFrontMatter(markdown); fields != nil {
+		start = strings.Count(markdown[:len(markdown)-len(body)], "\n")
+	}
+
+	var blocks []Block
+	for i := start; i < len(lines); {
+		line := lines[i]
+		trimmed := strings.TrimLeft(line, " ")
+		end := i + 1
+		block := Block{}
+
+		switch {
+		case strings.TrimSpace(line) == "":
+			i++
+			continue
+		case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+			fence := trimmed[:3]
+			for end < len(lines) && !strings.HasPrefix(strings.TrimLeft(lines[end], " "), fence) {
+				end++
+			}
+			block.Type = BlockCode
+			block.Language = strings.TrimSpace(strings.TrimLeft(trimmed, fence[:1]))
+			block.Text = strings.Join(lines[i+1:end], "\n")
+			end = min(end+1, len(lines))
+		case thematicBreak.MatchString(line):
+			block.Type = BlockRule
+		default:
+			if level, ok := headingLevel(trimmed); ok {
+				block.Type = BlockHeading
+				block.Level = level
+				block.Text = PlainText(strings.TrimRight(strings.TrimSpace(trimmed[level:]), "#"))
+				break
+			}
+			switch {
+			case strings.HasPrefix(trimmed, "|"):
+				for end < len(lines) && strings.HasPrefix(strings.TrimLeft(lines[end], " "), "|") {
+					end++
+				}
+				block.Type = BlockTable
+				for _, row := range lines[i:end] {
+					if tableDivider.MatchString(strings.TrimSpace(row)) {
+						continue
+					}
+					cells := splitTableRow(row)
+					for j, cell := range cells {
+						cells[j] = PlainText(cell)
+					}
+					block.Rows = append(block.Rows, cells)
+				}
+			case strings.HasPrefix(trimmed, ">"):
+				end = blockEnd(lines, end, func(l string) bool { return strings.HasPrefix(strings.TrimLeft(l, " "), ">") })
+				block.Type = BlockQuote
+				var quoted []string
+				for _, l := range lines[i:end] {
+					l = strings.TrimPrefix(strings.TrimLeft(l, " "), ">")
+					quoted = append(quoted, strings.TrimPrefix(l, " "))
+				}
+				block.Text = PlainText(strings.Join(quoted, "\n"))
+			case listItem.MatchString(line) && listItem.FindStringSubmatch(line)[1] == "":
+				end = listEnd(lines, end)
+				block.Type = BlockList
+				for _, l := range lines[i:end] {
+					if m := listItem.FindStringSubmatch(l); m != nil && m[1] == "" {
+						block.Items = append(block.Items, PlainText(l[len(m[0]):]))
+					} else if strings.TrimSpace(l) != "" && len(block.Items) > 0 {
+						item := strings.TrimSpace(listItem.ReplaceAllString(l, ""))
+						block.Items[len(block.Items)-1] += "\n" + PlainText(item)
+					}
+				}
+			case strings.HasPrefix(trimmed, "<"):
+				if !strings.HasPrefix(trimmed, "<!--") || !strings.HasSuffix(strings.TrimSpace(trimmed), "-->") {
+					end = blockEnd(lines, end, func(l string) bool { return !startsBlock(l) })
+				}
+				block.Type = BlockHTML
+				block.Text = PlainText(strings.Join(lines[i:end], "\n"))
+			default:
+				end = blockEnd(lines, end, func(l string) bool { return !startsBlock(l) })
+				block.Type = BlockParagraph
+				block.Text = PlainText(strings.Join(lines[i:end], "\n"))
+			}
+		}
+
+		switch block.Type {
+		case BlockList:
+			block.Text = strings.Join(block.Items, "\n")
+		case BlockTable:
+			rows := make([]string, len(block.Rows))
+			for j, row := range block.Rows {
+				rows[j] = strings.Join(row, "\t")
+			}
+			block.Text = strings.Join(rows, "\n")
+		}
+		block.Markdown = strings.Join(lines[i:end], "\n")
+		block.StartLine, block.EndLine = i+1, end
+		blocks = append(blocks, block)
+		i = end
+	}
+	return blocks
+}
+
+// blockEnd returns the index of the first line from i that is blank or for
+// which continues returns false.
+func blockEnd(lines []string, i int, continues func(string) bool) int {
+	for i < len(lines) && strings.TrimSpace(lines[i]) != "" && continues(lines[i]) {
+		i++
+	}
+	return i
+}
+
+// listEnd returns the index of the line after a list, which goes on over
+// blank lines while the next line is an item or indented.
+func listEnd(lines []string, i int) int {
+	for i < len(lines) {
+		line := lines[i]
+		if strings.TrimSpace(line) == "" {
+			next := i + 1
+			for next < len(lines) && strings.TrimSpace(lines[next]) == "" {
+				next++
+			}
+			if next == len(lines) || !(listItem.MatchString(lines[next]) || strings.HasPrefix(lines[next], "  ")) {
+				return i
+			}
+			i = next
+			continue
+		}
+		if !listItem.MatchString(line) && !strings.HasPrefix(line, "  ") && startsBlock(line) {
+			return i
+		}
+		i++
+	}
+	return i
+}
+
+// startsBlock reports whether a line starts a block other than a paragraph.
+func startsBlock(line string) bool {
+	trimmed := strings.TrimLeft(line, " ")
+	if _, ok := headingLevel(trimmed); ok {
+		return true
+	}
+	return strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") ||
+		strings.HasPrefix(trimmed, "|") || strings.HasPrefix(trimmed, ">") ||
+		thematicBreak.MatchString(line) || listItem.MatchString(line)
+}
+
+// splitTableRow returns the cells of a pipe table row, splitting at pipes
+// not escaped with a backslash.
+func splitTableRow(row string) []string {
+	row = strings.TrimSpace(row)
+	row = strings.TrimPrefix(row, "|")
+	if strings.HasSuffix(row, "|") && !strings.HasSuffix(row, `\|`) {
+		row = row[:len(row)-1]
+	}
+	var cells []string
+	var cell strings.Builder
+	for i := 0; i < len(row); i++ {
+		switch {
+		case row[i] == '\\' && i+1 < len(row) && row[i+1] == '|':
+			cell.WriteString(`\|`)
+			i++
+		case row[i] == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(row[i])
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+var (
+	inlineImage    = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	inlineLink     = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	inlineFootnote = regexp.MustCompile(`\[\^[^\]]+\]`)
+	inlineBreak    = regexp.MustCompile(`(?i)<br\s*/?>`)
+	inlineTag      = regexp.MustCompile(`</?[a-zA-Z][^>]*>|<!--.*?-->`)
+	inlineMarks    = regexp.MustCompile(`\*\*|__|~~|==|` + "`+")
+	inlineEmphasis = regexp.MustCompile(`(^|[^\w\\])[*_]|[*_]($|[^\w])`)
+	inlineEscape   = regexp.MustCompile(`\\([\\` + "`" + `*_{}\[\]()#+\-.!|~^=<>])`)
+)
+
+// escapedBase is the private use code point escaped ASCII characters are
+// moved to while PlainText strips syntax.
+const escapedBase = 0xE000
+
+// PlainText strips the inline markdown syntax of converter output, such as
+// emphasis, links and HTML tags, from text, keeping link labels and image
+// descriptions.
+func PlainText(text string) string {
+	// Escaped characters are moved to the private use area until the syntax
+	// is stripped, so that they are not taken for syntax.
+	text = inlineEscape.ReplaceAllStringFunc(text, func(m string) string {
+		return string(rune(escapedBase + int(m[1])))
+	})
+	text = inlineImage.ReplaceAllString(text, "$1")
+	text = inlineLink.ReplaceAllString(text, "$1")
+	text = inlineFootnote.ReplaceAllString(text, "")
+	text = inlineBreak.ReplaceAllString(text, "\n")
+	text = inlineTag.ReplaceAllString(text, "")
+	text = inlineMarks.ReplaceAllString(text, "")
+	text = inlineEmphasis.ReplaceAllStringFunc(text, func(m string) string {
+		return strings.NewReplacer("*", "", "_", "").Replace(m)
+	})
+	text = strings.Map(func(r rune) rune {
+		if r >= escapedBase && r < escapedBase+128 {
+			return r - escapedBase
+		}
+		return r
+	}, text)
+	return strings.TrimSpace(html.UnescapeString(text))
+}
@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseBlocks(t *testing.T) {
+	input := "---\ntitle: Report\n---\n\n" +
+		"# The **Report**\n\n" +
+		"First line of a [paragraph](https://example.com)\nsecond line.\n\n" +
+		"- one\n- two\n  continued\n\n- three\n\n" +
+		"| Name | Value |\n| --- | ---: |\n| a\\|b | 1 |\n\n" +
+		"```go\nx := 1\n```\n\n" +
+		"> quoted *text*\n\n" +
+		"---\n" +
+		"<!-- Page 2 -->\n# Next\n"
+
+	blocks := ParseBlocks(input)
+	expected := []Block{
+		{Type: BlockHeading, Level: 1, Text: "The Report", StartLine: 5, EndLine: 5},
+		{Type: BlockParagraph, Text: "First line of a paragraph\nsecond line.", StartLine: 7, EndLine: 8},
+		{Type: BlockList, Text: "one\ntwo\ncontinued\nthree", Items: []string{"one", "two\ncontinued", "three"}, StartLine: 10, EndLine: 14},
+		{Type: BlockTable, Text: "Name\tValue\na|b\t1", Rows: [][]string{{"Name", "Value"}, {"a|b", "1"}}, StartLine: 16, EndLine: 18},
+		{Type: BlockCode, Language: "go", Text: "x := 1", StartLine: 20, EndLine: 22},
+		{Type: BlockQuote, Text: "quoted text", StartLine: 24, EndLine: 24},
+		{Type: BlockRule, StartLine: 26, EndLine: 26},
+		{Type: BlockHTML, StartLine: 27, EndLine: 27},
+		{Type: BlockHeading, Level: 1, Text: "Next", StartLine: 28, EndLine: 28},
+	}
+
+	if len(blocks) != len(expected) {
+		t.Fatalf("ParseBlocks() returned %d blocks, want %d: %+v", len(blocks), len(expected), blocks)
+	}
+	for i := range blocks {
+		blocks[i].Markdown = ""
+		if !reflect.DeepEqual(blocks[i], expected[i]) {
+			t.Errorf("ParseBlocks()[%d] = %+v, want %+v", i, blocks[i], expected[i])
+		}
+	}
+}
+
+func TestPlainText(t *testing.T) {
+	cases := map[string]string{
+		"**bold** and _em_ text":           "bold and em text",
+		"![chart](chart.png) [link](a.md)": "chart link",
+		"snake_case stays":                 "snake_case stays",
+		`2 \* 3 \_ 4`:                      "2 * 3 _ 4",
+		"line<br>break &amp; `code`":       "line\nbreak & code",
+		"note[^1] <span>x</span>":          "note x",
+		"~~gone~~ ==mark==":                "gone mark",
+	}
+	for input, expected := range cases {
+		if got := PlainText(input); got != expected {
+			t.Errorf("PlainText(%q) = %q, want %q", input, got, expected)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Flavor selects the markdown dialect used for formatting that has no
+// CommonMark equivalent, such as underline or highlight.
+type Flavor int
+
+const (
+	// FlavorGFM renders unsupported inline formatting as inline HTML tags.
+	FlavorGFM Flavor = iota
+
+	// FlavorExtended uses the extended inline syntax understood by Pandoc and
+	// common markdown-it plugins (==mark==, ^sup^, ~sub~).
+	FlavorExtended
+)
+
+// EscapeMarkdown escapes the characters of inline text that markdown would
+// read as formatting: backslashes, asterisks, backticks, brackets, tildes and
+// the underscores that are not inside a word. FlavorExtended also escapes
+// carets and doubled equal signs, which mark superscript and highlight.
+func EscapeMarkdown(text string, flavor Flavor) string {
+	runes := []rune(text)
+	var b strings.Builder
+	for i, r := range runes {
+		escaped := false
+		switch r {
+		case '\\', '*', '`', '[', ']', '~':
+			escaped = true
+		case '_':
+			escaped = i == 0 || i == len(runes)-1 || !isWordRune(runes[i-1]) || !isWordRune(runes[i+1])
+		case '^':
+			escaped = flavor == FlavorExtended
+		case '=':
+			escaped = flavor == FlavorExtended && (i > 0 && runes[i-1] == '=' || i < len(runes)-1 && runes[i+1] == '=')
+		}
+		if escaped {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// EscapeCell escapes the pipes of the text of a table cell, which would
+// otherwise end the cell.
+func EscapeCell(text string) string {
+	return strings.ReplaceAll(text, "|", `\|`)
+}
+
+// EscapeURL escapes the parentheses of a link destination, which would
+// otherwise end the link.
+func EscapeURL(url string) string {
+	return strings.NewReplacer("(", `\(`, ")", `\)`).Replace(url)
+}
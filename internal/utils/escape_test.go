@@ -0,0 +1,36 @@
+package utils
+
+import "testing"
+
+func TestEscapeMarkdown(t *testing.T) {
+	tests := []struct {
+		text     string
+		flavor   Flavor
+		expected string
+	}{
+		{"plain text", FlavorGFM, "plain text"},
+		{"*bold* and `code`", FlavorGFM, `\*bold\* and \` + "`code\\`"},
+		{"[link](url)", FlavorGFM, `\[link\](url)`},
+		{`C:\path ~draft~`, FlavorGFM, `C:\\path \~draft\~`},
+		{"snake_case _emphasis_", FlavorGFM, `snake_case \_emphasis\_`},
+		{"x^2 == y", FlavorGFM, "x^2 == y"},
+		{"x^2 == y = z", FlavorExtended, `x\^2 \=\= y = z`},
+	}
+	for _, tt := range tests {
+		if got := EscapeMarkdown(tt.text, tt.flavor); got != tt.expected {
+			t.Errorf("EscapeMarkdown(%q, %d) = %q, want %q", tt.text, tt.flavor, got, tt.expected)
+		}
+	}
+}
+
+func TestEscapeCell(t *testing.T) {
+	if got := EscapeCell("a | b"); got != `a \| b` {
+		t.Errorf("EscapeCell() = %q, want %q", got, `a \| b`)
+	}
+}
+
+func TestEscapeURL(t *testing.T) {
+	if got := EscapeURL("https://en.wikipedia.org/wiki/Go_(language)"); got != `https://en.wikipedia.org/wiki/Go_\(language\)` {
+		t.Errorf("EscapeURL() = %q", got)
+	}
+}
@@ -0,0 +1,105 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+)
+
+// FrontMatterField is a key of a YAML front matter block, with a single
+// value or a list of values.
+type FrontMatterField struct {
+	Key   string
+	Value string
+
+	// List holds the values of list fields, such as keywords, instead of Value.
+	List []string
+}
+
+// FrontMatter builds a YAML front matter block from the fields in order,
+// leaving out fields without a value. It returns an empty string when no
+// field has a value.
+func FrontMatter(fields []FrontMatterField) string {
+	var b strings.Builder
+	for _, field := range fields {
+		value := strings.TrimSpace(field.Value)
+		var list []string
+		for _, item := range field.List {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+
+		switch {
+		case len(list) > 0:
+			b.WriteString(field.Key + ":\n")
+			for _, item := range list {
+				b.WriteString("  - " + yamlScalar(item) + "\n")
+			}
+		case value != "":
+			b.WriteString(field.Key + ": " + yamlScalar(value) + "\n")
+		}
+	}
+	if b.Len() == 0 {
+		return ""
+	}
+	return "---\n" + b.String() + "---\n"
+}
+
+// SplitFrontMatter separates the YAML front matter block written by
+// FrontMatter from the rest of a document. It returns no fields and the
+// document unchanged when the document does not start with front matter.
+func SplitFrontMatter(markdown string) ([]FrontMatterField, string) {
+	block, ok := strings.CutPrefix(markdown, "---\n")
+	if !ok {
+		return nil, markdown
+	}
+	block, body, ok := strings.Cut(block, "\n---\n")
+	if !ok {
+		return nil, markdown
+	}
+
+	var fields []FrontMatterField
+	for _, line := range strings.Split(block, "\n") {
+		if item, ok := strings.CutPrefix(line, "  - "); ok {
+			if len(fields) > 0 {
+				fields[len(fields)-1].List = append(fields[len(fields)-1].List, yamlValue(item))
+			}
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		fields = append(fields, FrontMatterField{Key: key, Value: yamlValue(strings.TrimSpace(value))})
+	}
+	return fields, strings.TrimPrefix(body, "\n")
+}
+
+// yamlValue reads a scalar written by yamlScalar.
+func yamlValue(scalar string) string {
+	if strings.HasPrefix(scalar, `"`) {
+		if value, err := strconv.Unquote(scalar); err == nil {
+			return value
+		}
+	}
+	return scalar
+}
+
+// yamlScalar returns a value as a plain YAML scalar, or double-quoted when a
+// plain scalar would be read differently, such as numbers, booleans and text
+// with YAML indicators.
+func yamlScalar(value string) string {
+	if strings.ContainsAny(value[:1], "-?:,[]{}#&*!|>'\"%@`") ||
+		strings.Contains(value, ": ") || strings.Contains(value, " #") ||
+		strings.ContainsAny(value, "\n\r\t\\") || strings.HasSuffix(value, ":") {
+		return strconv.Quote(value)
+	}
+	switch strings.ToLower(value) {
+	case "true", "false", "yes", "no", "on", "off", "null", "~":
+		return strconv.Quote(value)
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return strconv.Quote(value)
+	}
+	return value
+}
@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFrontMatter(t *testing.T) {
+	result := FrontMatter([]FrontMatterField{
+		{Key: "title", Value: "Annual Report: 2024"},
+		{Key: "author", Value: "Jane Doe"},
+		{Key: "subject", Value: " "},
+		{Key: "keywords", List: []string{"finance", "", "2024"}},
+		{Key: "created", Value: "2024-01-02T15:04:05+01:00"},
+	})
+
+	expected := "---\ntitle: \"Annual Report: 2024\"\nauthor: Jane Doe\nkeywords:\n  - finance\n  - \"2024\"\ncreated: 2024-01-02T15:04:05+01:00\n---\n"
+	if result != expected {
+		t.Errorf("FrontMatter() = %q, want %q", result, expected)
+	}
+}
+
+func TestFrontMatter_Empty(t *testing.T) {
+	if result := FrontMatter([]FrontMatterField{{Key: "title"}, {Key: "keywords", List: []string{""}}}); result != "" {
+		t.Errorf("FrontMatter() without values = %q, want empty string", result)
+	}
+}
+
+func TestYamlScalar(t *testing.T) {
+	cases := map[string]string{
+		"Plain text":   "Plain text",
+		"- dash":       `"- dash"`,
+		"true":         `"true"`,
+		"3.5":          `"3.5"`,
+		"C# notes":     "C# notes",
+		"a #comment":   `"a #comment"`,
+		"two\nlines":   `"two\nlines"`,
+		`say "hi"`:     `say "hi"`,
+		`"quoted"`:     `"\"quoted\""`,
+		"ends with:":   `"ends with:"`,
+		"Über & mehr!": "Über & mehr!",
+	}
+	for input, expected := range cases {
+		if got := yamlScalar(input); got != expected {
+			t.Errorf("yamlScalar(%q) = %s, want %s", input, got, expected)
+		}
+	}
+}
+
+func TestSplitFrontMatter(t *testing.T) {
+	fields := []FrontMatterField{
+		{Key: "title", Value: "Annual Report: 2024"},
+		{Key: "keywords", List: []string{"finance", "2024"}},
+		{Key: "author", Value: "Jane Doe"},
+	}
+
+	result, body := SplitFrontMatter(FrontMatter(fields) + "\n# Report\n")
+	if !reflect.DeepEqual(result, fields) {
+		t.Errorf("SplitFrontMatter() fields = %v, want %v", result, fields)
+	}
+	if body != "# Report\n" {
+		t.Errorf("SplitFrontMatter() body = %q, want %q", body, "# Report\n")
+	}
+
+	for _, input := range []string{"# Report\n", "---\ntitle: unterminated\n"} {
+		if result, body := SplitFrontMatter(input); result != nil || body != input {
+			t.Errorf("SplitFrontMatter(%q) = %v, %q, want the document unchanged", input, result, body)
+		}
+	}
+}
@@ -0,0 +1,264 @@
+package utils
+
+import (
+	"cmp"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// RenderHTML renders a markdown document as a standalone HTML page, to
+// preview the output of the converters in a browser. The front matter
+// becomes a table of metadata. Raw HTML is kept only for the tags the
+// converters write, such as <br>, <u> and tables, without attributes other
+// than alignment and cell spans.
+func RenderHTML(markdown string) string {
+	fields, _ := SplitFrontMatter(markdown)
+	blocks := ParseBlocks(markdown)
+
+	title := ""
+	for _, field := range fields {
+		if field.Key == "title" {
+			title = field.Value
+		}
+	}
+	for _, block := range blocks {
+		if title == "" && block.Type == BlockHeading {
+			title = block.Text
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	fmt.Fprintf(&b, "<title>%s</title>\n", html.EscapeString(cmp.Or(title, "Document")))
+	b.WriteString("<style>\n" + previewStyle + "</style>\n</head>\n<body>\n")
+	if len(fields) > 0 {
+		b.WriteString("<table class=\"metadata\">\n")
+		for _, field := range fields {
+			value := field.Value
+			if field.List != nil {
+				value = strings.Join(field.List, ", ")
+			}
+			fmt.Fprintf(&b, "<tr><th>%s</th><td>%s</td></tr>\n", html.EscapeString(field.Key), html.EscapeString(value))
+		}
+		b.WriteString("</table>\n")
+	}
+	writeHTMLBlocks(&b, blocks)
+	b.WriteString("</body>\n</html>\n")
+	return b.String()
+}
+
+const previewStyle = `body { max-width: 50em; margin: 2em auto; padding: 0 1em; font-family: sans-serif; line-height: 1.5; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 0.2em 0.5em; }
+pre { background: #f4f4f4; padding: 0.5em; overflow-x: auto; }
+blockquote { border-left: 4px solid #ccc; margin-left: 0; padding-left: 1em; color: #555; }
+table.metadata { margin-bottom: 2em; font-size: 0.9em; }
+`
+
+// writeHTMLBlocks writes the HTML of blocks parsed by ParseBlocks.
+func writeHTMLBlocks(b *strings.Builder, blocks []Block) {
+	slugs := make(map[string]int)
+	for _, block := range blocks {
+		lines := strings.Split(block.Markdown, "\n")
+		switch block.Type {
+		case BlockHeading:
+			trimmed := strings.TrimLeft(lines[0], " ")
+			text := strings.TrimSpace(strings.TrimRight(strings.TrimSpace(trimmed[block.Level:]), "#"))
+			id := Slug(block.Text)
+			if n := slugs[id]; n > 0 {
+				slugs[id]++
+				id = fmt.Sprintf("%s-%d", id, n)
+			} else {
+				slugs[id] = 1
+			}
+			fmt.Fprintf(b, "<h%d id=\"%s\">%s</h%d>\n", block.Level, html.EscapeString(id), InlineHTML(text), block.Level)
+		case BlockParagraph:
+			fmt.Fprintf(b, "<p>%s</p>\n", InlineHTML(strings.Join(lines, "\n")))
+		case BlockList:
+			writeHTMLList(b, lines)
+		case BlockTable:
+			writeHTMLTable(b, lines)
+		case BlockCode:
+			class := ""
+			if lang, _, _ := strings.Cut(block.Language, " "); lang != "" {
+				class = fmt.Sprintf(" class=\"language-%s\"", html.EscapeString(lang))
+			}
+			fmt.Fprintf(b, "<pre><code%s>%s\n</code></pre>\n", class, html.EscapeString(block.Text))
+		case BlockQuote:
+			quoted := make([]string, len(lines))
+			for i, line := range lines {
+				line = strings.TrimPrefix(strings.TrimLeft(line, " "), ">")
+				quoted[i] = strings.TrimPrefix(line, " ")
+			}
+			b.WriteString("<blockquote>\n")
+			writeHTMLBlocks(b, ParseBlocks(strings.Join(quoted, "\n")))
+			b.WriteString("</blockquote>\n")
+		case BlockRule:
+			b.WriteString("<hr>\n")
+		case BlockHTML:
+			if comment, ok := strings.CutPrefix(block.Markdown, "<!--"); ok && !strings.Contains(block.Markdown, "\n") {
+				// Page and slide markers
+				fmt.Fprintf(b, "<!--%s-->\n", html.EscapeString(strings.TrimSuffix(comment, "-->")))
+				continue
+			}
+			b.WriteString(safeHTML(block.Markdown) + "\n")
+		}
+	}
+}
+
+// writeHTMLList writes a list, ordered when its first marker is a number.
+// Lines continuing an item, including nested items, are joined to it.
+func writeHTMLList(b *strings.Builder, lines []string) {
+	tag := "ul"
+	if m := listItem.FindStringSubmatch(lines[0]); m != nil && m[2][0] >= '0' && m[2][0] <= '9' {
+		tag = "ol"
+	}
+
+	var items [][]string
+	for _, line := range lines {
+		if m := listItem.FindStringSubmatch(line); m != nil && m[1] == "" {
+			items = append(items, []string{line[len(m[0]):]})
+		} else if strings.TrimSpace(line) != "" && len(items) > 0 {
+			items[len(items)-1] = append(items[len(items)-1], strings.TrimSpace(line))
+		}
+	}
+
+	fmt.Fprintf(b, "<%s>\n", tag)
+	for _, item := range items {
+		parts := make([]string, len(item))
+		for i, part := range item {
+			parts[i] = InlineHTML(part)
+		}
+		fmt.Fprintf(b, "<li>%s</li>\n", strings.Join(parts, "<br>\n"))
+	}
+	fmt.Fprintf(b, "</%s>\n", tag)
+}
+
+// writeHTMLTable writes a pipe table, with the alignment of its divider row.
+func writeHTMLTable(b *strings.Builder, lines []string) {
+	var align []string
+	var rows [][]string
+	for _, line := range lines {
+		if tableDivider.MatchString(strings.TrimSpace(line)) {
+			for _, cell := range splitTableRow(line) {
+				switch {
+				case strings.HasPrefix(cell, ":") && strings.HasSuffix(cell, ":"):
+					align = append(align, "center")
+				case strings.HasSuffix(cell, ":"):
+					align = append(align, "right")
+				default:
+					align = append(align, "")
+				}
+			}
+			continue
+		}
+		rows = append(rows, splitTableRow(line))
+	}
+
+	b.WriteString("<table>\n")
+	for i, row := range rows {
+		tag := "td"
+		if i == 0 && align != nil {
+			tag = "th"
+		}
+		b.WriteString("<tr>")
+		for j, cell := range row {
+			attr := ""
+			if j < len(align) && align[j] != "" {
+				attr = fmt.Sprintf(" style=\"text-align: %s\"", align[j])
+			}
+			fmt.Fprintf(b, "<%s%s>%s</%s>", tag, attr, InlineHTML(cell), tag)
+		}
+		b.WriteString("</tr>\n")
+	}
+	b.WriteString("</table>\n")
+}
+
+var (
+	htmlCodeSpan  = regexp.MustCompile("(`+)(.+?)`+")
+	htmlImage     = regexp.MustCompile(`!\[([^\]]*)\]\(([^)\s]*)[^)]*\)`)
+	htmlLink      = regexp.MustCompile(`\[([^\]]*)\]\(([^)\s]*)[^)]*\)`)
+	htmlFootnote  = regexp.MustCompile(`\[\^([^\]]+)\]`)
+	htmlStrong    = regexp.MustCompile(`\*\*(.+?)\*\*|__(.+?)__`)
+	htmlEmphasis  = regexp.MustCompile(`(^|[^\w*])\*([^*\s](?:[^*]*[^*\s])?)\*|(^|[^\w])_([^_\s](?:[^_]*[^_\s])?)_($|[^\w])`)
+	htmlStrike    = regexp.MustCompile(`~~(.+?)~~`)
+	htmlMark      = regexp.MustCompile(`==(.+?)==`)
+	htmlSafeTag   = regexp.MustCompile(`&lt;(/?)(br|u|mark|sup|sub|s|del|ins|em|strong|b|i|code|kbd|table|thead|tbody|tr|th|td|p|div|span|details|summary)((?: (?:colspan|rowspan|align)=&#34;[\w ]*&#34;)*)\s*/?&gt;`)
+	htmlAttrQuote = regexp.MustCompile(`&#34;`)
+)
+
+// InlineHTML renders the inline markdown of a line of converter output as
+// HTML: code spans, links, images, emphasis, strikethrough, highlight,
+// footnote references and the inline tags safeHTML keeps.
+func InlineHTML(text string) string {
+	// Code spans and escaped characters are set aside, so that their
+	// content is not taken for syntax.
+	var spans []string
+	text = htmlCodeSpan.ReplaceAllStringFunc(text, func(m string) string {
+		sub := htmlCodeSpan.FindStringSubmatch(m)
+		spans = append(spans, "<code>"+html.EscapeString(strings.TrimSpace(sub[2]))+"</code>")
+		return string(rune(escapedBase + 128 + len(spans) - 1))
+	})
+	text = inlineEscape.ReplaceAllStringFunc(text, func(m string) string {
+		return string(rune(escapedBase + int(m[1])))
+	})
+
+	text = safeHTML(text)
+	text = htmlImage.ReplaceAllStringFunc(text, func(m string) string {
+		sub := htmlImage.FindStringSubmatch(m)
+		return fmt.Sprintf(`<img alt="%s" src="%s">`, sub[1], safeURL(sub[2]))
+	})
+	text = htmlLink.ReplaceAllStringFunc(text, func(m string) string {
+		sub := htmlLink.FindStringSubmatch(m)
+		return fmt.Sprintf(`<a href="%s">%s</a>`, safeURL(sub[2]), sub[1])
+	})
+	text = htmlFootnote.ReplaceAllString(text, "<sup>$1</sup>")
+	text = htmlStrong.ReplaceAllString(text, "<strong>$1$2</strong>")
+	text = htmlEmphasis.ReplaceAllString(text, "$1$3<em>$2$4</em>$5")
+	text = htmlStrike.ReplaceAllString(text, "<del>$1</del>")
+	text = htmlMark.ReplaceAllString(text, "<mark>$1</mark>")
+
+	return strings.Map(func(r rune) rune {
+		if r >= escapedBase && r < escapedBase+128 {
+			return r - escapedBase
+		}
+		return r
+	}, restoreSpans(text, spans))
+}
+
+// restoreSpans puts back the code spans set aside by InlineHTML.
+func restoreSpans(text string, spans []string) string {
+	if len(spans) == 0 {
+		return text
+	}
+	var b strings.Builder
+	for _, r := range text {
+		if i := int(r) - escapedBase - 128; i >= 0 && i < len(spans) {
+			b.WriteString(spans[i])
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// safeHTML escapes text, then restores the tags the converters write,
+// without attributes other than alignment and cell spans.
+func safeHTML(text string) string {
+	return htmlSafeTag.ReplaceAllStringFunc(html.EscapeString(text), func(m string) string {
+		return html.UnescapeString(htmlAttrQuote.ReplaceAllString(m, `"`))
+	})
+}
+
+// safeURL returns an escaped link target, or # for javascript: and other
+// script URLs.
+func safeURL(url string) string {
+	lower := strings.ToLower(html.UnescapeString(url))
+	if strings.HasPrefix(lower, "javascript:") || strings.HasPrefix(lower, "vbscript:") ||
+		(strings.HasPrefix(lower, "data:") && !strings.HasPrefix(lower, "data:image/")) {
+		return "#"
+	}
+	return url
+}
@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderHTML(t *testing.T) {
+	input := "---\ntitle: Report & Co\n---\n\n" +
+		"<!-- Page 1 -->\n# Intro\n\n" +
+		"Some **bold**, _em_ and `a<b>` text with a [link](https://example.com?a=1&b=2).\n\n" +
+		"1. one\n2. two\n\n" +
+		"| Name | Value |\n| --- | ---: |\n| x | 1 |\n\n" +
+		"```go\nif a < b {}\n```\n\n" +
+		"> quoted\n\n" +
+		"<table><tr><td colspan=\"2\" onclick=\"x()\">cell<br>two</td></tr></table>\n\n" +
+		"# Intro\n"
+
+	result := RenderHTML(input)
+	for _, expected := range []string{
+		"<title>Report &amp; Co</title>",
+		"<tr><th>title</th><td>Report &amp; Co</td></tr>",
+		"<!-- Page 1 -->",
+		`<h1 id="intro">Intro</h1>`,
+		`Some <strong>bold</strong>, <em>em</em> and <code>a&lt;b&gt;</code> text with a <a href="https://example.com?a=1&amp;b=2">link</a>.`,
+		"<ol>\n<li>one</li>\n<li>two</li>\n</ol>",
+		"<tr><th>Name</th><th style=\"text-align: right\">Value</th></tr>\n<tr><td>x</td><td style=\"text-align: right\">1</td></tr>",
+		"<pre><code class=\"language-go\">if a &lt; b {}\n</code></pre>",
+		"<blockquote>\n<p>quoted</p>\n</blockquote>",
+		"&lt;td colspan=&#34;2&#34; onclick=&#34;x()&#34;&gt;cell<br>two</td>",
+		`<h1 id="intro-1">Intro</h1>`,
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("RenderHTML() does not contain %q:\n%s", expected, result)
+		}
+	}
+}
+
+func TestInlineHTML(t *testing.T) {
+	cases := map[string]string{
+		"<script>alert(1)</script>":         "&lt;script&gt;alert(1)&lt;/script&gt;",
+		"[x](javascript:alert(1))":          `<a href="#">x</a>)`,
+		"![chart](chart.png)":               `<img alt="chart" src="chart.png">`,
+		`2 \* 3 * 4`:                        "2 * 3 * 4",
+		"snake_case_name":                   "snake_case_name",
+		"note[^1] ~~old~~ ==new== <u>u</u>": "note<sup>1</sup> <del>old</del> <mark>new</mark> <u>u</u>",
+	}
+	for input, expected := range cases {
+		if got := InlineHTML(input); got != expected {
+			t.Errorf("InlineHTML(%q) = %q, want %q", input, got, expected)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Image is an image a markdown document refers to.
+type Image struct {
+	Alt string `json:"alt,omitempty"`
+	URL string `json:"url"`
+}
+
+var (
+	markdownImage = regexp.MustCompile(`!\[([^\]]*)\]\(\s*<?([^)\s>]+)>?[^)]*\)`)
+	htmlImageTag  = regexp.MustCompile(`(?i)<img\s[^>]*>`)
+	htmlImageAttr = regexp.MustCompile(`(?i)\b(src|alt)\s*=\s*"([^"]*)"`)
+)
+
+// ExtractImages returns the images of a markdown document in order, written
+// as markdown images or HTML img tags, once per URL and ignoring lines
+// inside fenced code blocks.
+func ExtractImages(markdown string) []Image {
+	var images []Image
+	seen := make(map[string]bool)
+	add := func(image Image) {
+		if image.URL != "" && !seen[image.URL] {
+			seen[image.URL] = true
+			images = append(images, image)
+		}
+	}
+
+	inFence := false
+	for line := range strings.SplitSeq(markdown, "\n") {
+		if strings.HasPrefix(strings.TrimLeft(line, " "), "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+
+		for _, m := range markdownImage.FindAllStringSubmatch(line, -1) {
+			add(Image{Alt: m[1], URL: m[2]})
+		}
+		for _, tag := range htmlImageTag.FindAllString(line, -1) {
+			var image Image
+			for _, attr := range htmlImageAttr.FindAllStringSubmatch(tag, -1) {
+				if strings.EqualFold(attr[1], "src") {
+					image.URL = attr[2]
+				} else {
+					image.Alt = attr[2]
+				}
+			}
+			add(image)
+		}
+	}
+	return images
+}
@@ -0,0 +1,24 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractImages(t *testing.T) {
+	markdown := "# Title\n\n![Logo](images/logo.png \"Company\") and ![](data:image/gif;base64,R0lG)\n\n" +
+		"```\n![Not an image](code.png)\n```\n\n" +
+		"<table><tr><td><img alt=\"Chart\" src=\"chart.svg\"></td></tr></table>\n\n![Logo again](images/logo.png)\n"
+
+	want := []Image{
+		{Alt: "Logo", URL: "images/logo.png"},
+		{URL: "data:image/gif;base64,R0lG"},
+		{Alt: "Chart", URL: "chart.svg"},
+	}
+	if got := ExtractImages(markdown); !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtractImages() = %+v, want %+v", got, want)
+	}
+	if got := ExtractImages("No images here."); got != nil {
+		t.Errorf("ExtractImages() = %+v, want none", got)
+	}
+}
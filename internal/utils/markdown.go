@@ -2,12 +2,24 @@ package utils
 
 import (
 	"bytes"
+	"cmp"
 	"fmt"
+	"html"
+	"io"
+	"slices"
 	"strings"
+	"unicode"
 )
 
-// ToMarkdownTable converts a 2D string slice to a markdown table format.
+// ToMarkdownTable converts a 2D string slice to a markdown table format, in
+// the TableCompact style.
 func ToMarkdownTable(rows [][]string) string {
+	return TableOptions{}.ToMarkdown(rows)
+}
+
+// ToMarkdown converts a 2D string slice to a markdown table written with the
+// options.
+func (o TableOptions) ToMarkdown(rows [][]string) string {
 	if len(rows) == 0 {
 		return ""
 	}
@@ -18,39 +30,371 @@ func ToMarkdownTable(rows [][]string) string {
 	}
 
 	var buf bytes.Buffer
-	headerColCount := len(rows[0])
-
-	// Header
-	buf.WriteString("|")
-	for _, cell := range rows[0] {
-		// Escape pipe characters in cell content and trim whitespace
-		escapedCell := strings.ReplaceAll(strings.TrimSpace(cell), "|", "\\|")
-		fmt.Fprintf(&buf, " %s |", escapedCell)
-	}
-	buf.WriteString("\n|")
-
-	// Header separator
-	for range headerColCount {
-		buf.WriteString(" --- |")
-	}
-	buf.WriteString("\n")
-
-	// Data rows - only process if we have more than one row
-	if len(rows) > 1 {
-		for _, row := range rows[1:] {
-			buf.WriteString("|")
-			// Handle rows with different column counts
-			for i := range headerColCount {
-				var cell string
-				if i < len(row) {
-					// Escape pipe characters in cell content and trim whitespace
-					cell = strings.ReplaceAll(strings.TrimSpace(row[i]), "|", "\\|")
-				}
-				fmt.Fprintf(&buf, " %s |", cell)
+	table := o.NewTableWriter(&buf)
+	for _, row := range rows {
+		table.WriteRow(row)
+	}
+	table.Flush()
+
+	return buf.String()
+}
+
+// TableStyle controls the layout of markdown tables.
+type TableStyle int
+
+const (
+	// TableCompact writes each cell with a single space on either side.
+	TableCompact TableStyle = iota
+
+	// TablePretty pads the cells to the display width of their column, so
+	// the table lines up when read as plain text.
+	TablePretty
+)
+
+// TableOptions holds the layout of the tables a converter writes, given to
+// the table writers it creates. The zero value writes TableCompact tables
+// without HTML fallback or cut cells.
+type TableOptions struct {
+	Style             TableStyle
+	HTMLFallback      bool
+	HTMLFallbackWidth int
+	MaxCellWidth      int
+}
+
+// Merge returns the options with the fields set in o replaced.
+func (base TableOptions) Merge(o TableOptions) TableOptions {
+	base.Style = cmp.Or(o.Style, base.Style)
+	base.HTMLFallback = base.HTMLFallback || o.HTMLFallback
+	base.HTMLFallbackWidth = cmp.Or(o.HTMLFallbackWidth, base.HTMLFallbackWidth)
+	base.MaxCellWidth = cmp.Or(o.MaxCellWidth, base.MaxCellWidth)
+	return base
+}
+
+// TableWriter writes a markdown table one row at a time, so large tables can be
+// streamed without holding every row in memory. The first row written is the
+// header and sets the column count; later rows are padded or cut to fit it.
+type TableWriter struct {
+	// Align sets the alignment of the columns in the header separator. It
+	// must be set before the first row is written; missing columns use
+	// AlignDefault.
+	Align []Alignment
+
+	// Style sets the layout of the table, TableCompact for writers created
+	// with NewTableWriter. TablePretty tables hold their rows until
+	// Flush, since column widths are only known once every row is written.
+	Style TableStyle
+
+	// HTMLFallback writes the table as an HTML <table> when a cell holds
+	// line breaks or a code fence, or is wider than HTMLFallbackWidth
+	// characters when that is set. Rows are held until Flush to find out.
+	HTMLFallback      bool
+	HTMLFallbackWidth int
+
+	// MaxCellWidth cuts cells wider than that many characters, ending them
+	// with an ellipsis. Cells are not cut when zero.
+	MaxCellWidth int
+
+	// Footnotes numbers the footnotes holding the full text of the cells
+	// cut by MaxCellWidth, written beneath the table by Flush. The tables of
+	// a document share the counter so that labels stay unique. Cut cells get
+	// no footnote when it is nil.
+	Footnotes *int
+
+	// InferAlign right-aligns columns of numbers and centers columns of
+	// dates, inferred from the first rows. Columns given an alignment in
+	// Align keep it. The rows are held until the sample is complete.
+	InferAlign bool
+
+	w       io.Writer
+	columns int
+	started bool
+	written int
+	rows    [][]string
+	notes   []string
+}
+
+// alignSampleRows is the number of rows, the header included, held to infer
+// the alignment of the columns.
+const alignSampleRows = 21
+
+// Alignment is the alignment of a table column.
+type Alignment int
+
+const (
+	// AlignDefault leaves the alignment to the renderer, usually left.
+	AlignDefault Alignment = iota
+	AlignLeft
+	AlignCenter
+	AlignRight
+)
+
+// separator returns the header separator cell of the alignment, padded with
+// dashes to a width. It has at least three dashes.
+func (a Alignment) separator(width int) string {
+	switch a {
+	case AlignLeft:
+		return ":" + strings.Repeat("-", max(width-1, 3))
+	case AlignCenter:
+		return ":" + strings.Repeat("-", max(width-2, 3)) + ":"
+	case AlignRight:
+		return strings.Repeat("-", max(width-1, 3)) + ":"
+	}
+	return strings.Repeat("-", max(width, 3))
+}
+
+// pad pads a cell to a display width on the side given by the alignment.
+func (a Alignment) pad(cell string, width int) string {
+	space := max(width-StringWidth(cell), 0)
+	switch a {
+	case AlignRight:
+		return strings.Repeat(" ", space) + cell
+	case AlignCenter:
+		return strings.Repeat(" ", space/2) + cell + strings.Repeat(" ", space-space/2)
+	}
+	return cell + strings.Repeat(" ", space)
+}
+
+// NewTableWriter creates a table writer that writes to w.
+func NewTableWriter(w io.Writer) *TableWriter {
+	return TableOptions{}.NewTableWriter(w)
+}
+
+// NewTableWriter creates a table writer with the options that writes to w.
+func (o TableOptions) NewTableWriter(w io.Writer) *TableWriter {
+	return &TableWriter{
+		w:                 w,
+		Style:             o.Style,
+		HTMLFallback:      o.HTMLFallback,
+		HTMLFallbackWidth: o.HTMLFallbackWidth,
+		MaxCellWidth:      o.MaxCellWidth,
+	}
+}
+
+// WriteRow writes the header on the first call and a data row afterwards.
+func (t *TableWriter) WriteRow(row []string) {
+	if !t.started {
+		t.started = true
+		t.columns = len(row)
+	}
+
+	// Handle rows with different column counts
+	cells := tableCells(row, t.columns)
+	for i, cell := range cells {
+		cut, ok := TruncateCell(cell, t.MaxCellWidth)
+		if !ok {
+			continue
+		}
+		if t.Footnotes != nil {
+			*t.Footnotes++
+			label := fmt.Sprintf("[^%d]", *t.Footnotes)
+			cut += label
+			t.notes = append(t.notes, fmt.Sprintf("%s: %s", label, strings.Join(strings.Fields(cell), " ")))
+		}
+		cells[i] = cut
+	}
+	t.rows = append(t.rows, cells)
+	switch {
+	case t.Style == TablePretty || t.HTMLFallback:
+		return
+	case t.InferAlign && t.written == 0 && len(t.rows) < alignSampleRows:
+		return
+	}
+	t.writeRows(make([]int, t.columns))
+}
+
+// Flush writes the rows held by TablePretty and HTMLFallback tables and the
+// rows sampled to infer the alignment, followed by the footnotes of the cut
+// cells. Other rows are written as they arrive.
+func (t *TableWriter) Flush() {
+	t.flushRows()
+	if len(t.notes) > 0 {
+		fmt.Fprintf(t.w, "\n%s\n", strings.Join(t.notes, "\n"))
+		t.notes = nil
+	}
+}
+
+// flushRows writes the rows held.
+func (t *TableWriter) flushRows() {
+	if len(t.rows) == 0 {
+		return
+	}
+
+	if t.HTMLFallback && slices.ContainsFunc(t.rows, t.complexRow) {
+		t.inferAlign()
+		t.writeHTML()
+		t.rows = nil
+		return
+	}
+
+	// Columns are at least as wide as the header separator
+	widths := make([]int, t.columns)
+	if t.Style == TablePretty {
+		t.inferAlign()
+		for i := range widths {
+			widths[i] = len(t.alignment(i).separator(0))
+		}
+		for _, row := range t.rows {
+			for i, cell := range row {
+				widths[i] = max(widths[i], StringWidth(markdownCell(cell)))
 			}
-			buf.WriteString("\n")
 		}
 	}
+	t.writeRows(widths)
+}
 
-	return buf.String()
+// writeRows writes the rows held, padded to the widths of their columns,
+// followed by the header separator when they start the table.
+func (t *TableWriter) writeRows(widths []int) {
+	t.inferAlign()
+	for _, row := range t.rows {
+		t.writeCells(row, widths)
+		if t.written == 0 {
+			t.writeSeparator(widths)
+		}
+		t.written++
+	}
+	t.rows = t.rows[:0]
+}
+
+// inferAlign sets the alignment of the columns from the rows held before the
+// first one is written, keeping the alignments set in Align.
+func (t *TableWriter) inferAlign() {
+	if !t.InferAlign || t.written > 0 || len(t.rows) == 0 {
+		return
+	}
+	for col, align := range InferAlignment(t.rows[1:], t.columns) {
+		if col >= len(t.Align) {
+			t.Align = append(t.Align, align)
+		} else if t.Align[col] == AlignDefault {
+			t.Align[col] = align
+		}
+	}
+}
+
+// alignment returns the alignment of a column.
+func (t *TableWriter) alignment(col int) Alignment {
+	if col < len(t.Align) {
+		return t.Align[col]
+	}
+	return AlignDefault
+}
+
+// writeCells writes a row of cells padded to the widths of their columns.
+func (t *TableWriter) writeCells(cells []string, widths []int) {
+	fmt.Fprint(t.w, "|")
+	for i, cell := range cells {
+		fmt.Fprintf(t.w, " %s |", t.alignment(i).pad(markdownCell(cell), widths[i]))
+	}
+	fmt.Fprint(t.w, "\n")
+}
+
+// writeSeparator writes the header separator for columns of the widths.
+func (t *TableWriter) writeSeparator(widths []int) {
+	fmt.Fprint(t.w, "|")
+	for i, width := range widths {
+		fmt.Fprintf(t.w, " %s |", t.alignment(i).separator(width))
+	}
+	fmt.Fprint(t.w, "\n")
+}
+
+// complexRow reports whether a row has a cell a pipe table cannot hold
+// without breaking its layout: one with line breaks or block content, or
+// wider than HTMLFallbackWidth when set.
+func (t *TableWriter) complexRow(cells []string) bool {
+	for _, cell := range cells {
+		if strings.ContainsAny(cell, "\r\n") || strings.HasPrefix(cell, "```") ||
+			t.HTMLFallbackWidth > 0 && StringWidth(cell) > t.HTMLFallbackWidth {
+			return true
+		}
+	}
+	return false
+}
+
+// writeHTML writes the rows held as an HTML table, the first row being the
+// header.
+func (t *TableWriter) writeHTML() {
+	fmt.Fprint(t.w, "<table>\n")
+	for i, row := range t.rows {
+		tag := "td"
+		switch i {
+		case 0:
+			fmt.Fprint(t.w, "<thead>\n")
+			tag = "th"
+		case 1:
+			fmt.Fprint(t.w, "<tbody>\n")
+		}
+
+		fmt.Fprint(t.w, "<tr>")
+		for col, cell := range row {
+			fmt.Fprintf(t.w, "<%s%s>%s</%s>", tag, t.alignment(col).htmlAttr(), htmlCell(cell), tag)
+		}
+		fmt.Fprint(t.w, "</tr>\n")
+
+		if i == 0 {
+			fmt.Fprint(t.w, "</thead>\n")
+		}
+	}
+	if len(t.rows) > 1 {
+		fmt.Fprint(t.w, "</tbody>\n")
+	}
+	fmt.Fprint(t.w, "</table>\n")
+}
+
+// htmlAttr returns the align attribute of an HTML table cell with the
+// alignment, with a leading space.
+func (a Alignment) htmlAttr() string {
+	switch a {
+	case AlignLeft:
+		return ` align="left"`
+	case AlignCenter:
+		return ` align="center"`
+	case AlignRight:
+		return ` align="right"`
+	}
+	return ""
+}
+
+var cellLineBreaks = strings.NewReplacer("\r\n", "<br>", "\r", "<br>", "\n", "<br>")
+
+// tableCells returns the cells of a row for a table with a number of
+// columns, padding or cutting the row to fit. Cells are trimmed of
+// surrounding whitespace.
+func tableCells(row []string, columns int) []string {
+	cells := make([]string, columns)
+	for i := range min(columns, len(row)) {
+		cells[i] = strings.TrimSpace(row[i])
+	}
+	return cells
+}
+
+// markdownCell escapes pipe characters and keeps line breaks inside the cell
+// so they don't end the table row.
+func markdownCell(cell string) string {
+	return cellLineBreaks.Replace(EscapeCell(cell))
+}
+
+// htmlCell escapes the text of an HTML table cell, keeping its line breaks.
+func htmlCell(cell string) string {
+	return cellLineBreaks.Replace(html.EscapeString(cell))
+}
+
+// TruncateCell cuts a cell wider than width characters to that width, ending
+// it with an ellipsis, and reports whether it was cut. Cells are not cut when
+// width is zero.
+func TruncateCell(cell string, width int) (string, bool) {
+	if width <= 0 || StringWidth(cell) <= width {
+		return cell, false
+	}
+
+	var b strings.Builder
+	used := 0
+	for _, r := range cell {
+		w := RuneWidth(r)
+		if used+w > width-1 {
+			break
+		}
+		b.WriteRune(r)
+		used += w
+	}
+	return strings.TrimRightFunc(b.String(), unicode.IsSpace) + "…", true
 }
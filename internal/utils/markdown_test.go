@@ -69,6 +69,20 @@ func TestToMarkdownTable_TrimWhitespace(t *testing.T) {
 	}
 }
 
+func TestToMarkdownTable_LineBreaks(t *testing.T) {
+	input := [][]string{
+		{"Name", "Address"},
+		{"John", "1 Main St\nSpringfield\r\nUSA\n"},
+	}
+
+	result := ToMarkdownTable(input)
+	expected := "| Name | Address |\n| --- | --- |\n| John | 1 Main St<br>Springfield<br>USA |\n"
+
+	if result != expected {
+		t.Errorf("ToMarkdownTable() = %v, want %v", result, expected)
+	}
+}
+
 func TestToMarkdownTable_UnevenRows(t *testing.T) {
 	input := [][]string{
 		{"Name", "Age", "City", "Country"},
@@ -119,8 +133,8 @@ func TestToMarkdownTable_SpecialCharacters(t *testing.T) {
 		t.Error("ToMarkdownTable() should preserve special characters except pipes")
 	}
 
-	if !strings.Contains(result, "Line\nbreak") {
-		t.Error("ToMarkdownTable() should preserve newline characters")
+	if !strings.Contains(result, "Line<br>break") {
+		t.Error("ToMarkdownTable() should turn newline characters into line breaks")
 	}
 }
 
@@ -173,3 +187,160 @@ func TestToMarkdownTable_OnlyHeader(t *testing.T) {
 		t.Errorf("ToMarkdownTable() with only header = %v, want %v", result, expected)
 	}
 }
+
+func TestTableWriter_StreamsRows(t *testing.T) {
+	var buf strings.Builder
+	table := NewTableWriter(&buf)
+	table.WriteRow([]string{"Name", "Age"})
+	table.WriteRow([]string{"John", "30", "extra"})
+	table.WriteRow(nil)
+
+	expected := "| Name | Age |\n| --- | --- |\n| John | 30 |\n|  |  |\n"
+	if buf.String() != expected {
+		t.Errorf("TableWriter wrote %q, want %q", buf.String(), expected)
+	}
+}
+
+func TestTableWriter_Align(t *testing.T) {
+	var buf strings.Builder
+	table := NewTableWriter(&buf)
+	table.Align = []Alignment{AlignLeft, AlignRight, AlignCenter}
+	table.WriteRow([]string{"Name", "Amount", "Date", "Note"})
+	table.WriteRow([]string{"Rent", "1200", "2024-01-01", ""})
+
+	expected := "| Name | Amount | Date | Note |\n| :--- | ---: | :---: | --- |\n| Rent | 1200 | 2024-01-01 |  |\n"
+	if buf.String() != expected {
+		t.Errorf("TableWriter wrote %q, want %q", buf.String(), expected)
+	}
+}
+
+func TestTableWriter_Pretty(t *testing.T) {
+	var buf strings.Builder
+	table := NewTableWriter(&buf)
+	table.Style = TablePretty
+	table.Align = []Alignment{AlignDefault, AlignRight, AlignCenter}
+	table.WriteRow([]string{"Name", "Amount", "City"})
+	table.WriteRow([]string{"佐藤太郎", "1200", "東京"})
+	table.WriteRow([]string{"Al", "5", "Rome | Lazio"})
+	if buf.Len() > 0 {
+		t.Fatalf("TableWriter wrote %q before Flush", buf.String())
+	}
+	table.Flush()
+
+	expected := "| Name     | Amount |     City      |\n" +
+		"| -------- | -----: | :-----------: |\n" +
+		"| 佐藤太郎 |   1200 |     東京      |\n" +
+		"| Al       |      5 | Rome \\| Lazio |\n"
+	if buf.String() != expected {
+		t.Errorf("TableWriter wrote %q, want %q", buf.String(), expected)
+	}
+}
+
+func TestTableOptions_ToMarkdown(t *testing.T) {
+	options := TableOptions{Style: TablePretty}
+
+	result := options.ToMarkdown([][]string{{"A", "Long header"}, {"x", "y"}})
+	expected := "| A   | Long header |\n| --- | ----------- |\n| x   | y           |\n"
+	if result != expected {
+		t.Errorf("ToMarkdown() = %q, want %q", result, expected)
+	}
+}
+
+func TestTableOptions_Merge(t *testing.T) {
+	base := TableOptions{Style: TablePretty, MaxCellWidth: 20}
+	result := base.Merge(TableOptions{HTMLFallback: true, HTMLFallbackWidth: 80})
+	expected := TableOptions{Style: TablePretty, HTMLFallback: true, HTMLFallbackWidth: 80, MaxCellWidth: 20}
+	if result != expected {
+		t.Errorf("Merge() = %+v, want %+v", result, expected)
+	}
+}
+
+func TestTableWriter_HTMLFallback(t *testing.T) {
+	tests := []struct {
+		name     string
+		rows     [][]string
+		expected string
+	}{
+		{
+			name:     "simple cells",
+			rows:     [][]string{{"Name", "Note"}, {"Rent", "a | b"}},
+			expected: "| Name | Note |\n| --- | ---: |\n| Rent | a \\| b |\n",
+		},
+		{
+			name: "line breaks",
+			rows: [][]string{{"Name", "Note"}, {"Rent", "due <monthly>\nby the 5th"}},
+			expected: "<table>\n<thead>\n<tr><th>Name</th><th align=\"right\">Note</th></tr>\n</thead>\n" +
+				"<tbody>\n<tr><td>Rent</td><td align=\"right\">due &lt;monthly&gt;<br>by the 5th</td></tr>\n</tbody>\n</table>\n",
+		},
+		{
+			name: "wide cell",
+			rows: [][]string{{"Name", "Note"}, {"Rent", strings.Repeat("x", 11)}},
+			expected: "<table>\n<thead>\n<tr><th>Name</th><th align=\"right\">Note</th></tr>\n</thead>\n" +
+				"<tbody>\n<tr><td>Rent</td><td align=\"right\">xxxxxxxxxxx</td></tr>\n</tbody>\n</table>\n",
+		},
+		{
+			name:     "header only",
+			rows:     [][]string{{"```go", "Note"}},
+			expected: "<table>\n<thead>\n<tr><th>```go</th><th align=\"right\">Note</th></tr>\n</thead>\n</table>\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf strings.Builder
+			table := NewTableWriter(&buf)
+			table.HTMLFallback = true
+			table.HTMLFallbackWidth = 10
+			table.Align = []Alignment{AlignDefault, AlignRight}
+			for _, row := range tt.rows {
+				table.WriteRow(row)
+			}
+			table.Flush()
+
+			if buf.String() != tt.expected {
+				t.Errorf("TableWriter wrote %q, want %q", buf.String(), tt.expected)
+			}
+		})
+	}
+}
+
+func TestTruncateCell(t *testing.T) {
+	tests := []struct {
+		cell     string
+		width    int
+		expected string
+		cut      bool
+	}{
+		{"short", 10, "short", false},
+		{"exactly10!", 10, "exactly10!", false},
+		{"a long description", 10, "a long de…", true},
+		{"word    spaced", 8, "word…", true},
+		{"東京都千代田区", 6, "東京…", true},
+		{"anything", 0, "anything", false},
+	}
+	for _, tt := range tests {
+		got, cut := TruncateCell(tt.cell, tt.width)
+		if got != tt.expected || cut != tt.cut {
+			t.Errorf("TruncateCell(%q, %d) = %q, %v, want %q, %v", tt.cell, tt.width, got, cut, tt.expected, tt.cut)
+		}
+	}
+}
+
+func TestTableWriter_MaxCellWidth(t *testing.T) {
+	var buf strings.Builder
+	footnotes := 2
+	table := NewTableWriter(&buf)
+	table.MaxCellWidth = 8
+	table.Footnotes = &footnotes
+	table.WriteRow([]string{"Name", "Description"})
+	table.WriteRow([]string{"Widget", "A small\nwidget | part"})
+	table.Flush()
+
+	expected := "| Name | Descrip…[^3] |\n| --- | --- |\n| Widget | A small…[^4] |\n" +
+		"\n[^3]: Description\n[^4]: A small widget | part\n"
+	if buf.String() != expected {
+		t.Errorf("TableWriter wrote %q, want %q", buf.String(), expected)
+	}
+	if footnotes != 4 {
+		t.Errorf("footnote counter = %d, want 4", footnotes)
+	}
+}
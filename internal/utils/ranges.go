@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// NumberRange is a set of 1-based page, slide or sheet numbers parsed from a
+// specification such as "1-10,15". An empty NumberRange selects every number.
+type NumberRange [][2]int
+
+// ParseNumberRange parses a comma-separated list of numbers and inclusive
+// ranges. A range may omit its end ("20-") to select everything from its start.
+func ParseNumberRange(spec string) (NumberRange, error) {
+	var r NumberRange
+	if strings.TrimSpace(spec) == "" {
+		return r, nil
+	}
+
+	for part := range strings.SplitSeq(spec, ",") {
+		part = strings.TrimSpace(part)
+		startStr, endStr, isRange := strings.Cut(part, "-")
+
+		start, err := strconv.Atoi(strings.TrimSpace(startStr))
+		if err != nil || start < 1 {
+			return nil, fmt.Errorf("invalid range %q", part)
+		}
+
+		end := start
+		if isRange {
+			endStr = strings.TrimSpace(endStr)
+			if endStr == "" {
+				end = math.MaxInt
+			} else if end, err = strconv.Atoi(endStr); err != nil || end < start {
+				return nil, fmt.Errorf("invalid range %q", part)
+			}
+		}
+
+		r = append(r, [2]int{start, end})
+	}
+
+	return r, nil
+}
+
+// Contains reports whether n is selected by the range.
+func (r NumberRange) Contains(n int) bool {
+	if len(r) == 0 {
+		return true
+	}
+	for _, span := range r {
+		if n >= span[0] && n <= span[1] {
+			return true
+		}
+	}
+	return false
+}
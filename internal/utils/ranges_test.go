@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestParseNumberRange(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected NumberRange
+	}{
+		{"", nil},
+		{"3", NumberRange{{3, 3}}},
+		{"1-10,15", NumberRange{{1, 10}, {15, 15}}},
+		{" 2 - 4 , 7", NumberRange{{2, 4}, {7, 7}}},
+		{"20-", NumberRange{{20, math.MaxInt}}},
+	}
+
+	for _, c := range cases {
+		got, err := ParseNumberRange(c.input)
+		if err != nil {
+			t.Errorf("ParseNumberRange(%q) returned unexpected error: %v", c.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("ParseNumberRange(%q) = %v, want %v", c.input, got, c.expected)
+		}
+	}
+}
+
+func TestParseNumberRange_Invalid(t *testing.T) {
+	for _, input := range []string{"a", "0", "5-2", "1,,2", "-3", "1-x"} {
+		if _, err := ParseNumberRange(input); err == nil {
+			t.Errorf("ParseNumberRange(%q) should return error", input)
+		}
+	}
+}
+
+func TestNumberRange_Contains(t *testing.T) {
+	r := NumberRange{{1, 3}, {10, 10}}
+
+	for n, expected := range map[int]bool{1: true, 3: true, 4: false, 10: true, 11: false} {
+		if got := r.Contains(n); got != expected {
+			t.Errorf("Contains(%d) = %v, want %v", n, got, expected)
+		}
+	}
+
+	if !(NumberRange{}).Contains(42) {
+		t.Error("Contains() on empty range should select every number")
+	}
+}
@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// RenderText renders a markdown document as plain text, for search indexing:
+// the text of its blocks separated by blank lines, without front matter.
+func RenderText(markdown string) string {
+	blocks := ParseBlocks(markdown)
+	texts := make([]string, 0, len(blocks))
+	for _, block := range blocks {
+		if block.Text != "" {
+			texts = append(texts, block.Text)
+		}
+	}
+	if len(texts) == 0 {
+		return ""
+	}
+	return strings.Join(texts, "\n\n") + "\n"
+}
+
+// Document is the structured form of a markdown document rendered by
+// RenderJSON.
+type Document struct {
+	Metadata map[string]any `json:"metadata"`
+	Blocks   []Block        `json:"blocks"`
+}
+
+// RenderJSON renders a markdown document as a JSON Document with the fields
+// of its front matter as metadata and its blocks with their types and line
+// positions.
+func RenderJSON(markdown string) (string, error) {
+	fields, _ := SplitFrontMatter(markdown)
+	doc := Document{Metadata: FrontMatterMap(fields), Blocks: ParseBlocks(markdown)}
+	if doc.Blocks == nil {
+		doc.Blocks = []Block{}
+	}
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data) + "\n", nil
+}
+
+// FrontMatterMap returns the values of front matter fields by key, as
+// strings or, for list fields, slices of strings.
+func FrontMatterMap(fields []FrontMatterField) map[string]any {
+	m := make(map[string]any, len(fields))
+	for _, field := range fields {
+		if field.List != nil {
+			list := make([]any, len(field.List))
+			for i, item := range field.List {
+				list[i] = item
+			}
+			m[field.Key] = list
+		} else {
+			m[field.Key] = field.Value
+		}
+	}
+	return m
+}
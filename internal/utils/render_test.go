@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestRenderText(t *testing.T) {
+	input := "---\ntitle: Report\n---\n\n# Report\n\nSome *text*.\n\n| a | b |\n| --- | --- |\n| 1 | 2 |\n"
+
+	expected := "Report\n\nSome text.\n\na\tb\n1\t2\n"
+	if got := RenderText(input); got != expected {
+		t.Errorf("RenderText() = %q, want %q", got, expected)
+	}
+}
+
+func TestRenderJSON(t *testing.T) {
+	input := "---\ntitle: Report\nkeywords:\n  - a\n  - b\n---\n\n# Report\n"
+
+	result, err := RenderJSON(input)
+	if err != nil {
+		t.Fatalf("RenderJSON() returned unexpected error: %v", err)
+	}
+	var doc Document
+	if err := json.Unmarshal([]byte(result), &doc); err != nil {
+		t.Fatalf("RenderJSON() returned invalid JSON: %v", err)
+	}
+
+	expected := Document{
+		Metadata: map[string]any{"title": "Report", "keywords": []any{"a", "b"}},
+		Blocks:   []Block{{Type: BlockHeading, Level: 1, Text: "Report", StartLine: 8, EndLine: 8}},
+	}
+	if !reflect.DeepEqual(doc, expected) {
+		t.Errorf("RenderJSON() = %+v, want %+v", doc, expected)
+	}
+
+	if result, err := RenderJSON(""); err != nil || result != "{\n  \"metadata\": {},\n  \"blocks\": []\n}\n" {
+		t.Errorf("RenderJSON() of an empty document = %q, %v", result, err)
+	}
+}
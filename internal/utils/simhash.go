@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"hash/fnv"
+	"math/bits"
+	"strings"
+	"unicode"
+)
+
+// shingleSize is the number of consecutive words hashed together by
+// Simhash.
+const shingleSize = 3
+
+// ContentHash returns the SHA-256 hash, in hex, of the words of the plain
+// text of markdown, lowercased, so that documents differing only by their
+// markdown syntax, front matter, case or spacing share a hash.
+func ContentHash(markdown string) string {
+	sum := sha256.Sum256([]byte(strings.Join(contentWords(markdown), " ")))
+	return hex.EncodeToString(sum[:])
+}
+
+// Simhash returns a 64-bit signature of the plain text of markdown, from
+// shingles of consecutive words, such that near-duplicate documents have
+// signatures a few bits apart, as measured by HammingDistance.
+func Simhash(markdown string) uint64 {
+	words := contentWords(markdown)
+	var weights [64]int
+	for i := 0; i == 0 || i+shingleSize <= len(words); i++ {
+		h := fnv.New64a()
+		h.Write([]byte(strings.Join(words[i:min(i+shingleSize, len(words))], " ")))
+		sum := h.Sum64()
+		for bit := range weights {
+			if sum&(1<<bit) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+
+	var signature uint64
+	for bit, weight := range weights {
+		if weight > 0 {
+			signature |= 1 << bit
+		}
+	}
+	return signature
+}
+
+// HammingDistance returns the number of bits two signatures differ by.
+func HammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// contentWords returns the lowercase words of the plain text of markdown.
+func contentWords(markdown string) []string {
+	return strings.FieldsFunc(strings.ToLower(RenderText(markdown)), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -0,0 +1,31 @@
+package utils
+
+import "testing"
+
+func TestContentHash(t *testing.T) {
+	a := ContentHash("---\ntitle: A\n---\n\n# Report\n\nSome **bold** text.\n")
+	b := ContentHash("Report\n\nsome bold   TEXT")
+	if a != b {
+		t.Errorf("ContentHash() differs for the same text: %s, %s", a, b)
+	}
+	if c := ContentHash("Report\n\nother text"); c == a {
+		t.Errorf("ContentHash() = %s for different text", c)
+	}
+}
+
+func TestSimhash(t *testing.T) {
+	text := "The quick brown fox jumps over the lazy dog while the farmer watches from the porch of the old house."
+	same := Simhash(text)
+	near := Simhash(text + " Then it rains.")
+	far := Simhash("Quarterly revenue grew in every region, led by strong demand for the new product line.")
+
+	if Simhash("# "+text) != same {
+		t.Error("Simhash() differs for the same text with markdown syntax")
+	}
+	if d := HammingDistance(same, near); d > 12 {
+		t.Errorf("HammingDistance() of near duplicates = %d, want at most 12", d)
+	}
+	if d := HammingDistance(same, far); d <= 12 {
+		t.Errorf("HammingDistance() of different texts = %d, want more than 12", d)
+	}
+}
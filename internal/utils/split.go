@@ -0,0 +1,163 @@
+package utils
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Section is a part of a markdown document starting at a heading, as split
+// by SplitSections.
+type Section struct {
+	Title string
+
+	// File is the name of the file of the section, numbered in document
+	// order and named after its title, such as 02-installation.md.
+	File string
+
+	Markdown string
+}
+
+// SplitSections splits a markdown document before each heading of a level,
+// 1 for the top-level sections. The text before the first of these headings
+// becomes a first section titled after the title of the front matter, or
+// Introduction, unless only the front matter precedes it. Links to the
+// anchors of headings are rewritten to the files of their sections.
+func SplitSections(markdown string, level int) []Section {
+	fields, body := SplitFrontMatter(markdown)
+	lines := strings.Split(body, "\n")
+
+	var starts []int
+	var titles []string
+	for _, block := range ParseBlocks(body) {
+		if block.Type == BlockHeading && block.Level == level {
+			starts = append(starts, block.StartLine-1)
+			titles = append(titles, block.Text)
+		}
+	}
+
+	var sections []Section
+	first := len(lines)
+	if len(starts) > 0 {
+		first = starts[0]
+	}
+	if preamble := strings.Join(lines[:first], "\n"); strings.TrimSpace(preamble) != "" {
+		sections = append(sections, Section{Title: cmp.Or(frontMatterTitle(fields), "Introduction"), Markdown: preamble})
+	}
+	for i, start := range starts {
+		end := len(lines)
+		if i+1 < len(starts) {
+			end = starts[i+1]
+		}
+		sections = append(sections, Section{
+			Title:    titles[i],
+			Markdown: strings.Join(lines[start:end], "\n"),
+		})
+	}
+
+	width := len(strconv.Itoa(len(sections)))
+	for i := range sections {
+		sections[i].File = fmt.Sprintf("%0*d-%s.md", width, i+1, cmp.Or(Slug(sections[i].Title), "section"))
+		sections[i].Markdown = strings.TrimSpace(sections[i].Markdown) + "\n"
+	}
+	relinkSections(sections, ExtractHeadings(body))
+	return sections
+}
+
+var anchorLink = regexp.MustCompile(`\]\(#([^)\s]+)\)`)
+
+// relinkSections rewrites the links to the anchors of the headings of a
+// document, given in document order, to the files of the sections holding
+// them. Anchors are numbered per file once the document is split.
+func relinkSections(sections []Section, headings []Heading) {
+	type target struct{ file, anchor string }
+	targets := make(map[string]target)
+	global := Anchors(headings)
+	i := 0
+	for _, section := range sections {
+		for _, anchor := range Anchors(ExtractHeadings(section.Markdown)) {
+			if i < len(global) {
+				targets[global[i]] = target{section.File, anchor}
+			}
+			i++
+		}
+	}
+
+	for s := range sections {
+		lines := strings.Split(sections[s].Markdown, "\n")
+		inFence := false
+		for j, line := range lines {
+			if strings.HasPrefix(strings.TrimLeft(line, " "), "```") {
+				inFence = !inFence
+				continue
+			}
+			if inFence {
+				continue
+			}
+			lines[j] = anchorLink.ReplaceAllStringFunc(line, func(m string) string {
+				t, ok := targets[anchorLink.FindStringSubmatch(m)[1]]
+				if !ok {
+					return m
+				}
+				if t.file == sections[s].File {
+					return "](#" + t.anchor + ")"
+				}
+				return "](" + t.file + "#" + t.anchor + ")"
+			})
+		}
+		sections[s].Markdown = strings.Join(lines, "\n")
+	}
+}
+
+// SectionIndex returns the markdown of an index linking to the files of
+// sections, under a title, after the front matter of the document they were
+// split from.
+func SectionIndex(markdown, title string, sections []Section) string {
+	_, body := SplitFrontMatter(markdown)
+	var b strings.Builder
+	if frontMatter := markdown[:len(markdown)-len(body)]; frontMatter != "" {
+		b.WriteString(strings.TrimRight(frontMatter, "\n") + "\n\n")
+	}
+	fmt.Fprintf(&b, "# %s\n\n", title)
+	for _, section := range sections {
+		fmt.Fprintf(&b, "- [%s](%s)\n", strings.ReplaceAll(section.Title, "]", `\]`), EscapeURL(section.File))
+	}
+	return b.String()
+}
+
+// WriteSections splits a markdown document with SplitSections and writes
+// each section to a file of its own in dir, with an index.md file linking
+// them, titled after the title of the front matter, or Contents.
+func WriteSections(dir, markdown string, level int) ([]Section, error) {
+	sections := SplitSections(markdown, level)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	for _, section := range sections {
+		if err := os.WriteFile(filepath.Join(dir, section.File), []byte(section.Markdown), 0o644); err != nil {
+			return nil, err
+		}
+	}
+
+	fields, _ := SplitFrontMatter(markdown)
+	index := SectionIndex(markdown, cmp.Or(frontMatterTitle(fields), "Contents"), sections)
+	if err := os.WriteFile(filepath.Join(dir, "index.md"), []byte(index), 0o644); err != nil {
+		return nil, err
+	}
+	return sections, nil
+}
+
+// frontMatterTitle returns the title field of front matter, or an empty
+// string.
+func frontMatterTitle(fields []FrontMatterField) string {
+	for _, field := range fields {
+		if field.Key == "title" {
+			return field.Value
+		}
+	}
+	return ""
+}
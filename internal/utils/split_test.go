@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const splitInput = `---
+title: Guide
+---
+
+Intro text, see [Setup](#setup).
+
+# Install
+
+## Setup
+
+Back to [usage](#usage).
+
+` + "```" + `
+[x](#setup)
+` + "```" + `
+
+# Usage
+
+See [setup](#setup-1) and [install](#install).
+
+## Setup
+`
+
+func TestSplitSections(t *testing.T) {
+	result := SplitSections(splitInput, 1)
+	expected := []Section{
+		{Title: "Guide", File: "1-guide.md", Markdown: "Intro text, see [Setup](2-install.md#setup).\n"},
+		{Title: "Install", File: "2-install.md", Markdown: "# Install\n\n## Setup\n\nBack to [usage](3-usage.md#usage).\n\n```\n[x](#setup)\n```\n"},
+		{Title: "Usage", File: "3-usage.md", Markdown: "# Usage\n\nSee [setup](#setup) and [install](2-install.md#install).\n\n## Setup\n"},
+	}
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("SplitSections() = %#v, want %#v", result, expected)
+	}
+}
+
+func TestSplitSections_NoHeadings(t *testing.T) {
+	result := SplitSections("Just text.", 1)
+	expected := []Section{{Title: "Introduction", File: "1-introduction.md", Markdown: "Just text.\n"}}
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("SplitSections() = %#v, want %#v", result, expected)
+	}
+}
+
+func TestWriteSections(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "sections")
+
+	sections, err := WriteSections(dir, splitInput, 1)
+	if err != nil {
+		t.Fatalf("WriteSections() error = %v", err)
+	}
+	if len(sections) != 3 {
+		t.Fatalf("WriteSections() wrote %d sections, want 3", len(sections))
+	}
+
+	index, err := os.ReadFile(filepath.Join(dir, "index.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "---\ntitle: Guide\n---\n\n# Guide\n\n- [Guide](1-guide.md)\n- [Install](2-install.md)\n- [Usage](3-usage.md)\n"
+	if string(index) != expected {
+		t.Errorf("index.md = %q, want %q", index, expected)
+	}
+
+	usage, err := os.ReadFile(filepath.Join(dir, "3-usage.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(usage) != sections[2].Markdown {
+		t.Errorf("3-usage.md = %q, want %q", usage, sections[2].Markdown)
+	}
+}
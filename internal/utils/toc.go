@@ -0,0 +1,148 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Heading is an ATX heading found in a markdown document.
+type Heading struct {
+	Level int
+	Text  string
+}
+
+// ExtractHeadings returns the ATX headings of a markdown document in order,
+// ignoring lines inside fenced code blocks.
+func ExtractHeadings(markdown string) []Heading {
+	var headings []Heading
+	inFence := false
+
+	for line := range strings.SplitSeq(markdown, "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		if strings.HasPrefix(trimmed, "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+
+		level, ok := headingLevel(trimmed)
+		if !ok {
+			continue
+		}
+
+		text := strings.TrimSpace(strings.TrimRight(strings.TrimSpace(trimmed[level:]), "#"))
+		if text != "" {
+			headings = append(headings, Heading{Level: level, Text: text})
+		}
+	}
+
+	return headings
+}
+
+// headingLevel returns the level of an ATX heading line stripped of its
+// indentation, and reports whether the line is a heading.
+func headingLevel(line string) (int, bool) {
+	level := len(line) - len(strings.TrimLeft(line, "#"))
+	if level == 0 || level > 6 || (len(line) > level && line[level] != ' ') {
+		return 0, false
+	}
+	return level, true
+}
+
+// ShiftHeadings moves the ATX headings of a markdown document down by offset
+// levels, to embed it under the headings of another document, and caps their
+// level at maxDepth. Levels are kept between 1 and 6, the cap when maxDepth
+// is zero. Lines inside fenced code blocks are left alone.
+func ShiftHeadings(markdown string, offset, maxDepth int) string {
+	if maxDepth <= 0 || maxDepth > 6 {
+		maxDepth = 6
+	}
+	if offset == 0 && maxDepth == 6 {
+		return markdown
+	}
+
+	lines := strings.Split(markdown, "\n")
+	inFence := false
+	for i, line := range lines {
+		trimmed := strings.TrimLeft(line, " ")
+		if strings.HasPrefix(trimmed, "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+
+		level, ok := headingLevel(trimmed)
+		if !ok {
+			continue
+		}
+		shifted := min(max(level+offset, 1), maxDepth)
+		lines[i] = line[:len(line)-len(trimmed)] + strings.Repeat("#", shifted) + trimmed[level:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Slug converts heading text to a GitHub-style anchor.
+func Slug(text string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(text)) {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == '-', r == '_':
+			b.WriteRune(r)
+		case r == ' ':
+			b.WriteRune('-')
+		}
+	}
+	return b.String()
+}
+
+// TableOfContents builds a nested markdown list linking to every heading
+// in the document. Returns an empty string if there are no headings.
+func TableOfContents(markdown string) string {
+	return HeadingList(ExtractHeadings(markdown))
+}
+
+// HeadingList builds a nested markdown list linking to the given headings,
+// numbering repeated anchors like GitHub does. Returns an empty string if
+// there are no headings.
+func HeadingList(headings []Heading) string {
+	if len(headings) == 0 {
+		return ""
+	}
+
+	minLevel := headings[0].Level
+	for _, h := range headings {
+		minLevel = min(minLevel, h.Level)
+	}
+
+	var buf strings.Builder
+	for i, slug := range Anchors(headings) {
+		h := headings[i]
+		buf.WriteString(strings.Repeat("  ", h.Level-minLevel))
+		fmt.Fprintf(&buf, "- [%s](#%s)\n", strings.ReplaceAll(h.Text, "]", "\\]"), slug)
+	}
+
+	return buf.String()
+}
+
+// Anchors returns the anchors of headings of a document, numbering repeated
+// anchors like GitHub does.
+func Anchors(headings []Heading) []string {
+	anchors := make([]string, len(headings))
+	seen := make(map[string]int)
+	for i, h := range headings {
+		slug := Slug(h.Text)
+		if n := seen[slug]; n > 0 {
+			seen[slug] = n + 1
+			slug = fmt.Sprintf("%s-%d", slug, n)
+		} else {
+			seen[slug] = 1
+		}
+		anchors[i] = slug
+	}
+	return anchors
+}
@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractHeadings(t *testing.T) {
+	input := "# Title\n\ntext\n\n## Section ##\n```\n# not a heading\n```\n#hashtag\n### Sub"
+
+	result := ExtractHeadings(input)
+	expected := []Heading{
+		{Level: 1, Text: "Title"},
+		{Level: 2, Text: "Section"},
+		{Level: 3, Text: "Sub"},
+	}
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("ExtractHeadings() = %v, want %v", result, expected)
+	}
+}
+
+func TestSlug(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected string
+	}{
+		{"Hello World", "hello-world"},
+		{"What's new?", "whats-new"},
+		{"Café 数据", "café-数据"},
+		{"snake_case-name", "snake_case-name"},
+	}
+
+	for _, c := range cases {
+		if got := Slug(c.input); got != c.expected {
+			t.Errorf("Slug(%q) = %q, want %q", c.input, got, c.expected)
+		}
+	}
+}
+
+func TestTableOfContents(t *testing.T) {
+	input := "## Intro\n\n### Details\n\n## Intro\n"
+
+	result := TableOfContents(input)
+	expected := "- [Intro](#intro)\n  - [Details](#details)\n- [Intro](#intro-1)\n"
+
+	if result != expected {
+		t.Errorf("TableOfContents() = %q, want %q", result, expected)
+	}
+}
+
+func TestTableOfContents_NoHeadings(t *testing.T) {
+	if result := TableOfContents("just text"); result != "" {
+		t.Errorf("TableOfContents() without headings = %q, want empty string", result)
+	}
+}
+
+func TestShiftHeadings(t *testing.T) {
+	input := "# Title\n\n## Section\n```\n# code\n```\n#### Deep\n###### Deepest\n#hashtag\n"
+
+	cases := []struct {
+		offset, maxDepth int
+		expected         string
+	}{
+		{0, 0, input},
+		{1, 0, "## Title\n\n### Section\n```\n# code\n```\n##### Deep\n###### Deepest\n#hashtag\n"},
+		{2, 4, "### Title\n\n#### Section\n```\n# code\n```\n#### Deep\n#### Deepest\n#hashtag\n"},
+		{-1, 0, "# Title\n\n# Section\n```\n# code\n```\n### Deep\n##### Deepest\n#hashtag\n"},
+	}
+
+	for _, c := range cases {
+		if got := ShiftHeadings(input, c.offset, c.maxDepth); got != c.expected {
+			t.Errorf("ShiftHeadings(%d, %d) = %q, want %q", c.offset, c.maxDepth, got, c.expected)
+		}
+	}
+}
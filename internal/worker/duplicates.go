@@ -0,0 +1,66 @@
+package worker
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/flaviodelgrosso/marky/internal/utils"
+)
+
+// Fingerprints remembers the content of the documents converted by workers,
+// to flag the documents duplicating one converted before them.
+type Fingerprints struct {
+	// MaxDistance is the number of bits the simhash signatures of two
+	// documents may differ by for them to be near duplicates, 3 when zero.
+	// It is negative to flag exact duplicates only.
+	MaxDistance int
+
+	mu   sync.Mutex
+	seen []fingerprint
+}
+
+type fingerprint struct {
+	id      string
+	hash    string
+	simhash uint64
+}
+
+// Add remembers the content of a finished job from its status, such as one
+// read by Spool.Finished, without checking it.
+func (f *Fingerprints) Add(status Status) {
+	simhash, err := strconv.ParseUint(status.Simhash, 16, 64)
+	if status.Hash == "" || err != nil {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.seen = append(f.seen, fingerprint{status.ID, status.Hash, simhash})
+}
+
+// Check fingerprints the markdown of a job into its status, flagging it as
+// a duplicate of the first document remembered with the same content, or
+// else the nearest one with similar content, and remembers it.
+func (f *Fingerprints) Check(status *Status, markdown string) {
+	status.Hash = utils.ContentHash(markdown)
+	simhash := utils.Simhash(markdown)
+	status.Simhash = strconv.FormatUint(simhash, 16)
+
+	maxDistance := f.MaxDistance
+	if maxDistance == 0 {
+		maxDistance = 3
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	nearest := maxDistance + 1
+	for _, seen := range f.seen {
+		if seen.hash == status.Hash {
+			status.DuplicateOf, status.NearDuplicateOf = seen.id, ""
+			break
+		}
+		if distance := utils.HammingDistance(seen.simhash, simhash); distance < nearest {
+			status.NearDuplicateOf, nearest = seen.id, distance
+		}
+	}
+	f.seen = append(f.seen, fingerprint{status.ID, status.Hash, simhash})
+}
@@ -0,0 +1,222 @@
+package worker
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Spool is a queue kept in a directory, so that jobs survive restarts. Each
+// job is a JSON file that moves from the pending directory to the active
+// one while a worker converts it, and its status is written to the done or
+// failed directory. Workers sharing the directory claim jobs by renaming
+// their files, which is atomic on local file systems.
+type Spool struct {
+	Dir string
+}
+
+// Spool subdirectories.
+const (
+	spoolPending = "pending"
+	spoolActive  = "active"
+	spoolDone    = "done"
+	spoolFailed  = "failed"
+)
+
+// OpenSpool creates the directories of a spool in dir when they are missing.
+func OpenSpool(dir string) (*Spool, error) {
+	for _, sub := range []string{spoolPending, spoolActive, spoolDone, spoolFailed} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			return nil, fmt.Errorf("unable to create spool directory: %w", err)
+		}
+	}
+	return &Spool{Dir: dir}, nil
+}
+
+// Enqueue adds a job to the spool. Jobs without an ID are given one that
+// sorts after the jobs already added, with a random suffix so that the jobs
+// added at the same time by several processes get different IDs. It returns
+// an error when a pending job already has the ID of the job.
+func (s *Spool) Enqueue(job Job) (Job, error) {
+	if job.ID != "" {
+		if err := s.create(spoolPending, job.ID, job); err != nil {
+			return Job{}, err
+		}
+		return job, nil
+	}
+	for {
+		suffix := make([]byte, 4)
+		if _, err := rand.Read(suffix); err != nil {
+			return Job{}, err
+		}
+		job.ID = fmt.Sprintf("%020d-%s", time.Now().UnixNano(), hex.EncodeToString(suffix))
+		err := s.create(spoolPending, job.ID, job)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return Job{}, err
+		}
+		return job, nil
+	}
+}
+
+// Next claims the pending job with the lowest ID.
+func (s *Spool) Next(ctx context.Context) (Job, error) {
+	entries, err := os.ReadDir(filepath.Join(s.Dir, spoolPending))
+	if err != nil {
+		return Job{}, err
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), ".json") {
+			names = append(names, entry.Name())
+		}
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		if err := ctx.Err(); err != nil {
+			return Job{}, err
+		}
+		active := filepath.Join(s.Dir, spoolActive, name)
+		err := os.Rename(filepath.Join(s.Dir, spoolPending, name), active)
+		if errors.Is(err, os.ErrNotExist) {
+			// Claimed by another worker
+			continue
+		}
+		if err != nil {
+			return Job{}, err
+		}
+
+		data, err := os.ReadFile(active)
+		if err != nil {
+			return Job{}, err
+		}
+		var job Job
+		if err := json.Unmarshal(data, &job); err != nil {
+			return Job{}, fmt.Errorf("invalid job %s: %w", name, err)
+		}
+		job.ID = strings.TrimSuffix(name, ".json")
+		return job, nil
+	}
+	return Job{}, ErrEmpty
+}
+
+// Retry moves a claimed job back to the pending directory with its updated
+// number of attempts.
+func (s *Spool) Retry(job Job) error {
+	if err := s.write(spoolPending, job.ID, job); err != nil {
+		return err
+	}
+	return os.Remove(filepath.Join(s.Dir, spoolActive, job.ID+".json"))
+}
+
+// Finish writes the status of a claimed job to the done or failed directory.
+func (s *Spool) Finish(status Status) error {
+	dir := spoolDone
+	if status.Error != "" {
+		dir = spoolFailed
+	}
+	if err := s.write(dir, status.ID, status); err != nil {
+		return err
+	}
+	return os.Remove(filepath.Join(s.Dir, spoolActive, status.ID+".json"))
+}
+
+// Finished returns the statuses of the jobs that were converted, from the
+// done directory, in ID order.
+func (s *Spool) Finished() ([]Status, error) {
+	entries, err := os.ReadDir(filepath.Join(s.Dir, spoolDone))
+	if err != nil {
+		return nil, err
+	}
+	var statuses []Status
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.Dir, spoolDone, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var status Status
+		if err := json.Unmarshal(data, &status); err != nil {
+			return nil, fmt.Errorf("invalid status %s: %w", entry.Name(), err)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// Recover moves the jobs left active by workers that stopped before
+// finishing them back to the pending directory. It must only run while no
+// worker uses the spool.
+func (s *Spool) Recover() (int, error) {
+	entries, err := os.ReadDir(filepath.Join(s.Dir, spoolActive))
+	if err != nil {
+		return 0, err
+	}
+	recovered := 0
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		err := os.Rename(filepath.Join(s.Dir, spoolActive, entry.Name()), filepath.Join(s.Dir, spoolPending, entry.Name()))
+		if err != nil {
+			return recovered, err
+		}
+		recovered++
+	}
+	return recovered, nil
+}
+
+// write writes v as the JSON file of a job in a spool directory, replacing
+// the file of the job.
+func (s *Spool) write(dir, id string, v any) error {
+	return s.store(dir, id, v, os.Rename)
+}
+
+// create writes v as the JSON file of a new job in a spool directory. It
+// returns an error wrapping os.ErrExist when the job has a file already.
+func (s *Spool) create(dir, id string, v any) error {
+	err := s.store(dir, id, v, os.Link)
+	if errors.Is(err, os.ErrExist) {
+		return fmt.Errorf("job %s already exists: %w", id, os.ErrExist)
+	}
+	return err
+}
+
+// store writes v as the JSON file of a job in a spool directory through a
+// temporary file, put in place by place, so that workers never read a
+// partial file.
+func (s *Spool) store(dir, id string, v any, place func(oldpath, newpath string) error) error {
+	if id == "" || strings.ContainsAny(id, `/\`) || !filepath.IsLocal(id) {
+		return fmt.Errorf("invalid job ID %q", id)
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Join(s.Dir, dir), ".job-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return place(tmp.Name(), filepath.Join(s.Dir, dir, id+".json"))
+}
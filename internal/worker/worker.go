@@ -0,0 +1,175 @@
+// Package worker runs batches of conversions taken from a queue, writing the
+// markdown of each document and a status record once it is converted or has
+// failed too many times.
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/flaviodelgrosso/marky/internal/converters"
+)
+
+// ErrEmpty is returned by Queue.Next when no job is waiting.
+var ErrEmpty = errors.New("queue is empty")
+
+// Job is a document to convert.
+type Job struct {
+	// ID identifies the job in its queue.
+	ID string `json:"id"`
+
+	// Input is the path or http(s) URL of the document.
+	Input string `json:"input"`
+
+	// Output is the path the markdown is written to, the input path with
+	// its extension replaced by .md when empty.
+	Output string `json:"output,omitempty"`
+
+	// Attempts is the number of failed conversions of the job so far.
+	Attempts int `json:"attempts,omitempty"`
+}
+
+// Status records the outcome of a job.
+type Status struct {
+	Job
+
+	// Error is the error of the last attempt, empty when the job succeeded.
+	Error string `json:"error,omitempty"`
+
+	// Hash and Simhash fingerprint the content of the converted document,
+	// when the worker has Fingerprints. DuplicateOf is the ID of a job
+	// converted before with the same content, and NearDuplicateOf the ID
+	// of one with similar content.
+	Hash            string `json:"hash,omitempty"`
+	Simhash         string `json:"simhash,omitempty"`
+	DuplicateOf     string `json:"duplicate_of,omitempty"`
+	NearDuplicateOf string `json:"near_duplicate_of,omitempty"`
+
+	Finished time.Time `json:"finished"`
+}
+
+// Queue holds the jobs of a worker. Implementations, such as Spool, hand
+// each job to one worker at a time, and put it back when the worker stops
+// before finishing it. Other stores implement it through the public worker
+// package.
+type Queue interface {
+	// Next claims the next job, or returns ErrEmpty when none is waiting.
+	Next(ctx context.Context) (Job, error)
+
+	// Retry puts a claimed job that failed back in the queue.
+	Retry(job Job) error
+
+	// Finish removes a claimed job from the queue and records its status.
+	Finish(status Status) error
+}
+
+// Converter converts the document at a path to markdown.
+type Converter interface {
+	Convert(path string) (string, error)
+}
+
+// ContextConverter is a Converter whose conversions stop when a context is
+// done, such as the instances of marky.New. Workers stop converting their
+// job once the context of Run is done.
+type ContextConverter interface {
+	Converter
+	ConvertContext(ctx context.Context, path string) (string, error)
+}
+
+// Worker converts the jobs of a queue.
+type Worker struct {
+	Queue     Queue
+	Converter Converter
+
+	// MaxAttempts is the number of times a job is tried before it is
+	// recorded as failed, 3 when zero.
+	MaxAttempts int
+
+	// Fingerprints, when set, flags the jobs whose documents duplicate
+	// those of jobs converted before in their status.
+	Fingerprints *Fingerprints
+}
+
+// Run converts jobs until the queue is empty or ctx is done. It returns the
+// error of the queue, not of the conversions, which are recorded in the
+// status of their jobs.
+func (w *Worker) Run(ctx context.Context) error {
+	for ctx.Err() == nil {
+		job, err := w.Queue.Next(ctx)
+		if errors.Is(err, ErrEmpty) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("unable to take the next job: %w", err)
+		}
+		if err := w.run(ctx, job); err != nil {
+			return err
+		}
+	}
+	return ctx.Err()
+}
+
+// run converts a job and retries or finishes it. A job stopped because ctx
+// is done is put back without counting the attempt.
+func (w *Worker) run(ctx context.Context, job Job) error {
+	markdown, err := w.convert(ctx, job)
+	if err != nil && ctx.Err() != nil {
+		if err := w.Queue.Retry(job); err != nil {
+			return fmt.Errorf("unable to put back job %s: %w", job.ID, err)
+		}
+		return nil
+	}
+	if err != nil {
+		job.Attempts++
+		maxAttempts := w.MaxAttempts
+		if maxAttempts <= 0 {
+			maxAttempts = 3
+		}
+		if job.Attempts < maxAttempts {
+			if err := w.Queue.Retry(job); err != nil {
+				return fmt.Errorf("unable to retry job %s: %w", job.ID, err)
+			}
+			return nil
+		}
+	}
+
+	status := Status{Job: job, Finished: time.Now()}
+	if err != nil {
+		status.Error = err.Error()
+	} else if w.Fingerprints != nil {
+		w.Fingerprints.Check(&status, markdown)
+	}
+	if err := w.Queue.Finish(status); err != nil {
+		return fmt.Errorf("unable to finish job %s: %w", job.ID, err)
+	}
+	return nil
+}
+
+// convert converts the input of a job and writes the markdown, which it
+// returns.
+func (w *Worker) convert(ctx context.Context, job Job) (string, error) {
+	output := job.Output
+	if output == "" {
+		if converters.IsURL(job.Input) || converters.IsObjectURI(job.Input) {
+			return "", errors.New("jobs converting a URL need an output path")
+		}
+		output = strings.TrimSuffix(job.Input, filepath.Ext(job.Input)) + ".md"
+	}
+
+	var markdown string
+	var err error
+	if c, ok := w.Converter.(ContextConverter); ok {
+		markdown, err = c.ConvertContext(ctx, job.Input)
+	} else {
+		markdown, err = w.Converter.Convert(job.Input)
+	}
+	if err != nil {
+		return "", err
+	}
+	return markdown, os.WriteFile(output, []byte(markdown), 0o644)
+}
@@ -0,0 +1,296 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeConverter converts files by upper-casing them, and fails for the
+// paths it has no attempts left for.
+type fakeConverter struct {
+	failures map[string]int
+	calls    int
+}
+
+func (c *fakeConverter) Convert(path string) (string, error) {
+	c.calls++
+	if c.failures[path] > 0 {
+		c.failures[path]--
+		return "", errors.New("conversion failed")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.ToUpper(string(data)), nil
+}
+
+func readStatus(t *testing.T, path string) Status {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read status: %v", err)
+	}
+	var status Status
+	if err := json.Unmarshal(data, &status); err != nil {
+		t.Fatalf("Failed to parse status: %v", err)
+	}
+	return status
+}
+
+func TestWorker_Run(t *testing.T) {
+	dir := t.TempDir()
+	spool, err := OpenSpool(filepath.Join(dir, "spool"))
+	if err != nil {
+		t.Fatalf("OpenSpool() returned unexpected error: %v", err)
+	}
+
+	docs := map[string]string{"a.txt": "first", "b.txt": "second", "c.txt": "third"}
+	for name, content := range docs {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+	a := filepath.Join(dir, "a.txt")
+	b := filepath.Join(dir, "b.txt")
+	c := filepath.Join(dir, "c.txt")
+	for i, job := range []Job{{ID: "1", Input: a}, {ID: "2", Input: b, Output: filepath.Join(dir, "second.md")}, {ID: "3", Input: c}} {
+		if _, err := spool.Enqueue(job); err != nil {
+			t.Fatalf("Enqueue(%d) returned unexpected error: %v", i, err)
+		}
+	}
+
+	converter := &fakeConverter{failures: map[string]int{b: 1, c: 5}}
+	worker := &Worker{Queue: spool, Converter: converter, MaxAttempts: 2}
+	if err := worker.Run(context.Background()); err != nil {
+		t.Fatalf("Run() returned unexpected error: %v", err)
+	}
+
+	for path, expected := range map[string]string{filepath.Join(dir, "a.md"): "FIRST", filepath.Join(dir, "second.md"): "SECOND"} {
+		if data, err := os.ReadFile(path); err != nil || string(data) != expected {
+			t.Errorf("output %s = %q, %v, want %q", path, data, err, expected)
+		}
+	}
+	if status := readStatus(t, filepath.Join(spool.Dir, "done", "2.json")); status.Attempts != 1 || status.Error != "" {
+		t.Errorf("status of job 2 = %+v, want one failed attempt and no error", status)
+	}
+	if status := readStatus(t, filepath.Join(spool.Dir, "failed", "3.json")); status.Attempts != 2 || status.Error != "conversion failed" {
+		t.Errorf("status of job 3 = %+v, want two failed attempts", status)
+	}
+	if converter.calls != 5 {
+		t.Errorf("Convert() called %d times, want 5", converter.calls)
+	}
+	for _, sub := range []string{"pending", "active"} {
+		if entries, _ := os.ReadDir(filepath.Join(spool.Dir, sub)); len(entries) != 0 {
+			t.Errorf("%s directory holds %d files after Run(), want none", sub, len(entries))
+		}
+	}
+}
+
+// blockingConverter converts files once its context is done, failing with
+// the error of the context.
+type blockingConverter struct {
+	started chan struct{}
+}
+
+func (c *blockingConverter) Convert(path string) (string, error) {
+	return "", errors.New("Convert() called instead of ConvertContext()")
+}
+
+func (c *blockingConverter) ConvertContext(ctx context.Context, path string) (string, error) {
+	close(c.started)
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+func TestWorker_Run_Canceled(t *testing.T) {
+	dir := t.TempDir()
+	spool, err := OpenSpool(dir)
+	if err != nil {
+		t.Fatalf("OpenSpool() returned unexpected error: %v", err)
+	}
+	if _, err := spool.Enqueue(Job{ID: "1", Input: filepath.Join(dir, "a.txt")}); err != nil {
+		t.Fatalf("Enqueue() returned unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	converter := &blockingConverter{started: make(chan struct{})}
+	go func() {
+		<-converter.started
+		cancel()
+	}()
+	worker := &Worker{Queue: spool, Converter: converter, MaxAttempts: 1}
+	if err := worker.Run(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run() error = %v, want context.Canceled", err)
+	}
+
+	// The stopped job is put back rather than failed
+	if _, err := os.Stat(filepath.Join(dir, "pending", "1.json")); err != nil {
+		t.Errorf("the stopped job was not put back: %v", err)
+	}
+	if entries, _ := os.ReadDir(filepath.Join(dir, "failed")); len(entries) != 0 {
+		t.Errorf("the stopped job was recorded as failed")
+	}
+}
+
+func TestSpool_Recover(t *testing.T) {
+	spool, err := OpenSpool(t.TempDir())
+	if err != nil {
+		t.Fatalf("OpenSpool() returned unexpected error: %v", err)
+	}
+	if _, err := spool.Enqueue(Job{ID: "1", Input: "a.pdf"}); err != nil {
+		t.Fatalf("Enqueue() returned unexpected error: %v", err)
+	}
+
+	// A worker stopping after claiming the job leaves it active
+	if _, err := spool.Next(context.Background()); err != nil {
+		t.Fatalf("Next() returned unexpected error: %v", err)
+	}
+	if _, err := spool.Next(context.Background()); !errors.Is(err, ErrEmpty) {
+		t.Fatalf("Next() with the only job claimed = %v, want ErrEmpty", err)
+	}
+
+	if n, err := spool.Recover(); err != nil || n != 1 {
+		t.Fatalf("Recover() = %d, %v, want 1 job", n, err)
+	}
+	job, err := spool.Next(context.Background())
+	if err != nil || job.ID != "1" || job.Input != "a.pdf" {
+		t.Errorf("Next() after Recover() = %+v, %v, want job 1", job, err)
+	}
+}
+
+func TestSpool_Enqueue_InvalidID(t *testing.T) {
+	spool, err := OpenSpool(t.TempDir())
+	if err != nil {
+		t.Fatalf("OpenSpool() returned unexpected error: %v", err)
+	}
+	for _, id := range []string{"../escape", "a/b", ".."} {
+		if _, err := spool.Enqueue(Job{ID: id, Input: "a.pdf"}); err == nil {
+			t.Errorf("Enqueue() with ID %q should return error", id)
+		}
+	}
+}
+
+func TestSpool_Enqueue_Concurrent(t *testing.T) {
+	spool, err := OpenSpool(t.TempDir())
+	if err != nil {
+		t.Fatalf("OpenSpool() returned unexpected error: %v", err)
+	}
+
+	const jobs = 200
+	var wg sync.WaitGroup
+	ids := make([]string, jobs)
+	for n := range jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			job, err := spool.Enqueue(Job{Input: fmt.Sprintf("%d.pdf", n)})
+			if err != nil {
+				t.Errorf("Enqueue() returned unexpected error: %v", err)
+			}
+			ids[n] = job.ID
+		}()
+	}
+	wg.Wait()
+
+	seen := make(map[string]bool)
+	for _, id := range ids {
+		if seen[id] {
+			t.Errorf("Enqueue() gave the ID %s to several jobs", id)
+		}
+		seen[id] = true
+	}
+	entries, err := os.ReadDir(filepath.Join(spool.Dir, spoolPending))
+	if err != nil {
+		t.Fatalf("Failed to read pending jobs: %v", err)
+	}
+	if len(entries) != jobs {
+		t.Errorf("Enqueue() left %d pending jobs, want %d", len(entries), jobs)
+	}
+
+	if _, err := spool.Enqueue(Job{ID: ids[0], Input: "other.pdf"}); !errors.Is(err, os.ErrExist) {
+		t.Errorf("Enqueue() with the ID of a pending job = %v, want os.ErrExist", err)
+	}
+}
+
+func TestWorker_Run_URLWithoutOutput(t *testing.T) {
+	spool, err := OpenSpool(t.TempDir())
+	if err != nil {
+		t.Fatalf("OpenSpool() returned unexpected error: %v", err)
+	}
+	if _, err := spool.Enqueue(Job{ID: "1", Input: "https://example.com/page"}); err != nil {
+		t.Fatalf("Enqueue() returned unexpected error: %v", err)
+	}
+
+	converter := &fakeConverter{}
+	if err := (&Worker{Queue: spool, Converter: converter, MaxAttempts: 1}).Run(context.Background()); err != nil {
+		t.Fatalf("Run() returned unexpected error: %v", err)
+	}
+	if converter.calls != 0 {
+		t.Errorf("Convert() called %d times, want 0", converter.calls)
+	}
+	if status := readStatus(t, filepath.Join(spool.Dir, "failed", "1.json")); !strings.Contains(status.Error, "output path") {
+		t.Errorf("status error = %q, want a missing output path", status.Error)
+	}
+}
+
+func TestWorker_Run_Duplicates(t *testing.T) {
+	dir := t.TempDir()
+	spool, err := OpenSpool(filepath.Join(dir, "spool"))
+	if err != nil {
+		t.Fatalf("OpenSpool() returned unexpected error: %v", err)
+	}
+
+	text := "The quarterly report covers revenue, costs and the outlook for the next year in every region we operate."
+	docs := []string{text, "  " + text + "\n", text + " Appendix.", "Something else entirely, about gardening and the weather."}
+	for i, content := range docs {
+		input := filepath.Join(dir, fmt.Sprintf("%d.txt", i+1))
+		if err := os.WriteFile(input, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+		if _, err := spool.Enqueue(Job{ID: fmt.Sprint(i + 1), Input: input}); err != nil {
+			t.Fatalf("Enqueue(%d) returned unexpected error: %v", i, err)
+		}
+	}
+
+	worker := &Worker{Queue: spool, Converter: &fakeConverter{}, Fingerprints: &Fingerprints{MaxDistance: 12}}
+	if err := worker.Run(context.Background()); err != nil {
+		t.Fatalf("Run() returned unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		id, duplicateOf, nearDuplicateOf string
+	}{
+		{"1", "", ""},
+		{"2", "1", ""},
+		{"3", "", "1"},
+		{"4", "", ""},
+	}
+	for _, tt := range tests {
+		status := readStatus(t, filepath.Join(spool.Dir, "done", tt.id+".json"))
+		if status.Hash == "" || status.DuplicateOf != tt.duplicateOf || status.NearDuplicateOf != tt.nearDuplicateOf {
+			t.Errorf("status of job %s = %+v, want duplicate of %q and near duplicate of %q", tt.id, status, tt.duplicateOf, tt.nearDuplicateOf)
+		}
+	}
+
+	finished, err := spool.Finished()
+	if err != nil {
+		t.Fatalf("Finished() returned unexpected error: %v", err)
+	}
+	fingerprints := &Fingerprints{}
+	for _, status := range finished {
+		fingerprints.Add(status)
+	}
+	status := Status{Job: Job{ID: "5"}}
+	fingerprints.Check(&status, strings.ToUpper(text))
+	if status.DuplicateOf != "1" {
+		t.Errorf("Check() after Add() flagged %+v, want a duplicate of job 1", status)
+	}
+}
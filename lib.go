@@ -1,24 +1,586 @@
 package marky
 
 import (
+	"log/slog"
+
 	"github.com/flaviodelgrosso/marky/internal/converters"
 	"github.com/flaviodelgrosso/marky/internal/marky"
+	"github.com/flaviodelgrosso/marky/internal/utils"
+)
+
+// Signature identifies a file format by the magic bytes at an offset of its
+// header, for registering formats the built-in detection does not know.
+type Signature = marky.Signature
+
+// DetectionHook lets callers supply the MIME type of a file they already know,
+// or veto its conversion, before the type is detected.
+type DetectionHook = marky.DetectionHook
+
+// Summarizer writes a summary placed at the top of each converted document.
+type Summarizer = marky.Summarizer
+
+// SummarizerFunc adapts a function to the Summarizer interface.
+type SummarizerFunc = marky.SummarizerFunc
+
+// ExtractiveSummarizer summarizes documents locally with their most
+// representative sentences.
+type ExtractiveSummarizer = marky.ExtractiveSummarizer
+
+// PostProcessor transforms the markdown of every converted document.
+type PostProcessor = marky.PostProcessor
+
+// PostProcessorFunc adapts a function to the PostProcessor interface.
+type PostProcessorFunc = marky.PostProcessorFunc
+
+// StripHTMLComments is a PostProcessor removing the HTML comments of
+// documents outside code blocks.
+var StripHTMLComments = marky.StripHTMLComments
+
+// CollapseBlankLines is a PostProcessor replacing runs of blank lines
+// outside code blocks with a single blank line.
+var CollapseBlankLines = marky.CollapseBlankLines
+
+// ConversionResult is a converted document along with its MIME type, its
+// converter, its title, its page, slide or sheet count and the warnings of
+// its conversion, as returned by ConvertWithResult.
+type ConversionResult = marky.ConversionResult
+
+// BatchOptions configures the conversion of a directory by ConvertDir: the
+// number of workers, the extensions to convert, whether to convert hidden
+// files and the simhash distance of near duplicates.
+type BatchOptions = marky.BatchOptions
+
+// BatchResult is the conversion result or error of a file of a directory
+// converted by ConvertDir, with the fingerprint of its content and the file
+// it duplicates.
+type BatchResult = marky.BatchResult
+
+// ErrNoConverter is returned for documents no registered converter accepts.
+var ErrNoConverter = marky.ErrNoConverter
+
+// Fidelity measures how much of the structure and text of a reference
+// markdown document a conversion kept.
+type Fidelity = marky.Fidelity
+
+// ScoreMarkdown measures the fidelity of converted markdown against
+// reference markdown.
+func ScoreMarkdown(markdown, reference string) Fidelity {
+	return marky.ScoreMarkdown(markdown, reference)
+}
+
+// Section is a part of a markdown document starting at a heading, with the
+// name of the file it is written to.
+type Section = utils.Section
+
+// SplitSections splits a markdown document before each heading of a level,
+// 1 for the top-level sections, rewriting links to headings across sections.
+func SplitSections(markdown string, level int) []Section {
+	return utils.SplitSections(markdown, level)
+}
+
+// WriteSections writes the sections of a markdown document split by
+// SplitSections to files of their own in dir, with an index.md file linking
+// them.
+func WriteSections(dir, markdown string, level int) ([]Section, error) {
+	return utils.WriteSections(dir, markdown, level)
+}
+
+// OutputFormat selects how documents are rendered.
+type OutputFormat = marky.OutputFormat
+
+const (
+	// FormatMarkdown renders documents as markdown.
+	FormatMarkdown = marky.FormatMarkdown
+
+	// FormatText renders documents as plain text, for search indexing.
+	FormatText = marky.FormatText
+
+	// FormatJSON renders the metadata and the typed blocks of documents,
+	// with their line positions in the markdown, as JSON.
+	FormatJSON = marky.FormatJSON
+
+	// FormatHTML renders documents as standalone HTML pages.
+	FormatHTML = marky.FormatHTML
+)
+
+// TableStyle controls the layout of the markdown tables written by the
+// converters.
+type TableStyle = utils.TableStyle
+
+const (
+	// TableCompact writes each cell with a single space on either side.
+	TableCompact = utils.TableCompact
+
+	// TablePretty pads the cells to the display width of their column, so
+	// tables line up when read as plain text.
+	TablePretty = utils.TablePretty
+)
+
+// ObjectOpener opens the objects of a cloud store, such as with the SDK of
+// the store and its credentials.
+type ObjectOpener = converters.ObjectOpener
+
+// ObjectOpenerFunc is a function implementing ObjectOpener.
+type ObjectOpenerFunc = converters.ObjectOpenerFunc
+
+// PublicObjects opens the publicly readable objects of Amazon S3, Google
+// Cloud Storage and Azure Blob Storage anonymously. It reads the objects of
+// the schemes without an opener.
+type PublicObjects = converters.PublicObjects
+
+// ArchiveLimits bounds the uncompressed size and number of members of the
+// ZIP archives behind DOCX, PPTX, XLSX and EPUB files and of ZIP archives.
+// A limit is the default one when zero, and is not checked when negative.
+type ArchiveLimits = converters.ArchiveLimits
+
+// ArchiveError is returned for archives exceeding the limits, and for paths
+// in a document that would be written outside the working directory.
+type ArchiveError = converters.ArchiveError
+
+// Errors wrapped by ArchiveError.
+var (
+	ErrTooManyEntries  = converters.ErrTooManyEntries
+	ErrEntryTooLarge   = converters.ErrEntryTooLarge
+	ErrArchiveTooLarge = converters.ErrArchiveTooLarge
+	ErrUnsafePath      = converters.ErrUnsafePath
+)
+
+// Marky converts documents with the converters registered with it, such
+// as those of formats marky does not support, written with the converter
+// package and added by RegisterConverter or Register.
+type Marky = marky.Marky
+
+// IMarky is the conversion interface of Marky, for code converting documents
+// without configuring the instance.
+type IMarky = marky.IMarky
+
+// Converter converts the documents of a format to markdown.
+type Converter = converters.Converter
+
+// ContextConverter is a Converter whose conversions stop when a context is
+// done, used by ConvertContext.
+type ContextConverter = converters.ContextConverter
+
+// BytesConverter is a Converter of documents held in memory, used by
+// ConvertBytes without touching the filesystem.
+type BytesConverter = converters.BytesConverter
+
+// WriterConverter is a Converter writing markdown as it converts a
+// document, used by ConvertTo to stream large documents.
+type WriterConverter = converters.WriterConverter
+
+// LoggingConverter is a Converter logging diagnostics, such as files that
+// could not be closed, with the logger of WithLogger.
+type LoggingConverter = converters.LoggingConverter
+
+// ProgressConverter is a Converter reporting its progress through the
+// pages, slides, sheets or chapters of documents to the function of
+// WithProgress.
+type ProgressConverter = converters.ProgressConverter
+
+// ProgressEvent reports that one more page, slide, sheet or chapter of a
+// document was converted.
+type ProgressEvent = converters.ProgressEvent
+
+// MetadataReader is a Converter reading the title, author and dates of
+// documents for the front matter added by WithFrontMatter.
+type MetadataReader = converters.MetadataReader
+
+// DocumentMetadata holds the descriptive properties of a document.
+type DocumentMetadata = converters.DocumentMetadata
+
+// Capabilities describes what a converter can extract from documents.
+type Capabilities = converters.Capabilities
+
+// ErrEncryptedDocument is returned for password-protected documents
+// converted without their password, or with a wrong one.
+var ErrEncryptedDocument = converters.ErrEncryptedDocument
+
+// ErrCorruptDocument is returned for documents whose content cannot be
+// read, such as truncated packages or malformed XML, JSON or CSV.
+var ErrCorruptDocument = converters.ErrCorruptDocument
+
+// ErrDiskWrite is returned for conversions needing to write to disk by the
+// instances created with WithInMemory.
+var ErrDiskWrite = converters.ErrDiskWrite
+
+// FormatOptions configures the converter of one format, with Configure.
+type FormatOptions = converters.FormatOptions
+
+// Options of the converter of each format. Applying options replaces every
+// setting of the converter, so zero fields restore the defaults.
+type (
+	PDFOptions      = converters.PDFOptions
+	ExcelOptions    = converters.ExcelOptions
+	CSVOptions      = converters.CSVOptions
+	DocxOptions     = converters.DocxOptions
+	PPTXOptions     = converters.PPTXOptions
+	EPUBOptions     = converters.EPUBOptions
+	HTMLOptions     = converters.HTMLOptions
+	NotebookOptions = converters.NotebookOptions
+)
+
+// ContainerLimits bounds the conversion of the attachments of emails and
+// the files of ZIP archives, applied to both converters with Configure.
+type ContainerLimits = converters.ContainerLimits
+
+// ConvertOptions holds the settings of paged formats, embedded in the
+// options of PDF, DOCX, PPTX, EPUB, HTML and notebook files.
+type ConvertOptions = converters.ConvertOptions
+
+// OptionField names fields of ConvertOptions, listed in its Set field to
+// apply them even when zero.
+type OptionField = converters.OptionField
+
+// Fields of ConvertOptions.
+const (
+	FieldKeepDataURIs  = converters.FieldKeepDataURIs
+	FieldSlides        = converters.FieldSlides
+	FieldPages         = converters.FieldPages
+	FieldPageMarkers   = converters.FieldPageMarkers
+	FieldMarker        = converters.FieldMarker
+	FieldProvenance    = converters.FieldProvenance
+	FieldChapters      = converters.FieldChapters
+	FieldPassword      = converters.FieldPassword
+	FieldPartial       = converters.FieldPartial
+	FieldImages        = converters.FieldImages
+	FieldImageDir      = converters.FieldImageDir
+	FieldInMemory      = converters.FieldInMemory
+	FieldMmapThreshold = converters.FieldMmapThreshold
+	FieldArchiveLimits = converters.FieldArchiveLimits
+	FieldTables        = converters.FieldTables
+)
+
+// Marker templates for slides, PDF pages and sheets, set with the Marker
+// option of PDFOptions, PPTXOptions and ExcelOptions. {n} stands for the
+// number of the slide, page or sheet and {name} for the name of the sheet,
+// or "Slide N" and "Page N".
+const (
+	MarkerHeading = converters.MarkerHeading
+	MarkerRule    = converters.MarkerRule
+	MarkerComment = converters.MarkerComment
+)
+
+// PdfEngine extracts the text of PDF files, and OCREngine recognizes the
+// text of scanned pages.
+type (
+	PdfEngine = converters.PdfEngine
+	OCREngine = converters.OCREngine
+	OCRResult = converters.OCRResult
+)
+
+// ImagePolicy controls how converters handle the images a document refers to.
+type ImagePolicy = converters.ImagePolicy
+
+const (
+	ImagesLink     = converters.ImagesLink
+	ImagesInline   = converters.ImagesInline
+	ImagesDownload = converters.ImagesDownload
+	ImagesDrop     = converters.ImagesDrop
+)
+
+// HeaderMode controls which row of a CSV file becomes the table header.
+type HeaderMode = converters.HeaderMode
+
+const (
+	HeaderDetect   = converters.HeaderDetect
+	HeaderFirstRow = converters.HeaderFirstRow
+	HeaderNone     = converters.HeaderNone
+)
+
+// ValueMode controls how Excel cell values are written, and FormulaMode how
+// formula cells are.
+type (
+	ValueMode   = converters.ValueMode
+	FormulaMode = converters.FormulaMode
+)
+
+const (
+	ValuesFormatted = converters.ValuesFormatted
+	ValuesISODates  = converters.ValuesISODates
+	ValuesRaw       = converters.ValuesRaw
+
+	FormulasValue = converters.FormulasValue
+	FormulasText  = converters.FormulasText
+	FormulasBoth  = converters.FormulasBoth
 )
 
-// Creates a new marky instance with all available loaders registered.
-func New() marky.IMarky {
-	m := &marky.Marky{
-		Converters: make([]converters.Converter, 0, 8),
+// TOCMode controls how DOCX table of contents fields are converted.
+type TOCMode = converters.TOCMode
+
+const (
+	TOCRegenerate = converters.TOCRegenerate
+	TOCStrip      = converters.TOCStrip
+)
+
+// Flavor selects the markdown dialect of formatting without a CommonMark
+// equivalent, such as underline or highlight.
+type Flavor = converters.Flavor
+
+const (
+	FlavorGFM      = converters.FlavorGFM
+	FlavorExtended = converters.FlavorExtended
+)
+
+// ErrFileTooLarge is returned for files larger than the size set with
+// WithMaxFileSize.
+var ErrFileTooLarge = marky.ErrFileTooLarge
+
+// ErrUnknownConverter is returned for names no converter is registered
+// under, such as by Replace and Remove.
+var ErrUnknownConverter = marky.ErrUnknownConverter
+
+// Registration is a converter registered with its name and priority.
+type Registration = marky.Registration
+
+// Option configures the instances created by New, returning an error when
+// it cannot be applied.
+type Option func(m *Marky) error
+
+// WithConvertOptions shares options, such as the image policy, between the
+// converters of PDF, DOCX, PPTX, EPUB, HTML and notebook files, and those
+// registered later. Zero fields are applied when listed in the Set field of
+// the options, so that later options can turn settings off.
+func WithConvertOptions(options ConvertOptions) Option {
+	return func(m *Marky) error {
+		m.Share(options)
+		return nil
+	}
+}
+
+// WithFormatOptions configures the converters of formats, as Configure does.
+// New returns an error for options of formats without a converter.
+func WithFormatOptions(options ...FormatOptions) Option {
+	return func(m *Marky) error {
+		return m.Configure(options...)
+	}
+}
+
+// WithFlavor sets the markdown dialect of formatting without a CommonMark
+// equivalent, such as underline or highlight, FlavorGFM by default.
+func WithFlavor(flavor Flavor) Option {
+	return func(m *Marky) error {
+		for _, c := range m.Converters {
+			if d, ok := c.(*converters.DocConverter); ok {
+				d.Flavor = flavor
+			}
+		}
+		return nil
+	}
+}
+
+// WithMaxFileSize makes files larger than size bytes fail with
+// ErrFileTooLarge instead of being converted.
+func WithMaxFileSize(size int64) Option {
+	return func(m *Marky) error {
+		m.MaxFileSize = size
+		return nil
+	}
+}
+
+// WithHeadingLevels sets the offset and maximum depth of the headings of the
+// converted documents.
+func WithHeadingLevels(offset, maxDepth int) Option {
+	return func(m *Marky) error {
+		m.SetHeadingLevels(offset, maxDepth)
+		return nil
+	}
+}
+
+// WithOutputFormat sets how the converted documents are rendered.
+func WithOutputFormat(format OutputFormat) Option {
+	return func(m *Marky) error {
+		m.SetOutputFormat(format)
+		return nil
+	}
+}
+
+// WithSummarizer sets the summarizer of the converted documents.
+func WithSummarizer(summarizer Summarizer) Option {
+	return func(m *Marky) error {
+		m.SetSummarizer(summarizer)
+		return nil
+	}
+}
+
+// WithPostProcessor adds a post-processor run on the markdown of the
+// converted documents, after those added before it.
+func WithPostProcessor(processor PostProcessor) Option {
+	return func(m *Marky) error {
+		m.AddPostProcessor(processor)
+		return nil
+	}
+}
+
+// WithFrontMatter sets whether the converted documents start with a YAML
+// front matter block of their title, author, creation and modification
+// dates, source filename and format.
+func WithFrontMatter(enabled bool) Option {
+	return func(m *Marky) error {
+		m.SetFrontMatter(enabled)
+		return nil
+	}
+}
+
+// WithLogger sets the logger of the diagnostics of the converters, such as
+// files that could not be closed, which go to slog.Default otherwise.
+func WithLogger(logger *slog.Logger) Option {
+	return func(m *Marky) error {
+		m.SetLogger(logger)
+		return nil
+	}
+}
+
+// WithProgress sets the function called after each page of PDF files,
+// slide of presentations, sheet of workbooks and chapter of EPUB books, so
+// that frontends can render progress bars for long documents.
+func WithProgress(progress func(ProgressEvent)) Option {
+	return func(m *Marky) error {
+		m.SetProgress(progress)
+		return nil
+	}
+}
+
+// WithDetectionHook sets the hook called before the MIME type of each file
+// is detected.
+func WithDetectionHook(hook DetectionHook) Option {
+	return func(m *Marky) error {
+		m.SetDetectionHook(hook)
+		return nil
+	}
+}
+
+// WithInMemory makes the converters work without writing to disk, for
+// read-only filesystems such as those of serverless functions, by setting
+// the InMemory field of their ConvertOptions, kept when their format
+// options are set. Downloading images and the
+// mutool engine then fail with ErrDiskWrite, or keep links to the images in
+// HTML and EPUB files. Converters write nothing by default either, unless
+// images are downloaded.
+func WithInMemory() Option {
+	return func(m *Marky) error {
+		m.Share(ConvertOptions{InMemory: true})
+		return nil
+	}
+}
+
+// WithMmapThreshold sets the size in bytes from which PDF files and office
+// packages are mapped into memory rather than read, 64 MiB by default, to
+// lower the peak memory use of converting very large files, by setting the
+// MmapThreshold field of the ConvertOptions of the converters. Files are
+// always read when size is negative.
+func WithMmapThreshold(size int64) Option {
+	return func(m *Marky) error {
+		m.Share(ConvertOptions{MmapThreshold: size})
+		return nil
+	}
+}
+
+// WithObjectOpener reads the objects of a URI scheme, "s3", "gs" or "az",
+// with an opener, such as one using the SDK of the store so that private
+// objects are read with its credentials. Objects of schemes without an
+// opener are read anonymously.
+func WithObjectOpener(scheme string, opener ObjectOpener) Option {
+	return func(m *Marky) error {
+		if m.Objects == nil {
+			m.Objects = make(map[string]converters.ObjectOpener)
+		}
+		m.Objects[scheme] = opener
+		return nil
+	}
+}
+
+// WithArchiveLimits sets the limits the converters check archives against,
+// by setting the ArchiveLimits field of their ConvertOptions. The limits
+// left zero keep their defaults.
+func WithArchiveLimits(limits ArchiveLimits) Option {
+	return func(m *Marky) error {
+		m.Share(ConvertOptions{ArchiveLimits: limits})
+		return nil
+	}
+}
+
+// WithTableStyle sets the style of the tables written by the converters,
+// TableCompact by default.
+func WithTableStyle(style TableStyle) Option {
+	return func(m *Marky) error {
+		m.Share(ConvertOptions{Tables: utils.TableOptions{Style: style}})
+		return nil
+	}
+}
+
+// WithHTMLTableFallback makes the converters write tables as HTML <table>
+// elements when a cell holds line breaks or a code fence, or is more than
+// width characters wide when width is positive. Pipe tables cannot hold
+// such cells without breaking their layout.
+func WithHTMLTableFallback(width int) Option {
+	return func(m *Marky) error {
+		m.Share(ConvertOptions{Tables: utils.TableOptions{HTMLFallback: true, HTMLFallbackWidth: width}})
+		return nil
+	}
+}
+
+// WithMaxCellWidth makes the converters cut table cells wider than width
+// characters, ending them with an ellipsis. Cells are not cut when width is
+// zero, the default.
+func WithMaxCellWidth(width int) Option {
+	return func(m *Marky) error {
+		m.Share(ConvertOptions{Tables: utils.TableOptions{MaxCellWidth: width}})
+		return nil
 	}
+}
 
-	m.RegisterConverter(converters.NewCsvConverter())
-	m.RegisterConverter(converters.NewDocConverter())
-	m.RegisterConverter(converters.NewEpubConverter())
-	m.RegisterConverter(converters.NewExcelConverter())
-	m.RegisterConverter(converters.NewHTMLConverter())
-	m.RegisterConverter(converters.NewIpynbConverter())
-	m.RegisterConverter(converters.NewPdfConverter())
-	m.RegisterConverter(converters.NewPptxConverter())
+// WithConverter registers a converter after the built-in ones, such as one
+// for a format marky does not support.
+func WithConverter(converter Converter) Option {
+	return func(m *Marky) error {
+		m.RegisterConverter(converter)
+		return nil
+	}
+}
 
-	return m
+// WithReplacedConverter swaps out the built-in converter registered under a
+// name, such as "pdf", for another one. New returns ErrUnknownConverter
+// for names without a converter.
+func WithReplacedConverter(name string, converter Converter) Option {
+	return func(m *Marky) error {
+		return m.Replace(name, converter)
+	}
+}
+
+// WithPriority changes the priority of the converter registered under a
+// name, so that it is tried before the converters of lower priority for
+// the files they both accept. Built-in converters have priority 0. New
+// returns ErrUnknownConverter for names without a converter.
+func WithPriority(name string, priority int) Option {
+	return func(m *Marky) error {
+		return m.SetPriority(name, priority)
+	}
+}
+
+// New creates a marky instance with all available converters registered,
+// configured by the options in order. The converters are registered under the
+// names csv, docx, eml, epub, excel, html, ipynb, json, pdf, pptx and zip.
+// It returns the error of the first option that cannot be applied.
+func New(options ...Option) (*Marky, error) {
+	m := &marky.Marky{}
+
+	m.Register("csv", converters.NewCsvConverter(), 0)
+	m.Register("docx", converters.NewDocConverter(), 0)
+	m.Register("eml", converters.NewEmlConverter(), 0)
+	m.Register("epub", converters.NewEpubConverter(), 0)
+	m.Register("excel", converters.NewExcelConverter(), 0)
+	m.Register("html", converters.NewHTMLConverter(), 0)
+	m.Register("ipynb", converters.NewIpynbConverter(), 0)
+	m.Register("json", converters.NewJSONConverter(), 0)
+	m.Register("pdf", converters.NewPdfConverter(), 0)
+	m.Register("pptx", converters.NewPptxConverter(), 0)
+	m.Register("zip", converters.NewZipConverter(), 0)
+
+	for _, option := range options {
+		if err := option(m); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
 }
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"os"
@@ -24,7 +25,7 @@ func main() {
 		mcp.WithDescription("Convert a file to markdown format"),
 		mcp.WithString("input",
 			mcp.Required(),
-			mcp.Description("Path to the input file to convert to markdown"),
+			mcp.Description("Path to the input file, http(s) URL of a web page, or s3://, gs:// or az:// URI of a stored object, to convert to markdown"),
 		),
 		mcp.WithString("output",
 			mcp.Description("Path to the output markdown file"),
@@ -34,6 +35,10 @@ func main() {
 	// Add tool handler
 	s.AddTool(tool, convertToMarkdown)
 
+	s.AddTool(mcp.NewTool("list_formats",
+		mcp.WithDescription("List the supported file formats, with whether their converter writes images, tables and metadata, streams documents and opens password-protected files"),
+	), listFormats)
+
 	// Start the stdio server
 	if err := server.ServeStdio(s); err != nil {
 		log.Printf("Server error: %v\n", err)
@@ -48,8 +53,15 @@ func convertToMarkdown(ctx context.Context, request mcp.CallToolRequest) (*mcp.C
 
 	outputFile := request.GetString("output", "console")
 
-	m := marky.New()
-	result, err := m.Convert(inputFile)
+	var options []marky.Option
+	if request.Params.Meta != nil && request.Params.Meta.ProgressToken != nil {
+		options = append(options, marky.WithProgress(notifyProgress(ctx, request.Params.Meta.ProgressToken)))
+	}
+	m, err := marky.New(options...)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to configure the conversion: %v", err)), nil
+	}
+	result, err := m.ConvertContext(ctx, inputFile)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to convert file: %v", err)), nil
 	}
@@ -62,3 +74,48 @@ func convertToMarkdown(ctx context.Context, request mcp.CallToolRequest) (*mcp.C
 
 	return mcp.NewToolResultText(result), nil
 }
+
+// notifyProgress returns a progress function sending the progress of a
+// conversion to the client as notifications for the request of token.
+func notifyProgress(ctx context.Context, token mcp.ProgressToken) func(marky.ProgressEvent) {
+	s := server.ServerFromContext(ctx)
+	return func(event marky.ProgressEvent) {
+		if s == nil {
+			return
+		}
+		if err := s.SendNotificationToClient(ctx, "notifications/progress", map[string]any{
+			"progressToken": token,
+			"progress":      event.Done,
+			"total":         event.Total,
+			"message":       fmt.Sprintf("%s %d/%d", event.Unit, event.Done, event.Total),
+		}); err != nil {
+			log.Printf("Failed to send progress: %v\n", err)
+		}
+	}
+}
+
+// format is a converted format as listed by the list_formats tool.
+type format struct {
+	Name         string             `json:"name"`
+	Extensions   []string           `json:"extensions"`
+	MimeTypes    []string           `json:"mime_types"`
+	Capabilities marky.Capabilities `json:"capabilities"`
+}
+
+func listFormats(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	m, err := marky.New()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to list formats: %v", err)), nil
+	}
+	var formats []format
+	for _, r := range m.Registrations() {
+		c := r.Converter
+		formats = append(formats, format{r.Name, c.AcceptedExtensions(), c.AcceptedMimeTypes(), c.Capabilities()})
+	}
+
+	result, err := json.MarshalIndent(formats, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to list formats: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(result)), nil
+}
@@ -0,0 +1,51 @@
+// Package worker runs batches of conversions taken from a queue, as the
+// --spool mode of the marky command does. Jobs are kept in a Spool
+// directory, or in any other store, such as a message broker, implementing
+// Queue:
+//
+//	w := &worker.Worker{Queue: queue, Converter: marky.New()}
+//	err := w.Run(ctx)
+//
+// The markdown of each job is written to its output path, and its status is
+// recorded with Queue.Finish once it is converted or has failed MaxAttempts
+// times.
+package worker
+
+import "github.com/flaviodelgrosso/marky/internal/worker"
+
+// ErrEmpty is returned by Queue.Next when no job is waiting.
+var ErrEmpty = worker.ErrEmpty
+
+// Job is a document to convert.
+type Job = worker.Job
+
+// Status records the outcome of a job.
+type Status = worker.Status
+
+// Queue holds the jobs of a worker. Implementations hand each job to one
+// worker at a time, and put it back when the worker stops before finishing
+// it.
+type Queue = worker.Queue
+
+// Converter converts the document at a path to markdown. The instances of
+// marky.New implement it.
+type Converter = worker.Converter
+
+// ContextConverter is a Converter whose conversions stop when the context
+// of Worker.Run is done, such as the instances of marky.New.
+type ContextConverter = worker.ContextConverter
+
+// Worker converts the jobs of a queue.
+type Worker = worker.Worker
+
+// Fingerprints remembers the content of the converted documents, to flag
+// the jobs duplicating one converted before them.
+type Fingerprints = worker.Fingerprints
+
+// Spool is a Queue kept in a directory, so that jobs survive restarts.
+type Spool = worker.Spool
+
+// OpenSpool creates the directories of a spool in dir when they are missing.
+func OpenSpool(dir string) (*Spool, error) {
+	return worker.OpenSpool(dir)
+}
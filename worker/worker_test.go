@@ -0,0 +1,60 @@
+package worker_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/flaviodelgrosso/marky"
+	"github.com/flaviodelgrosso/marky/worker"
+)
+
+// sliceQueue is a Queue of other modules, holding its jobs in memory.
+type sliceQueue struct {
+	jobs     []worker.Job
+	finished []worker.Status
+}
+
+func (q *sliceQueue) Next(context.Context) (worker.Job, error) {
+	if len(q.jobs) == 0 {
+		return worker.Job{}, worker.ErrEmpty
+	}
+	job := q.jobs[0]
+	q.jobs = q.jobs[1:]
+	return job, nil
+}
+
+func (q *sliceQueue) Retry(job worker.Job) error {
+	q.jobs = append(q.jobs, job)
+	return nil
+}
+
+func (q *sliceQueue) Finish(status worker.Status) error {
+	q.finished = append(q.finished, status)
+	return nil
+}
+
+func TestWorker_CustomQueue(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "data.csv")
+	if err := os.WriteFile(input, []byte("name,age\nAda,36\n"), 0o644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	queue := &sliceQueue{jobs: []worker.Job{{ID: "1", Input: input}}}
+	m, err := marky.New()
+	if err != nil {
+		t.Fatalf("New() returned unexpected error: %v", err)
+	}
+	w := &worker.Worker{Queue: queue, Converter: m}
+	if err := w.Run(context.Background()); err != nil {
+		t.Fatalf("Run() returned unexpected error: %v", err)
+	}
+	if len(queue.finished) != 1 || queue.finished[0].Error != "" {
+		t.Fatalf("finished jobs = %+v, want one succeeded job", queue.finished)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "data.md")); err != nil {
+		t.Errorf("the markdown of the job was not written: %v", err)
+	}
+}